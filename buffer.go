@@ -1,18 +1,31 @@
 package wap
 
 import (
+	"encoding/binary"
 	"github.com/moontrade/nogc"
 	"unsafe"
 )
 
 type VPointer int32
 
+// Deref returns the pointer vp points to. It reads the offset in native byte order so
+// it is only right on little-endian hosts.
 func (vp *VPointer) Deref() unsafe.Pointer {
 	return unsafe.Add(unsafe.Pointer(vp), *vp)
 }
 
 func (vp *VPointer) deref() nogc.Pointer {
-	return nogc.Pointer(uintptr(int64(uintptr(unsafe.Pointer(vp))) + int64(*vp)))
+	return nogc.Pointer(uintptr(int64(uintptr(unsafe.Pointer(vp))) + int64(vp.offset())))
+}
+
+// offset returns the offset stored in vp. The Builder stores it little-endian like the
+// rest of the wire format so the big-endian generated code can decode it on any host.
+func (vp *VPointer) offset() int32 {
+	return nogc.Pointer(unsafe.Pointer(vp)).Int32LE(0)
+}
+
+func (vp *VPointer) setOffset(offset int32) {
+	nogc.Pointer(unsafe.Pointer(vp)).SetInt32LE(0, offset)
 }
 
 func Str(p *VPointer) string {
@@ -46,6 +59,48 @@ func Slice(p *VPointer) unsafe.Pointer {
 	return nogc.Pointer(p.Deref()).Unsafe()
 }
 
+// SliceHeader returns the size of the length header in front of the elements of a
+// slice written by Mutable.WriteSlice with the given element alignment. The header
+// is padded to the alignment so the elements following it are aligned.
+func SliceHeader(align int32) int32 {
+	if align > 4 {
+		return align
+	}
+	return 4
+}
+
+// AppendVPointer appends the length prefixed data vp points to onto b and points the
+// VPointer at offset at in b to the copy. align is the alignment of the data, 1 for
+// strings and bytes, and start is the offset in b the copy is aligned relative to.
+// Both the offset and the length are read little-endian so it works on any host.
+func AppendVPointer(b []byte, start, at int, vp *VPointer, align int32) []byte {
+	offset := int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(vp))[:]))
+	if offset == 0 {
+		binary.LittleEndian.PutUint32(b[at:], 0)
+		return b
+	}
+	ptr := nogc.Pointer(unsafe.Add(unsafe.Pointer(vp), offset))
+	size := int(SliceHeader(align)) + int(ptr.Int32LE(0))
+	for (len(b)-start)%int(align) != 0 {
+		b = append(b, 0)
+	}
+	target := len(b)
+	b = append(b, ptr.Bytes(0, size, size)...)
+	binary.LittleEndian.PutUint32(b[at:], uint32(target-at))
+	return b
+}
+
+// SlabLE returns the data p points to, which follows a length header of header bytes,
+// and its length. Unlike Slab it decodes the offset as little-endian, the byte order of
+// the wire format, so it is right on any host. The big-endian generated code uses it.
+func SlabLE(p *VPointer, header int32) (unsafe.Pointer, int32) {
+	if p == nil || *p == 0 {
+		return nil, 0
+	}
+	ptr := p.deref()
+	return (ptr + nogc.Pointer(header)).Unsafe(), ptr.Int32LE(0)
+}
+
 func Slab(p *VPointer) (unsafe.Pointer, int32) {
 	if p == nil || *p == 0 {
 		return nil, 0
@@ -25,7 +25,7 @@ func (b *Mutable) Free(vp *VPointer) {
 	if vp == nil || *vp == 0 {
 		return
 	}
-	length := vp.Len()
+	length := vp.deref().Int32LE(0)
 	b.trash += length
 	*vp = 0
 }
@@ -56,9 +56,9 @@ func (b *Mutable) SliceAlloc(vp *VPointer, size int32) Mutable {
 		if *vp == 0 {
 			return Mutable{}
 		}
-		return Mutable{b.Builder, offset + int32(*vp)}
+		return Mutable{b.Builder, offset + vp.offset()}
 	} else {
-		return Mutable{b.Builder, b.VPointerOffset(vp) + int32(*vp)}
+		return Mutable{b.Builder, b.VPointerOffset(vp) + vp.offset()}
 	}
 }
 
@@ -68,6 +68,10 @@ type _string struct {
 }
 
 func (b *Mutable) WStr(existing *VPointer, value string) {
+	if len(value) == 0 {
+		b.Free(existing)
+		return
+	}
 	val := *(*_string)(unsafe.Pointer(&value))
 	b.writeString(existing, val.Data, int32(len(value)))
 }
@@ -76,6 +80,29 @@ func (b *Mutable) WriteBytes(existing *VPointer, value []byte) {
 	b.writeBytes(existing, unsafe.Pointer(&value[0]), int32(len(value)), int32(len(value)))
 }
 
+// WBytes writes value using the same length prefixed layout as WStr so
+// it can be read back with VPointer.Bytes.
+func (b *Mutable) WBytes(existing *VPointer, value []byte) {
+	if len(value) == 0 {
+		b.Free(existing)
+		return
+	}
+	b.writeString(existing, unsafe.Pointer(&value[0]), int32(len(value)))
+}
+
+// WriteSlice writes size bytes starting at data as a length prefixed slice. It is
+// used for variable length lists where the length header holds the size in bytes.
+// align is the alignment of the elements. The slice starts at a multiple of align
+// in the buffer and its header is SliceHeader(align) bytes so the elements are
+// aligned too.
+func (b *Mutable) WriteSlice(existing *VPointer, data unsafe.Pointer, size, align int32) {
+	if size == 0 {
+		b.Free(existing)
+		return
+	}
+	b.writeAligned(existing, data, size, align)
+}
+
 type Builder struct {
 	ptr      nogc.Pointer
 	len      int32
@@ -236,9 +263,9 @@ func (b *Builder) alloc(vp *VPointer, size int32) (*VPointer, nogc.Pointer, bool
 		b.cap = int32(c)
 		extended = true
 		vp = (*VPointer)((b.ptr + nogc.Pointer(offset)).Unsafe())
-		*vp = value
+		vp.setOffset(int32(value))
 	} else {
-		*vp = value
+		vp.setOffset(int32(value))
 	}
 	r := b.ptr + nogc.Pointer(b.len)
 	b.len = newLen
@@ -253,8 +280,8 @@ func (b *Builder) deleteSlice(existing *VPointer, size int32) {
 }
 
 func (b *Builder) writeSlice(vp *VPointer, data unsafe.Pointer, size int32) bool {
-	if *vp > 0 {
-		nogc.Copy(vp.Deref(), data, uintptr(size))
+	if vp.offset() > 0 {
+		nogc.Copy(vp.deref().Unsafe(), data, uintptr(size))
 		return false
 	}
 
@@ -276,7 +303,7 @@ func (b *Builder) deleteString(existing *VPointer) {
 }
 
 func (b *Builder) writeString(vp *VPointer, data unsafe.Pointer, size int32) bool {
-	if *vp > 0 {
+	if vp.offset() > 0 {
 		ptr := vp.deref()
 		prevSize := ptr.Int32LE(0)
 		if prevSize >= size+4 {
@@ -297,6 +324,34 @@ func (b *Builder) writeString(vp *VPointer, data unsafe.Pointer, size int32) boo
 	return extended
 }
 
+func (b *Builder) writeAligned(vp *VPointer, data unsafe.Pointer, size, align int32) bool {
+	header := SliceHeader(align)
+	if vp.offset() > 0 {
+		ptr := vp.deref()
+		prevSize := ptr.Int32LE(0)
+		if prevSize >= size {
+			b.trash += prevSize - size
+			ptr.SetInt32LE(0, size)
+			nogc.Copy((ptr + nogc.Pointer(header)).Unsafe(), data, uintptr(size))
+			return false
+		}
+		b.trash += prevSize + header
+	}
+
+	pad := (align - b.len%align) % align
+	vp, ptr, extended := b.alloc(vp, pad+header+size)
+	if *vp == 0 || b.ptr == 0 {
+		panic(ErrOutOfMemory)
+	}
+	vp.setOffset(vp.offset() + pad)
+	b.trash += pad
+	nogc.Zero(ptr.Unsafe(), uintptr(pad+header))
+	ptr += nogc.Pointer(pad)
+	ptr.SetInt32LE(0, size)
+	nogc.Copy((ptr + nogc.Pointer(header)).Unsafe(), data, uintptr(size))
+	return extended
+}
+
 func (b *Builder) deleteBytes(vp *VPointer) {
 	if vp == nil || *vp == 0 {
 		return
@@ -306,7 +361,7 @@ func (b *Builder) deleteBytes(vp *VPointer) {
 }
 
 func (b *Builder) writeBytes(existing *VPointer, data unsafe.Pointer, length, size int32) bool {
-	if *existing > 0 {
+	if existing.offset() > 0 {
 		ptr := existing.deref()
 		prevSize := ptr.Int32LE(0)
 		if prevSize >= size+8 {
@@ -0,0 +1,54 @@
+package wap
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestMutableVariable(t *testing.T) {
+	type record struct {
+		name  VPointer
+		data  VPointer
+		items VPointer
+	}
+	b := NewBuilder()
+	m := b.New(int32(unsafe.Sizeof(record{})), 16)
+	r := (*record)(m.Unsafe())
+
+	m.WStr(&r.name, "hello")
+	r = (*record)(m.Unsafe())
+	m.WBytes(&r.data, []byte{1, 2})
+	r = (*record)(m.Unsafe())
+	items := []int64{7, 8, 9}
+	m.WriteSlice(&r.items, unsafe.Pointer(&items[0]), int32(len(items)*8), 8)
+	r = (*record)(m.Unsafe())
+
+	if s := r.name.Str(); s != "hello" {
+		t.Fatalf("name = %s, expected = hello", s)
+	}
+	if d := r.data.Bytes(); len(d) != 2 || d[1] != 2 {
+		t.Fatalf("data = %v, expected = [1 2]", d)
+	}
+	p, n := r.items.Slab()
+	if n != 24 {
+		t.Fatalf("items size = %d, expected = 24", n)
+	}
+	// The name and data leave the end of the buffer unaligned so the items are padded.
+	data := unsafe.Add(p, SliceHeader(8))
+	if uintptr(data)%8 != 0 {
+		t.Fatalf("items at %d are not aligned", uintptr(data))
+	}
+	if v := unsafe.Slice((*int64)(data), int(n)/8); v[2] != 9 {
+		t.Fatalf("items = %v, expected = [7 8 9]", v)
+	}
+	b2 := unsafe.Slice((*byte)(m.Unsafe()), m.Len())
+	if off, size, err := VerifyVSlice(b2, int(unsafe.Offsetof(r.items)), 8); err != nil ||
+		unsafe.Pointer(&b2[off]) != data || size != 24 {
+		t.Fatalf("off = %d, size = %d, err = %v", off, size, err)
+	}
+
+	m.WStr(&r.name, "")
+	if r.name != 0 || r.name.Str() != "" {
+		t.Fatal("expected empty string to free pointer")
+	}
+}
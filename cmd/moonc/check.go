@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/moontrade/proto/schema"
+)
+
+// runCheck parses and resolves the schema and reports uses of deprecated definitions.
+func runCheck(p *Project, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	s, err := p.Load()
+	if err != nil {
+		return err
+	}
+	uses := s.DeprecatedUses()
+	for _, use := range uses {
+		fmt.Fprintf(stdout, "warning: %s\n", use)
+	}
+	if p.FailOnDeprecated && len(uses) > 0 {
+		return errors.New("deprecated definitions are used")
+	}
+	fmt.Fprintf(stdout, "ok: %d files\n", len(s.Files))
+	return nil
+}
+
+// runLayout prints the layout report of the named structs or of every struct when no
+// names are given, as tables or as a JSON array with -json.
+func runLayout(p *Project, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("layout", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the layouts as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	s, err := p.Load()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for _, name := range flags.Args() {
+		names[name] = true
+	}
+	paths := make([]string, 0, len(s.Files))
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	layouts := make([]*schema.Layout, 0)
+	for _, path := range paths {
+		for _, st := range s.Files[path].Structs {
+			if len(names) > 0 && !names[st.Name] {
+				continue
+			}
+			layout, err := st.Layout()
+			if err != nil {
+				return err
+			}
+			layouts = append(layouts, layout)
+		}
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(layouts, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", data)
+		return err
+	}
+	for _, layout := range layouts {
+		if err = layout.WriteText(stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/moontrade/proto/schema"
+)
+
+// errUnformatted is returned by fmt -check when a schema file is not formatted.
+var errUnformatted = errors.New("schema files are not formatted")
+
+// runFmt rewrites the named schema files, or every schema file of the project, in the
+// canonical format. With -check the files are not written and the unformatted ones are
+// listed instead.
+func runFmt(p *Project, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "list unformatted files and fail instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		var err error
+		if paths, err = schemaFiles(p.Abs(p.Schema)); err != nil {
+			return err
+		}
+	}
+
+	unformatted := false
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := schema.FormatSource(path, src)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+		fmt.Fprintln(stdout, p.Rel(path))
+		if *check {
+			unformatted = true
+			continue
+		}
+		if err = os.WriteFile(path, formatted, 0644); err != nil {
+			return err
+		}
+	}
+	if unformatted {
+		return errUnformatted
+	}
+	return nil
+}
+
+// schemaFiles returns the schema files under dir, or dir itself when it is a file.
+func schemaFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && schema.IsSchemaFile(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
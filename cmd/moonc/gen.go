@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/moontrade/proto/compile"
+	"github.com/moontrade/proto/compile/as"
+	"github.com/moontrade/proto/compile/c"
+	_go "github.com/moontrade/proto/compile/go"
+	"github.com/moontrade/proto/compile/jsonschema"
+	"github.com/moontrade/proto/compile/python"
+	"github.com/moontrade/proto/compile/ts"
+	"github.com/moontrade/proto/schema"
+)
+
+// errDiff is returned by diff when the generated code differs from the output directory.
+var errDiff = errors.New("generated code is out of date")
+
+// target generates code for a single language.
+type target struct {
+	name string
+	// owns reports whether a file in the output directory is generated by the target.
+	owns compile.Owns
+	// output returns the configured output directory or an error if the target is not configured.
+	output func(p *Project) (string, error)
+	// generate generates s in memory.
+	generate func(p *Project, s *schema.Schema) (compile.Files, error)
+}
+
+var targets = []*target{
+	{
+		name: "go",
+		owns: _go.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.Go == nil {
+				return "", errors.New("go target is not configured in " + p.Path)
+			}
+			return p.Abs(p.Go.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			layoutCheck, err := p.Go.layoutCheck()
+			if err != nil {
+				return nil, err
+			}
+			c, err := _go.NewCompiler(s, &_go.Config{
+				Package:          p.Go.Package,
+				Output:           p.Abs(p.Go.Output),
+				Mutable:          p.Go.Mutable,
+				BigEndian:        p.Go.BigEndian,
+				BoundsChecked:    p.Go.BoundsChecked,
+				Msgpack:          p.Go.Msgpack,
+				JSON:             p.Go.JSON,
+				FailOnDeprecated: p.FailOnDeprecated,
+				LayoutCheck:      layoutCheck,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return c.Generate()
+		},
+	},
+	{
+		name: "as",
+		owns: as.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.AS == nil {
+				return "", errors.New("as target is not configured in " + p.Path)
+			}
+			return p.Abs(p.AS.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			c, err := as.NewCompiler(s, &as.ASConfig{
+				Output:           p.Abs(p.AS.Output),
+				Mutable:          p.AS.Mutable,
+				FailOnDeprecated: p.FailOnDeprecated,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return c.Generate()
+		},
+	},
+	{
+		name: "ts",
+		owns: ts.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.TS == nil {
+				return "", errors.New("ts target is not configured in " + p.Path)
+			}
+			return p.Abs(p.TS.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			c, err := ts.NewCompiler(s, &ts.TSConfig{
+				Output:           p.Abs(p.TS.Output),
+				Mutable:          p.TS.Mutable,
+				FailOnDeprecated: p.FailOnDeprecated,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return c.Generate()
+		},
+	},
+	{
+		name: "python",
+		owns: python.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.Python == nil {
+				return "", errors.New("python target is not configured in " + p.Path)
+			}
+			return p.Abs(p.Python.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			c, err := python.NewCompiler(s, &python.PyConfig{
+				Output:           p.Abs(p.Python.Output),
+				Mutable:          p.Python.Mutable,
+				FailOnDeprecated: p.FailOnDeprecated,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return c.Generate()
+		},
+	},
+	{
+		name: "c",
+		owns: c.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.C == nil {
+				return "", errors.New("c target is not configured in " + p.Path)
+			}
+			return p.Abs(p.C.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			compiler, err := c.NewCompiler(s, &c.CConfig{
+				Output:           p.Abs(p.C.Output),
+				FailOnDeprecated: p.FailOnDeprecated,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return compiler.Generate()
+		},
+	},
+	{
+		name: "jsonschema",
+		owns: jsonschema.IsGenerated,
+		output: func(p *Project) (string, error) {
+			if p.JSONSchema == nil {
+				return "", errors.New("jsonschema target is not configured in " + p.Path)
+			}
+			return p.Abs(p.JSONSchema.Output), nil
+		},
+		generate: func(p *Project, s *schema.Schema) (compile.Files, error) {
+			c, err := jsonschema.NewCompiler(s, &jsonschema.JSONSchemaConfig{
+				Output:           p.Abs(p.JSONSchema.Output),
+				Title:            p.JSONSchema.Title,
+				Version:          p.JSONSchema.Version,
+				FailOnDeprecated: p.FailOnDeprecated,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return c.Generate()
+		},
+	},
+	// Targets the command accepts that have no compiler yet. Naming one is an error.
+	{name: "rust"},
+	{name: "proto"},
+}
+
+func findTarget(name string) (*target, error) {
+	for _, t := range targets {
+		if t.name != name {
+			continue
+		}
+		if t.generate == nil {
+			return nil, fmt.Errorf("target '%s' is not implemented: there is no %s compiler yet", name, name)
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown target '%s'", name)
+}
+
+// selectTargets returns the named targets or every configured target when names is empty.
+func selectTargets(p *Project, names []string) ([]*target, error) {
+	if len(names) == 0 {
+		if p.Go != nil {
+			names = append(names, "go")
+		}
+		if p.AS != nil {
+			names = append(names, "as")
+		}
+		if p.TS != nil {
+			names = append(names, "ts")
+		}
+		if p.Python != nil {
+			names = append(names, "python")
+		}
+		if p.C != nil {
+			names = append(names, "c")
+		}
+		if p.JSONSchema != nil {
+			names = append(names, "jsonschema")
+		}
+		if len(names) == 0 {
+			return nil, errors.New("no targets configured in " + p.Path)
+		}
+	}
+	result := make([]*target, 0, len(names))
+	for _, name := range names {
+		t, err := findTarget(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = t.output(p); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// runGen generates code for each target and writes the files that changed to its
+// output directory.
+func runGen(p *Project, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	selected, err := selectTargets(p, flags.Args())
+	if err != nil {
+		return err
+	}
+	for _, t := range selected {
+		s, err := p.Load()
+		if err != nil {
+			return err
+		}
+		files, err := t.generate(p, s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		output, _ := t.output(p)
+		changed, err := compile.Write(output, files, t.owns)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		fmt.Fprintf(stdout, "%s: %d files in %s, %d changed\n", t.name, len(files), output, len(changed))
+	}
+	return nil
+}
+
+// runDiff generates each target in memory and reports the files that differ from its
+// output directory. It returns errDiff when any file differs.
+func runDiff(p *Project, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	selected, err := selectTargets(p, flags.Args())
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, t := range selected {
+		s, err := p.Load()
+		if err != nil {
+			return err
+		}
+		files, err := t.generate(p, s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		output, _ := t.output(p)
+		diffs, err := compile.Diff(output, files, t.owns)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		for _, d := range diffs {
+			fmt.Fprintf(stdout, "%s: %s\n", t.name, d)
+		}
+		changed = changed || len(diffs) > 0
+	}
+	if changed {
+		return errDiff
+	}
+	return nil
+}
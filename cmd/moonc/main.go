@@ -0,0 +1,102 @@
+// Command moonc compiles .moon schemas.
+//
+//	moonc [-project moon.yaml] <command> [arguments]
+//
+// The commands are:
+//
+//	gen [go|as|ts|python|c|jsonschema|rust|proto]...   generate code for the targets or every configured target
+//	check                                              parse and resolve the schema and report deprecated uses
+//	fmt [-check] [file]...                             format the schema files or report the unformatted ones
+//	diff [go|as|ts|python|c|jsonschema|rust|proto]...  report generated files that are out of date
+//	layout [-json] [struct]...                         report the memory layout of structs
+//
+// The rust and proto targets are not implemented yet and naming one is an error.
+//
+// The project file is moon.yaml, moon.yml or moon.json in the working directory unless
+// -project is given. It is typically invoked from go:generate or CI:
+//
+//	//go:generate go run github.com/moontrade/proto/cmd/moonc gen go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(p *Project, args []string, stdout io.Writer) error
+}
+
+var commands = []*command{
+	{"gen", "gen [go|as|ts|python|c|jsonschema|rust|proto]...", runGen},
+	{"check", "check", runCheck},
+	{"fmt", "fmt [-check] [file]...", runFmt},
+	{"diff", "diff [go|as|ts|python|c|jsonschema|rust|proto]...", runDiff},
+	{"layout", "layout [-json] [struct]...", runLayout},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("moonc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	projectPath := flags.String("project", "", "path to the moon.yaml or moon.json project file")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: moonc [-project moon.yaml] <command> [arguments]")
+		fmt.Fprintln(stderr, "\ncommands:")
+		for _, c := range commands {
+			fmt.Fprintf(stderr, "  %s\n", c.usage)
+		}
+		fmt.Fprintln(stderr, "\nflags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var cmd *command
+	for _, c := range commands {
+		if c.name == flags.Arg(0) {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "moonc: unknown command '%s'\n", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+
+	path := *projectPath
+	if len(path) == 0 {
+		var err error
+		if path, err = FindProject("."); err != nil {
+			fmt.Fprintf(stderr, "moonc: %s\n", err)
+			return 1
+		}
+	}
+	p, err := LoadProject(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "moonc: %s\n", err)
+		return 1
+	}
+
+	if err = cmd.run(p, flags.Args()[1:], stdout); err != nil {
+		if !errors.Is(err, errDiff) {
+			fmt.Fprintf(stderr, "moonc %s: %s\n", cmd.name, err)
+		}
+		return 1
+	}
+	return 0
+}
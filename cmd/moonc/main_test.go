@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moontrade/proto/schema"
+)
+
+const testSchema = `
+@deprecated("use Trade")
+struct Fill {
+	price f64
+}
+
+struct Order {
+	id    i64
+	side  ?byte
+	fills [2]Fill
+}
+`
+
+// writeProject creates a project in a temporary directory and returns its path.
+func writeProject(t *testing.T, name, project string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "schema", "model"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schema", "model", "schema.moon"), []byte(testSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runMoonc(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestGenAndDiff(t *testing.T) {
+	path := writeProject(t, "moon.yaml", `
+schema: schema
+go:
+  package: example.com/project/gen
+  output: gen
+  mutable: true
+  layoutCheck: test
+as:
+  output: web
+ts:
+  output: ui
+python:
+  output: py
+c:
+  output: include
+jsonschema:
+  output: api
+`)
+	if code, _, stderr := runMoonc(t, "-project", path, "diff", "go"); code != 1 || len(stderr) > 0 {
+		t.Fatalf("diff before gen = %d %s", code, stderr)
+	}
+	if code, _, stderr := runMoonc(t, "-project", path, "gen"); code != 0 {
+		t.Fatalf("gen = %d %s", code, stderr)
+	}
+	dir := filepath.Dir(path)
+	for _, name := range []string{"gen/model/proto.go", "gen/model/proto_layout_test.go", "web/model/index.ts", "ui/model/index.ts", "ui/_moon.ts", "py/model/__init__.py", "py/_moon.py", "include/model.moon.h", "include/_moon.h", "api/model/schema.json", "api/openapi.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code, stdout, stderr := runMoonc(t, "-project", path, "diff"); code != 0 {
+		t.Fatalf("diff after gen = %d %s %s", code, stdout, stderr)
+	}
+
+	schemaFile := filepath.Join(dir, "schema", "model", "schema.moon")
+	if err := os.WriteFile(schemaFile, []byte(testSchema+"\nstruct Extra {\n\tid i32\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, _ := runMoonc(t, "-project", path, "diff", "go")
+	if code != 1 || stdout != "go: M model/proto.go\ngo: M model/proto_layout_test.go\n" {
+		t.Fatalf("diff after change = %d %q", code, stdout)
+	}
+
+	if code, _, stderr := runMoonc(t, "-project", path, "gen", "rust"); code != 1 || !strings.Contains(stderr, "target 'rust' is not implemented") {
+		t.Fatalf("gen rust = %d %s", code, stderr)
+	}
+	if code, _, stderr := runMoonc(t, "-project", path, "diff", "proto"); code != 1 || !strings.Contains(stderr, "target 'proto' is not implemented") {
+		t.Fatalf("diff proto = %d %s", code, stderr)
+	}
+	if code, _, stderr := runMoonc(t, "-project", path, "gen", "java"); code != 1 || !strings.Contains(stderr, "unknown target 'java'") {
+		t.Fatalf("gen java = %d %s", code, stderr)
+	}
+}
+
+func TestRelativeProject(t *testing.T) {
+	// A project beside its schema files loaded by a relative path names the package
+	// after the directory rather than ".".
+	dir := filepath.Dir(writeProject(t, "moon.yaml", "schema: schema\n"))
+	model := filepath.Join(dir, "schema", "model")
+	if err := os.WriteFile(filepath.Join(model, "moon.yaml"), []byte(`
+schema: .
+go:
+  package: example.com/project/model
+  output: .
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(model); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+	if code, _, stderr := runMoonc(t, "-project", "moon.yaml", "gen", "go"); code != 0 {
+		t.Fatalf("gen = %d %s", code, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(model, "proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "package model\n") {
+		t.Fatalf("generated package clause:\n%s", data[:200])
+	}
+}
+
+func TestCheckAndLayout(t *testing.T) {
+	path := writeProject(t, "moon.json", `{"schema": "schema", "failOnDeprecated": true}`)
+	code, stdout, stderr := runMoonc(t, "-project", path, "check")
+	if code != 1 || !strings.Contains(stdout, "field 'Order.fills' uses deprecated struct 'Fill': use Trade") {
+		t.Fatalf("check = %d %s %s", code, stdout, stderr)
+	}
+
+	code, stdout, stderr = runMoonc(t, "-project", path, "layout", "Order")
+	if code != 0 {
+		t.Fatalf("layout = %d %s", code, stderr)
+	}
+	for _, expected := range []string{
+		"model/schema.moon Order size 48 align 8 padding 14 cache lines 1",
+		"0       1     1      0     (optionals)",
+		"1       7     1      0     (padding)",
+		"8       8     8      0     id           I64",
+		"16      1     1      0     side         ?U8",
+		"24      24    8      0     fills        Fill2List",
+		"suggested order: side, id, fills (size 40, saves 8)",
+	} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("layout missing %q\n%s", expected, stdout)
+		}
+	}
+	if strings.Contains(stdout, "Fill ") {
+		t.Fatalf("layout should only print Order\n%s", stdout)
+	}
+
+	code, stdout, stderr = runMoonc(t, "-project", path, "layout", "-json", "Fill")
+	if code != 0 {
+		t.Fatalf("layout -json = %d %s", code, stderr)
+	}
+	var layouts []*schema.Layout
+	if err := json.Unmarshal([]byte(stdout), &layouts); err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != 1 || layouts[0].Struct != "Fill" || layouts[0].Size != 8 || layouts[0].Fields[0].Name != "price" {
+		t.Fatalf("layout -json = %s", stdout)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	path := writeProject(t, "moon.yaml", "schema: schema\n")
+	schemaFile := filepath.Join(filepath.Dir(path), "schema", "model", "schema.moon")
+
+	code, stdout, _ := runMoonc(t, "-project", path, "fmt", "-check")
+	if code != 1 || stdout != filepath.Join("schema", "model", "schema.moon")+"\n" {
+		t.Fatalf("fmt -check = %d %q", code, stdout)
+	}
+	if data, _ := os.ReadFile(schemaFile); string(data) != testSchema {
+		t.Fatal("fmt -check should not write files")
+	}
+	if code, _, stderr := runMoonc(t, "-project", path, "fmt"); code != 0 {
+		t.Fatalf("fmt = %d %s", code, stderr)
+	}
+	data, err := os.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "@deprecated(\"use Trade\")\nstruct Fill {\n\tprice f64\n}\n") {
+		t.Fatalf("formatted:\n%s", data)
+	}
+	if code, stdout, _ = runMoonc(t, "-project", path, "fmt", "-check", schemaFile); code != 0 || len(stdout) > 0 {
+		t.Fatalf("fmt -check after fmt = %d %q", code, stdout)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	_go "github.com/moontrade/proto/compile/go"
+	"github.com/moontrade/proto/schema"
+)
+
+// ProjectFileNames are the project file names searched for in order.
+var ProjectFileNames = []string{"moon.yaml", "moon.yml", "moon.json"}
+
+// Project describes where the schema lives and where each target is generated.
+// Paths are relative to the directory holding the project file.
+//
+//	schema: schema
+//	failOnDeprecated: true
+//	go:
+//	  package: github.com/acme/markets/model
+//	  output: model
+//	  mutable: true
+//	as:
+//	  output: web/src/model
+//	ts:
+//	  output: ui/src/model
+//	python:
+//	  output: research/model
+//	c:
+//	  output: gateway/include
+//	jsonschema:
+//	  output: gateway/schema
+//	  title: markets
+type Project struct {
+	// Schema is the directory or file holding the .moon files.
+	Schema string `json:"schema" yaml:"schema"`
+	// FailOnDeprecated fails check and gen when a definition uses a deprecated one.
+	FailOnDeprecated bool              `json:"failOnDeprecated,omitempty" yaml:"failOnDeprecated,omitempty"`
+	Go               *GoTarget         `json:"go,omitempty" yaml:"go,omitempty"`
+	AS               *ASTarget         `json:"as,omitempty" yaml:"as,omitempty"`
+	TS               *TSTarget         `json:"ts,omitempty" yaml:"ts,omitempty"`
+	Python           *PyTarget         `json:"python,omitempty" yaml:"python,omitempty"`
+	C                *CTarget          `json:"c,omitempty" yaml:"c,omitempty"`
+	JSONSchema       *JSONSchemaTarget `json:"jsonschema,omitempty" yaml:"jsonschema,omitempty"`
+
+	// Path of the project file.
+	Path string `json:"-" yaml:"-"`
+}
+
+// GoTarget configures the Go compiler.
+type GoTarget struct {
+	// Package is the import path of the output directory.
+	Package       string `json:"package" yaml:"package"`
+	Output        string `json:"output" yaml:"output"`
+	Mutable       bool   `json:"mutable,omitempty" yaml:"mutable,omitempty"`
+	BigEndian     bool   `json:"bigEndian,omitempty" yaml:"bigEndian,omitempty"`
+	BoundsChecked bool   `json:"boundsChecked,omitempty" yaml:"boundsChecked,omitempty"`
+	// Msgpack generates AppendMsgpack and UnmarshalMsgpack. See _go.Config.Msgpack.
+	Msgpack bool `json:"msgpack,omitempty" yaml:"msgpack,omitempty"`
+	// JSON generates UnmarshalJSONLexer. See _go.Config.JSON.
+	JSON bool `json:"json,omitempty" yaml:"json,omitempty"`
+	// LayoutCheck is "init" (default), "test" or "none". See _go.LayoutCheck.
+	LayoutCheck string `json:"layoutCheck,omitempty" yaml:"layoutCheck,omitempty"`
+}
+
+// layoutCheck returns the Go compiler's LayoutCheck for the configured name.
+func (t *GoTarget) layoutCheck() (_go.LayoutCheck, error) {
+	switch t.LayoutCheck {
+	case "", "init":
+		return _go.LayoutCheckInit, nil
+	case "test":
+		return _go.LayoutCheckTest, nil
+	case "none":
+		return _go.LayoutCheckNone, nil
+	}
+	return 0, fmt.Errorf("invalid layoutCheck '%s': expected init, test or none", t.LayoutCheck)
+}
+
+// ASTarget configures the AssemblyScript compiler.
+type ASTarget struct {
+	Output  string `json:"output" yaml:"output"`
+	Mutable bool   `json:"mutable,omitempty" yaml:"mutable,omitempty"`
+}
+
+// TSTarget configures the TypeScript compiler.
+type TSTarget struct {
+	Output  string `json:"output" yaml:"output"`
+	Mutable bool   `json:"mutable,omitempty" yaml:"mutable,omitempty"`
+}
+
+// PyTarget configures the Python compiler.
+type PyTarget struct {
+	Output  string `json:"output" yaml:"output"`
+	Mutable bool   `json:"mutable,omitempty" yaml:"mutable,omitempty"`
+}
+
+// CTarget configures the C header compiler.
+type CTarget struct {
+	Output string `json:"output" yaml:"output"`
+}
+
+// JSONSchemaTarget configures the JSON Schema and OpenAPI compiler.
+type JSONSchemaTarget struct {
+	Output string `json:"output" yaml:"output"`
+	// Title and Version of the OpenAPI document.
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// FindProject returns the path of the first project file found in dir.
+func FindProject(dir string) (string, error) {
+	for _, name := range ProjectFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no project file found in %s: expected one of %v", dir, ProjectFileNames)
+}
+
+// LoadProject reads a moon.yaml or moon.json project file.
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{}
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, p)
+	} else {
+		err = yaml.Unmarshal(data, p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(p.Schema) == 0 {
+		return nil, fmt.Errorf("%s: 'schema' is required", path)
+	}
+	// Schema files are named after their directory so the project path must not be
+	// relative to the directory of the project itself.
+	if p.Path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Dir returns the directory project paths are relative to.
+func (p *Project) Dir() string {
+	return filepath.Dir(p.Path)
+}
+
+// Abs returns path relative to the project directory.
+func (p *Project) Abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Dir(), path)
+}
+
+// Rel returns an absolute path relative to the project directory when it is inside it.
+func (p *Project) Rel(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(p.Dir(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// Load loads and resolves the schema.
+func (p *Project) Load() (*schema.Schema, error) {
+	return schema.LoadFromFS(p.Abs(p.Schema), true)
+}
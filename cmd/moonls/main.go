@@ -0,0 +1,37 @@
+// Command moonls is a language server for .moon schema files. Editors start it with
+// stdin and stdout connected to the client:
+//
+//	moonls [-log file]
+//
+// The workspace root sent by the client is scanned for schema files so definitions,
+// diagnostics and renames work across imports.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/moontrade/proto/lsp"
+)
+
+func main() {
+	logFile := flag.String("log", "", "write a log of the session to file")
+	flag.Parse()
+
+	server := lsp.NewServer(os.Stdin, os.Stdout)
+	if len(*logFile) > 0 {
+		f, err := os.Create(*logFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "moonls: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		server.Log = log.New(f, "moonls: ", log.LstdFlags)
+	}
+	if err := server.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "moonls: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"sort"
 
-	//. "github.com/moontrade/proto/compile"
+	"github.com/moontrade/proto/compile"
 	. "github.com/moontrade/proto/schema"
-	"io/fs"
-	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -21,50 +20,49 @@ func NewCompiler(schema *Schema, config *ASConfig) (*Compiler, error) {
 	}, nil
 }
 
-func (c *Compiler) Compile() error {
-	// Convert into Go specific model
+// Generate generates the AssemblyScript source of every package in memory. Paths are
+// relative to the output directory.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
 	packages := make(map[string]*asPackage)
-	var err error
 	for k, v := range c.schema.Files {
-		packages[k], err = c.createPackage(v, 0)
+		pkg, err := c.createPackage(v, 0)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		packages[k] = pkg
 	}
 
-	path := c.schema.Config.Path
-	err = filepath.Walk(path, c.walkClear)
+	files := make(compile.Files)
 	for _, f := range packages {
 		b := NewBuilder()
 		if err := c.writeFile(f, b); err != nil {
-			return err
-		}
-
-		// Compute dir path
-		dir := filepath.Join(path, f.path)
-
-		// mkdir
-		_ = os.MkdirAll(dir, 0755)
-
-		// Create new "moon.go"
-		out, err := os.Create(filepath.Join(dir, TSFileName))
-		if err != nil {
-			return err
-		}
-		_, err = out.WriteString(b.String())
-		if err != nil {
-			return err
+			return nil, err
 		}
+		files[filepath.ToSlash(filepath.Join(f.path, TSFileName))] = []byte(b.String())
 	}
-
-	return nil
+	return files, nil
 }
 
-func (c *Compiler) walkClear(path string, info fs.FileInfo, err error) error {
-	if strings.HasSuffix(path, TSFileNameSuffix) {
-		_ = os.Remove(path)
+// Compile generates the AssemblyScript source and writes it to the output directory.
+// Unchanged files are left untouched and generated files that are no longer produced
+// are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
 	}
-	return nil
+	_, err = compile.Write(c.config.Output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the AssemblyScript compiler generates.
+func IsGenerated(name string) bool {
+	return name == TSFileName || strings.HasSuffix(name, TSFileNameSuffix)
 }
 
 func (c *Compiler) goName(n string, types map[string]*Type) string {
@@ -127,13 +125,14 @@ func (c *Compiler) createPackage(file *File, level int) (*asPackage, error) {
 	pkg := &asPackage{
 		file:        file,
 		path:        path,
-		dir:         filepath.Join(c.schema.Path, path),
+		dir:         filepath.Join(c.config.Output, path),
 		packageName: packageParts[len(packageParts)-1],
 		byType:      make(map[*Type]*asType),
 		importMap:   make(map[string]*asImport),
 		types:       make(map[string]*asType),
 		structs:     make(map[string]*asType),
 		strings:     make(map[string]*asType),
+		decimals:    make(map[string]*asType),
 		enums:       make(map[string]*asType),
 		lists:       make(map[string]*asType),
 		unions:      make(map[string]*asType),
@@ -229,7 +228,13 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 	//b.W("package %s\n", file.packageName)
 
 	if len(file.importMap) > 0 {
-		for _, imp := range file.importMap {
+		paths := make([]string, 0, len(file.importMap))
+		for path := range file.importMap {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, key := range paths {
+			imp := file.importMap[key]
 			path, err := filepath.Rel(file.path, imp.path)
 			if err != nil {
 				return err
@@ -239,12 +244,16 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 		b.W("")
 	}
 
-	for _, enum := range file.enums {
+	for _, enum := range sortedTypes(file.enums) {
 		if err := c.genEnum(file, enum, b); err != nil {
 			return err
 		}
 	}
 
+	if err := c.genConsts(file, b); err != nil {
+		return err
+	}
+
 	init := NewBuilder()
 	//init.W("func init() {")
 	//
@@ -291,7 +300,7 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 	//init.WriteLine("")
 	//init.WriteLine("")
 
-	for _, st := range file.structs {
+	for _, st := range sortedTypes(file.structs) {
 		if err := c.genStruct(file, st, false, b); err != nil {
 			return err
 		}
@@ -318,7 +327,7 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 	//	}
 	//}
 
-	for _, str := range file.strings {
+	for _, str := range sortedTypes(file.strings) {
 		if err := c.genString(str, false, b); err != nil {
 			return err
 		}
@@ -327,7 +336,14 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 		}
 	}
 
-	for _, list := range file.lists {
+	if len(file.decimals) > 0 {
+		c.genDecimalArithmetic(b)
+	}
+	for _, decimal := range sortedTypes(file.decimals) {
+		c.genDecimal(decimal, b)
+	}
+
+	for _, list := range sortedTypes(file.lists) {
 		if err := c.genList(list, false, b); err != nil {
 			return err
 		}
@@ -343,6 +359,20 @@ func (c *Compiler) writeFile(file *asPackage, b *Builder) error {
 	return nil
 }
 
+// sortedTypes returns the types ordered by name so generated code is deterministic.
+func sortedTypes(types map[string]*asType) []*asType {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*asType, 0, len(types))
+	for _, name := range names {
+		result = append(result, types[name])
+	}
+	return result
+}
+
 func (c *asPackage) uniqueName(n string) string {
 	for {
 		if _, ok := c.names[n]; ok {
@@ -555,6 +585,8 @@ func (c *Compiler) resolve(pkg *asPackage, t *Type, level int) (*asType, error)
 		pkg.strings[gt.name] = gt
 		pkg.byType[t] = gt
 		return gt, nil
+	case KindDecimal64, KindDecimal128:
+		return c.decimalType(pkg, t), nil
 
 	case KindPad:
 		return &asType{
@@ -575,7 +607,7 @@ func (c *Compiler) resolve(pkg *asPackage, t *Type, level int) (*asType, error)
 
 func (c *Compiler) isPointerType(t *Type) bool {
 	switch t.Kind {
-	case KindString, KindStruct, KindUnion, KindList, KindMap:
+	case KindString, KindDecimal64, KindDecimal128, KindStruct, KindUnion, KindList, KindMap:
 		return true
 	default:
 		return false
@@ -650,6 +682,8 @@ func (c *Compiler) asTypeName(t *Type) string {
 		return "f64"
 	case KindString:
 		return "string"
+	case KindDecimal64, KindDecimal128:
+		return DecimalTypeName(t.Kind, t.Scale)
 	case KindEnum:
 		return Capitalize(t.Enum.Name)
 	case KindStruct:
@@ -665,9 +699,12 @@ func (c *Compiler) asTypeName(t *Type) string {
 }
 
 func (c *Compiler) genEnum(file *asPackage, t *asType, b *Builder) error {
+	deprecated, msg := t.t.Enum.Deprecated, t.t.Enum.DeprecatedMessage
+	compile.WriteJSDocDeprecated(b, "", deprecated, msg)
 	b.W("export namespace %s {", t.name)
 	for _, option := range t.enum.options {
-		c.writeComments("    ", b, option.option.Comments)
+		c.writeComments("    ", b, option.option.Doc())
+		compile.WriteJSDocDeprecated(b, "    ", option.option.Deprecated, option.option.DeprecatedMessage)
 		b.W("    export const %s:%s = %d", option.name, t.name, option.option.Value)
 		//if i < len(t.enum.options)-1 {
 		//	b.W("    export const %s:%s = %d,", option.name, t.enum.value.name, option.option.Value)
@@ -676,11 +713,54 @@ func (c *Compiler) genEnum(file *asPackage, t *asType, b *Builder) error {
 		//}
 	}
 	b.W("}")
-	c.writeComments("    ", b, t.t.Comments)
+	c.writeComments("    ", b, t.t.Base().Doc())
+	compile.WriteJSDocDeprecated(b, "", deprecated, msg)
 	b.W("export type %s = %s\n", t.name, t.enum.value.name)
 	return nil
 }
 
+// genConsts generates a typed constant for each const declared in the file.
+func (c *Compiler) genConsts(file *asPackage, b *Builder) error {
+	for _, cst := range file.file.Consts {
+		t, err := c.resolve(file, cst.Type, 0)
+		if err != nil {
+			return err
+		}
+		typeName := t.name
+		switch cst.Type.Kind {
+		case KindString, KindBytes:
+			typeName = "string"
+		case KindStruct, KindUnion, KindList, KindMap, KindDecimal64, KindDecimal128:
+			return fmt.Errorf("%s:%d const '%s' must be a primitive, string or enum",
+				cst.Type.File.Path, cst.Type.Line.Number, cst.Name)
+		}
+		value := ""
+		switch v := cst.Type.Init.(type) {
+		case bool:
+			value = strconv.FormatBool(v)
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case uint64:
+			value = strconv.FormatUint(v, 10)
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+		case string:
+			value = strconv.Quote(v)
+		case *EnumOption:
+			value = fmt.Sprintf("%s.%s", t.name, c.enumOptionName(v))
+		default:
+			return fmt.Errorf("%s:%d const '%s' has an unsupported value: %v",
+				cst.Type.File.Path, cst.Type.Line.Number, cst.Name, cst.Type.Init)
+		}
+		c.writeComments("", b, cst.Type.Doc())
+		b.W("export const %s: %s = %s", cst.Name, typeName, value)
+	}
+	if len(file.file.Consts) > 0 {
+		b.W("")
+	}
+	return nil
+}
+
 func (c *Compiler) writeFieldGetter(mut bool, b *Builder, st *asType, f *asField) {
 	fieldName := f.public
 	typeName := f.t.name
@@ -689,7 +769,8 @@ func (c *Compiler) writeFieldGetter(mut bool, b *Builder, st *asType, f *asField
 	}
 
 	getBuffer := "changetype<usize>(this)"
-	c.writeComments("    ", b, f.field.Type.Comments)
+	c.writeComments("    ", b, f.field.Type.Doc())
+	compile.WriteJSDocDeprecated(b, "    ", f.field.Deprecated, f.field.DeprecatedMessage)
 
 	W := b.W
 	if f.field.Type.Optional {
@@ -740,7 +821,8 @@ func (c *Compiler) writeFieldGetter(mut bool, b *Builder, st *asType, f *asField
 func (c *Compiler) writeFieldSetter(mut bool, b *Builder, st *asType, f *asField) {
 	//embeddedStructName := st.name
 	fieldName := f.public
-	c.writeComments("    ", b, f.field.Type.Comments)
+	c.writeComments("    ", b, f.field.Type.Doc())
+	compile.WriteJSDocDeprecated(b, "    ", f.field.Deprecated, f.field.DeprecatedMessage)
 
 	getBuffer := "changetype<usize>(this)"
 	name := f.t.name
@@ -803,7 +885,8 @@ func (c *Compiler) writeFieldSetter(mut bool, b *Builder, st *asType, f *asField
 
 func (c *Compiler) genStruct(file *asPackage, t *asType, mut bool, b *Builder) error {
 	st := t.st
-	c.writeComments("", b, t.t.Comments)
+	c.writeComments("", b, t.t.Base().Doc())
+	compile.WriteJSDocDeprecated(b, "", st.st.Deprecated, st.st.DeprecatedMessage)
 	W := b.W
 
 	name := t.name
@@ -1,23 +1,44 @@
 package as
 
 import (
-	"fmt"
-	. "github.com/moontrade/proto/schema"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	. "github.com/moontrade/proto/schema"
 )
 
 func TestNewGenerator(t *testing.T) {
-	schema, err := NewSchema(&Schema{
-		Path: "../../../code/test/assembly",
-	})
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+// Highest value
+const HIGH i64 = 1000
+const NAME string8 = "HELLO"
+const CODE Code = Close
 
+enum Code : byte {
+	Open = 0
+	Close = 1
+}
+
+struct Candle {
+	open  f64
+	close f64
+	code  Code
+}
+`))
 	if err != nil {
 		t.Fatal(err)
 	}
+	schema := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = schema.Resolve(); err != nil {
+		t.Fatal(err)
+	}
 
+	output := t.TempDir()
 	compiler, err := NewCompiler(schema, &ASConfig{
 		Mutable: true,
-		Output:  "../../../code/test/assembly",
+		Output:  output,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -27,5 +48,121 @@ func TestNewGenerator(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	fmt.Println(schema)
+	data, err := os.ReadFile(filepath.Join(output, "model", TSFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"// Highest value",
+		"export const HIGH: i64 = 1000",
+		`export const NAME: string = "HELLO"`,
+		"export const CODE: Code = Code.Close",
+		"export class Candle {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+@deprecated("use Direction")
+enum Side : byte {
+	Buy = 1
+	@deprecated
+	Sell = 2
+}
+
+struct Order {
+	@deprecated("use direction")
+	side  Side
+	price f64
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = schema.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(schema, &ASConfig{
+		Mutable:          true,
+		Output:           output,
+		FailOnDeprecated: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "model", TSFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"/** @deprecated use Direction */\nexport namespace Side {",
+		"    /** @deprecated */\n    export const Sell:Side = 2",
+		"    /** @deprecated use direction */\n    @inline get side(): Side {",
+		"    /** @deprecated use direction */\n    set side(v: Side) {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Fill {
+	qty   decimal64(8)
+	price ?decimal128(2)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = schema.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(schema, &ASConfig{Mutable: true, Output: output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "model", TSFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"@unmanaged\nexport class Decimal64S8 {",
+		"    @inline get units(): i64 {",
+		"            throw new RangeError(\"decimal overflow\")",
+		"export class Decimal128S2 {\n    private _0: u64\n    private _8: u64",
+		"        digits = digits.substring(0, digits.length - 2) + \".\" + digits.substring(digits.length - 2)",
+		"    @inline get qty(): Decimal64S8 {\n        return changetype<Decimal64S8>(changetype<usize>(this)+",
+		"    @inline get price(): Decimal128S2 | null {",
+		"function decimalDiv(n: StaticArray<u64>, d0: u64, d1: u64): StaticArray<u64> {",
+		"        this.units = decimalToI64(decimalDiv(p, <u64>100000000, 0), (a ^ b) < 0)",
+		"        let p = decimalMul(a[0], a[1], <u64>100, <u64>0)",
+		"    cmp(v: Decimal128S2): i32 {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
 }
@@ -0,0 +1,328 @@
+package as
+
+import (
+	"math/big"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+// decimalType maps a decimal to an unmanaged class viewing its units in the buffer. A
+// decimal64 is an i64 and a decimal128 the little-endian lo u64 and hi i64 halves.
+func (c *Compiler) decimalType(pkg *asPackage, t *Type) *asType {
+	name := DecimalTypeName(t.Kind, t.Scale)
+	gt := &asType{
+		pkg:  pkg,
+		t:    t,
+		name: name,
+		mut:  name,
+	}
+	pkg.decimals[gt.name] = gt
+	pkg.byType[t] = gt
+	return gt
+}
+
+// genDecimalArithmetic generates the functions the decimal classes of a file share to
+// multiply and divide. Magnitudes are multiplied into 256 bits and divided back down
+// to 128 bits one bit at a time, rounding half to even.
+func (c *Compiler) genDecimalArithmetic(b *Builder) {
+	W := b.W
+	W("// decimalMul returns the 256-bit product of the unsigned 128-bit a and b, least")
+	W("// significant limb first.")
+	W("function decimalMul(a0: u64, a1: u64, b0: u64, b1: u64): StaticArray<u64> {")
+	W("    let a = new StaticArray<u64>(4)")
+	W("    let b = new StaticArray<u64>(4)")
+	W("    a[0] = a0 & 0xFFFFFFFF")
+	W("    a[1] = a0 >> 32")
+	W("    a[2] = a1 & 0xFFFFFFFF")
+	W("    a[3] = a1 >> 32")
+	W("    b[0] = b0 & 0xFFFFFFFF")
+	W("    b[1] = b0 >> 32")
+	W("    b[2] = b1 & 0xFFFFFFFF")
+	W("    b[3] = b1 >> 32")
+	W("    // 32-bit limbs so each partial product and its carries fit a u64.")
+	W("    let r = new StaticArray<u64>(8)")
+	W("    for (let i = 0; i < 4; i++) {")
+	W("        let carry: u64 = 0")
+	W("        for (let j = 0; j < 4; j++) {")
+	W("            let t = a[i] * b[j] + r[i + j] + carry")
+	W("            r[i + j] = t & 0xFFFFFFFF")
+	W("            carry = t >> 32")
+	W("        }")
+	W("        r[i + 4] = carry")
+	W("    }")
+	W("    let p = new StaticArray<u64>(4)")
+	W("    for (let i = 0; i < 4; i++) {")
+	W("        p[i] = r[i * 2] | (r[i * 2 + 1] << 32)")
+	W("    }")
+	W("    return p")
+	W("}\n")
+
+	W("// decimalDiv returns the unsigned 256-bit n divided by the 128-bit d rounded half to")
+	W("// even. It throws when the quotient needs more than 128 bits.")
+	W("function decimalDiv(n: StaticArray<u64>, d0: u64, d1: u64): StaticArray<u64> {")
+	W("    let q = new StaticArray<u64>(4)")
+	W("    let r0: u64 = 0")
+	W("    let r1: u64 = 0")
+	W("    for (let i = 255; i >= 0; i--) {")
+	W("        // The remainder may reach 129 bits before the subtraction.")
+	W("        let carry = r1 >> 63")
+	W("        r1 = (r1 << 1) | (r0 >> 63)")
+	W("        r0 = (r0 << 1) | ((n[i >> 6] >> <u64>(i & 63)) & 1)")
+	W("        if (carry != 0 || r1 > d1 || (r1 == d1 && r0 >= d0)) {")
+	W("            let borrow = <u64>(r0 < d0)")
+	W("            r0 -= d0")
+	W("            r1 -= d1 + borrow")
+	W("            q[i >> 6] |= <u64>1 << <u64>(i & 63)")
+	W("        }")
+	W("    }")
+	W("    // Round up when twice the remainder is above d, or equal and q is odd.")
+	W("    let carry = r1 >> 63")
+	W("    let t1 = (r1 << 1) | (r0 >> 63)")
+	W("    let t0 = r0 << 1")
+	W("    if (carry != 0 || t1 > d1 || (t1 == d1 && (t0 > d0 || (t0 == d0 && (q[0] & 1) != 0)))) {")
+	W("        for (let i = 0; i < 4; i++) {")
+	W("            q[i] += 1")
+	W("            if (q[i] != 0) {")
+	W("                break")
+	W("            }")
+	W("        }")
+	W("    }")
+	W("    if (q[2] != 0 || q[3] != 0) {")
+	W("        throw new RangeError(\"decimal overflow\")")
+	W("    }")
+	W("    return q")
+	W("}\n")
+
+	W("function decimalAbs64(v: i64): u64 {")
+	W("    // The magnitude of i64.MIN_VALUE wraps to 2^63 as a u64.")
+	W("    return v < 0 ? <u64>(-v) : <u64>v")
+	W("}\n")
+
+	W("// decimalToI64 returns the quotient q with the sign, throwing when it overflows.")
+	W("function decimalToI64(q: StaticArray<u64>, negative: bool): i64 {")
+	W("    if (q[1] != 0 || q[0] > (negative ? <u64>0x8000000000000000 : <u64>0x7FFFFFFFFFFFFFFF)) {")
+	W("        throw new RangeError(\"decimal overflow\")")
+	W("    }")
+	W("    return negative ? -<i64>q[0] : <i64>q[0]")
+	W("}\n")
+
+	W("// decimalAbs128 returns the magnitude of the i128 lo, hi. That of the minimum is 2^127.")
+	W("function decimalAbs128(lo: u64, hi: i64): StaticArray<u64> {")
+	W("    let m = new StaticArray<u64>(2)")
+	W("    m[0] = lo")
+	W("    m[1] = <u64>hi")
+	W("    if (hi < 0) {")
+	W("        m[0] = ~lo + 1")
+	W("        m[1] = ~<u64>hi + <u64>(m[0] == 0)")
+	W("    }")
+	W("    return m")
+	W("}\n")
+}
+
+// genDecimal generates the class of a decimal. Arithmetic throws a RangeError rather
+// than wrap, mul and div round half to even and toString renders exactly scale
+// decimals.
+func (c *Compiler) genDecimal(t *asType, b *Builder) {
+	W := b.W
+	name, scale := t.name, t.t.Scale
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	pow0 := new(big.Int).And(pow, new(big.Int).SetUint64(1<<64-1)).Uint64()
+	pow1 := new(big.Int).Rsh(pow, 64).Uint64()
+	if t.t.Kind == KindDecimal64 {
+		W("// %s is a decimal64(%d) stored as an i64 count of 10^-%d.", name, scale, scale)
+	} else {
+		W("// %s is a decimal128(%d) stored as the little-endian i128 count of 10^-%d.", name, scale, scale)
+	}
+	W("@unmanaged")
+	W("export class %s {", name)
+	for i := 0; i < t.t.Size; i += 8 {
+		W("    private _%d: u64", i)
+	}
+	W("")
+	W("    @inline static get sizeof(): usize {")
+	W("        return %d", t.t.Size)
+	W("    }\n")
+
+	W("    @inline static get scale(): i32 {")
+	W("        return %d", scale)
+	W("    }\n")
+
+	if t.t.Kind == KindDecimal64 {
+		W("    @inline get units(): i64 {")
+		W("        return load<i64>(changetype<usize>(this))")
+		W("    }\n")
+
+		W("    @inline set units(v: i64) {")
+		W("        store<i64>(changetype<usize>(this), v)")
+		W("    }\n")
+
+		W("    add(v: %s): void {", name)
+		W("        let a = this.units")
+		W("        let b = v.units")
+		W("        let r = a + b")
+		W("        if (((a ^ r) & (b ^ r)) < 0) {")
+		W("            throw new RangeError(\"decimal overflow\")")
+		W("        }")
+		W("        this.units = r")
+		W("    }\n")
+
+		W("    sub(v: %s): void {", name)
+		W("        let a = this.units")
+		W("        let b = v.units")
+		W("        let r = a - b")
+		W("        if (((a ^ b) & (a ^ r)) < 0) {")
+		W("            throw new RangeError(\"decimal overflow\")")
+		W("        }")
+		W("        this.units = r")
+		W("    }\n")
+
+		W("    mul(v: %s): void {", name)
+		W("        let a = this.units")
+		W("        let b = v.units")
+		W("        let p = decimalMul(decimalAbs64(a), 0, decimalAbs64(b), 0)")
+		W("        this.units = decimalToI64(decimalDiv(p, <u64>%d, 0), (a ^ b) < 0)", pow0)
+		W("    }\n")
+
+		W("    div(v: %s): void {", name)
+		W("        let a = this.units")
+		W("        let b = v.units")
+		W("        if (b == 0) {")
+		W("            throw new RangeError(\"decimal division by zero\")")
+		W("        }")
+		W("        let p = decimalMul(decimalAbs64(a), 0, <u64>%d, 0)", pow0)
+		W("        this.units = decimalToI64(decimalDiv(p, decimalAbs64(b), 0), (a ^ b) < 0)")
+		W("    }\n")
+
+		W("    cmp(v: %s): i32 {", name)
+		W("        let a = this.units")
+		W("        let b = v.units")
+		W("        return a < b ? -1 : a > b ? 1 : 0")
+		W("    }\n")
+
+		W("    toString(): string {")
+		W("        let u = this.units")
+		W("        // The magnitude of i64.MIN_VALUE wraps to 2^63 as a u64.")
+		W("        let digits = (u < 0 ? <u64>(-u) : <u64>u).toString()")
+	} else {
+		W("    @inline get lo(): u64 {")
+		W("        return load<u64>(changetype<usize>(this))")
+		W("    }\n")
+
+		W("    @inline set lo(v: u64) {")
+		W("        store<u64>(changetype<usize>(this), v)")
+		W("    }\n")
+
+		W("    @inline get hi(): i64 {")
+		W("        return load<i64>(changetype<usize>(this)+8)")
+		W("    }\n")
+
+		W("    @inline set hi(v: i64) {")
+		W("        store<i64>(changetype<usize>(this)+8, v)")
+		W("    }\n")
+
+		W("    add(v: %s): void {", name)
+		W("        let lo = this.lo + v.lo")
+		W("        let a = this.hi")
+		W("        let b = v.hi")
+		W("        let r = a + b + <i64>(lo < v.lo)")
+		W("        if (((a ^ r) & (b ^ r)) < 0) {")
+		W("            throw new RangeError(\"decimal overflow\")")
+		W("        }")
+		W("        this.lo = lo")
+		W("        this.hi = r")
+		W("    }\n")
+
+		W("    sub(v: %s): void {", name)
+		W("        let lo = this.lo - v.lo")
+		W("        let a = this.hi")
+		W("        let b = v.hi")
+		W("        let r = a - b - <i64>(this.lo < v.lo)")
+		W("        if (((a ^ b) & (a ^ r)) < 0) {")
+		W("            throw new RangeError(\"decimal overflow\")")
+		W("        }")
+		W("        this.lo = lo")
+		W("        this.hi = r")
+		W("    }\n")
+
+		W("    mul(v: %s): void {", name)
+		W("        let a = decimalAbs128(this.lo, this.hi)")
+		W("        let b = decimalAbs128(v.lo, v.hi)")
+		W("        let p = decimalMul(a[0], a[1], b[0], b[1])")
+		W("        this.setMagnitude(decimalDiv(p, <u64>%d, <u64>%d), (this.hi ^ v.hi) < 0)", pow0, pow1)
+		W("    }\n")
+
+		W("    div(v: %s): void {", name)
+		W("        if (v.lo == 0 && v.hi == 0) {")
+		W("            throw new RangeError(\"decimal division by zero\")")
+		W("        }")
+		W("        let a = decimalAbs128(this.lo, this.hi)")
+		W("        let b = decimalAbs128(v.lo, v.hi)")
+		W("        let p = decimalMul(a[0], a[1], <u64>%d, <u64>%d)", pow0, pow1)
+		W("        this.setMagnitude(decimalDiv(p, b[0], b[1]), (this.hi ^ v.hi) < 0)")
+		W("    }\n")
+
+		W("    cmp(v: %s): i32 {", name)
+		W("        if (this.hi != v.hi) {")
+		W("            return this.hi < v.hi ? -1 : 1")
+		W("        }")
+		W("        if (this.lo != v.lo) {")
+		W("            return this.lo < v.lo ? -1 : 1")
+		W("        }")
+		W("        return 0")
+		W("    }\n")
+
+		W("    // setMagnitude stores the 128-bit magnitude q with the sign.")
+		W("    private setMagnitude(q: StaticArray<u64>, negative: bool): void {")
+		W("        if (q[1] > <u64>0x8000000000000000 || (q[1] == <u64>0x8000000000000000 && (!negative || q[0] != 0))) {")
+		W("            throw new RangeError(\"decimal overflow\")")
+		W("        }")
+		W("        let lo = q[0]")
+		W("        let hi = q[1]")
+		W("        if (negative) {")
+		W("            lo = ~lo + 1")
+		W("            hi = ~hi + <u64>(lo == 0)")
+		W("        }")
+		W("        this.lo = lo")
+		W("        this.hi = <i64>hi")
+		W("    }\n")
+
+		W("    toString(): string {")
+		W("        let lo = this.lo")
+		W("        let hi = <u64>this.hi")
+		W("        let u = this.hi")
+		W("        if (u < 0) {")
+		W("            lo = ~lo + 1")
+		W("            hi = ~hi + <u64>(lo == 0)")
+		W("        }")
+		W("        // Long division of the magnitude by 10 over 32-bit limbs.")
+		W("        let limbs = new StaticArray<u64>(4)")
+		W("        limbs[0] = hi >> 32")
+		W("        limbs[1] = hi & 0xFFFFFFFF")
+		W("        limbs[2] = lo >> 32")
+		W("        limbs[3] = lo & 0xFFFFFFFF")
+		W("        let digits = \"\"")
+		W("        while (true) {")
+		W("            let r: u64 = 0")
+		W("            let rest: u64 = 0")
+		W("            for (let i = 0; i < 4; i++) {")
+		W("                let n = (r << 32) | limbs[i]")
+		W("                limbs[i] = n / 10")
+		W("                r = n %% 10")
+		W("                rest |= limbs[i]")
+		W("            }")
+		W("            digits = String.fromCharCode(48 + <i32>r) + digits")
+		W("            if (rest == 0) {")
+		W("                break")
+		W("            }")
+		W("        }")
+	}
+	if scale > 0 {
+		W("        while (digits.length <= %d) {", scale)
+		W("            digits = \"0\" + digits")
+		W("        }")
+		W("        digits = digits.substring(0, digits.length - %d) + \".\" + digits.substring(digits.length - %d)", scale, scale)
+	}
+	W("        return u < 0 ? \"-\" + digits : digits")
+	W("    }")
+	W("}\n")
+}
@@ -17,6 +17,9 @@ type ASConfig struct {
 	Mutable       bool
 	MultipleFiles bool
 	Output        string
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
 }
 
 // Generates Go code
@@ -60,6 +63,7 @@ type asPackage struct {
 	types     map[string]*asType
 	lists     map[string]*asType
 	strings   map[string]*asType
+	decimals  map[string]*asType
 	structs   map[string]*asType
 	enums     map[string]*asType
 	unions    map[string]*asType
@@ -0,0 +1,474 @@
+// Package c generates C headers for native consumers. Each package becomes a header of
+// structs with fixed width fields and explicit padding that share the binary layout of
+// the schema, checked at compile time with static assertions on sizeof and offsetof.
+package c
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moontrade/proto/compile"
+	. "github.com/moontrade/proto/schema"
+)
+
+func NewCompiler(schema *Schema, config *CConfig) (*Compiler, error) {
+	return &Compiler{
+		schema:   schema,
+		config:   config,
+		packages: make(map[string]*cPackage),
+	}, nil
+}
+
+// Generate generates the header of every package in memory. Paths are relative to the
+// output directory.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
+	c.packages = make(map[string]*cPackage)
+	paths := make([]string, 0, len(c.schema.Files))
+	for path := range c.schema.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f := c.schema.Files[path]
+		pkg := c.packages[packagePath(f)]
+		if pkg == nil {
+			pkg = &cPackage{
+				path:     packagePath(f),
+				prefix:   prefix(f),
+				includes: make(map[string]struct{}),
+				lists:    make(map[string]*Type),
+				strings:  make(map[int]struct{}),
+			}
+			c.packages[pkg.path] = pkg
+		}
+		pkg.files = append(pkg.files, f)
+	}
+
+	files := compile.Files{RuntimeFileName: []byte(runtime)}
+	for _, pkg := range c.packages {
+		b := NewBuilder()
+		if err := c.writePackage(pkg, b); err != nil {
+			return nil, err
+		}
+		files[headerPath(pkg.path)] = []byte(b.String())
+	}
+	return files, nil
+}
+
+// Compile generates the headers and writes them to the output directory. Unchanged
+// files are left untouched and generated files that are no longer produced are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
+	}
+	_, err = compile.Write(c.config.Output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the C compiler generates.
+func IsGenerated(name string) bool {
+	return name == RuntimeFileName || strings.HasSuffix(name, HeaderSuffix)
+}
+
+// packagePath returns the path of a file's package relative to the output.
+func packagePath(f *File) string {
+	return filepath.ToSlash(filepath.Join(strings.Split(f.Package, ".")...))
+}
+
+// headerPath returns the path of the header of a package relative to the output.
+func headerPath(path string) string {
+	return path + HeaderSuffix
+}
+
+// prefix returns the prefix of the names declared by a file's package.
+func prefix(f *File) string {
+	return strings.ReplaceAll(packagePath(f), "/", "_")
+}
+
+func (c *Compiler) writePackage(pkg *cPackage, b *Builder) error {
+	body := NewBuilder()
+
+	var enums []*Enum
+	var structs []*Struct
+	for _, f := range pkg.files {
+		enums = append(enums, f.Enums...)
+		structs = append(structs, f.Structs...)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+
+	for _, enum := range enums {
+		c.genEnum(pkg, enum, body)
+	}
+	for _, f := range pkg.files {
+		if err := c.genConsts(pkg, f, body); err != nil {
+			return err
+		}
+	}
+	// A struct embeds the structs and lists of its fields by value so they are
+	// declared first.
+	decls := NewBuilder()
+	done := make(map[string]bool)
+	for _, st := range structs {
+		if err := c.genDecl(pkg, st.Type, decls, done); err != nil {
+			return err
+		}
+	}
+
+	guard := "MOON_" + strings.ToUpper(pkg.prefix) + "_H"
+	b.W("// Code generated by moonc. DO NOT EDIT.")
+	b.W("#ifndef %s", guard)
+	b.W("#define %s\n", guard)
+	b.W("#include \"%s\"", RuntimeFileName)
+	includes := make([]string, 0, len(pkg.includes))
+	for path := range pkg.includes {
+		includes = append(includes, path)
+	}
+	sort.Strings(includes)
+	for _, path := range includes {
+		b.W("#include \"%s\"", headerPath(path))
+	}
+	b.W("")
+	_, _ = b.WriteString(body.String())
+	sizes := make([]int, 0, len(pkg.strings))
+	for size := range pkg.strings {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	for _, size := range sizes {
+		genString(size, b)
+	}
+	_, _ = b.WriteString(decls.String())
+	b.W("#endif")
+	return nil
+}
+
+// declName returns the C name of a struct or enum.
+func (c *Compiler) declName(pkg *cPackage, t *Type, name string) string {
+	name = Capitalize(name)
+	if t.Import == nil || t.Import.File == nil {
+		return pkg.prefix + "_" + name
+	}
+	if path := packagePath(t.Import.File); path != pkg.path {
+		pkg.includes[path] = struct{}{}
+	}
+	return prefix(t.Import.File) + "_" + name
+}
+
+// stringName returns the name of the fixed string type of size bytes. String types are
+// shared by every header.
+func stringName(size int) string {
+	return fmt.Sprintf("moon_String%d", size)
+}
+
+// typeName returns the C type of t.
+func (c *Compiler) typeName(pkg *cPackage, t *Type) (string, error) {
+	if p, ok := primitives[t.Kind]; ok {
+		return p, nil
+	}
+	switch t.Kind {
+	case KindEnum:
+		return c.declName(pkg, t, t.Enum.Name), nil
+	case KindStruct:
+		return c.declName(pkg, t, t.Struct.Name), nil
+	case KindString, KindBytes, KindList:
+		if t.Len == 0 {
+			return "", fmt.Errorf("%s:%d variable length %s is not supported by the C compiler yet",
+				t.File.Path, t.Line.Number, t.Name)
+		}
+		switch t.Kind {
+		case KindString:
+			pkg.strings[t.Size] = struct{}{}
+			return stringName(t.Size), nil
+		case KindBytes:
+			return "uint8_t", nil
+		}
+		if _, err := c.typeName(pkg, t.Element); err != nil {
+			return "", err
+		}
+		name := pkg.prefix + "_" + Capitalize(t.Name)
+		pkg.lists[name] = t
+		return name, nil
+	}
+	return "", fmt.Errorf("%s:%d type not supported by the C compiler yet: %s",
+		t.File.Path, t.Line.Number, t.Name)
+}
+
+// declaration returns the declaration of a member named name of type t.
+func (c *Compiler) declaration(pkg *cPackage, t *Type, name string) (string, error) {
+	typeName, err := c.typeName(pkg, t)
+	if err != nil {
+		return "", err
+	}
+	if t.Kind == KindBytes {
+		return fmt.Sprintf("uint8_t %s[%d];", name, t.Size), nil
+	}
+	return fmt.Sprintf("%s %s;", typeName, name), nil
+}
+
+// fieldName returns the member name of a field.
+func fieldName(name string) string {
+	if keywords[name] || strings.HasPrefix(name, "_") {
+		return name + "_"
+	}
+	return name
+}
+
+func (c *Compiler) writeComments(prefix string, b *Builder, comments []string) {
+	for _, comment := range comments {
+		b.W("%s//%s", prefix, comment)
+	}
+}
+
+// genEnum generates a typedef of the enum's underlying kind, so the enum has the size
+// it has in the schema, and a constant for each option.
+func (c *Compiler) genEnum(pkg *cPackage, enum *Enum, b *Builder) {
+	name := pkg.prefix + "_" + Capitalize(enum.Name)
+	c.writeComments("", b, enum.Type.Doc())
+	compile.WriteDeprecated(b, "", "//", enum.Deprecated, enum.DeprecatedMessage)
+	b.W("typedef %s %s;\n", primitives[enum.Type.Element.Kind], name)
+	if len(enum.Options) > 0 {
+		b.W("enum {")
+		for _, option := range enum.Options {
+			c.writeComments("    ", b, option.Doc())
+			compile.WriteDeprecated(b, "    ", "//", option.Deprecated, option.DeprecatedMessage)
+			b.W("    %s_%s = %v,", name, option.Name, option.Value)
+		}
+		b.W("};\n")
+	}
+
+	b.W("// %s_string returns the name of an option or an empty string.", name)
+	b.W("static inline const char *%s_string(%s v) {", name, name)
+	b.W("    switch (v) {")
+	seen := make(map[string]bool)
+	for _, option := range enum.Options {
+		value := fmt.Sprint(option.Value)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		b.W("    case %s_%s:", name, option.Name)
+		b.W("        return \"%s\";", option.Name)
+	}
+	b.W("    default:")
+	b.W("        return \"\";")
+	b.W("    }")
+	b.W("}\n")
+}
+
+// genConsts generates a macro for each const declared in the file.
+func (c *Compiler) genConsts(pkg *cPackage, f *File, b *Builder) error {
+	for _, cst := range f.Consts {
+		t := cst.Type
+		value := ""
+		switch v := t.Init.(type) {
+		case bool:
+			value = strconv.FormatBool(v)
+		case int64:
+			value = fmt.Sprintf("((%s)%d)", primitives[t.Kind], v)
+			if t.Kind == KindInt64 && v == -1<<63 {
+				value = "INT64_MIN"
+			}
+		case uint64:
+			value = fmt.Sprintf("((%s)%dU)", primitives[t.Kind], v)
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+			if !strings.ContainsAny(value, ".eIN") {
+				value += ".0"
+			}
+			value = fmt.Sprintf("((%s)%s)", primitives[t.Kind], value)
+		case string:
+			value = strconv.Quote(v)
+		case *EnumOption:
+			value = fmt.Sprintf("%s_%s", c.declName(pkg, t, v.Enum.Name), v.Name)
+		default:
+			return fmt.Errorf("%s:%d const '%s' has an unsupported value: %v",
+				t.File.Path, t.Line.Number, cst.Name, t.Init)
+		}
+		c.writeComments("", b, t.Doc())
+		b.W("#define %s_%s %s", pkg.prefix, cst.Name, value)
+	}
+	if len(f.Consts) > 0 {
+		b.W("")
+	}
+	return nil
+}
+
+// genString generates the fixed string type of size bytes and its helpers guarded so
+// that headers of several packages can declare it.
+func genString(size int, b *Builder) {
+	name := stringName(size)
+	guard := strings.ToUpper(name)
+	capacity, length := size-1, "the last byte"
+	if size > 256 {
+		capacity, length = size-2, "the last two bytes"
+	}
+	b.W("#ifndef %s", guard)
+	b.W("#define %s", guard)
+	b.W("// %s holds up to %d bytes with the length in %s.", name, capacity, length)
+	b.W("typedef struct %s {", name)
+	b.W("    uint8_t data[%d];", size)
+	b.W("} %s;\n", name)
+	b.W("MOON_STATIC_ASSERT(sizeof(%s) == %d, \"sizeof %s\");\n", name, size, name)
+	b.W("static inline size_t %s_len(const %s *s) {", name, name)
+	b.W("    return moon_string_len(s->data, %d);", size)
+	b.W("}\n")
+	b.W("// %s_data returns the bytes of the string. They are not NUL terminated.", name)
+	b.W("static inline const char *%s_data(const %s *s) {", name, name)
+	b.W("    return (const char *)s->data;")
+	b.W("}\n")
+	b.W("// %s_set stores n bytes of v truncated to the capacity and returns the length.", name)
+	b.W("static inline size_t %s_set(%s *s, const char *v, size_t n) {", name, name)
+	b.W("    return moon_string_set(s->data, %d, v, n);", size)
+	b.W("}")
+	b.W("#endif\n")
+}
+
+// genDecl declares the struct or list t after the structs and lists it embeds.
+func (c *Compiler) genDecl(pkg *cPackage, t *Type, b *Builder, done map[string]bool) error {
+	if t.Kind == KindStruct {
+		if t.Import != nil {
+			return nil
+		}
+		t = t.Base()
+	}
+	name, err := c.typeName(pkg, t)
+	if err != nil || done[name] {
+		return err
+	}
+	done[name] = true
+
+	switch t.Kind {
+	case KindStruct:
+		for _, field := range t.Struct.Fields {
+			if field.Type.Kind == KindStruct || field.Type.Kind == KindList {
+				if err = c.genDecl(pkg, field.Type, b, done); err != nil {
+					return err
+				}
+			}
+		}
+		return c.genStruct(pkg, name, t.Struct, b)
+	case KindList:
+		if t.Element.Kind == KindStruct || t.Element.Kind == KindList {
+			if err = c.genDecl(pkg, t.Element, b, done); err != nil {
+				return err
+			}
+		}
+		return c.genList(pkg, name, t, b)
+	}
+	return nil
+}
+
+func (c *Compiler) genStruct(pkg *cPackage, name string, st *Struct, b *Builder) error {
+	t := st.Type
+	if t.Size == 0 {
+		return fmt.Errorf("%s:%d empty struct '%s' is not supported by the C compiler",
+			t.File.Path, t.Line.Number, st.Name)
+	}
+	W := b.W
+	c.writeComments("", b, t.Doc())
+	compile.WriteDeprecated(b, "", "//", st.Deprecated, st.DeprecatedMessage)
+	W("typedef struct %s {", name)
+	if t.HeaderSize > 0 {
+		W("    uint8_t _optionals[%d];", t.HeaderSize)
+	}
+	pads := 0
+	for _, field := range st.Fields {
+		if field.Type.Kind == KindPad && len(field.Name) == 0 {
+			W("    uint8_t _pad%d[%d];", pads, field.Type.Size)
+			pads++
+			continue
+		}
+		decl, err := c.declaration(pkg, field.Type, fieldName(field.Name))
+		if err != nil {
+			return err
+		}
+		c.writeComments("    ", b, field.Type.Doc())
+		compile.WriteDeprecated(b, "    ", "//", field.Deprecated, field.DeprecatedMessage)
+		if field.Type.Optional {
+			decl += fmt.Sprintf(" // optional: _optionals[%d] & %d", field.OptOffset, field.OptMask)
+		}
+		W("    %s", decl)
+	}
+	W("} %s;\n", name)
+
+	W("MOON_STATIC_ASSERT(sizeof(%s) == %d, \"sizeof %s\");", name, t.Size, name)
+	for _, field := range st.Fields {
+		if field.Type.Kind == KindPad {
+			continue
+		}
+		member := fieldName(field.Name)
+		W("MOON_STATIC_ASSERT(offsetof(%s, %s) == %d, \"offsetof %s.%s\");",
+			name, member, field.Offset, name, member)
+		W("MOON_STATIC_ASSERT(sizeof(((%s *)0)->%s) == %d, \"sizeof %s.%s\");",
+			name, member, field.Type.Size, name, member)
+	}
+	W("")
+
+	for _, field := range st.Fields {
+		if !field.Type.Optional {
+			continue
+		}
+		member := fieldName(field.Name)
+		W("static inline bool %s_has_%s(const %s *v) {", name, field.Name, name)
+		W("    return (v->_optionals[%d] & %d) != 0;", field.OptOffset, field.OptMask)
+		W("}\n")
+		W("// %s_set_has_%s sets whether %s is present. Clearing it zeroes the field.", name, field.Name, member)
+		W("static inline void %s_set_has_%s(%s *v, bool has) {", name, field.Name, name)
+		W("    if (has) {")
+		W("        v->_optionals[%d] |= %d;", field.OptOffset, field.OptMask)
+		W("    } else {")
+		W("        v->_optionals[%d] &= (uint8_t)~%d;", field.OptOffset, field.OptMask)
+		W("        memset(&v->%s, 0, sizeof(v->%s));", member, member)
+		W("    }")
+		W("}\n")
+	}
+	return nil
+}
+
+func (c *Compiler) genList(pkg *cPackage, name string, t *Type, b *Builder) error {
+	element := t.Element
+	if element.Size != t.ItemSize {
+		return fmt.Errorf("%s:%d list '%s' items of %d bytes hold elements of %d bytes",
+			t.File.Path, t.Line.Number, t.Name, t.ItemSize, element.Size)
+	}
+	decl, err := c.declaration(pkg, element, fmt.Sprintf("items[%d]", t.Len))
+	if err != nil {
+		return err
+	}
+	if element.Kind == KindBytes {
+		decl = fmt.Sprintf("uint8_t items[%d][%d];", t.Len, element.Size)
+	}
+	lengthType := "uint8_t"
+	if t.HeaderSize == 2 {
+		lengthType = "uint16_t"
+	}
+	pad := t.Size - t.Len*t.ItemSize - t.HeaderSize
+
+	W := b.W
+	W("// %s is a fixed list of up to %d elements.", name, t.Len)
+	W("typedef struct %s {", name)
+	W("    %s", decl)
+	if pad > 0 {
+		W("    uint8_t _pad0[%d];", pad)
+	}
+	W("    %s length;", lengthType)
+	W("} %s;\n", name)
+	W("MOON_STATIC_ASSERT(sizeof(%s) == %d, \"sizeof %s\");", name, t.Size, name)
+	W("MOON_STATIC_ASSERT(offsetof(%s, length) == %d, \"offsetof %s.length\");\n",
+		name, t.Size-t.HeaderSize, name)
+	W("// %s_len returns the number of elements in the list.", name)
+	W("static inline size_t %s_len(const %s *l) {", name, name)
+	W("    return l->length < %d ? l->length : %d;", t.Len, t.Len)
+	W("}\n")
+	return nil
+}
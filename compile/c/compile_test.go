@@ -0,0 +1,161 @@
+package c
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+func TestNewGenerator(t *testing.T) {
+	s, errs := ParseFiles("", map[string][]byte{
+		"common/schema.moon": []byte(`
+struct Span {
+	start u32
+	end   u32
+}
+`),
+		"model/schema.moon": []byte(`
+import "../common/schema.moon"
+
+// Largest payload
+const MTU u32 = 1500
+const PROTO string8 = "udp"
+const KIND Kind = Control
+
+enum Kind : u16 {
+	Data = 0
+	// Connection control
+	Control = 1
+}
+
+struct Packet {
+	seq   u64
+	kind  Kind
+	span  ?common.Span
+	host  string8
+	ports [4]u16
+	hops  [2]Hop
+	mac   bytes6
+}
+
+struct Hop {
+	rtt  f32
+	up   bool
+	int  i16
+	note string300
+}
+`),
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(s, &CConfig{Output: output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{RuntimeFileName, "common" + HeaderSuffix} {
+		if _, err = os.Stat(filepath.Join(output, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "model"+HeaderSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"#ifndef MOON_MODEL_H\n#define MOON_MODEL_H\n\n#include \"_moon.h\"\n#include \"common.moon.h\"\n",
+		"typedef uint16_t model_Kind;\n\nenum {\n    model_Kind_Data = 0,\n    // Connection control\n    model_Kind_Control = 1,\n};",
+		"    case model_Kind_Control:\n        return \"Control\";",
+		"// Largest payload\n#define model_MTU ((uint32_t)1500U)",
+		`#define model_PROTO "udp"`,
+		"#define model_KIND model_Kind_Control",
+		"typedef struct moon_String8 {\n    uint8_t data[8];\n} moon_String8;",
+		"// moon_String300 holds up to 298 bytes with the length in the last two bytes.",
+		"typedef struct model_Packet {\n    uint8_t _optionals[1];\n    uint8_t _pad0[7];\n    uint64_t seq;",
+		"    model_Kind kind;\n",
+		"    common_Span span; // optional: _optionals[0] & 1\n",
+		"    moon_String8 host;\n    model_U164List ports;\n",
+		"    uint8_t mac[6];\n",
+		"    int16_t int_;\n",
+		"typedef struct model_U164List {\n    uint16_t items[4];\n    uint8_t _pad0[7];\n    uint8_t length;\n} model_U164List;",
+		"MOON_STATIC_ASSERT(offsetof(model_Packet, seq) == 8, \"offsetof model_Packet.seq\");",
+		"MOON_STATIC_ASSERT(sizeof(((model_Packet *)0)->span) == 8, \"sizeof model_Packet.span\");",
+		"static inline bool model_Packet_has_span(const model_Packet *v) {\n    return (v->_optionals[0] & 1) != 0;\n}",
+		"static inline size_t model_U164List_len(const model_U164List *l) {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
+	// Structs and lists are declared before the structs embedding them.
+	if strings.Index(code, "} model_Hop;") > strings.Index(code, "} model_Hop2List;") ||
+		strings.Index(code, "} model_Hop2List;") > strings.Index(code, "} model_Packet;") {
+		t.Fatalf("declarations out of order\n%s", code)
+	}
+
+	// The static assertions check the layout when a C compiler is available.
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler")
+	}
+	source := filepath.Join(output, "main.c")
+	if err = os.WriteFile(source, []byte(`#include "model.moon.h"
+
+int main(void) {
+    model_Packet p = {0};
+    moon_String8_set(&p.host, "localhost", 9);
+    model_Packet_set_has_span(&p, true);
+    p.span.end = 2;
+    if (moon_String8_len(&p.host) != 7 || !model_Packet_has_span(&p) || p._optionals[0] != 1) {
+        return 1;
+    }
+    model_Packet_set_has_span(&p, false);
+    if (p.span.end != 0 || model_U164List_len(&p.ports) != 0) {
+        return 2;
+    }
+    return 0;
+}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(output, "main")
+	if out, err := exec.Command(cc, "-std=c11", "-Wall", "-Werror", "-I", output, "-o", binary, source).CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if out, err := exec.Command(binary).CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Note {
+	text string
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, _ := NewCompiler(s, &CConfig{})
+	if _, err = compiler.Generate(); err == nil || !strings.Contains(err.Error(), "variable length") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package c
+
+import . "github.com/moontrade/proto/schema"
+
+// HeaderSuffix ends the name of the header generated for each package. The header of
+// package model is model.moon.h in the output directory.
+const HeaderSuffix = ".moon.h"
+
+// RuntimeFileName is the header of helpers the generated headers share. It is written
+// to the root of the output directory, which consumers add to the include path.
+const RuntimeFileName = "_moon.h"
+
+// Configuration for the C header generator
+type CConfig struct {
+	Output string
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
+}
+
+// Generates C headers
+type Compiler struct {
+	schema   *Schema
+	config   *CConfig
+	packages map[string]*cPackage
+}
+
+type cPackage struct {
+	files []*File
+	path  string
+	// prefix of every name declared by the package
+	prefix string
+
+	// Included headers by the path of the imported package
+	includes map[string]struct{}
+	lists    map[string]*Type
+	strings  map[int]struct{}
+}
+
+// primitives maps a fixed size primitive to its C type.
+var primitives = map[Kind]string{
+	KindBool:    "bool",
+	KindByte:    "uint8_t",
+	KindInt8:    "int8_t",
+	KindInt16:   "int16_t",
+	KindUInt16:  "uint16_t",
+	KindInt32:   "int32_t",
+	KindUInt32:  "uint32_t",
+	KindInt64:   "int64_t",
+	KindUInt64:  "uint64_t",
+	KindFloat32: "float",
+	KindFloat64: "double",
+}
+
+// keywords are C and C++ keywords that cannot name a field.
+var keywords = map[string]bool{
+	"auto": true, "bool": true, "break": true, "case": true, "catch": true, "char": true,
+	"class": true, "const": true, "continue": true, "default": true, "delete": true,
+	"do": true, "double": true, "else": true, "enum": true, "explicit": true, "extern": true,
+	"float": true, "for": true, "friend": true, "goto": true, "if": true, "inline": true,
+	"int": true, "long": true, "namespace": true, "new": true, "operator": true,
+	"private": true, "protected": true, "public": true, "register": true, "restrict": true,
+	"return": true, "short": true, "signed": true, "sizeof": true, "static": true,
+	"struct": true, "switch": true, "template": true, "this": true, "throw": true,
+	"try": true, "typedef": true, "typename": true, "union": true, "unsigned": true,
+	"virtual": true, "void": true, "volatile": true, "while": true,
+}
@@ -0,0 +1,51 @@
+package c
+
+// runtime is the source of RuntimeFileName. Fixed strings hold their length in the last
+// byte, or in the last two bytes little endian when they are over 256 bytes.
+const runtime = `// Code generated by moonc. DO NOT EDIT.
+#ifndef MOON_H
+#define MOON_H
+
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+#include <string.h>
+
+#ifdef __cplusplus
+#define MOON_STATIC_ASSERT(cond, msg) static_assert(cond, msg)
+#else
+#define MOON_STATIC_ASSERT(cond, msg) _Static_assert(cond, msg)
+#endif
+
+// moon_string_cap returns the number of bytes a fixed string of size bytes can hold.
+static inline size_t moon_string_cap(size_t size) {
+    return size > 256 ? size - 2 : size - 1;
+}
+
+// moon_string_len returns the length of the fixed string of size bytes at data.
+static inline size_t moon_string_len(const uint8_t *data, size_t size) {
+    size_t cap = moon_string_cap(size);
+    size_t n = size > 256 ? (size_t)data[cap] | (size_t)data[cap + 1] << 8 : (size_t)data[cap];
+    return n < cap ? n : cap;
+}
+
+// moon_string_set copies n bytes of s truncated to the capacity of the fixed string of
+// size bytes at data and zeroes the rest. It returns the length stored.
+static inline size_t moon_string_set(uint8_t *data, size_t size, const char *s, size_t n) {
+    size_t cap = moon_string_cap(size);
+    if (n > cap) {
+        n = cap;
+    }
+    memcpy(data, s, n);
+    memset(data + n, 0, cap - n);
+    if (size > 256) {
+        data[cap] = (uint8_t)n;
+        data[cap + 1] = (uint8_t)(n >> 8);
+    } else {
+        data[cap] = (uint8_t)n;
+    }
+    return n;
+}
+
+#endif
+`
@@ -0,0 +1,43 @@
+package compile
+
+import (
+	"strings"
+
+	"github.com/moontrade/proto/schema"
+)
+
+// CheckDeprecated returns the first use of a deprecated struct, enum or enum option by a
+// definition that is not itself deprecated. Compilers call it when configured with
+// FailOnDeprecated.
+func CheckDeprecated(s *schema.Schema) error {
+	if errs := s.DeprecatedUses(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// WriteDeprecated writes a line comment starting with comment when the declaration is
+// annotated with @deprecated in the schema.
+func WriteDeprecated(b *schema.Builder, prefix, comment string, deprecated bool, msg string) {
+	if !deprecated {
+		return
+	}
+	if len(msg) == 0 {
+		b.W("%s%s Deprecated.", prefix, comment)
+		return
+	}
+	b.W("%s%s Deprecated: %s", prefix, comment, msg)
+}
+
+// WriteJSDocDeprecated writes a JSDoc @deprecated tag when the declaration is annotated
+// with @deprecated in the schema.
+func WriteJSDocDeprecated(b *schema.Builder, prefix string, deprecated bool, msg string) {
+	if !deprecated {
+		return
+	}
+	if len(msg) == 0 {
+		b.W("%s/** @deprecated */", prefix)
+		return
+	}
+	b.W("%s/** @deprecated %s */", prefix, strings.ReplaceAll(msg, "*/", "* /"))
+}
@@ -4,10 +4,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"github.com/moontrade/proto/compile"
 	. "github.com/moontrade/proto/schema"
-	"io/fs"
+	"go/format"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -21,101 +21,87 @@ func NewCompiler(schema *Schema, config *Config) (*Compiler, error) {
 	}, nil
 }
 
-func (c *Compiler) Compile() error {
+// Generate generates the Go source of every package in memory. Paths are relative to the
+// output directory. Sources are formatted with go/format unless NoGoFmt is set.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
 	// Convert into Go specific model
 	packages := make(map[string]*goPackage)
-	var (
-		err    error
-		output string
-		info   os.FileInfo
-	)
 	for k, v := range c.schema.Files {
-		packages[k], err = c.createPackage(v, 0)
+		pkg, err := c.createPackage(v, 0)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		packages[k] = pkg
 	}
 
-	//path := c.config.Output
-	//path, err = filepath.Abs(path)
-	//output := RelativePath(path, c.config.Output)
-	//output := RelativePath(path, c.config.Output)
-	output, err = filepath.Abs(c.config.Output)
-	if err != nil {
-		return err
-	}
-	_ = os.MkdirAll(output, 0755)
-	info, err = os.Stat(output)
-	if err != nil {
-		return err
-	}
-	if !info.IsDir() {
-		return fmt.Errorf("output directory '%s' is not a directory", output)
-	}
-	//output := filepath.Join(path, c.config.Output)
-	createFile := func(f *goPackage, order binary.ByteOrder) error {
+	files := make(compile.Files)
+	generate := func(f *goPackage, order binary.ByteOrder) error {
 		b := NewBuilder()
 		if err := c.writeFile(f, b, order); err != nil {
 			return err
 		}
-
-		// Compute dir path
-		dir := filepath.Join(output, f.path)
-
-		// mkdir
-		_ = os.MkdirAll(dir, 0755)
-
-		name, err := filepath.Abs(filepath.Join(dir, goFileName(order)))
-		if err != nil {
-			return err
-		}
-		// Create new "moon.go"
-		out, err := os.Create(name)
-		if err != nil {
-			return err
-		}
-		_, err = out.WriteString(b.String())
-		if err != nil {
-			return err
-		}
-
-		if err = out.Sync(); err != nil {
-			return err
-		}
-		if err = out.Close(); err != nil {
-			return err
-		}
-
+		path := filepath.ToSlash(filepath.Join(f.path, goFileName(order)))
+		src := []byte(b.String())
 		if !c.config.NoGoFmt {
-			err = exec.Command("gofmt", "-w", name).Run()
+			formatted, err := format.Source(src)
 			if err != nil {
-
+				return fmt.Errorf("%s: %w", path, err)
 			}
+			src = formatted
 		}
+		files[path] = src
 		return nil
 	}
-	err = filepath.Walk(output, c.walkClear)
 	for _, f := range packages {
-		if err = createFile(f, binary.LittleEndian); err != nil {
-			return err
+		if err := generate(f, binary.LittleEndian); err != nil {
+			return nil, err
 		}
 		if c.config.BigEndian {
-			if err = createFile(f, binary.BigEndian); err != nil {
-				return err
+			if err := generate(f, binary.BigEndian); err != nil {
+				return nil, err
 			}
 		}
+		if c.config.LayoutCheck == LayoutCheckTest && len(f.structs) > 0 {
+			b := NewBuilder()
+			c.genLayoutTest(f, b)
+			path := filepath.ToSlash(filepath.Join(f.path, layoutTestFileName))
+			src, err := format.Source([]byte(b.String()))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			files[path] = src
+		}
 	}
-
-	return nil
+	return files, nil
 }
 
-func (c *Compiler) walkClear(path string, info fs.FileInfo, err error) error {
-	if strings.HasSuffix(path, "proto_be.go") {
-		_ = os.Remove(path)
-	} else if strings.HasSuffix(path, "proto.go") {
-		_ = os.Remove(path)
+// Compile generates the Go source and writes it to the output directory. Unchanged files
+// are left untouched and generated files that are no longer produced are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
 	}
-	return nil
+	output, err := filepath.Abs(c.config.Output)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(output); err == nil && !info.IsDir() {
+		return fmt.Errorf("output directory '%s' is not a directory", output)
+	}
+	_, err = compile.Write(output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the Go compiler generates.
+func IsGenerated(name string) bool {
+	return name == goFileName(binary.LittleEndian) || name == goFileName(binary.BigEndian) ||
+		name == layoutTestFileName
 }
 
 func (c *Compiler) goName(n string, types map[string]*Type) string {
@@ -158,6 +144,33 @@ func (c *Compiler) stringType(file *goPackage, t *Type) *goType {
 	}
 }
 
+// variableType maps a variable length string, bytes or list to a wap.VPointer field.
+// The data lives in the heap portion of the buffer and is accessed through the pointer.
+func (c *Compiler) variableType(pkg *goPackage, t *Type, level int) (*goType, error) {
+	imp := c.addImport(pkg.importMap, wapImportPath, wapImportAlias)
+	name := fmt.Sprintf("%s.VPointer", imp.alias)
+	gt := &goType{
+		pkg:       pkg,
+		t:         t,
+		name:      name,
+		mut:       name,
+		primitive: true,
+		imp:       imp,
+	}
+	if t.Kind == KindList {
+		_ = c.addImport(pkg.importMap, "unsafe", "")
+		element, err := c.resolve(pkg, t.Element, level+1)
+		if err != nil {
+			return nil, err
+		}
+		gt.list = &goList{
+			element: element,
+		}
+	}
+	pkg.byType[t] = gt
+	return gt, nil
+}
+
 func joinWithSlash(elem ...string) string {
 	for i, e := range elem {
 		if e != "" {
@@ -194,6 +207,7 @@ func (c *Compiler) createPackage(file *File, level int) (*goPackage, error) {
 		types:       make(map[string]*goType),
 		structs:     make(map[string]*goType),
 		strings:     make(map[string]*goType),
+		decimals:    make(map[string]*goType),
 		enums:       make(map[string]*goType),
 		lists:       make(map[string]*goType),
 		unions:      make(map[string]*goType),
@@ -211,6 +225,8 @@ func (c *Compiler) createPackage(file *File, level int) (*goPackage, error) {
 		} else if t.Struct != nil {
 			pkg.names["Reinterpret"+n] = struct{}{}
 			pkg.names["Unmarshal"+n] = struct{}{}
+			pkg.names["Verify"+n] = struct{}{}
+			pkg.names["New"+n] = struct{}{}
 		}
 	}
 	for _, value := range file.Types {
@@ -220,6 +236,13 @@ func (c *Compiler) createPackage(file *File, level int) (*goPackage, error) {
 		}
 	}
 
+	if c.config.Msgpack && len(pkg.enums)+len(pkg.structs)+len(pkg.strings)+len(pkg.decimals)+len(pkg.lists) > 0 {
+		_ = c.addImport(pkg.importMap, msgpackImportPath, "msgpack")
+	}
+	if c.config.JSON && len(pkg.enums)+len(pkg.structs)+len(pkg.strings)+len(pkg.decimals)+len(pkg.lists) > 0 {
+		_ = c.addImport(pkg.importMap, runtime2ImportPath, "runtime2")
+	}
+
 	imps := make([]string, 0, len(pkg.importMap))
 	for k := range pkg.importMap {
 		imps = append(imps, k)
@@ -279,36 +302,77 @@ func (c *Compiler) bigEndianFlags(b *Builder) {
 	b.W("")
 }
 
-func (c *Compiler) writeFile(file *goPackage, b *Builder, order binary.ByteOrder) error {
-	W := b.W
+// bigEndianImports are the imports the big-endian accessors decoding the little-endian
+// wire format may use and the code they are used by.
+var bigEndianImports = []struct {
+	path string
+	used string
+}{
+	{"\"encoding/binary\"", "binary.LittleEndian."},
+	{"\"math\"", "math.Float"},
+}
 
+func (c *Compiler) writeFile(file *goPackage, out *Builder, order binary.ByteOrder) error {
 	switch order {
 	case binary.LittleEndian:
-		c.littleEndianFlags(b)
+		c.littleEndianFlags(out)
 	case binary.BigEndian:
-		c.bigEndianFlags(b)
+		c.bigEndianFlags(out)
 	}
 
-	W("package %s\n", file.packageName)
+	out.W("package %s\n", file.packageName)
+
+	// The body is generated first so only the imports it uses are added.
+	b := NewBuilder()
+	if err := c.writeBody(file, b, order); err != nil {
+		return err
+	}
 
-	if len(file.imports) > 0 {
-		W("import (")
-		for _, imp := range file.imports {
-			W("    %s", imp)
+	imports := file.imports
+	if order == binary.BigEndian {
+		// Accessors decode the little-endian wire format from the backing bytes.
+		imports = append(make([]string, 0, len(imports)+len(bigEndianImports)), imports...)
+		for _, imp := range bigEndianImports {
+			if strings.Contains(b.String(), imp.used) {
+				imports = append(imports, imp.path)
+			}
 		}
-		W(")\n")
+		sort.Strings(imports)
 	}
+	if len(imports) > 0 {
+		out.W("import (")
+		for _, imp := range imports {
+			out.W("    %s", imp)
+		}
+		out.W(")\n")
+	}
+	_, _ = out.WriteString(b.String())
+	return nil
+}
+
+func (c *Compiler) writeBody(file *goPackage, b *Builder, order binary.ByteOrder) error {
+	W := b.W
 
-	for _, enum := range file.enums {
+	for _, enum := range sortedTypes(file.enums) {
 		if err := c.genEnum(file, enum, b); err != nil {
 			return err
 		}
+		if c.config.Msgpack {
+			c.genMsgpackEnum(enum, b)
+		}
+		if c.config.JSON {
+			c.genJSONEnum(enum, b)
+		}
+	}
+
+	if err := c.genConsts(file, b); err != nil {
+		return err
 	}
 
 	init := NewBuilder()
 	init.W("func init() {")
 
-	if len(file.structs) > 0 {
+	if len(file.structs) > 0 && order != binary.BigEndian {
 		init.W(`    {
 		var b [2]byte
         v := uint16(1)
@@ -318,54 +382,33 @@ func (c *Compiler) writeFile(file *goPackage, b *Builder, order binary.ByteOrder
 			panic("BigEndian not supported")
 		}
 	}`)
-		_, _ = init.Write([]byte(`    type b struct {
-        n string
-        o, s uintptr
-    }
-    a := func(x interface{}, y interface{}, s uintptr, z []b) {
-        t := reflect.TypeOf(x)
-        r := reflect.TypeOf(y)
-        if t.Size() != s {
-            panic(fmt.Sprintf("sizeof %%s = %%d, expected = %%d", t.Name(), t.Size(), s))
-        }
-        if r.Size() != s {
-            panic(fmt.Sprintf("sizeof %%s = %%d, expected = %%d", r.Name(), r.Size(), s))
-        }
-        if t.NumField() != len(z) {
-            panic(fmt.Sprintf("%%s field count = %%d: expected %%d", t.Name(), t.NumField(), len(z)))
-        }
-        for i, e := range z {
-            f := t.Field(i)
-            if f.Offset != e.o {
-                panic(fmt.Sprintf("%%s.%%s offset = %%d, expected = %%d", t.Name(), f.Name, f.Offset, e.o))
-            }
-            if f.Type.Size() != e.s {
-                panic(fmt.Sprintf("%%s.%%s size = %%d, expected = %%d", t.Name(), f.Name, f.Type.Size(), e.s))
-            }
-            if f.Name != e.n {
-                panic(fmt.Sprintf("%%s.%%s expected field: %%s", t.Name(), f.Name, e.n))
-            }
-        }
-    }`))
-		init.W("")
-		init.W("")
-
-		for _, st := range file.structs {
-			if err := c.genStruct(file, st, false, b, order); err != nil {
+	}
+	if len(file.structs) > 0 && c.config.LayoutCheck == LayoutCheckInit {
+		init.W("    layout := func(name string, actual, expected uintptr) {")
+		init.W("        if actual != expected {")
+		init.W("            panic(fmt.Sprintf(\"%%s = %%d, expected = %%d\", name, actual, expected))")
+		init.W("        }")
+		init.W("    }")
+	}
+	for _, st := range sortedTypes(file.structs) {
+		if err := c.genStruct(file, st, false, b, order); err != nil {
+			return err
+		}
+		if err := c.genStruct(file, st, true, b, order); err != nil {
+			return err
+		}
+		if c.config.Msgpack {
+			if err := c.genMsgpackStruct(st, b); err != nil {
 				return err
 			}
-			if err := c.genStruct(file, st, true, b, order); err != nil {
+		}
+		if c.config.JSON {
+			if err := c.genJSONStruct(st, b); err != nil {
 				return err
 			}
-
-			init.W("    a(%s{}, %s{}, %d, []b{", st.name, st.mut, st.t.Size)
-			if st.t.HeaderSize > 0 {
-				init.W("        {\"%s\", %d, %d},", headerFieldName, 0, st.t.HeaderSize)
-			}
-			for _, field := range st.st.fields {
-				init.W("        {\"%s\", %d, %d},", field.private, field.field.Offset, field.t.t.Size)
-			}
-			init.W("    })")
+		}
+		if c.config.LayoutCheck == LayoutCheckInit {
+			c.genLayoutCheck(init, st, "layout")
 		}
 	}
 
@@ -378,33 +421,69 @@ func (c *Compiler) writeFile(file *goPackage, b *Builder, order binary.ByteOrder
 	//	}
 	//}
 
-	for _, str := range file.strings {
+	for _, str := range sortedTypes(file.strings) {
 		if err := c.genString(str, false, b, order); err != nil {
 			return err
 		}
 		if err := c.genString(str, true, b, order); err != nil {
 			return err
 		}
+		if c.config.Msgpack {
+			c.genMsgpackString(str, b)
+		}
+		if c.config.JSON {
+			c.genJSONString(str, b)
+		}
+	}
+
+	for _, decimal := range sortedTypes(file.decimals) {
+		c.genDecimal(decimal, b)
+		if c.config.Msgpack {
+			c.genMsgpackDecimal(decimal, b)
+		}
+		if c.config.JSON {
+			c.genJSONDecimal(decimal, b)
+		}
 	}
 
-	for _, list := range file.lists {
+	for _, list := range sortedTypes(file.lists) {
 		if err := c.genArrayList(list, false, b, order); err != nil {
 			return err
 		}
 		if err := c.genArrayList(list, true, b, order); err != nil {
 			return err
 		}
+		if c.config.Msgpack {
+			c.genMsgpackList(list, b, order)
+		}
+		if c.config.JSON {
+			c.genJSONList(list, b)
+		}
 	}
 
 	initStr := init.String()
 	if initStr != "func init() {\n" {
-		W(initStr)
+		_, _ = b.WriteString(initStr)
 		W("}\n")
 	}
 
 	return nil
 }
 
+// sortedTypes returns the types ordered by name so generated code is deterministic.
+func sortedTypes(types map[string]*goType) []*goType {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*goType, 0, len(types))
+	for _, name := range names {
+		result = append(result, types[name])
+	}
+	return result
+}
+
 func (c *goPackage) uniqueName(n string) string {
 	for {
 		if _, ok := c.names[n]; ok {
@@ -454,6 +533,10 @@ func (c *Compiler) resolve(pkg *goPackage, t *Type, level int) (*goType, error)
 		}, nil
 	}
 
+	if t.IsVariable() {
+		return c.variableType(pkg, t, level)
+	}
+
 	switch t.Kind {
 	case KindStruct:
 		structName := Capitalize(t.Struct.Name)
@@ -466,8 +549,8 @@ func (c *Compiler) resolve(pkg *goPackage, t *Type, level int) (*goType, error)
 		}
 		_ = c.addImport(pkg.importMap, "fmt", "")
 		_ = c.addImport(pkg.importMap, "io", "")
-		_ = c.addImport(pkg.importMap, "reflect", "")
 		_ = c.addImport(pkg.importMap, "unsafe", "")
+		_ = c.addImport(pkg.importMap, wapImportPath, wapImportAlias)
 
 		fields := make([]*goField, 0, len(t.Struct.Fields))
 		names := make(map[string]struct{})
@@ -562,8 +645,10 @@ func (c *Compiler) resolve(pkg *goPackage, t *Type, level int) (*goType, error)
 
 	case KindList:
 		_ = c.addImport(pkg.importMap, "fmt", "")
+		_ = c.addImport(pkg.importMap, "io", "")
 		_ = c.addImport(pkg.importMap, "reflect", "")
 		_ = c.addImport(pkg.importMap, "unsafe", "")
+		_ = c.addImport(pkg.importMap, wapImportPath, wapImportAlias)
 		element, err := c.resolve(pkg, t.Element, level+1)
 		if err != nil {
 			return nil, err
@@ -613,10 +698,15 @@ func (c *Compiler) resolve(pkg *goPackage, t *Type, level int) (*goType, error)
 	case KindFloat64:
 		return c.primitive(pkg, t, "float64"), nil
 	case KindString, KindBytes:
+		if t.Kind == KindString {
+			_ = c.addImport(pkg.importMap, wapImportPath, wapImportAlias)
+		}
 		gt := c.stringType(pkg, t)
 		pkg.strings[gt.name] = gt
 		pkg.byType[t] = gt
 		return gt, nil
+	case KindDecimal64, KindDecimal128:
+		return c.decimalType(pkg, t), nil
 
 	case KindPad:
 		return &goType{
@@ -635,6 +725,20 @@ func (c *Compiler) resolve(pkg *goPackage, t *Type, level int) (*goType, error)
 	return nil, fmt.Errorf("type not supported yet: %s:%d %s", t.File.Path, t.Line.Number, t.Name)
 }
 
+// listZero returns the zero value of a list element. Fixed strings and bytes are
+// arrays even though they are marked primitive.
+func listZero(element *goType) string {
+	switch {
+	case element.t.Kind == KindBool:
+		return "false"
+	case element.t.Kind == KindString || element.t.Kind == KindBytes:
+		return element.name + "{}"
+	case element.primitive || element.t.Kind == KindEnum:
+		return "0"
+	}
+	return element.name + "{}"
+}
+
 func (c *Compiler) isPointerType(t *Type) bool {
 	switch t.Kind {
 	case KindString, KindStruct, KindUnion, KindList, KindMap:
@@ -653,6 +757,22 @@ func (c *Compiler) genComments(b *Builder, comments []string) {
 	}
 }
 
+// genDoc writes the comments followed by a "Deprecated:" paragraph when the
+// declaration is annotated with @deprecated.
+func (c *Compiler) genDoc(b *Builder, comments []string, deprecated bool, msg string) {
+	c.genComments(b, comments)
+	if !deprecated {
+		return
+	}
+	if len(comments) > 0 {
+		b.W("//")
+	}
+	if len(msg) == 0 {
+		msg = "marked @deprecated in the schema."
+	}
+	b.W("// Deprecated: %s", msg)
+}
+
 func (c *Compiler) fieldName(f string) string {
 	f = Capitalize(f)
 	switch f {
@@ -682,8 +802,18 @@ func (c *Compiler) fieldName(f string) string {
 		return "Copy_"
 	case "MarshalMap":
 		return "MarshalMap_"
+	case "AppendVariable":
+		return "AppendVariable_"
 	case "String":
 		return "String_"
+	case "Verify":
+		return "Verify_"
+	case "AppendMsgpack":
+		return "AppendMsgpack_"
+	case "UnmarshalMsgpack":
+		return "UnmarshalMsgpack_"
+	case "UnmarshalJSONLexer":
+		return "UnmarshalJSONLexer_"
 	}
 	return f
 }
@@ -722,6 +852,8 @@ func (c *Compiler) goTypeName(t *Type) string {
 		return "float64"
 	case KindString, KindBytes:
 		return "string"
+	case KindDecimal64, KindDecimal128:
+		return DecimalTypeName(t.Kind, t.Scale)
 	case KindEnum:
 		return Capitalize(t.Enum.Name)
 	case KindStruct:
@@ -737,27 +869,26 @@ func (c *Compiler) goTypeName(t *Type) string {
 }
 
 func (c *Compiler) genEnum(file *goPackage, t *goType, b *Builder) error {
-	c.genComments(b, t.t.Comments)
+	c.genDoc(b, t.t.Base().Doc(), t.t.Enum.Deprecated, t.t.Enum.DeprecatedMessage)
 	b.W("type %s %s\n", t.name, t.enum.value.name)
 
 	b.W("const (")
 	for _, option := range t.enum.options {
-		if len(option.option.Comments) > 0 {
-			c.genComments(b, option.option.Comments)
-		}
+		c.genDoc(b, option.option.Doc(), option.option.Deprecated, option.option.DeprecatedMessage)
 		b.W("    %s = %s(%d)", option.name, t.name, option.option.Value)
 		//if i < len(t.enum.options)-1 {
 		//	b.W("")
 		//}
 	}
 	b.W(")\n")
+	c.genEnumValid(b, t)
 	return nil
 }
 
 func (c *Compiler) genStructBytes(file *goPackage, t *goType, mut bool, b *Builder, order binary.ByteOrder) error {
 	//_ = c.genStruct(file, t, mut, b)
 	st := t.st
-	c.genComments(b, t.t.Comments)
+	c.genComments(b, t.t.Base().Doc())
 	name := t.name
 	if mut {
 		name = t.mut
@@ -971,7 +1102,7 @@ func (c *Compiler) genStructFieldGetter(mut bool, b *Builder, st *goType, f *goF
 	if mut {
 		getBuffer = fmt.Sprintf("s.%s", st.name)
 	}
-	c.genComments(b, f.field.Type.Comments)
+	c.genComments(b, f.field.Type.Doc())
 
 	if f.field.Type.Optional {
 		/*
@@ -1017,7 +1148,7 @@ func (c *Compiler) genStructFieldSetter(b *Builder, st *goType, f *goField, orde
 	goStructName := st.mut
 	fieldName := f.public
 	typeName := f.t.name
-	c.genComments(b, f.field.Type.Comments)
+	c.genComments(b, f.field.Type.Doc())
 
 	getBuffer := fmt.Sprintf("s.%s", st.name)
 
@@ -1082,7 +1213,7 @@ func (c *Compiler) genStructFieldSetter(b *Builder, st *goType, f *goField, orde
 
 func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, order binary.ByteOrder) error {
 	st := t.st
-	c.genComments(b, t.t.Comments)
+	c.genDoc(b, t.t.Base().Doc(), t.t.Struct.Deprecated, t.t.Struct.DeprecatedMessage)
 	W := b.W
 
 	headerName := ""
@@ -1097,14 +1228,22 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 		W("}")
 
 		W("func (s *%s) Clone() *%s {", t.mut, t.mut)
-		W("    v := &%s{}", t.mut)
-		W("    *v = *s")
-		W("    return v")
+		if t.t.HasVariable() {
+			W("    return s.%s.Clone().Mut()", t.name)
+		} else {
+			W("    v := &%s{}", t.mut)
+			W("    *v = *s")
+			W("    return v")
+		}
 		W("}")
 
 		W("func (s *%s) Freeze() *%s {", t.mut, t.name)
 		W("    return (*%s)(unsafe.Pointer(s))", t.name)
 		W("}")
+
+		if err := c.genDefaults(b, t); err != nil {
+			return err
+		}
 	} else {
 		W("type %s struct {", t.name)
 
@@ -1133,6 +1272,10 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 		W("    return fmt.Sprintf(\"%%v\", s.MarshalMap(nil))")
 		W("}\n")
 
+		if c.config.BoundsChecked && t.t.HasVariable() {
+			W("// MarshalMap leaves out variable length fields since reading them safely needs")
+			W("// the buffer.")
+		}
 		W("func (s *%s) MarshalMap(m map[string]interface{}) map[string]interface{} {", t.name)
 		W("    if m == nil {")
 		W("        m = make(map[string]interface{})")
@@ -1141,6 +1284,9 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 			if field.t.t.Kind == KindPad {
 				continue
 			}
+			if c.config.BoundsChecked && field.field.Type.IsVariable() {
+				continue
+			}
 			fieldName := Capitalize(field.public)
 			switch field.field.Type.Kind {
 			case KindStruct:
@@ -1157,7 +1303,9 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 					W("    m[\"%s\"] = s.%s().MarshalMap(nil)", field.field.Name, fieldName)
 				}
 			case KindList:
-				if field.field.Type.Optional {
+				if field.field.Type.IsVariable() {
+					W("    m[\"%s\"] = s.%s()", field.field.Name, fieldName)
+				} else if field.field.Type.Optional {
 					W("    {")
 					W("        v := s.%s()", fieldName)
 					W("        if v == nil {")
@@ -1206,62 +1354,73 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 			}
 		*/
 
-		W("func (s *%s) ReadFrom(r io.Reader) (int64, error) {", t.name)
-		W("    n, err := io.ReadFull(r, (*(*[%d]byte)(unsafe.Pointer(s)))[0:])", t.t.Size)
-		W("    if err != nil {")
-		W("        return int64(n), err")
-		W("    }")
-		W("    if n != %d {", t.t.Size)
-		W("        return int64(n), io.ErrShortBuffer")
-		W("    }")
-		W("    return int64(n), nil")
-		W("}")
+		if t.t.HasVariable() {
+			c.genVariableCopy(b, t)
+		} else {
+			W("func (s *%s) ReadFrom(r io.Reader) (int64, error) {", t.name)
+			W("    n, err := io.ReadFull(r, (*(*[%d]byte)(unsafe.Pointer(s)))[0:])", t.t.Size)
+			W("    if err != nil {")
+			W("        return int64(n), err")
+			W("    }")
+			W("    if n != %d {", t.t.Size)
+			W("        return int64(n), io.ErrShortBuffer")
+			W("    }")
+			W("    return int64(n), nil")
+			W("}")
 
-		W("func (s *%s) WriteTo(w io.Writer) (int64, error) {", t.name)
-		W("    n, err := w.Write((*(*[%d]byte)(unsafe.Pointer(s)))[0:])", t.t.Size)
-		W("    return int64(n), err")
-		W("}")
+			W("func (s *%s) WriteTo(w io.Writer) (int64, error) {", t.name)
+			W("    n, err := w.Write((*(*[%d]byte)(unsafe.Pointer(s)))[0:])", t.t.Size)
+			W("    return int64(n), err")
+			W("}")
 
-		W("func (s *%s) MarshalBinaryTo(b []byte) []byte {", t.name)
-		W("    return append(b, (*(*[%d]byte)(unsafe.Pointer(s)))[0:]...)", t.t.Size)
-		W("}")
+			W("func (s *%s) MarshalBinaryTo(b []byte) []byte {", t.name)
+			W("    return append(b, (*(*[%d]byte)(unsafe.Pointer(s)))[0:]...)", t.t.Size)
+			W("}")
 
-		W("func (s *%s) MarshalBinary() ([]byte, error) {", t.name)
-		W("    var v []byte")
-		W("    return append(v, (*(*[%d]byte)(unsafe.Pointer(s)))[0:]...), nil", t.t.Size)
-		W("}")
+			W("func (s *%s) MarshalBinary() ([]byte, error) {", t.name)
+			W("    var v []byte")
+			W("    return append(v, (*(*[%d]byte)(unsafe.Pointer(s)))[0:]...), nil", t.t.Size)
+			W("}")
 
-		W("func (s *%s) Read(b []byte) (n int, err error) {", t.name)
-		W("    if len(b) < %d {", t.t.Size)
-		W("        return -1, io.ErrShortBuffer")
-		W("    }")
-		W("    v := (*%s)(unsafe.Pointer(&b[0]))", t.name)
-		W("    *v = *s")
-		W("    return %d, nil", t.t.Size)
-		W("}")
+			W("func (s *%s) Read(b []byte) (n int, err error) {", t.name)
+			W("    if len(b) < %d {", t.t.Size)
+			W("        return -1, io.ErrShortBuffer")
+			W("    }")
+			W("    v := (*%s)(unsafe.Pointer(&b[0]))", t.name)
+			W("    *v = *s")
+			W("    return %d, nil", t.t.Size)
+			W("}")
 
-		W("func (s *%s) UnmarshalBinary(b []byte) error {", t.name)
-		W("    if len(b) < %d {", t.t.Size)
-		W("        return io.ErrShortBuffer")
-		W("    }")
-		W("    v := (*%s)(unsafe.Pointer(&b[0]))", t.name)
-		W("    *s = *v")
-		W("    return nil")
-		W("}")
+			W("func (s *%s) UnmarshalBinary(b []byte) error {", t.name)
+			W("    if len(b) < %d {", t.t.Size)
+			W("        return io.ErrShortBuffer")
+			W("    }")
+			W("    v := (*%s)(unsafe.Pointer(&b[0]))", t.name)
+			if c.config.BoundsChecked {
+				W("    if err := v.Verify(b); err != nil {")
+				W("        return err")
+				W("    }")
+			}
+			W("    *s = *v")
+			W("    return nil")
+			W("}")
 
-		W("func (s *%s) Clone() *%s {", t.name, t.name)
-		W("    v := &%s{}", t.name)
-		W("    *v = *s")
-		W("    return v")
-		W("}")
+			W("func (s *%s) Clone() *%s {", t.name, t.name)
+			W("    v := &%s{}", t.name)
+			W("    *v = *s")
+			W("    return v")
+			W("}")
 
-		W("func (s *%s) Bytes() []byte {", t.name)
-		W("    return (*(*[%d]byte)(unsafe.Pointer(s)))[0:]", t.t.Size)
-		W("}")
+			W("func (s *%s) Bytes() []byte {", t.name)
+			W("    return (*(*[%d]byte)(unsafe.Pointer(s)))[0:]", t.t.Size)
+			W("}")
+		}
 
 		W("func (s *%s) Mut() *%s {", t.name, t.mut)
 		W("    return (*%s)(unsafe.Pointer(s))", t.mut)
 		W("}")
+
+		c.genVerifyStruct(b, t, order)
 	}
 
 	// Getters
@@ -1270,9 +1429,19 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 			continue
 		}
 
+		deprecated := func() {
+			c.genDoc(b, nil, field.field.Deprecated, field.field.DeprecatedMessage)
+		}
+
+		if field.field.Type.IsVariable() {
+			c.genVariableField(b, t, field, mut, order)
+			continue
+		}
+
 		if field.field.Type.Optional {
 			if mut {
 				if field.t.name != field.t.mut {
+					deprecated()
 					W("func (s *%s) %s() *%s {", t.mut, field.public, field.t.mut)
 					W("    if s.%s[%d]&%d == 0 {", headerName, field.field.OptOffset, field.field.OptMask)
 					W("        return nil")
@@ -1281,6 +1450,7 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 					W("}")
 				}
 
+				deprecated()
 				W("func (s *%s) Set%s(v *%s) *%s {", t.mut, field.public, field.t.name, t.mut)
 				W("    if v == nil {")
 				W("        s.%s[%d] = s.%s[%d] &^ %d",
@@ -1292,15 +1462,26 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 				)
 				W("        return s")
 				W("    }")
-				W("    s.%s = *v", field.private)
+				if c.swaps(field.field.Type, order) {
+					W("    %s", c.encodeLE(field.field.Type, "&s."+field.private, "*v"))
+				} else {
+					W("    s.%s = *v", field.private)
+				}
+				W("    s.%s[%d] |= %d", headerName, field.field.OptOffset, field.field.OptMask)
 				W("    return s")
 				W("}")
 			} else {
+				deprecated()
 				W("func (s *%s) %s() *%s {", t.name, field.public, field.t.name)
 				W("    if s.%s[%d]&%d == 0 {", headerName, field.field.OptOffset, field.field.OptMask)
 				W("        return nil")
 				W("    }")
-				W("    return &s.%s", field.private)
+				if c.swaps(field.field.Type, order) {
+					W("    v := %s", c.decodeLE(field.field.Type, field.t.name, "&s."+field.private))
+					W("    return &v")
+				} else {
+					W("    return &s.%s", field.private)
+				}
 				W("}")
 			}
 		} else {
@@ -1310,29 +1491,42 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 						if strings.HasSuffix(field.t.mut, "_") {
 							field.t.mut = field.t.mut[0 : len(field.t.mut)-1]
 						}
+						deprecated()
 						W("func (s *%s) %s() *%s {", t.mut, field.public, field.t.mut)
 						W("    return s.%s.Mut()", field.private)
 						W("}")
 					}
 
+					deprecated()
 					W("func (s *%s) Set%s(v *%s) *%s {", t.mut, field.public, field.t.name, t.mut)
 					W("    s.%s = *v", field.private)
 					W("    return s")
 					W("}")
 				} else {
+					deprecated()
 					W("func (s *%s) Set%s(v %s) *%s {", t.mut, field.public, field.t.name, t.mut)
-					W("    s.%s = v", field.private)
+					if c.swaps(field.field.Type, order) {
+						W("    %s", c.encodeLE(field.field.Type, "&s."+field.private, "v"))
+					} else {
+						W("    s.%s = v", field.private)
+					}
 					W("    return s")
 					W("}")
 				}
 			} else {
 				if field.isPointer || field.t.t.Kind == KindBytes {
+					deprecated()
 					W("func (s *%s) %s() *%s {", t.name, field.public, field.t.name)
 					W("    return &s.%s", field.private)
 					W("}")
 				} else {
+					deprecated()
 					W("func (s *%s) %s() %s {", t.name, field.public, field.t.name)
-					W("    return s.%s", field.private)
+					if c.swaps(field.field.Type, order) {
+						W("    return %s", c.decodeLE(field.field.Type, field.t.name, "&s."+field.private))
+					} else {
+						W("    return s.%s", field.private)
+					}
 					W("}")
 				}
 			}
@@ -1342,11 +1536,249 @@ func (c *Compiler) genStruct(file *goPackage, t *goType, mut bool, b *Builder, o
 	return nil
 }
 
+// genVariableCopy generates the methods copying a struct that has variable length fields.
+// They copy the data the VPointers point to along with the struct and rebase the
+// VPointers of the copy. Reading a struct from bytes or a reader is left out since its
+// size is only known once its VPointers are followed.
+func (c *Compiler) genVariableCopy(b *Builder, t *goType) {
+	W := b.W
+	W("func (s *%s) WriteTo(w io.Writer) (int64, error) {", t.name)
+	W("    n, err := w.Write(s.MarshalBinaryTo(nil))")
+	W("    return int64(n), err")
+	W("}")
+
+	W("// MarshalBinaryTo appends s followed by the variable length data it points to.")
+	W("func (s *%s) MarshalBinaryTo(b []byte) []byte {", t.name)
+	W("    start := len(b)")
+	W("    b = append(b, (*(*[%d]byte)(unsafe.Pointer(s)))[0:]...)", t.t.Size)
+	W("    return s.AppendVariable(b, start, start)")
+	W("}")
+
+	W("func (s *%s) MarshalBinary() ([]byte, error) {", t.name)
+	W("    return s.MarshalBinaryTo(nil), nil")
+	W("}")
+
+	W("func (s *%s) Read(b []byte) (n int, err error) {", t.name)
+	W("    v := s.MarshalBinaryTo(nil)")
+	W("    if len(b) < len(v) {")
+	W("        return -1, io.ErrShortBuffer")
+	W("    }")
+	W("    return copy(b, v), nil")
+	W("}")
+
+	W("// Clone copies s and the variable length data it points to into a new buffer.")
+	W("func (s *%s) Clone() *%s {", t.name, t.name)
+	W("    b := s.MarshalBinaryTo(make([]byte, 0, %d))", t.t.Size)
+	W("    return (*%s)(unsafe.Pointer(&b[0]))", t.name)
+	W("}")
+
+	W("// AppendVariable appends the variable length data of s to b and points the VPointers of")
+	W("// the copy of s at offset at in b to it. The data is aligned relative to start, the")
+	W("// offset of the outermost copy in b.")
+	W("func (s *%s) AppendVariable(b []byte, start, at int) []byte {", t.name)
+	for _, field := range t.st.fields {
+		ft := field.field.Type
+		switch {
+		case ft.Kind == KindPad:
+		case ft.IsVariable():
+			align := 1
+			if ft.Kind == KindList {
+				align, _ = variableListLayout(ft)
+			}
+			W("    b = %s.AppendVPointer(b, start, at+%d, &s.%s, %d)", field.t.imp.alias, field.field.Offset, field.private, align)
+		case ft.HasVariable():
+			W("    b = s.%s.AppendVariable(b, start, at+%d)", field.private, field.field.Offset)
+		}
+	}
+	W("    return b")
+	W("}")
+}
+
+// genVariableField generates accessors for a variable length field. Setters take the
+// *wap.Mutable that owns the buffer since writing may grow and reallocate it. Any
+// pointer into the buffer, including the receiver, must be re-fetched afterwards.
+func (c *Compiler) genVariableField(b *Builder, t *goType, field *goField, mut bool, order binary.ByteOrder) {
+	W := b.W
+	ft := field.field.Type
+	alias := field.t.imp.alias
+	if !mut && c.config.BoundsChecked {
+		c.genCheckedVariableField(b, t, field, order)
+		return
+	}
+	c.genDoc(b, nil, field.field.Deprecated, field.field.DeprecatedMessage)
+	if !mut && order == binary.BigEndian {
+		// The VPointer offset and the elements are decoded from little-endian.
+		switch ft.Kind {
+		case KindString:
+			W("func (s *%s) %s() string {", t.name, field.public)
+			W("    p, n := %s.SlabLE(&s.%s, 4)", alias, field.private)
+			W("    if n == 0 {")
+			W("        return \"\"")
+			W("    }")
+			W("    v := unsafe.Slice((*byte)(p), n)")
+			W("    return *(*string)(unsafe.Pointer(&v))")
+			W("}")
+		case KindBytes:
+			W("func (s *%s) %s() []byte {", t.name, field.public)
+			W("    p, n := %s.SlabLE(&s.%s, 4)", alias, field.private)
+			W("    if n == 0 {")
+			W("        return nil")
+			W("    }")
+			W("    return unsafe.Slice((*byte)(p), n)")
+			W("}")
+		case KindList:
+			element := field.t.list.element
+			_, header := variableListLayout(ft)
+			W("func (s *%s) %s() []%s {", t.name, field.public, element.name)
+			W("    p, n := %s.SlabLE(&s.%s, %d)", alias, field.private, header)
+			W("    if n == 0 {")
+			W("        return nil")
+			W("    }")
+			c.genVariableListElements(b, field.t, "p", "int(n)", "", order)
+			W("}")
+		}
+		return
+	}
+	if !mut {
+		switch ft.Kind {
+		case KindString:
+			W("func (s *%s) %s() string {", t.name, field.public)
+			W("    return s.%s.Str()", field.private)
+			W("}")
+		case KindBytes:
+			W("func (s *%s) %s() []byte {", t.name, field.public)
+			W("    return s.%s.Bytes()", field.private)
+			W("}")
+		case KindList:
+			element := field.t.list.element.name
+			W("func (s *%s) %s() []%s {", t.name, field.public, element)
+			W("    p, n := s.%s.Slab()", field.private)
+			W("    if n == 0 {")
+			W("        return nil")
+			W("    }")
+			_, header := variableListLayout(ft)
+			W("    return unsafe.Slice((*%s)(unsafe.Add(p, %d)), int(n)/%d)", element, header, ft.ItemSize)
+			W("}")
+		}
+		return
+	}
+
+	switch ft.Kind {
+	case KindString:
+		W("func (s *%s) Set%s(m *%s.Mutable, v string) {", t.mut, field.public, alias)
+		W("    m.WStr(&s.%s, v)", field.private)
+		W("}")
+	case KindBytes:
+		W("func (s *%s) Set%s(m *%s.Mutable, v []byte) {", t.mut, field.public, alias)
+		W("    m.WBytes(&s.%s, v)", field.private)
+		W("}")
+	case KindList:
+		element := field.t.list.element
+		W("func (s *%s) Set%s(m *%s.Mutable, v []%s) {", t.mut, field.public, alias, element.name)
+		W("    if len(v) == 0 {")
+		W("        m.Free(&s.%s)", field.private)
+		W("        return")
+		W("    }")
+		if c.swaps(element.t, order) {
+			W("    e := make([]%s, len(v))", element.name)
+			W("    for i := range v {")
+			W("        %s", c.encodeLE(element.t, "&e[i]", "v[i]"))
+			W("    }")
+			W("    v = e")
+		}
+		align, _ := variableListLayout(ft)
+		W("    m.WriteSlice(&s.%s, unsafe.Pointer(&v[0]), int32(len(v)*%d), %d)", field.private, ft.ItemSize, align)
+		W("}")
+	}
+}
+
+// genVariableListElements writes the return of the elements of a variable length list.
+// data is the pointer expression of the elements and size their size in bytes. Elements
+// are decoded into a new slice when the order requires it, otherwise the slice points
+// into the buffer. result is appended to the returned slice, such as ", nil".
+func (c *Compiler) genVariableListElements(b *Builder, t *goType, data, size, result string, order binary.ByteOrder) {
+	W := b.W
+	element := t.list.element
+	if !c.swaps(element.t, order) {
+		W("    return unsafe.Slice((*%s)(%s), %s/%d)%s", element.name, data, size, t.t.ItemSize, result)
+		return
+	}
+	W("    v := make([]%s, %s/%d)", element.name, size, t.t.ItemSize)
+	W("    for i := range v {")
+	W("        v[i] = %s", c.decodeLE(element.t, element.name, fmt.Sprintf("unsafe.Add(%s, i*%d)", data, t.t.ItemSize)))
+	W("    }")
+	W("    return v%s", result)
+}
+
+// variableListLayout returns the alignment of the elements of a variable length list and
+// the size of the length header in front of them, which wap.SliceHeader pads to the
+// alignment.
+func variableListLayout(t *Type) (align, header int) {
+	align = FieldAlign(t.ItemSize)
+	if align > 4 {
+		return align, align
+	}
+	return align, 4
+}
+
+// genCheckedVariableField generates the getter of a variable length field when bounds
+// checking is enabled. It takes the buffer beginning at s and fails rather than follow
+// a VPointer outside of it.
+func (c *Compiler) genCheckedVariableField(b *Builder, t *goType, field *goField, order binary.ByteOrder) {
+	W := b.W
+	ft := field.field.Type
+	alias := field.t.imp.alias
+	var result, zero string
+	switch ft.Kind {
+	case KindString:
+		result, zero = "string", `""`
+	case KindBytes:
+		result, zero = "[]byte", "nil"
+	case KindList:
+		result, zero = "[]"+field.t.list.element.name, "nil"
+	}
+	c.genDoc(b, []string{
+		fmt.Sprintf("%s reads the field from b, the buffer beginning at s.", field.public),
+	}, field.field.Deprecated, field.field.DeprecatedMessage)
+	W("func (s *%s) %s(b []byte) (%s, error) {", t.name, field.public, result)
+	W("    if len(b) == 0 || unsafe.Pointer(&b[0]) != unsafe.Pointer(s) {")
+	W("        return %s, %s.ErrOutOfBounds", zero, alias)
+	W("    }")
+	if ft.Kind == KindList {
+		align, _ := variableListLayout(ft)
+		W("    p, n, err := %s.VerifyVSlice(b, %d, %d)", alias, field.field.Offset, align)
+	} else {
+		W("    p, n, err := %s.VerifyVPointer(b, %d)", alias, field.field.Offset)
+	}
+	W("    if err != nil || n == 0 {")
+	W("        return %s, err", zero)
+	W("    }")
+	switch ft.Kind {
+	case KindString:
+		W("    v := b[p : p+n]")
+		W("    return *(*string)(unsafe.Pointer(&v)), nil")
+	case KindBytes:
+		W("    return b[p : p+n : p+n], nil")
+	case KindList:
+		W("    if n%%%d != 0 {", ft.ItemSize)
+		W("        return nil, %s.ErrInvalidLength", alias)
+		W("    }")
+		c.genVariableListElements(b, field.t, "unsafe.Pointer(&b[p])", "n", ", nil", order)
+	}
+	W("}")
+}
+
 func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.ByteOrder) error {
 	if t.list == nil {
 		return errors.New("type is not a list")
 	}
 	W := b.W
+	element := t.list.element
+	swaps := c.swaps(element.t, order)
+	length := "int(s.l)"
+	if t.t.HeaderSize == 2 && order == binary.BigEndian {
+		length = fmt.Sprintf("int(%s)", c.decodeLE(&Type{Kind: KindUInt16}, "uint16", "&s.l"))
+	}
 
 	if mut {
 		if strings.HasSuffix(t.mut, "_") {
@@ -1359,6 +1791,8 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		W("func (s *%s) setLen(l int) {", t.mut)
 		if t.t.HeaderSize == 1 {
 			W("    s.l = byte(l)")
+		} else if order == binary.BigEndian {
+			W("    %s", c.encodeLE(&Type{Kind: KindUInt16}, "&s.l", "l"))
 		} else {
 			//W("    s[%d] = byte(l)", t.t.Size-2)
 			//W("    s[%d] = byte(l >> 8)", t.t.Size-1)
@@ -1384,7 +1818,11 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 			W("    if l == %d {", t.t.Len)
 			W("        return false")
 			W("    }")
-			W("    s.b[l] = v")
+			if swaps {
+				W("    %s", c.encodeLE(element.t, "&s.b[l]", "v"))
+			} else {
+				W("    s.b[l] = v")
+			}
 			//W("    *(*%s)(unsafe.Pointer(&s[l * %d])) = *v", t.list.element.name, t.t.ItemSize)
 			W("    s.setLen(l+1)")
 			W("    return true")
@@ -1399,17 +1837,15 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		W("    }")
 		W("    l -= 1")
 		W("    if v != nil {")
-		W("        *v = s.b[l]")
+		if swaps {
+			W("        *v = %s", c.decodeLE(element.t, element.name, "&s.b[l]"))
+		} else {
+			W("        *v = s.b[l]")
+		}
 		//W("        *v = *(*%s)(unsafe.Pointer(&s[l * %d]))", t.list.element.name, t.t.ItemSize)
 		W("    }")
 		// Clear last element.
-		if t.list.element.primitive {
-			W("    s.b[l] = 0")
-			//W("    *(*%s)(unsafe.Pointer(&s[l * %d])) = 0", t.list.element.name, t.t.ItemSize)
-		} else {
-			W("    s.b[l] = %s{}", t.list.element.name)
-			//W("    *(*%s)(unsafe.Pointer(&s[l * %d])) = %s{}", t.list.element.name, t.t.ItemSize, t.list.element.name)
-		}
+		W("    s.b[l] = %s", listZero(t.list.element))
 		W("    s.setLen(l)")
 		W("    return true")
 		W("}")
@@ -1421,22 +1857,20 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		W("        return false")
 		W("    }")
 		W("    if v != nil {")
-		W("        *v = s.b[0]")
+		if swaps {
+			W("        *v = %s", c.decodeLE(element.t, element.name, "&s.b[0]"))
+		} else {
+			W("        *v = s.b[0]")
+		}
 		W("    }")
 		W("    if l > 1 {")
 		// Shift bytes over
-		W("        copy(s.b[0:], s.b[1:s.l])")
+		W("        copy(s.b[0:], s.b[1:l])")
 		//W("        copy(s[0:], s[%d:l*%d])", t.t.ItemSize, t.t.ItemSize)
 		W("    }")
 		W("    l -= 1")
 		// Clear last element
-		if t.list.element.primitive {
-			W("    s.b[l] = 0")
-			//W("    *(*%s)(unsafe.Pointer(&s[l*%d])) = 0", t.list.element.name, t.t.ItemSize)
-		} else {
-			W("    s.b[l] = %s{}", t.list.element.name)
-			//W("    *(*%s)(unsafe.Pointer(&s[l*%d])) = %s{}", t.list.element.name, t.t.ItemSize, t.list.element.name)
-		}
+		W("    s.b[l] = %s", listZero(t.list.element))
 		W("    s.setLen(l)")
 		W("    return true")
 		W("}")
@@ -1466,7 +1900,12 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		W("}")
 
 		W("func (s *%s) Len() int {", t.name)
-		W("    return int(s.l)")
+		if c.config.BoundsChecked {
+			W("    if %s > %d {", length, t.t.Len)
+			W("        return %d", t.t.Len)
+			W("    }")
+		}
+		W("    return %s", length)
 		//if t.t.Len < 256 {
 		//	W("    return int(s[%d])", t.t.Size-1)
 		//} else {
@@ -1493,9 +1932,19 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		}
 
 		W("func (s *%s) CopyTo(v []%s) []%s {", t.name, t.list.element.name, t.list.element.name)
-		W("    return append(v, s.Unsafe()...)")
+		if swaps {
+			W("    for i := 0; i < s.Len(); i++ {")
+			W("        v = append(v, %s)", c.decodeLE(element.t, element.name, "&s.b[i]"))
+			W("    }")
+			W("    return v")
+		} else {
+			W("    return append(v, s.Unsafe()...)")
+		}
 		W("}")
 
+		if swaps {
+			W("// Unsafe returns the elements as stored which is little-endian.")
+		}
 		W("func (s *%s) Unsafe() []%s {", t.name, t.list.element.name)
 		W("    return s.b[0:s.Len()]")
 		//W("    return *(*[]%s)(unsafe.Pointer(&reflect.SliceHeader{", t.list.element.name)
@@ -1550,9 +1999,16 @@ func (c *Compiler) genArrayList(t *goType, mut bool, b *Builder, order binary.By
 		W("        return io.ErrShortBuffer")
 		W("    }")
 		W("    v := (*%s)(unsafe.Pointer(&b[0]))", t.name)
+		if c.config.BoundsChecked {
+			W("    if err := v.Verify(b); err != nil {")
+			W("        return err")
+			W("    }")
+		}
 		W("    *s = *v")
 		W("    return nil")
 		W("}")
+
+		c.genVerifyList(b, t, length, order)
 	}
 
 	//W("func (s *%s) Hash() uint32 {", t.name)
@@ -1635,15 +2091,24 @@ func (c *Compiler) genString(t *goType, mut bool, b *Builder, order binary.ByteO
 			W("    return %d", t.t.Len)
 			W("}")
 		} else {
-			W("func (s *%s) Len() int {", t.name)
-			if sizeBytes == 1 {
-				W("    return int(s[%d])", sizeIndex)
+			length := fmt.Sprintf("int(s[%d])", sizeIndex)
+			if sizeBytes == 2 && order == binary.BigEndian {
+				length = fmt.Sprintf("int(binary.LittleEndian.Uint16(s[%d:]))", sizeIndex)
 			} else if sizeBytes == 2 {
-				W("    return int(*(*uint16)(unsafe.Pointer(&s[%d])))", sizeIndex)
+				length = fmt.Sprintf("int(*(*uint16)(unsafe.Pointer(&s[%d])))", sizeIndex)
 				//W("    return int(uint16(s[%d]) | uint16(s[%d]) << 8)", sizeIndex, sizeIndex+1)
 			}
+			W("func (s *%s) Len() int {", t.name)
+			if c.config.BoundsChecked {
+				W("    if %s > %d {", length, sizeIndex)
+				W("        return %d", sizeIndex)
+				W("    }")
+			}
+			W("    return %s", length)
 			W("}")
 
+			c.genVerifyString(b, t, length, sizeIndex)
+
 			W("func (s *%s) Cap() int {", t.name)
 			W("    return %d", sizeIndex)
 			W("}")
@@ -1683,23 +2148,18 @@ func (c *Compiler) genString(t *goType, mut bool, b *Builder, order binary.ByteO
 		W("    return *(**%s)(unsafe.Pointer(&s))", t.mut)
 		W("}")
 
-		W("func (s *%s) ReadFrom(r io.Reader) error {", t.name)
-		W("    n, err := io.ReadFull(r, (*(*[%d]byte)(unsafe.Pointer(&s)))[0:])", t.t.Size)
-		W("    if err != nil {")
-		W("        return err")
-		W("    }")
-		W("    if n != %d {", t.t.Size)
-		W("        return io.ErrShortBuffer")
-		W("    }")
-		W("    return nil")
+		W("func (s *%s) ReadFrom(r io.Reader) (int64, error) {", t.name)
+		W("    n, err := io.ReadFull(r, s[0:])")
+		W("    return int64(n), err")
 		W("}")
 
-		W("func (s *%s) WriteTo(w io.Writer) (n int, err error) {", t.name)
-		W("    return w.Write((*(*[%d]byte)(unsafe.Pointer(&s)))[0:])", t.t.Size)
+		W("func (s *%s) WriteTo(w io.Writer) (int64, error) {", t.name)
+		W("    n, err := w.Write(s[0:])")
+		W("    return int64(n), err")
 		W("}")
 
 		W("func (s *%s) MarshalBinaryTo(b []byte) []byte {", t.name)
-		W("    return append(b, (*(*[%d]byte)(unsafe.Pointer(&s)))[0:]...)", t.t.Size)
+		W("    return append(b, s[0:]...)")
 		W("}")
 
 		W("func (s *%s) MarshalBinary() ([]byte, error) {", t.name)
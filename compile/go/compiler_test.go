@@ -1,11 +1,19 @@
 package _go
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	. "github.com/moontrade/proto/schema"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
+var update = flag.Bool("update", false, "update golden files")
+
 func TestNewGenerator(t *testing.T) {
 	var (
 		err      error
@@ -32,3 +40,1094 @@ func TestNewGenerator(t *testing.T) {
 
 	fmt.Println(p)
 }
+
+// generate compiles a single schema file and returns the output directory.
+func generate(t *testing.T, source string, config *Config) string {
+	t.Helper()
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Dir = "model"
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	config.Package = "github.com/moontrade/proto/model"
+	config.Output = t.TempDir()
+	compiler, err := NewCompiler(s, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(config.Output, "model")
+}
+
+// compileSource generates Go for a single schema file and returns the generated code.
+func compileSource(t *testing.T, source string, config *Config) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(generate(t, source, config), "proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// goTest runs "go test" over files in a temporary module that depends on this repository.
+func goTest(t *testing.T, files map[string]string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files["go.mod"] = fmt.Sprintf(`module example.com/generated
+
+go 1.17
+
+require github.com/moontrade/proto v0.0.0
+
+replace github.com/moontrade/proto => %s
+`, root)
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["go.sum"] = string(sum)
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goBin, "test", "-count=1", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+// expectCode checks each expected snippet is in code ignoring differences in whitespace.
+func expectCode(t *testing.T, code string, expected ...string) {
+	t.Helper()
+	code = strings.Join(strings.Fields(code), " ")
+	for _, e := range expected {
+		if !strings.Contains(code, strings.Join(strings.Fields(e), " ")) {
+			t.Fatalf("generated code missing: %s", e)
+		}
+	}
+}
+
+func TestVariableFields(t *testing.T) {
+	code := compileSource(t, `
+struct Order {
+	id      i64
+	symbol  string
+	data    bytes
+	fills   [] f64
+	legs    [] Leg
+	info    Info
+}
+
+struct Leg {
+	price f64
+	qty   i32
+}
+
+struct Info {
+	venue string
+}
+`, &Config{})
+	expectCode(t, code,
+		`wap "github.com/moontrade/proto"`,
+		"symbol wap.VPointer",
+		"func (s *Order) Symbol() string {",
+		"func (s *Order) Data() []byte {",
+		"func (s *Order) Fills() []float64 {",
+		"func (s *Order) Legs() []Leg {",
+		"func (s *OrderMut) SetSymbol(m *wap.Mutable, v string) {",
+		"func (s *OrderMut) SetLegs(m *wap.Mutable, v []Leg) {",
+		"func (s *Order) AppendVariable(b []byte, start, at int) []byte {",
+		"b = s.info.AppendVariable(b, start, at+",
+	)
+	if strings.Contains(code, "func (s *Order) UnmarshalBinary(") || strings.Contains(code, "func (s *Order) ReadFrom(") {
+		t.Fatal("structs with variable length fields cannot be read from bytes without their buffer")
+	}
+	goTest(t, map[string]string{
+		"proto.go": code,
+		"proto_test.go": `package model
+
+import (
+	"testing"
+	"unsafe"
+
+	wap "github.com/moontrade/proto"
+)
+
+func TestVariableFields(t *testing.T) {
+	m := wap.NewBuilder().New(int32(unsafe.Sizeof(Order{})), 64)
+	o := (*Order)(m.Unsafe())
+	o.Mut().SetId(7)
+	o.Mut().SetSymbol(&m, "BTCUSDT")
+	o = (*Order)(m.Unsafe())
+	o.Mut().SetData(&m, []byte{1, 2, 3})
+	o = (*Order)(m.Unsafe())
+	o.Mut().SetFills(&m, []float64{1.5, 2.5, 3.5})
+	o = (*Order)(m.Unsafe())
+	var leg Leg
+	leg.Mut().SetPrice(9.5).SetQty(4)
+	o.Mut().SetLegs(&m, []Leg{leg, leg})
+	o = (*Order)(m.Unsafe())
+	o.Mut().Info().SetVenue(&m, "XNAS")
+	o = (*Order)(m.Unsafe())
+
+	if o.Id() != 7 || o.Symbol() != "BTCUSDT" {
+		t.Fatalf("id = %d, symbol = %s", o.Id(), o.Symbol())
+	}
+	if d := o.Data(); len(d) != 3 || d[2] != 3 {
+		t.Fatalf("data = %v", d)
+	}
+	if f := o.Fills(); len(f) != 3 || f[2] != 3.5 {
+		t.Fatalf("fills = %v", f)
+	} else if uintptr(unsafe.Pointer(&f[0]))%unsafe.Alignof(f[0]) != 0 {
+		t.Fatalf("fills at %d are not aligned", uintptr(unsafe.Pointer(&f[0])))
+	}
+	if l := o.Legs(); len(l) != 2 || l[1].Price() != 9.5 || l[1].Qty() != 4 {
+		t.Fatalf("legs = %v", l)
+	}
+	if err := VerifyOrder(unsafe.Slice((*byte)(m.Unsafe()), m.Len())); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copies take the variable length data along.
+	c := o.Clone()
+	if c.Symbol() != "BTCUSDT" || c.Info().Venue() != "XNAS" || len(c.Data()) != 3 || len(c.Legs()) != 2 {
+		t.Fatalf("clone symbol = %s, venue = %s", c.Symbol(), c.Info().Venue())
+	}
+	if f := c.Fills(); len(f) != 3 || f[2] != 3.5 || uintptr(unsafe.Pointer(&f[0]))%8 != 0 {
+		t.Fatalf("clone fills = %v", f)
+	}
+	if c.Mut().Clone().Symbol() != "BTCUSDT" {
+		t.Fatal("mutable clone lost the symbol")
+	}
+	b, _ := o.MarshalBinary()
+	if err := VerifyOrder(b); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := ReinterpretOrder(b); err != nil || r.Symbol() != "BTCUSDT" || r.Info().Venue() != "XNAS" {
+		t.Fatalf("marshaled symbol = %s, err = %v", r.Symbol(), err)
+	}
+
+	// A shorter value is written in place and an empty one frees the pointer.
+	o.Mut().SetSymbol(&m, "ETH")
+	o = (*Order)(m.Unsafe())
+	o.Mut().SetFills(&m, nil)
+	o = (*Order)(m.Unsafe())
+	if o.Symbol() != "ETH" || o.Fills() != nil || len(o.Legs()) != 2 {
+		t.Fatalf("symbol = %s, fills = %v", o.Symbol(), o.Fills())
+	}
+}
+`,
+	})
+}
+
+func TestVerify(t *testing.T) {
+	const source = `
+enum Side : byte {
+	Buy = 1
+	Sell = 2
+}
+
+struct Trade {
+	side    Side
+	symbol  string8
+	venue   string
+	legs    [4] Leg
+	fills   [] f64
+}
+
+struct Leg {
+	side  Side
+	qty   i32
+}
+`
+	code := compileSource(t, source, &Config{})
+	expectCode(t, code,
+		"func (v Side) Valid() bool {",
+		"func VerifyTrade(b []byte) error {",
+		"func (s *Trade) Verify(b []byte) error {",
+		"func ReinterpretTrade(b []byte) (*Trade, error) {",
+		"func (s *String8) Verify() error {",
+		"func (s *Leg4List) Verify(b []byte) error {",
+		"wap.VerifyVPointer(b[",
+		"wap.ErrInvalidEnum",
+	)
+	if strings.Contains(code, "if err := v.Verify(b); err != nil {") {
+		t.Fatal("UnmarshalBinary should not verify unless bounds checking is enabled")
+	}
+
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": verifyTest})
+
+	code = compileSource(t, source, &Config{BoundsChecked: true})
+	expectCode(t, code,
+		"if err := v.Verify(b); err != nil {",
+		"if err := VerifyTrade(b); err != nil {",
+		"return 4",
+		"func (s *Trade) Venue(b []byte) (string, error) {",
+		"func (s *Trade) Fills(b []byte) ([]float64, error) {",
+		"// MarshalMap leaves out variable length fields",
+	)
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": boundsCheckedTest})
+}
+
+// boundsCheckedTest checks the variable length getters refuse to read outside of b.
+const boundsCheckedTest = `package model
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	wap "github.com/moontrade/proto"
+)
+
+func TestBoundsChecked(t *testing.T) {
+	m := wap.NewBuilder().New(int32(unsafe.Sizeof(Trade{})), 64)
+	v := (*Trade)(m.Unsafe())
+	v.Mut().SetVenue(&m, "XNAS")
+	v = (*Trade)(m.Unsafe())
+	v.Mut().SetFills(&m, []float64{1.5, 2.5})
+	v = (*Trade)(m.Unsafe())
+	b := unsafe.Slice((*byte)(m.Unsafe()), m.Len())
+	if venue, err := v.Venue(b); err != nil || venue != "XNAS" {
+		t.Fatalf("venue = %s, err = %v", venue, err)
+	}
+	if fills, err := v.Fills(b); err != nil || len(fills) != 2 || fills[1] != 2.5 {
+		t.Fatalf("fills = %v, err = %v", fills, err)
+	}
+
+	// The fills are cut off by a shorter buffer.
+	if _, err := v.Fills(b[:len(b)-1]); !errors.Is(err, wap.ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+	// A buffer that does not begin at v.
+	if _, err := v.Venue(append([]byte(nil), b...)); !errors.Is(err, wap.ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+	// A venue pointing past the end of the buffer.
+	f, _ := reflect.TypeOf(Trade{}).FieldByName("venue")
+	binary.LittleEndian.PutUint32(b[f.Offset:], uint32(len(b)))
+	if _, err := v.Venue(b); !errors.Is(err, wap.ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+}
+`
+
+// verifyTest checks a Trade built in a buffer verifies and that corrupting its lengths,
+// enums and pointers is caught.
+const verifyTest = `package model
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	wap "github.com/moontrade/proto"
+)
+
+func offsetOf(name string) int {
+	f, _ := reflect.TypeOf(Trade{}).FieldByName(name)
+	return int(f.Offset)
+}
+
+func TestVerify(t *testing.T) {
+	m := wap.NewBuilder().New(int32(unsafe.Sizeof(Trade{})), 64)
+	v := (*Trade)(m.Unsafe())
+	v.Mut().SetSide(Side_Sell)
+	v.Mut().SetVenue(&m, "XNAS")
+	v = (*Trade)(m.Unsafe())
+	v.Mut().SetFills(&m, []float64{1.5, 2.5})
+	v = (*Trade)(m.Unsafe())
+	b := unsafe.Slice((*byte)(m.Unsafe()), m.Len())
+	if err := VerifyTrade(b); err != nil {
+		t.Fatal(err)
+	}
+	if v.Venue() != "XNAS" || len(v.Fills()) != 2 || v.Fills()[1] != 2.5 {
+		t.Fatalf("venue = %s, fills = %v", v.Venue(), v.Fills())
+	}
+
+	// A fills slab that is not a whole number of f64.
+	corrupt := append([]byte(nil), b...)
+	p := offsetOf("fills") + int(binary.LittleEndian.Uint32(corrupt[offsetOf("fills"):]))
+	binary.LittleEndian.PutUint32(corrupt[p:], 12)
+	if err := VerifyTrade(corrupt); !errors.Is(err, wap.ErrInvalidLength) {
+		t.Fatalf("expected ErrInvalidLength, got %v", err)
+	}
+
+	// The fills moved to a header at 4 past a multiple of 8 leaves the f64 misaligned.
+	at := (len(b)+7)&^7 + 4
+	corrupt = append(append([]byte(nil), b...), make([]byte, at+16-len(b))...)
+	binary.LittleEndian.PutUint32(corrupt[offsetOf("fills"):], uint32(at-offsetOf("fills")))
+	binary.LittleEndian.PutUint32(corrupt[at:], 8)
+	if err := VerifyTrade(corrupt); !errors.Is(err, wap.ErrMisaligned) {
+		t.Fatalf("expected ErrMisaligned, got %v", err)
+	}
+
+	// A venue pointing past the end of the buffer.
+	corrupt = append([]byte(nil), b...)
+	binary.LittleEndian.PutUint32(corrupt[offsetOf("venue"):], uint32(len(b)))
+	if err := VerifyTrade(corrupt); !errors.Is(err, wap.ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+
+	corrupt = append([]byte(nil), b...)
+	corrupt[offsetOf("side")] = 9
+	if err := VerifyTrade(corrupt); !errors.Is(err, wap.ErrInvalidEnum) {
+		t.Fatalf("expected ErrInvalidEnum, got %v", err)
+	}
+}
+`
+
+func TestBigEndian(t *testing.T) {
+	dir := generate(t, `
+enum Side : u16 {
+	Buy = 1
+	Sell = 2
+}
+
+struct Tick {
+	price   f64
+	qty     i32
+	side    Side
+	count   u16
+	sizes   [300] i32
+	bid     ?i64
+}
+
+struct Trade {
+	venue   string
+	fills   [] f64
+}
+`, &Config{BigEndian: true})
+	data, err := os.ReadFile(filepath.Join(dir, "proto_be.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	expectCode(t, code,
+		"// +build ppc64 s390x mips mips64",
+		"binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.qty))[:])",
+		"binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.price))[:], math.Float64bits(v))",
+		"p, n := wap.SlabLE(&s.venue, 4)",
+		"v[i] = float64(math.Float64frombits(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(unsafe.Add(p, i*8)))[:])))",
+	)
+	if strings.Contains(code, "BigEndian not supported") {
+		t.Fatal("big-endian file should not reject big-endian hosts")
+	}
+	if strings.Contains(code, "s.venue.Str()") || strings.Contains(code, "_ = binary.LittleEndian") {
+		t.Fatal("big-endian file should decode the VPointer offset and only import what it uses")
+	}
+
+	// The big-endian accessors decode from bytes so they are correct on any host. Drop
+	// the build constraint and check the encoded bytes are little-endian.
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "//go:build") || strings.HasPrefix(line, "// +build") {
+			lines[i] = ""
+		}
+	}
+	goTest(t, map[string]string{
+		"proto.go": strings.Join(lines, "\n"),
+		"proto_test.go": `package model
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	wap "github.com/moontrade/proto"
+)
+
+func (s *Tick) offsetOf(name string) uintptr {
+	f, _ := reflect.TypeOf(*s).FieldByName(name)
+	return f.Offset
+}
+
+func TestEncoded(t *testing.T) {
+	var v Tick
+	bid := int64(-2)
+	v.Mut().SetPrice(1.5).SetQty(0x01020304).SetSide(Side_Sell).SetCount(0x0A0B).SetBid(&bid)
+	sizes := v.Mut().Sizes().Mut()
+	for i := 0; i < 258; i++ {
+		sizes.Push(int32(i))
+	}
+	b := v.Bytes()
+	if math.Float64frombits(binary.LittleEndian.Uint64(b[v.offsetOf("price"):])) != 1.5 {
+		t.Fatal("price not little-endian")
+	}
+	if binary.LittleEndian.Uint32(b[v.offsetOf("qty"):]) != 0x01020304 {
+		t.Fatal("qty not little-endian")
+	}
+	if binary.LittleEndian.Uint16(b[v.offsetOf("side"):]) != uint16(Side_Sell) {
+		t.Fatal("side not little-endian")
+	}
+	if binary.LittleEndian.Uint16(b[v.offsetOf("count"):]) != 0x0A0B {
+		t.Fatal("count not little-endian")
+	}
+	if int64(binary.LittleEndian.Uint64(b[v.offsetOf("bid"):])) != -2 {
+		t.Fatal("bid not little-endian")
+	}
+	if v.Price() != 1.5 || v.Qty() != 0x01020304 || v.Side() != Side_Sell || v.Count() != 0x0A0B || *v.Bid() != -2 {
+		t.Fatalf("decoded = %v", v.String())
+	}
+	if v.Sizes().Len() != 258 || v.Sizes().CopyTo(nil)[257] != 257 {
+		t.Fatal("list length or elements not decoded")
+	}
+	if err := VerifyTick(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncodedVariable(t *testing.T) {
+	m := wap.NewBuilder().New(int32(unsafe.Sizeof(Trade{})), 64)
+	v := (*Trade)(m.Unsafe())
+	v.Mut().SetVenue(&m, "XNAS")
+	v = (*Trade)(m.Unsafe())
+	v.Mut().SetFills(&m, []float64{1.5, 2.5})
+	v = (*Trade)(m.Unsafe())
+	b := unsafe.Slice((*byte)(m.Unsafe()), m.Len())
+
+	// Each VPointer holds a little-endian offset from itself to a little-endian length.
+	deref := func(name string) int {
+		f, _ := reflect.TypeOf(Trade{}).FieldByName(name)
+		return int(f.Offset) + int(int32(binary.LittleEndian.Uint32(b[f.Offset:])))
+	}
+	if p := deref("venue"); binary.LittleEndian.Uint32(b[p:]) != 4 || string(b[p+4:p+8]) != "XNAS" {
+		t.Fatal("venue not little-endian")
+	}
+	if p := deref("fills"); binary.LittleEndian.Uint32(b[p:]) != 16 ||
+		math.Float64frombits(binary.LittleEndian.Uint64(b[p+8+8:])) != 2.5 {
+		t.Fatal("fills not little-endian")
+	}
+	if f := v.Fills(); v.Venue() != "XNAS" || len(f) != 2 || f[0] != 1.5 || f[1] != 2.5 {
+		t.Fatalf("venue = %s, fills = %v", v.Venue(), f)
+	}
+	if err := VerifyTrade(b); err != nil {
+		t.Fatal(err)
+	}
+}
+`,
+	})
+}
+
+func TestConstsAndDefaults(t *testing.T) {
+	dir := generate(t, `
+// Highest value
+const HIGH i64 = 1000
+const NAME string8 = "HELLO"
+const CODE Code = Close
+const RATE f64 = 1.5
+
+enum Code : byte {
+	Open = 0
+	Close = 1
+}
+
+struct Candle {
+	open  ?i64 = 5
+	high  Code = Close
+	low   i64 = HIGH
+	name  string8 = "abc"
+	flag  bool = true
+	rate  f64 = 2.5
+	close ?f64 = nil
+}
+`, &Config{})
+	data, err := os.ReadFile(filepath.Join(dir, "proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	expectCode(t, code,
+		"// Highest value",
+		"HIGH int64 = 1000",
+		`NAME string = "HELLO"`,
+		"CODE Code = Code_Close",
+		"RATE float64 = 1.5",
+		"func NewCandle() *Candle {",
+		"func (s *CandleMut) Reset() *CandleMut {",
+	)
+	goTest(t, map[string]string{
+		"proto.go": code,
+		"proto_test.go": `package model
+
+import "testing"
+
+func TestDefaults(t *testing.T) {
+	c := NewCandle()
+	if c.Open() == nil || *c.Open() != 5 {
+		t.Fatal("open default not applied")
+	}
+	if c.High() != Code_Close || c.Low() != HIGH || c.Name().String() != "abc" || !c.Flag() || c.Rate() != 2.5 {
+		t.Fatalf("defaults not applied: %s", c.String())
+	}
+	if c.Close() != nil {
+		t.Fatal("close should be nil")
+	}
+	c.Mut().SetLow(1).SetOpen(nil)
+	c.Mut().Reset()
+	if c.Low() != HIGH || *c.Open() != 5 {
+		t.Fatal("reset did not apply defaults")
+	}
+}
+`,
+	})
+}
+
+func TestDeprecated(t *testing.T) {
+	source := `
+// Side of the book
+@deprecated("use Direction")
+enum Side : byte {
+	Buy = 1
+	@deprecated
+	Sell = 2
+}
+
+@deprecated("use Trade")
+struct Fill {
+	price f64
+}
+
+struct Order {
+	@deprecated("use direction")
+	side  Side
+	fills [4]Fill
+	name  string
+}
+`
+	code := compileSource(t, source, &Config{Mutable: false})
+	expectCode(t, code,
+		"// Side of the book\n//\n// Deprecated: use Direction\ntype Side byte",
+		"// Deprecated: marked @deprecated in the schema.\nSide_Sell = Side(2)",
+		"// Deprecated: use Trade\ntype Fill struct",
+		"// Deprecated: use Trade\ntype FillMut struct",
+		"// Deprecated: use direction\nfunc (s *Order) Side() Side",
+		"// Deprecated: use direction\nfunc (s *OrderMut) SetSide(v Side) *OrderMut",
+	)
+
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, err := NewCompiler(s, &Config{FailOnDeprecated: true, Output: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err == nil || !strings.Contains(err.Error(), "field 'Order.fills' uses deprecated struct 'Fill'") {
+		t.Fatalf("expected deprecated use error, got: %v", err)
+	}
+}
+
+func TestMsgpack(t *testing.T) {
+	source := `
+enum Side : u16 {
+	Buy = 1
+	Sell = 2
+}
+
+struct Leg {
+	price f64
+	qty   i32
+}
+
+struct Order {
+	id|i      i64
+	side|s    Side
+	symbol    string8
+	key       bytes4
+	legs      [3] Leg
+	sizes     [300] i32
+	bid       ?f32
+	last      ?Leg
+	flags     [2] Side
+	open      bool
+}
+`
+	code := compileSource(t, source, &Config{Msgpack: true})
+	expectCode(t, code,
+		"\"github.com/moontrade/proto/compile/go/msgpack\"",
+		"func (e Side) AppendMsgpack(b []byte) []byte {",
+		"b = msgpack.AppendString(b, \"i\")",
+		"case \"i\", \"id\":",
+		"func (s *String8) UnmarshalMsgpack(b []byte) ([]byte, error) {",
+	)
+
+	test := `package model
+
+import (
+	"testing"
+
+	"github.com/moontrade/proto/compile/go/msgpack"
+)
+
+func TestMsgpack(t *testing.T) {
+	var o Order
+	m := o.Mut()
+	m.SetId(-7).SetSide(Side_Sell).SetBid(nil).SetOpen(true)
+	m.Symbol().Set("BTCUSDT")
+	m.Key().Mut().set("abcd")
+	var leg Leg
+	leg.Mut().SetPrice(1.5).SetQty(3)
+	m.Legs().Mut().Push(&leg)
+	m.SetLast(&leg)
+	for i := 0; i < 260; i++ {
+		m.Sizes().Mut().Push(int32(i))
+	}
+	m.Flags().Mut().Push(Side_Buy)
+
+	b := o.AppendMsgpack(nil)
+	var decoded Order
+	rest, err := decoded.UnmarshalMsgpack(append(b, 0xc0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 {
+		t.Fatalf("rest = %d", len(rest))
+	}
+	if decoded != o {
+		t.Fatalf("decoded = %v, expected %v", decoded.String(), o.String())
+	}
+
+	// Full names are accepted, unknown keys are skipped and nil clears optionals.
+	b = msgpack.AppendMapHeader(nil, 4)
+	b = msgpack.AppendString(b, "id")
+	b = msgpack.AppendInt(b, 42)
+	b = msgpack.AppendString(b, "unknown")
+	b = msgpack.AppendArrayHeader(b, 1)
+	b = msgpack.AppendString(b, "x")
+	b = msgpack.AppendString(b, "last")
+	b = msgpack.AppendNil(b)
+	b = msgpack.AppendString(b, "bid")
+	b = msgpack.AppendFloat64(b, 2.5)
+	if _, err = decoded.UnmarshalMsgpack(b); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Id() != 42 || decoded.Last() != nil || *decoded.Bid() != 2.5 || decoded.Symbol().String() != "BTCUSDT" {
+		t.Fatalf("decoded = %v", decoded.String())
+	}
+
+	b = msgpack.AppendMapHeader(nil, 1)
+	b = msgpack.AppendString(b, "s")
+	b = msgpack.AppendInt(b, -1)
+	if _, err = decoded.UnmarshalMsgpack(b); err == nil {
+		t.Fatal("expected overflow")
+	}
+	b = msgpack.AppendMapHeader(nil, 1)
+	b = msgpack.AppendString(b, "legs")
+	b = msgpack.AppendArrayHeader(b, 4)
+	if _, err = decoded.UnmarshalMsgpack(b); err == nil {
+		t.Fatal("expected too many elements")
+	}
+}
+`
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": test})
+
+	// The big-endian accessors decode from bytes so they run on any host.
+	dir := generate(t, source, &Config{BigEndian: true, Msgpack: true})
+	data, err := os.ReadFile(filepath.Join(dir, "proto_be.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "//go:build") || strings.HasPrefix(line, "// +build") {
+			lines[i] = ""
+		}
+	}
+	goTest(t, map[string]string{"proto.go": strings.Join(lines, "\n"), "proto_test.go": test})
+
+	// Variable length fields cannot be decoded without the Mutable of the buffer.
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Book {
+	symbol string8
+	levels [] f64
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, err := NewCompiler(s, &Config{Msgpack: true, Output: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err == nil || !strings.Contains(err.Error(), "msgpack is not supported for variable length field 'Book.levels'") {
+		t.Fatalf("expected variable length field error, got: %v", err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	source := `
+enum Side : u16 {
+	Buy = 1
+	Sell = 2
+}
+
+struct Leg {
+	price f64
+	qty   i32
+}
+
+struct Trade {
+	id|i   i64
+	side   Side
+	@intern
+	symbol string8
+	venue  string16
+	@intern
+	venues [2] string8
+	key    bytes4
+	legs   [3] Leg
+	fee    decimal64(2)
+	bid    ?f32
+	last   ?Leg
+	open   bool
+}
+`
+	code := compileSource(t, source, &Config{JSON: true})
+	expectCode(t, code,
+		"\"github.com/moontrade/proto/runtime2\"",
+		"func (e *Side) UnmarshalJSONLexer(l *runtime2.JsonLexer) {",
+		"case \"i\", \"id\":",
+		"s.symbol.unmarshalJSONLexer(l, true)",
+		"s.venue.UnmarshalJSONLexer(l)",
+		"s.venues.unmarshalJSONLexer(l, true)",
+		"func (s *String82List) unmarshalJSONLexer(l *runtime2.JsonLexer, intern bool) {",
+	)
+
+	test := `package model
+
+import (
+	"testing"
+
+	"github.com/moontrade/proto/runtime2"
+	"github.com/moontrade/proto/runtime2/intern"
+)
+
+func read(input string, table *intern.Table, trade *Trade) error {
+	l := runtime2.JsonLexer{Data: []byte(input), Intern: table}
+	trade.UnmarshalJSONLexer(&l)
+	l.Consumed()
+	return l.Error()
+}
+
+func TestJSON(t *testing.T) {
+	input := ` + "`" + `{"i":42,"side":2,"symbol":"BTCUSDT","venue":"binance","venues":["a","b"],
+		"key":"YWJjZA==","legs":[{"price":1.5,"qty":3}],"fee":"0.25","bid":null,
+		"last":{"price":2,"qty":1},"open":true,"unknown":[1,{"x":2}]}` + "`" + `
+	table := intern.NewTable(64)
+	var trade Trade
+	for i := 0; i < 2; i++ {
+		if err := read(input, table, &trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if trade.Id() != 42 || trade.Side() != Side_Sell || trade.Symbol().String() != "BTCUSDT" ||
+		trade.Venue().String() != "binance" || trade.Venues().Len() != 2 ||
+		trade.Venues().Get(1).String() != "b" || string(trade.Key()[:]) != "abcd" ||
+		trade.Legs().Len() != 1 || trade.Legs().Get(0).Qty() != 3 || trade.Fee() != 25 ||
+		trade.Bid() != nil || trade.Last() == nil || trade.Last().Price() != 2 || !trade.Open() {
+		t.Fatalf("trade = %v", trade.String())
+	}
+	// Only the @intern fields go through the table: the first read misses the symbol
+	// and both venues, the second finds them.
+	if stats := table.Stats(); stats.Misses != 3 || stats.Hits != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	if err := read(` + "`" + `{"last":null,"bid":1.5}` + "`" + `, nil, &trade); err != nil {
+		t.Fatal(err)
+	}
+	if trade.Last() != nil || *trade.Bid() != 1.5 || trade.Id() != 42 {
+		t.Fatalf("trade = %v", trade.String())
+	}
+	if err := read(` + "`" + `{"venues":["a","b","c"]}` + "`" + `, nil, &trade); err == nil {
+		t.Fatal("expected too many elements")
+	}
+	if err := read(` + "`" + `{"fee":0.125}` + "`" + `, nil, &trade); err == nil {
+		t.Fatal("expected too many decimals")
+	}
+}
+`
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": test})
+
+	// Variable length fields cannot be decoded without the Mutable of the buffer.
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Book {
+	symbol string8
+	levels [] f64
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, err := NewCompiler(s, &Config{JSON: true, Output: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err == nil || !strings.Contains(err.Error(), "json is not supported for variable length field 'Book.levels'") {
+		t.Fatalf("expected variable length field error, got: %v", err)
+	}
+}
+
+func TestListZero(t *testing.T) {
+	code := compileSource(t, `
+struct Flags {
+	on    [2] bool
+	names [2] string8
+	keys  [2] bytes4
+}
+`, &Config{})
+	test := `package model
+
+import "testing"
+
+func TestListZero(t *testing.T) {
+	var f Flags
+	on, names := f.On().Mut(), f.Names().Mut()
+	var name String8
+	name.set("a")
+	on.Push(true)
+	on.Push(true)
+	names.Push(&name)
+	names.Push(&name)
+	f.Keys().Mut().Push(Bytes4{1})
+	if !on.Pop(nil) || !on.Shift(nil) || !names.Pop(nil) || !names.Shift(nil) || !f.Keys().Mut().Pop(nil) {
+		t.Fatal("empty list")
+	}
+	if f != (Flags{}) {
+		t.Fatalf("flags = %v", f.String())
+	}
+}
+`
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": test})
+}
+
+func TestDecimals(t *testing.T) {
+	source := `
+struct Fill {
+	qty|q   decimal64(8) = 0.1
+	price   decimal128(2) = -12.5
+	fee     ?decimal64(8)
+	legs    [4] decimal64(8)
+	steps   [2] decimal128(2)
+}
+`
+	code := compileSource(t, source, &Config{Msgpack: true})
+	expectCode(t, code,
+		"type Decimal64S8 int64",
+		"type Decimal128S2 [16]byte",
+		"func (d Decimal64S8) Add(v Decimal64S8) (Decimal64S8, error) {",
+		"s.SetQty(10000000)",
+		"s.SetPrice(Decimal128S2(wap.Int128{Hi: -1, Lo: 18446744073709550366}.Bytes()))",
+	)
+
+	test := `package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	wap "github.com/moontrade/proto"
+)
+
+func TestDecimals(t *testing.T) {
+	f := NewFill()
+	if f.Qty().String() != "0.10000000" || f.Price().String() != "-12.50" || f.Fee() != nil {
+		t.Fatalf("defaults = %s %s", f.Qty(), f.Price())
+	}
+
+	// 0.1 accumulated ten times is exactly 1.
+	sum := Decimal64S8(0)
+	for i := 0; i < 10; i++ {
+		var err error
+		if sum, err = sum.Add(f.Qty()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sum != 100000000 || sum.String() != "1.00000000" {
+		t.Fatalf("sum = %s", sum)
+	}
+	if _, err := Decimal64S8(math.MaxInt64).Add(1); !errors.Is(err, wap.ErrDecimalOverflow) {
+		t.Fatalf("overflow err = %v", err)
+	}
+	fee, err := ParseDecimal64S8("0.00000125")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := f.Mut()
+	m.SetFee(&fee)
+	m.Legs().Mut().Push(sum)
+	price, err := Decimal128S2Of(wap.Decimal128{Units: wap.Int128FromInt64(3), Scale: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Steps().Mut().Push(price)
+	if total, err := f.Price().Mul(price); err != nil || total.String() != "-37.50" {
+		t.Fatalf("total = %s, %v", total, err)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"qty": f.Qty(), "fee": f.Fee(), "price": f.Price()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ` + "`" + `{"fee":0.00000125,"price":-12.50,"qty":0.10000000}` + "`" + ` {
+		t.Fatalf("json = %s", data)
+	}
+	var decoded struct {
+		Qty   Decimal64S8
+		Price Decimal128S2
+	}
+	if err = json.Unmarshal([]byte(` + "`" + `{"Qty":1e-8,"Price":"99.9"}` + "`" + `), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Qty != 1 || decoded.Price.String() != "99.90" {
+		t.Fatalf("decoded = %s %s", decoded.Qty, decoded.Price)
+	}
+	if err = json.Unmarshal([]byte(` + "`" + `{"Price":0.001}` + "`" + `), &decoded); !errors.Is(err, wap.ErrDecimalPrecision) {
+		t.Fatalf("precision err = %v", err)
+	}
+
+	var copied Fill
+	if _, err = copied.UnmarshalMsgpack(f.AppendMsgpack(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if copied != *f {
+		t.Fatalf("msgpack = %v, expected %v", copied.String(), f.String())
+	}
+}
+`
+	goTest(t, map[string]string{"proto.go": code, "proto_test.go": test})
+
+	dir := generate(t, source, &Config{BigEndian: true, Msgpack: true})
+	data, err := os.ReadFile(filepath.Join(dir, "proto_be.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "//go:build") || strings.HasPrefix(line, "// +build") {
+			lines[i] = ""
+		}
+	}
+	goTest(t, map[string]string{"proto.go": strings.Join(lines, "\n"), "proto_test.go": test})
+}
+
+func TestLayoutCheck(t *testing.T) {
+	source := `
+struct Tick {
+	price f64
+	size  ?i32
+	flag  bool
+}
+`
+	dir := generate(t, source, &Config{LayoutCheck: LayoutCheckTest})
+	code, err := os.ReadFile(filepath.Join(dir, "proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(code), "layout(") {
+		t.Fatal("init should not check the layout when the layout check is a test")
+	}
+	test, err := os.ReadFile(filepath.Join(dir, "proto_layout_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCode(t, string(test),
+		`layout("sizeof Tick", unsafe.Sizeof(Tick{}), 24)`,
+		`layout("offsetof Tick.size", unsafe.Offsetof(Tick{}.size), 16)`,
+		`layout("offsetof Tick.flag", unsafe.Offsetof(Tick{}.flag), 20)`,
+	)
+	goTest(t, map[string]string{
+		"proto.go":             string(code),
+		"proto_layout_test.go": string(test),
+	})
+
+	init := compileSource(t, source, &Config{})
+	expectCode(t, init, `layout("sizeof TickMut", unsafe.Sizeof(TickMut{}), 24)`)
+	if strings.Contains(init, `"reflect"`) {
+		t.Fatal("reflect should only be imported for lists")
+	}
+}
+
+// TestSnapshot compares the code generated in memory for testdata/snapshot with the golden
+// files. Run with -update to rewrite them.
+func TestSnapshot(t *testing.T) {
+	s, err := LoadFromFS("testdata/snapshot", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler, err := NewCompiler(s, &Config{
+		Package:   "example.com/snapshot",
+		BigEndian: true,
+		Mutable:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := compiler.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("generated %d files, expected = 2", len(files))
+	}
+	for _, path := range files.Paths() {
+		golden := filepath.Join("testdata", "snapshot", "golden", filepath.FromSlash(path)+".golden")
+		if *update {
+			if err = os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err = os.WriteFile(golden, files[path], 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expected, files[path]) {
+			t.Fatalf("%s differs from %s: run go test -run TestSnapshot -update and review the diff", path, golden)
+		}
+	}
+}
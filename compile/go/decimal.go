@@ -0,0 +1,170 @@
+package _go
+
+import (
+	"fmt"
+	"math/big"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+// decimalType maps a decimal64(scale) to an int64 of units and a decimal128(scale) to the
+// 16 little-endian bytes of its units. Each scale is a distinct type so values of
+// different scales cannot be mixed without converting through wap.Decimal64 or
+// wap.Decimal128.
+func (c *Compiler) decimalType(pkg *goPackage, t *Type) *goType {
+	_ = c.addImport(pkg.importMap, wapImportPath, wapImportAlias)
+	name := DecimalTypeName(t.Kind, t.Scale)
+	if t.Kind == KindDecimal128 && c.config.Msgpack {
+		_ = c.addImport(pkg.importMap, "fmt", "")
+	}
+	gt := &goType{
+		pkg:       pkg,
+		t:         t,
+		name:      name,
+		mut:       name,
+		primitive: t.Kind == KindDecimal64,
+	}
+	pkg.decimals[gt.name] = gt
+	pkg.byType[t] = gt
+	return gt
+}
+
+// decimalValue returns the Go expression of an initial decimal value.
+func (c *Compiler) decimalValue(t *goType, v Decimal) string {
+	if t.t.Kind == KindDecimal64 {
+		return v.Units.String()
+	}
+	// The two's complement of the units split into 64-bit halves.
+	u := new(big.Int).Set(v.Units)
+	if u.Sign() < 0 {
+		u.Add(u, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	lo := new(big.Int).And(u, new(big.Int).SetUint64(1<<64-1)).Uint64()
+	hi := int64(new(big.Int).Rsh(u, 64).Uint64())
+	alias := wapImportAlias
+	if imp := t.pkg.importMap[wapImportPath]; imp != nil {
+		alias = imp.alias
+	}
+	return fmt.Sprintf("%s(%s.Int128{Hi: %d, Lo: %d}.Bytes())", t.name, alias, hi, lo)
+}
+
+// genDecimal generates a decimal type with arithmetic returning an error instead of
+// overflowing and a JSON rendering with exactly scale decimals.
+func (c *Compiler) genDecimal(t *goType, b *Builder) {
+	W := b.W
+	wap := t.pkg.importMap[wapImportPath].alias
+	name, scale := t.name, t.t.Scale
+	var kind, runtime, units, of string
+	if t.t.Kind == KindDecimal64 {
+		kind = "Decimal64"
+		units = "int64(d)"
+		of = name + "(%s.Units)"
+		W("// %s is a decimal64(%d) stored as an int64 count of 10^-%d.", name, scale, scale)
+		W("type %s int64\n", name)
+	} else {
+		kind = "Decimal128"
+		units = wap + ".Int128FromBytes(d)"
+		of = name + "(%s.Units.Bytes())"
+		W("// %s is a decimal128(%d) stored as the little-endian int128 count of 10^-%d.", name, scale, scale)
+		W("type %s [16]byte\n", name)
+	}
+	runtime = wap + "." + kind
+
+	W("// %sOf returns d with %d decimals. It fails when that drops digits.", name, scale)
+	W("func %sOf(d %s) (%s, error) {", name, runtime, name)
+	W("    v, err := d.Rescale(%d)", scale)
+	W("    return %s, err", fmt.Sprintf(of, "v"))
+	W("}\n")
+
+	W("// Parse%s parses a JSON number with at most %d decimals.", name, scale)
+	W("func Parse%s(s string) (%s, error) {", name, name)
+	W("    v, err := %s.Parse%s(s, %d)", wap, kind, scale)
+	W("    return %s, err", fmt.Sprintf(of, "v"))
+	W("}\n")
+
+	W("func (d %s) Decimal() %s {", name, runtime)
+	W("    return %s{Units: %s, Scale: %d}", runtime, units, scale)
+	W("}\n")
+
+	for _, op := range []struct {
+		name string
+		doc  string
+	}{
+		{"Add", "d+v"},
+		{"Sub", "d-v"},
+		{"Mul", "d*v rounded half to even"},
+		{"Div", "d/v rounded half to even"},
+	} {
+		W("// %s returns %s. It fails rather than overflow.", op.name, op.doc)
+		W("func (d %s) %s(v %s) (%s, error) {", name, op.name, name, name)
+		W("    r, err := d.Decimal().%s(v.Decimal())", op.name)
+		W("    return %s, err", fmt.Sprintf(of, "r"))
+		W("}\n")
+	}
+
+	W("func (d %s) Cmp(v %s) int {", name, name)
+	W("    return d.Decimal().Cmp(v.Decimal())")
+	W("}\n")
+
+	W("// Float64 returns the nearest float64 for display and statistics.")
+	W("func (d %s) Float64() float64 {", name)
+	W("    return d.Decimal().Float64()")
+	W("}\n")
+
+	W("func (d %s) String() string {", name)
+	W("    return d.Decimal().String()")
+	W("}\n")
+
+	W("// AppendString appends d with exactly %d decimals and no exponent.", scale)
+	W("func (d %s) AppendString(b []byte) []byte {", name)
+	W("    return d.Decimal().AppendString(b)")
+	W("}\n")
+
+	W("func (d %s) MarshalJSON() ([]byte, error) {", name)
+	W("    return d.Decimal().AppendString(nil), nil")
+	W("}\n")
+
+	W("// UnmarshalJSON reads a JSON number, or a string holding one, with at most %d", scale)
+	W("// decimals.")
+	W("func (d *%s) UnmarshalJSON(b []byte) error {", name)
+	W("    v := d.Decimal()")
+	W("    if err := v.UnmarshalJSON(b); err != nil {")
+	W("        return err")
+	W("    }")
+	W("    *d = %s", fmt.Sprintf(of, "v"))
+	W("    return nil")
+	W("}\n")
+}
+
+// genMsgpackDecimal encodes a decimal64 as the integer of its units and a decimal128 as
+// the 16 bytes of its units.
+func (c *Compiler) genMsgpackDecimal(t *goType, b *Builder) {
+	W := b.W
+	if t.t.Kind == KindDecimal64 {
+		W("func (d %s) AppendMsgpack(b []byte) []byte {", t.name)
+		W("    return %s", msgpackAppend(KindInt64, "d"))
+		W("}\n")
+
+		W("func (d *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+		genConsumeMsgpack(b, KindInt64, "v")
+		W("    *d = %s(v)", t.name)
+		W("    return b, nil")
+		W("}\n")
+		return
+	}
+	W("func (d %s) AppendMsgpack(b []byte) []byte {", t.name)
+	W("    return msgpack.AppendBytes(b, d[:])")
+	W("}\n")
+
+	W("func (d *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+	W("    v, n := msgpack.ConsumeBytes(b)")
+	W("    if n < 0 {")
+	W("        return b, msgpack.ParseError(n)")
+	W("    }")
+	W("    if len(v) != %d {", t.t.Size)
+	W("        return b, fmt.Errorf(\"msgpack: %%d bytes for %s\", len(v))", t.name)
+	W("    }")
+	W("    copy(d[:], v)")
+	W("    return b[n:], nil")
+	W("}\n")
+}
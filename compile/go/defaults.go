@@ -0,0 +1,133 @@
+package _go
+
+import (
+	"fmt"
+	. "github.com/moontrade/proto/schema"
+	"strconv"
+)
+
+// goValue returns the Go literal for an initial value of a const or field of type t.
+func (c *Compiler) goValue(t *goType, init interface{}) (string, error) {
+	switch v := init.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return strconv.Quote(v), nil
+	case *EnumOption:
+		if t.imp != nil {
+			return fmt.Sprintf("%s.%s", t.imp.alias, c.enumOptionName(v)), nil
+		}
+		return c.enumOptionName(v), nil
+	case *Const:
+		return Capitalize(v.Name), nil
+	case Decimal:
+		return c.decimalValue(t, v), nil
+	}
+	return "", fmt.Errorf("%s:%d unsupported value: %v", t.t.File.Path, t.t.Line.Number, init)
+}
+
+// hasDefault reports whether the field declares an initial value other than nil.
+func hasDefault(f *goField) bool {
+	switch f.field.Type.Init.(type) {
+	case nil, Nil:
+		return false
+	}
+	return true
+}
+
+// genConsts generates a typed Go constant for each const declared in the file.
+func (c *Compiler) genConsts(file *goPackage, b *Builder) error {
+	if len(file.file.Consts) == 0 {
+		return nil
+	}
+	W := b.W
+	W("const (")
+	for _, cst := range file.file.Consts {
+		t, err := c.resolve(file, cst.Type, 0)
+		if err != nil {
+			return err
+		}
+		typeName := t.name
+		switch cst.Type.Kind {
+		case KindString, KindBytes:
+			typeName = "string"
+		case KindStruct, KindUnion, KindList, KindMap:
+			return fmt.Errorf("%s:%d const '%s' must be a primitive, string or enum",
+				cst.Type.File.Path, cst.Type.Line.Number, cst.Name)
+		case KindDecimal128:
+			return fmt.Errorf("%s:%d const '%s' cannot be a decimal128 since Go has no 128-bit constants",
+				cst.Type.File.Path, cst.Type.Line.Number, cst.Name)
+		}
+		value, err := c.goValue(t, cst.Type.Init)
+		if err != nil {
+			return err
+		}
+		c.genComments(b, cst.Type.Doc())
+		W("    %s %s = %s", Capitalize(cst.Name), typeName, value)
+	}
+	W(")\n")
+	return nil
+}
+
+// genDefaults generates NewXxx and Reset for a struct that has fields with initial values.
+func (c *Compiler) genDefaults(b *Builder, t *goType) error {
+	fields := make([]*goField, 0, len(t.st.fields))
+	for _, field := range t.st.fields {
+		if hasDefault(field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	W := b.W
+	W("// New%s returns a new %s with the schema default values applied.", t.name, t.name)
+	W("func New%s() *%s {", t.name, t.name)
+	W("    s := &%s{}", t.name)
+	W("    s.Mut().Reset()")
+	W("    return s")
+	W("}\n")
+
+	W("// Reset zeroes s and applies the schema default values.")
+	W("func (s *%s) Reset() *%s {", t.mut, t.mut)
+	W("    *s = %s{}", t.mut)
+	for _, field := range fields {
+		ft := field.field.Type
+		if ft.IsVariable() {
+			return fmt.Errorf("%s:%d default values are not supported for variable length field '%s'",
+				ft.File.Path, ft.Line.Number, field.field.Name)
+		}
+		value, err := c.goValue(field.t, ft.Init)
+		if err != nil {
+			return err
+		}
+		switch ft.Kind {
+		case KindString, KindBytes:
+			if ft.Optional {
+				W("    s.%s[%d] |= %d", headerFieldName, field.field.OptOffset, field.field.OptMask)
+			}
+			W("    s.%s.set(%s)", field.private, value)
+		case KindStruct, KindUnion, KindList, KindMap:
+			return fmt.Errorf("%s:%d default values are not supported for field '%s'",
+				ft.File.Path, ft.Line.Number, field.field.Name)
+		default:
+			if ft.Optional {
+				W("    {")
+				W("        v := %s(%s)", field.t.name, value)
+				W("        s.Set%s(&v)", field.public)
+				W("    }")
+			} else {
+				W("    s.Set%s(%s)", field.public, value)
+			}
+		}
+	}
+	W("    return s")
+	W("}\n")
+	return nil
+}
@@ -0,0 +1,70 @@
+package _go
+
+import (
+	"encoding/binary"
+	"fmt"
+	. "github.com/moontrade/proto/schema"
+)
+
+// swaps reports whether values of the type must be decoded from little-endian bytes
+// when generating for order. Only multi-byte primitives and enums are affected.
+func (c *Compiler) swaps(t *Type, order binary.ByteOrder) bool {
+	if order != binary.BigEndian {
+		return false
+	}
+	return c.wireBits(t) > 8
+}
+
+// wireBits returns the width in bits of the unsigned integer the type is encoded as.
+func (c *Compiler) wireBits(t *Type) int {
+	switch t.Kind {
+	case KindInt16, KindUInt16:
+		return 16
+	case KindInt32, KindUInt32, KindFloat32:
+		return 32
+	case KindInt64, KindUInt64, KindFloat64, KindDecimal64:
+		return 64
+	case KindEnum:
+		if t.Element != nil {
+			return c.wireBits(t.Element)
+		}
+	}
+	return 8
+}
+
+// decodeLE returns an expression that reads the value of type typeName stored
+// little-endian at the address expression addr.
+func (c *Compiler) decodeLE(t *Type, typeName, addr string) string {
+	bits := c.wireBits(t)
+	raw := fmt.Sprintf("binary.LittleEndian.Uint%d((*[%d]byte)(unsafe.Pointer(%s))[:])", bits, bits/8, addr)
+	kind := t.Kind
+	if kind == KindEnum {
+		kind = t.Element.Kind
+	}
+	switch kind {
+	case KindFloat32:
+		raw = fmt.Sprintf("math.Float32frombits(%s)", raw)
+	case KindFloat64:
+		raw = fmt.Sprintf("math.Float64frombits(%s)", raw)
+	}
+	return fmt.Sprintf("%s(%s)", typeName, raw)
+}
+
+// encodeLE returns a statement that writes the value expression v little-endian
+// to the address expression addr.
+func (c *Compiler) encodeLE(t *Type, addr, v string) string {
+	bits := c.wireBits(t)
+	kind := t.Kind
+	if kind == KindEnum {
+		kind = t.Element.Kind
+	}
+	switch kind {
+	case KindFloat32:
+		v = fmt.Sprintf("math.Float32bits(%s)", v)
+	case KindFloat64:
+		v = fmt.Sprintf("math.Float64bits(%s)", v)
+	default:
+		v = fmt.Sprintf("uint%d(%s)", bits, v)
+	}
+	return fmt.Sprintf("binary.LittleEndian.PutUint%d((*[%d]byte)(unsafe.Pointer(%s))[:], %s)", bits, bits/8, addr, v)
+}
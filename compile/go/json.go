@@ -0,0 +1,224 @@
+package _go
+
+import (
+	. "github.com/moontrade/proto/schema"
+)
+
+const runtime2ImportPath = "github.com/moontrade/proto/runtime2"
+
+// jsonRead returns the JsonLexer call reading a primitive of kind.
+func jsonRead(kind Kind) string {
+	switch kind {
+	case KindBool:
+		return "l.Bool()"
+	case KindByte:
+		return "l.Uint8()"
+	case KindInt8:
+		return "l.Int8()"
+	case KindInt16:
+		return "l.Int16()"
+	case KindUInt16:
+		return "l.Uint16()"
+	case KindInt32:
+		return "l.Int32()"
+	case KindUInt32:
+		return "l.Uint32()"
+	case KindInt64:
+		return "l.Int64()"
+	case KindUInt64:
+		return "l.Uint64()"
+	case KindFloat32:
+		return "l.Float32()"
+	case KindFloat64:
+		return "l.Float64()"
+	}
+	return ""
+}
+
+// jsonInterns returns whether t is a string or a list of strings, which read their
+// strings with StringIntern when the field is annotated @intern.
+func jsonInterns(t *Type) bool {
+	for t.Kind == KindList && t.Element != nil {
+		t = t.Element
+	}
+	return t.Kind == KindString
+}
+
+func (c *Compiler) genJSONEnum(t *goType, b *Builder) {
+	W := b.W
+	W("func (e *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+	W("    *e = %s(%s)", t.name, jsonRead(t.t.Element.Kind))
+	W("}\n")
+}
+
+func (c *Compiler) genJSONDecimal(t *goType, b *Builder) {
+	W := b.W
+	W("func (d *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+	W("    v := l.Raw()")
+	W("    if !l.Ok() {")
+	W("        return")
+	W("    }")
+	W("    if err := d.UnmarshalJSON(v); err != nil {")
+	W("        l.AddError(err)")
+	W("    }")
+	W("}\n")
+}
+
+// genJSONString reads fixed strings as JSON strings and fixed bytes as base64 like
+// encoding/json. Strings are truncated to the capacity.
+func (c *Compiler) genJSONString(t *goType, b *Builder) {
+	W := b.W
+	if t.t.Kind == KindBytes {
+		W("func (s *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+		W("    *s = %s{}", t.name)
+		W("    copy(s[:], l.Bytes())")
+		W("}\n")
+		return
+	}
+	W("func (s *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+	W("    s.unmarshalJSONLexer(l, false)")
+	W("}\n")
+
+	W("func (s *%s) unmarshalJSONLexer(l *runtime2.JsonLexer, intern bool) {", t.name)
+	W("    *s = %s{}", t.name)
+	W("    if intern {")
+	W("        s.set(l.StringIntern())")
+	W("    } else {")
+	W("        s.set(l.UnsafeString())")
+	W("    }")
+	W("}\n")
+}
+
+// genJSONReadElement writes statements reading a list element into v.
+func (c *Compiler) genJSONReadElement(b *Builder, element *goType, intern string) {
+	switch element.t.Kind {
+	case KindEnum, KindDecimal64, KindDecimal128, KindStruct, KindBytes:
+		b.W("var v %s", element.name)
+		b.W("v.UnmarshalJSONLexer(l)")
+	case KindString, KindList:
+		b.W("var v %s", element.name)
+		if len(intern) > 0 {
+			b.W("v.unmarshalJSONLexer(l, %s)", intern)
+		} else {
+			b.W("v.UnmarshalJSONLexer(l)")
+		}
+	default:
+		b.W("v := %s", jsonRead(element.t.Kind))
+	}
+}
+
+func (c *Compiler) genJSONList(t *goType, b *Builder) {
+	W := b.W
+	element := t.list.element
+	interns := jsonInterns(t.t)
+	if interns {
+		W("func (s *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+		W("    s.unmarshalJSONLexer(l, false)")
+		W("}\n")
+		W("func (s *%s) unmarshalJSONLexer(l *runtime2.JsonLexer, intern bool) {", t.name)
+	} else {
+		W("func (s *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+	}
+	W("    m := s.Mut()")
+	W("    m.Clear()")
+	W("    l.Delim('[')")
+	W("    for !l.IsDelim(']') {")
+	if interns {
+		c.genJSONReadElement(b, element, "intern")
+	} else {
+		c.genJSONReadElement(b, element, "")
+	}
+	push := "m.Push(v)"
+	if c.isPointerType(element.t) {
+		push = "m.Push(&v)"
+	}
+	W("        if !%s {", push)
+	W("            l.AddError(fmt.Errorf(\"json: more than %d elements for %s\"))", t.t.Len, t.name)
+	W("            return")
+	W("        }")
+	W("        l.WantComma()")
+	W("    }")
+	W("    l.Delim(']')")
+	W("}\n")
+}
+
+// genJSONStruct generates UnmarshalJSONLexer reading a JSON object holding the fields
+// under their name or short name. Null clears optional fields and unknown fields are
+// skipped.
+func (c *Compiler) genJSONStruct(t *goType, b *Builder) error {
+	W := b.W
+	fields, err := codecFields(t, "json")
+	if err != nil {
+		return err
+	}
+
+	usesMut := false
+	for _, field := range fields {
+		switch field.field.Type.Kind {
+		case KindStruct, KindList, KindString, KindBytes:
+		default:
+			usesMut = true
+		}
+	}
+
+	W("func (s *%s) UnmarshalJSONLexer(l *runtime2.JsonLexer) {", t.name)
+	if usesMut {
+		W("    m := s.Mut()")
+	}
+	W("    l.Delim('{')")
+	W("    for !l.IsDelim('}') {")
+	W("        key := l.UnsafeFieldName(false)")
+	W("        l.WantColon()")
+	W("        switch key {")
+	for _, field := range fields {
+		ft := field.field.Type
+		if key := msgpackKey(field.field); key != field.field.Name {
+			W("        case %q, %q:", key, field.field.Name)
+		} else {
+			W("        case %q:", key)
+		}
+		if ft.Optional {
+			W("if l.IsNull() {")
+			W("    l.Skip()")
+			switch ft.Kind {
+			case KindStruct, KindList, KindString, KindBytes:
+				W("s.%s[%d] &^= %d", headerFieldName, field.field.OptOffset, field.field.OptMask)
+				W("s.%s = %s{}", field.private, field.t.name)
+			default:
+				W("m.Set%s(nil)", field.public)
+			}
+			W("    break")
+			W("}")
+		}
+		switch ft.Kind {
+		case KindStruct, KindList, KindString, KindBytes:
+			if field.field.Intern && jsonInterns(ft) {
+				W("s.%s.unmarshalJSONLexer(l, true)", field.private)
+			} else {
+				W("s.%s.UnmarshalJSONLexer(l)", field.private)
+			}
+			if ft.Optional {
+				W("s.%s[%d] |= %d", headerFieldName, field.field.OptOffset, field.field.OptMask)
+			}
+			continue
+		case KindEnum, KindDecimal64, KindDecimal128:
+			W("var v %s", field.t.name)
+			W("v.UnmarshalJSONLexer(l)")
+		default:
+			W("v := %s", jsonRead(ft.Kind))
+		}
+		if ft.Optional {
+			W("m.Set%s(&v)", field.public)
+		} else {
+			W("m.Set%s(v)", field.public)
+		}
+	}
+	W("        default:")
+	W("            l.SkipRecursive()")
+	W("        }")
+	W("        l.WantComma()")
+	W("    }")
+	W("    l.Delim('}')")
+	W("}\n")
+	return nil
+}
@@ -0,0 +1,50 @@
+package _go
+
+import (
+	. "github.com/moontrade/proto/schema"
+)
+
+// genLayoutCheck writes assertions that the size of the struct and its Mut type and the
+// offset and size of every field match the schema. check names a function taking a
+// description, the actual and the expected value.
+func (c *Compiler) genLayoutCheck(b *Builder, t *goType, check string) {
+	W := b.W
+	W("    %s(\"sizeof %s\", unsafe.Sizeof(%s{}), %d)", check, t.name, t.name, t.t.Size)
+	W("    %s(\"sizeof %s\", unsafe.Sizeof(%s{}), %d)", check, t.mut, t.mut, t.t.Size)
+	if t.t.HeaderSize > 0 {
+		W("    %s(\"sizeof %s.%s\", unsafe.Sizeof(%s{}.%s), %d)",
+			check, t.name, headerFieldName, t.name, headerFieldName, t.t.HeaderSize)
+	}
+	for _, field := range t.st.fields {
+		if field.t.t.Kind == KindPad {
+			continue
+		}
+		W("    %s(\"offsetof %s.%s\", unsafe.Offsetof(%s{}.%s), %d)",
+			check, t.name, field.private, t.name, field.private, field.field.Offset)
+		W("    %s(\"sizeof %s.%s\", unsafe.Sizeof(%s{}.%s), %d)",
+			check, t.name, field.private, t.name, field.private, field.field.Type.Size)
+	}
+}
+
+// genLayoutTest generates a test in the package asserting the layout of every struct.
+func (c *Compiler) genLayoutTest(file *goPackage, b *Builder) {
+	W := b.W
+	W("package %s\n", file.packageName)
+	W("import (")
+	W("    \"testing\"")
+	W("    \"unsafe\"")
+	W(")\n")
+	W("// TestLayout checks the Go layout of each struct matches the offsets and sizes")
+	W("// computed from the schema.")
+	W("func TestLayout(t *testing.T) {")
+	W("    layout := func(name string, actual, expected uintptr) {")
+	W("        t.Helper()")
+	W("        if actual != expected {")
+	W("            t.Errorf(\"%%s = %%d, expected = %%d\", name, actual, expected)")
+	W("        }")
+	W("    }")
+	for _, st := range sortedTypes(file.structs) {
+		c.genLayoutCheck(b, st, "layout")
+	}
+	W("}")
+}
@@ -5,7 +5,27 @@ import (
 	. "github.com/moontrade/proto/schema"
 )
 
-const headerFieldName = "_h_"
+const (
+	headerFieldName = "_h_"
+	wapImportPath   = "github.com/moontrade/proto"
+	wapImportAlias  = "wap"
+)
+
+// layoutTestFileName is the name of the test generated by LayoutCheckTest.
+const layoutTestFileName = "proto_layout_test.go"
+
+// LayoutCheck selects how the generated code asserts the Go layout of each struct
+// matches the offsets and sizes computed from the schema.
+type LayoutCheck int
+
+const (
+	// LayoutCheckInit panics in a generated init function on a mismatch.
+	LayoutCheckInit LayoutCheck = iota
+	// LayoutCheckTest generates a proto_layout_test.go alongside each package instead.
+	LayoutCheckTest
+	// LayoutCheckNone generates no layout check.
+	LayoutCheckNone
+)
 
 func goFileName(order binary.ByteOrder) string {
 	if order == binary.BigEndian {
@@ -26,6 +46,22 @@ type Config struct {
 	Mutable       bool
 	MultipleFiles bool
 	Output        string
+	// BoundsChecked generates accessors that are safe over untrusted bytes. Lengths are
+	// clamped to capacity, UnmarshalBinary and Reinterpret verify the buffer first and
+	// variable length getters take the buffer and check their VPointer stays within it.
+	BoundsChecked bool
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
+	// LayoutCheck selects how generated code asserts struct sizes and field offsets.
+	LayoutCheck LayoutCheck
+	// Msgpack generates AppendMsgpack and UnmarshalMsgpack for structs, enums, fixed
+	// strings and lists. Structs with variable length fields are rejected.
+	Msgpack bool
+	// JSON generates UnmarshalJSONLexer reading structs, enums, fixed strings, decimals
+	// and lists from a runtime2.JsonLexer. String fields annotated @intern are read with
+	// StringIntern. Structs with variable length fields are rejected.
+	JSON bool
 }
 
 // Compiler generates Go code for a supplied Schema
@@ -67,6 +103,7 @@ type goPackage struct {
 	types       map[string]*goType
 	lists       map[string]*goType
 	strings     map[string]*goType
+	decimals    map[string]*goType
 	structs     map[string]*goType
 	enums       map[string]*goType
 	unions      map[string]*goType
@@ -0,0 +1,327 @@
+package _go
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+const msgpackImportPath = "github.com/moontrade/proto/compile/go/msgpack"
+
+// msgpackAppend returns an expression appending the primitive value v of kind to b.
+func msgpackAppend(kind Kind, v string) string {
+	switch kind {
+	case KindBool:
+		return fmt.Sprintf("msgpack.AppendBool(b, %s)", v)
+	case KindInt8, KindInt16, KindInt32, KindInt64:
+		return fmt.Sprintf("msgpack.AppendInt(b, int64(%s))", v)
+	case KindByte, KindUInt16, KindUInt32, KindUInt64:
+		return fmt.Sprintf("msgpack.AppendUint(b, uint64(%s))", v)
+	case KindFloat32:
+		return fmt.Sprintf("msgpack.AppendFloat32(b, %s)", v)
+	case KindFloat64:
+		return fmt.Sprintf("msgpack.AppendFloat64(b, %s)", v)
+	}
+	return ""
+}
+
+// msgpackConsume returns the function consuming a primitive of kind.
+func msgpackConsume(kind Kind) string {
+	switch kind {
+	case KindBool:
+		return "msgpack.ConsumeBool"
+	case KindByte:
+		return "msgpack.ConsumeUint8"
+	case KindInt8:
+		return "msgpack.ConsumeInt8"
+	case KindInt16:
+		return "msgpack.ConsumeInt16"
+	case KindUInt16:
+		return "msgpack.ConsumeUint16"
+	case KindInt32:
+		return "msgpack.ConsumeInt32"
+	case KindUInt32:
+		return "msgpack.ConsumeUint32"
+	case KindInt64:
+		return "msgpack.ConsumeInt64"
+	case KindUInt64:
+		return "msgpack.ConsumeUint64"
+	case KindFloat32:
+		return "msgpack.ConsumeFloat32"
+	case KindFloat64:
+		return "msgpack.ConsumeFloat64"
+	}
+	return ""
+}
+
+// msgpackKey returns the map key of a struct field. The short name is used when the
+// field has one.
+func msgpackKey(field *StructField) string {
+	if len(field.Short) > 0 {
+		return field.Short
+	}
+	return field.Name
+}
+
+// genConsumeMsgpack writes statements consuming a primitive of kind into v.
+func genConsumeMsgpack(b *Builder, kind Kind, v string) {
+	b.W("%s, n := %s(b)", v, msgpackConsume(kind))
+	b.W("if n < 0 {")
+	b.W("    return b, msgpack.ParseError(n)")
+	b.W("}")
+	b.W("b = b[n:]")
+}
+
+func (c *Compiler) genMsgpackEnum(t *goType, b *Builder) {
+	W := b.W
+	kind := t.t.Element.Kind
+	W("func (e %s) AppendMsgpack(b []byte) []byte {", t.name)
+	W("    return %s", msgpackAppend(kind, "e"))
+	W("}\n")
+
+	W("func (e *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+	genConsumeMsgpack(b, kind, "v")
+	W("    *e = %s(v)", t.name)
+	W("    return b, nil")
+	W("}\n")
+}
+
+func (c *Compiler) genMsgpackString(t *goType, b *Builder) {
+	W := b.W
+	W("func (s *%s) AppendMsgpack(b []byte) []byte {", t.name)
+	if t.t.Kind == KindBytes {
+		W("    return msgpack.AppendBytes(b, s[:])")
+	} else {
+		W("    return msgpack.AppendString(b, s.String())")
+	}
+	W("}\n")
+
+	W("func (s *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+	W("    v, n := msgpack.ConsumeBytes(b)")
+	W("    if n < 0 {")
+	W("        return b, msgpack.ParseError(n)")
+	W("    }")
+	W("    *s = %s{}", t.name)
+	if t.t.Kind == KindBytes {
+		W("    copy(s[:], v)")
+	} else {
+		W("    s.set(*(*string)(unsafe.Pointer(&v)))")
+	}
+	W("    return b[n:], nil")
+	W("}\n")
+}
+
+// genMsgpackAppendElement writes a statement appending the list element at s.b[i].
+func (c *Compiler) genMsgpackAppendElement(b *Builder, element *goType, order binary.ByteOrder) {
+	value := "s.b[i]"
+	if c.swaps(element.t, order) {
+		value = c.decodeLE(element.t, element.name, "&s.b[i]")
+	}
+	switch element.t.Kind {
+	case KindEnum, KindDecimal64, KindDecimal128:
+		b.W("b = %s.AppendMsgpack(b)", value)
+	case KindStruct, KindList, KindString, KindBytes:
+		b.W("b = s.b[i].AppendMsgpack(b)")
+	default:
+		b.W("b = %s", msgpackAppend(element.t.Kind, value))
+	}
+}
+
+func (c *Compiler) genMsgpackList(t *goType, b *Builder, order binary.ByteOrder) {
+	W := b.W
+	element := t.list.element
+	W("func (s *%s) AppendMsgpack(b []byte) []byte {", t.name)
+	W("    b = msgpack.AppendArrayHeader(b, s.Len())")
+	W("    for i := 0; i < s.Len(); i++ {")
+	c.genMsgpackAppendElement(b, element, order)
+	W("    }")
+	W("    return b")
+	W("}\n")
+
+	W("func (s *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+	W("    l, n := msgpack.ConsumeArrayHeader(b)")
+	W("    if n < 0 {")
+	W("        return b, msgpack.ParseError(n)")
+	W("    }")
+	W("    if l > %d {", t.t.Len)
+	W("        return b, fmt.Errorf(\"msgpack: %%d elements overflow %s of %d\", l)", t.name, t.t.Len)
+	W("    }")
+	W("    b = b[n:]")
+	W("    m := s.Mut()")
+	W("    m.Clear()")
+	W("    for i := 0; i < l; i++ {")
+	switch element.t.Kind {
+	case KindEnum, KindDecimal64, KindDecimal128, KindStruct, KindList, KindString, KindBytes:
+		W("var v %s", element.name)
+		W("var err error")
+		W("if b, err = v.UnmarshalMsgpack(b); err != nil {")
+		W("    return b, err")
+		W("}")
+	default:
+		genConsumeMsgpack(b, element.t.Kind, "v")
+	}
+	if c.isPointerType(element.t) {
+		W("        m.Push(&v)")
+	} else {
+		W("        m.Push(v)")
+	}
+	W("    }")
+	W("    return b, nil")
+	W("}\n")
+}
+
+// codecFields returns the fields of a struct that the msgpack or json codec encodes.
+// Variable length fields live in the heap of the buffer which the decoders cannot
+// grow, so they are rejected rather than silently dropped.
+func codecFields(t *goType, codec string) ([]*goField, error) {
+	fields := make([]*goField, 0, len(t.st.fields))
+	for _, field := range t.st.fields {
+		ft := field.field.Type
+		if ft.IsVariable() {
+			return nil, fmt.Errorf("%s:%d %s is not supported for variable length field '%s.%s'",
+				ft.File.Path, ft.Line.Number, codec, t.name, field.field.Name)
+		}
+		if ft.Kind == KindPad {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// genMsgpackStruct generates AppendMsgpack encoding the struct as a map of its fields
+// and UnmarshalMsgpack decoding one. Absent optional fields are encoded as nil and
+// unknown keys are skipped.
+func (c *Compiler) genMsgpackStruct(t *goType, b *Builder) error {
+	W := b.W
+	fields, err := codecFields(t, "msgpack")
+	if err != nil {
+		return err
+	}
+
+	W("func (s *%s) AppendMsgpack(b []byte) []byte {", t.name)
+	W("    b = msgpack.AppendMapHeader(b, %d)", len(fields))
+	for _, field := range fields {
+		ft := field.field.Type
+		W("    b = msgpack.AppendString(b, %q)", msgpackKey(field.field))
+		switch ft.Kind {
+		case KindStruct, KindList, KindString, KindBytes:
+			if ft.Optional {
+				W("    if s.%s[%d]&%d == 0 {", headerFieldName, field.field.OptOffset, field.field.OptMask)
+				W("        b = msgpack.AppendNil(b)")
+				W("    } else {")
+				W("        b = s.%s.AppendMsgpack(b)", field.private)
+				W("    }")
+			} else {
+				W("    b = s.%s.AppendMsgpack(b)", field.private)
+			}
+		default:
+			appendValue := func(v string) string {
+				if ft.Kind == KindEnum || ft.Kind == KindDecimal64 || ft.Kind == KindDecimal128 {
+					return fmt.Sprintf("%s.AppendMsgpack(b)", v)
+				}
+				return msgpackAppend(ft.Kind, v)
+			}
+			if ft.Optional {
+				W("    if v := s.%s(); v == nil {", field.public)
+				W("        b = msgpack.AppendNil(b)")
+				W("    } else {")
+				W("        b = %s", appendValue("(*v)"))
+				W("    }")
+			} else {
+				W("    b = %s", appendValue(fmt.Sprintf("s.%s()", field.public)))
+			}
+		}
+	}
+	W("    return b")
+	W("}\n")
+
+	// Nested values decode themselves and primitives are stored through the setters
+	// which encode them in the byte order of the build.
+	usesErr, usesMut := false, false
+	for _, field := range fields {
+		switch field.field.Type.Kind {
+		case KindStruct, KindList, KindString, KindBytes:
+			usesErr = true
+		case KindEnum, KindDecimal64, KindDecimal128:
+			usesErr, usesMut = true, true
+		default:
+			usesMut = true
+		}
+	}
+
+	W("func (s *%s) UnmarshalMsgpack(b []byte) ([]byte, error) {", t.name)
+	W("    l, n := msgpack.ConsumeMapHeader(b)")
+	W("    if n < 0 {")
+	W("        return b, msgpack.ParseError(n)")
+	W("    }")
+	W("    b = b[n:]")
+	if usesErr {
+		W("    var err error")
+	}
+	if usesMut {
+		W("    m := s.Mut()")
+	}
+	W("    for i := 0; i < l; i++ {")
+	W("        k, n := msgpack.ConsumeBytes(b)")
+	W("        if n < 0 {")
+	W("            return b, msgpack.ParseError(n)")
+	W("        }")
+	W("        b = b[n:]")
+	W("        switch string(k) {")
+	for _, field := range fields {
+		ft := field.field.Type
+		if key := msgpackKey(field.field); key != field.field.Name {
+			W("        case %q, %q:", key, field.field.Name)
+		} else {
+			W("        case %q:", key)
+		}
+		if ft.Optional {
+			W("if msgpack.IsNil(b) {")
+			W("    b = b[1:]")
+			switch ft.Kind {
+			case KindStruct, KindList, KindString, KindBytes:
+				W("s.%s[%d] &^= %d", headerFieldName, field.field.OptOffset, field.field.OptMask)
+				W("s.%s = %s{}", field.private, field.t.name)
+			default:
+				W("m.Set%s(nil)", field.public)
+			}
+			W("    continue")
+			W("}")
+		}
+		switch ft.Kind {
+		case KindStruct, KindList, KindString, KindBytes:
+			W("if b, err = s.%s.UnmarshalMsgpack(b); err != nil {", field.private)
+			W("    return b, err")
+			W("}")
+			if ft.Optional {
+				W("s.%s[%d] |= %d", headerFieldName, field.field.OptOffset, field.field.OptMask)
+			}
+			continue
+		case KindEnum, KindDecimal64, KindDecimal128:
+			W("var v %s", field.t.name)
+			W("if b, err = v.UnmarshalMsgpack(b); err != nil {")
+			W("    return b, err")
+			W("}")
+		default:
+			genConsumeMsgpack(b, ft.Kind, "v")
+		}
+		if ft.Optional {
+			W("m.Set%s(&v)", field.public)
+		} else {
+			W("m.Set%s(v)", field.public)
+		}
+	}
+	W("        default:")
+	W("            n = msgpack.ConsumeValue(b)")
+	W("            if n < 0 {")
+	W("                return b, msgpack.ParseError(n)")
+	W("            }")
+	W("            b = b[n:]")
+	W("        }")
+	W("    }")
+	W("    return b, nil")
+	W("}\n")
+	return nil
+}
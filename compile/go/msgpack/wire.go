@@ -0,0 +1,538 @@
+// Package msgpack parses and formats the MessagePack encoding used by generated
+// AppendMsgpack and UnmarshalMsgpack methods.
+// See https://github.com/msgpack/msgpack/blob/master/spec.md.
+//
+// Append functions append the smallest encoding of a value. Consume functions return
+// the value and the number of bytes read, or a negative number on error which
+// ParseError converts into an error value.
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// Format bytes that start a value.
+const (
+	Nil      byte = 0xc0
+	False    byte = 0xc2
+	True     byte = 0xc3
+	Bin8     byte = 0xc4
+	Bin16    byte = 0xc5
+	Bin32    byte = 0xc6
+	Ext8     byte = 0xc7
+	Ext16    byte = 0xc8
+	Ext32    byte = 0xc9
+	Float32  byte = 0xca
+	Float64  byte = 0xcb
+	Uint8    byte = 0xcc
+	Uint16   byte = 0xcd
+	Uint32   byte = 0xce
+	Uint64   byte = 0xcf
+	Int8     byte = 0xd0
+	Int16    byte = 0xd1
+	Int32    byte = 0xd2
+	Int64    byte = 0xd3
+	FixExt1  byte = 0xd4
+	FixExt2  byte = 0xd5
+	FixExt4  byte = 0xd6
+	FixExt8  byte = 0xd7
+	FixExt16 byte = 0xd8
+	Str8     byte = 0xd9
+	Str16    byte = 0xda
+	Str32    byte = 0xdb
+	Array16  byte = 0xdc
+	Array32  byte = 0xdd
+	Map16    byte = 0xde
+	Map32    byte = 0xdf
+
+	FixMap   byte = 0x80
+	FixArray byte = 0x90
+	FixStr   byte = 0xa0
+)
+
+const (
+	_ = -iota
+	errCodeTruncated
+	errCodeType
+	errCodeOverflow
+)
+
+var (
+	errType     = errors.New("msgpack: unexpected type")
+	errOverflow = errors.New("msgpack: value overflows the field")
+	errParse    = errors.New("msgpack: parse error")
+)
+
+// ParseError converts an error code into an error value.
+// This returns nil if n is a non-negative number.
+func ParseError(n int) error {
+	if n >= 0 {
+		return nil
+	}
+	switch n {
+	case errCodeTruncated:
+		return io.ErrUnexpectedEOF
+	case errCodeType:
+		return errType
+	case errCodeOverflow:
+		return errOverflow
+	}
+	return errParse
+}
+
+// AppendNil appends nil to b.
+func AppendNil(b []byte) []byte {
+	return append(b, Nil)
+}
+
+// IsNil reports whether the next value in b is nil.
+func IsNil(b []byte) bool {
+	return len(b) > 0 && b[0] == Nil
+}
+
+// ConsumeNil parses b as nil, reporting its length.
+func ConsumeNil(b []byte) int {
+	if len(b) == 0 {
+		return errCodeTruncated
+	}
+	if b[0] != Nil {
+		return errCodeType
+	}
+	return 1
+}
+
+// AppendBool appends v to b.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, True)
+	}
+	return append(b, False)
+}
+
+// ConsumeBool parses b as a bool, reporting its length.
+func ConsumeBool(b []byte) (v bool, n int) {
+	if len(b) == 0 {
+		return false, errCodeTruncated
+	}
+	switch b[0] {
+	case True:
+		return true, 1
+	case False:
+		return false, 1
+	}
+	return false, errCodeType
+}
+
+// AppendInt appends v to b in the smallest integer format that holds it.
+func AppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return AppendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, Int8, byte(v))
+	case v >= math.MinInt16:
+		return append(b, Int16, byte(v>>8), byte(v))
+	case v >= math.MinInt32:
+		return append(b, Int32, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return appendUint64(append(b, Int64), uint64(v))
+}
+
+// AppendUint appends v to b in the smallest integer format that holds it.
+func AppendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, Uint8, byte(v))
+	case v <= math.MaxUint16:
+		return append(b, Uint16, byte(v>>8), byte(v))
+	case v <= math.MaxUint32:
+		return append(b, Uint32, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return appendUint64(append(b, Uint64), v)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return append(b, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// consumeInteger parses b as an integer in any format. Values over math.MaxInt64 are
+// reported through u with neg false.
+func consumeInteger(b []byte) (i int64, u uint64, neg bool, n int) {
+	if len(b) == 0 {
+		return 0, 0, false, errCodeTruncated
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), uint64(c), false, 1
+	case c >= 0xe0:
+		return int64(int8(c)), 0, true, 1
+	}
+	size := 0
+	switch c {
+	case Uint8, Int8:
+		size = 1
+	case Uint16, Int16:
+		size = 2
+	case Uint32, Int32:
+		size = 4
+	case Uint64, Int64:
+		size = 8
+	default:
+		return 0, 0, false, errCodeType
+	}
+	if len(b) < 1+size {
+		return 0, 0, false, errCodeTruncated
+	}
+	p := b[1 : 1+size]
+	switch c {
+	case Uint8:
+		u = uint64(p[0])
+	case Uint16:
+		u = uint64(binary.BigEndian.Uint16(p))
+	case Uint32:
+		u = uint64(binary.BigEndian.Uint32(p))
+	case Uint64:
+		u = binary.BigEndian.Uint64(p)
+	case Int8:
+		i = int64(int8(p[0]))
+	case Int16:
+		i = int64(int16(binary.BigEndian.Uint16(p)))
+	case Int32:
+		i = int64(int32(binary.BigEndian.Uint32(p)))
+	case Int64:
+		i = int64(binary.BigEndian.Uint64(p))
+	}
+	if c >= Int8 {
+		if i < 0 {
+			return i, 0, true, 1 + size
+		}
+		return i, uint64(i), false, 1 + size
+	}
+	return int64(u), u, false, 1 + size
+}
+
+// ConsumeInt parses b as an integer that fits in bits, reporting its length.
+func ConsumeInt(b []byte, bits int) (v int64, n int) {
+	i, u, neg, n := consumeInteger(b)
+	if n < 0 {
+		return 0, n
+	}
+	if neg {
+		if bits < 64 && i < -1<<(bits-1) {
+			return 0, errCodeOverflow
+		}
+		return i, n
+	}
+	if u > 1<<(bits-1)-1 {
+		return 0, errCodeOverflow
+	}
+	return int64(u), n
+}
+
+// ConsumeUint parses b as an unsigned integer that fits in bits, reporting its length.
+func ConsumeUint(b []byte, bits int) (v uint64, n int) {
+	_, u, neg, n := consumeInteger(b)
+	if n < 0 {
+		return 0, n
+	}
+	if neg || (bits < 64 && u > 1<<bits-1) {
+		return 0, errCodeOverflow
+	}
+	return u, n
+}
+
+func ConsumeInt8(b []byte) (int8, int) {
+	v, n := ConsumeInt(b, 8)
+	return int8(v), n
+}
+
+func ConsumeInt16(b []byte) (int16, int) {
+	v, n := ConsumeInt(b, 16)
+	return int16(v), n
+}
+
+func ConsumeInt32(b []byte) (int32, int) {
+	v, n := ConsumeInt(b, 32)
+	return int32(v), n
+}
+
+func ConsumeInt64(b []byte) (int64, int) {
+	return ConsumeInt(b, 64)
+}
+
+func ConsumeUint8(b []byte) (uint8, int) {
+	v, n := ConsumeUint(b, 8)
+	return uint8(v), n
+}
+
+func ConsumeUint16(b []byte) (uint16, int) {
+	v, n := ConsumeUint(b, 16)
+	return uint16(v), n
+}
+
+func ConsumeUint32(b []byte) (uint32, int) {
+	v, n := ConsumeUint(b, 32)
+	return uint32(v), n
+}
+
+func ConsumeUint64(b []byte) (uint64, int) {
+	return ConsumeUint(b, 64)
+}
+
+// AppendFloat32 appends v to b as a float 32.
+func AppendFloat32(b []byte, v float32) []byte {
+	u := math.Float32bits(v)
+	return append(b, Float32, byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+// AppendFloat64 appends v to b as a float 64.
+func AppendFloat64(b []byte, v float64) []byte {
+	return appendUint64(append(b, Float64), math.Float64bits(v))
+}
+
+// ConsumeFloat64 parses b as a float or an integer, reporting its length.
+func ConsumeFloat64(b []byte) (v float64, n int) {
+	if len(b) == 0 {
+		return 0, errCodeTruncated
+	}
+	switch b[0] {
+	case Float32:
+		if len(b) < 5 {
+			return 0, errCodeTruncated
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b[1:]))), 5
+	case Float64:
+		if len(b) < 9 {
+			return 0, errCodeTruncated
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), 9
+	}
+	i, u, neg, n := consumeInteger(b)
+	if n < 0 {
+		return 0, n
+	}
+	if neg {
+		return float64(i), n
+	}
+	return float64(u), n
+}
+
+// ConsumeFloat32 parses b as a float or an integer, reporting its length.
+func ConsumeFloat32(b []byte) (v float32, n int) {
+	f, n := ConsumeFloat64(b)
+	return float32(f), n
+}
+
+// AppendString appends v to b as a str.
+func AppendString(b []byte, v string) []byte {
+	b = appendHeader(b, len(v), FixStr, 31, Str8, Str16, Str32)
+	return append(b, v...)
+}
+
+// AppendBytes appends v to b as a bin.
+func AppendBytes(b []byte, v []byte) []byte {
+	l := len(v)
+	switch {
+	case l <= math.MaxUint8:
+		b = append(b, Bin8, byte(l))
+	case l <= math.MaxUint16:
+		b = append(b, Bin16, byte(l>>8), byte(l))
+	default:
+		b = append(b, Bin32, byte(l>>24), byte(l>>16), byte(l>>8), byte(l))
+	}
+	return append(b, v...)
+}
+
+// ConsumeBytes parses b as a str or bin, reporting its length. The value aliases b.
+func ConsumeBytes(b []byte) (v []byte, n int) {
+	if len(b) == 0 {
+		return nil, errCodeTruncated
+	}
+	l, h := 0, 0
+	c := b[0]
+	switch {
+	case c >= FixStr && c <= FixStr|31:
+		l, h = int(c&31), 1
+	case c == Str8 || c == Bin8:
+		l, h = readLength(b, 1)
+	case c == Str16 || c == Bin16:
+		l, h = readLength(b, 2)
+	case c == Str32 || c == Bin32:
+		l, h = readLength(b, 4)
+	default:
+		return nil, errCodeType
+	}
+	if h < 0 {
+		return nil, h
+	}
+	if len(b) < h+l {
+		return nil, errCodeTruncated
+	}
+	return b[h : h+l], h + l
+}
+
+// ConsumeString parses b as a str or bin, reporting its length.
+func ConsumeString(b []byte) (v string, n int) {
+	bb, n := ConsumeBytes(b)
+	return string(bb), n
+}
+
+// AppendArrayHeader appends the header of an array of n values to b.
+func AppendArrayHeader(b []byte, n int) []byte {
+	return appendHeader(b, n, FixArray, 15, 0, Array16, Array32)
+}
+
+// ConsumeArrayHeader parses b as the header of an array, reporting the number of
+// values and the length of the header.
+func ConsumeArrayHeader(b []byte) (l int, n int) {
+	return consumeHeader(b, FixArray, 15, Array16, Array32)
+}
+
+// AppendMapHeader appends the header of a map of n key value pairs to b.
+func AppendMapHeader(b []byte, n int) []byte {
+	return appendHeader(b, n, FixMap, 15, 0, Map16, Map32)
+}
+
+// ConsumeMapHeader parses b as the header of a map, reporting the number of key value
+// pairs and the length of the header.
+func ConsumeMapHeader(b []byte) (l int, n int) {
+	return consumeHeader(b, FixMap, 15, Map16, Map32)
+}
+
+func appendHeader(b []byte, n int, fix byte, fixMax int, f8, f16, f32 byte) []byte {
+	switch {
+	case n <= fixMax:
+		return append(b, fix|byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		return append(b, f8, byte(n))
+	case n <= math.MaxUint16:
+		return append(b, f16, byte(n>>8), byte(n))
+	}
+	return append(b, f32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func consumeHeader(b []byte, fix byte, fixMax int, f16, f32 byte) (l int, n int) {
+	if len(b) == 0 {
+		return 0, errCodeTruncated
+	}
+	c := b[0]
+	switch {
+	case c >= fix && int(c-fix) <= fixMax:
+		return int(c - fix), 1
+	case c == f16:
+		return readLength(b, 2)
+	case c == f32:
+		return readLength(b, 4)
+	}
+	return 0, errCodeType
+}
+
+// readLength reads the big endian length of size bytes following the format byte.
+func readLength(b []byte, size int) (l int, n int) {
+	if len(b) < 1+size {
+		return 0, errCodeTruncated
+	}
+	switch size {
+	case 1:
+		l = int(b[1])
+	case 2:
+		l = int(binary.BigEndian.Uint16(b[1:]))
+	default:
+		u := binary.BigEndian.Uint32(b[1:])
+		if uint64(u) > math.MaxInt32 {
+			return 0, errCodeOverflow
+		}
+		l = int(u)
+	}
+	return l, 1 + size
+}
+
+// ConsumeValue parses b as any value including nested arrays and maps, reporting its
+// length. It is used to skip values of unknown keys.
+func ConsumeValue(b []byte) (n int) {
+	for remaining := 1; remaining > 0; remaining-- {
+		if len(b) <= n {
+			return errCodeTruncated
+		}
+		c := b[n]
+		size := 0
+		switch {
+		case c <= 0x7f || c >= 0xe0 || c == Nil || c == False || c == True:
+			size = 1
+		case c >= FixMap && c <= FixMap|15:
+			remaining += 2 * int(c&15)
+			size = 1
+		case c >= FixArray && c <= FixArray|15:
+			remaining += int(c & 15)
+			size = 1
+		case c >= FixStr && c <= FixStr|31:
+			size = 1 + int(c&31)
+		case c == Uint8 || c == Int8:
+			size = 2
+		case c == Uint16 || c == Int16 || c == FixExt1:
+			size = 3
+		case c == FixExt2:
+			size = 4
+		case c == Uint32 || c == Int32 || c == Float32:
+			size = 5
+		case c == FixExt4:
+			size = 6
+		case c == Uint64 || c == Int64 || c == Float64:
+			size = 9
+		case c == FixExt8:
+			size = 10
+		case c == FixExt16:
+			size = 18
+		case c == Str8 || c == Bin8 || c == Str16 || c == Bin16 || c == Str32 || c == Bin32,
+			c == Ext8 || c == Ext16 || c == Ext32:
+			width := 1
+			switch c {
+			case Str16, Bin16, Ext16:
+				width = 2
+			case Str32, Bin32, Ext32:
+				width = 4
+			}
+			l, h := readLength(b[n:], width)
+			if h < 0 {
+				return h
+			}
+			size = h + l
+			if c == Ext8 || c == Ext16 || c == Ext32 {
+				size++ // type
+			}
+		case c == Array16 || c == Array32 || c == Map16 || c == Map32:
+			width := 2
+			if c == Array32 || c == Map32 {
+				width = 4
+			}
+			l, h := readLength(b[n:], width)
+			if h < 0 {
+				return h
+			}
+			if c == Map16 || c == Map32 {
+				l *= 2
+			}
+			if l > len(b)-n {
+				return errCodeTruncated
+			}
+			remaining += l
+			size = h
+		default:
+			return errCodeType
+		}
+		if len(b)-n < size {
+			return errCodeTruncated
+		}
+		n += size
+	}
+	return n
+}
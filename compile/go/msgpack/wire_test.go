@@ -0,0 +1,142 @@
+package msgpack
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestIntegers(t *testing.T) {
+	for _, test := range []struct {
+		v       int64
+		encoded []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{Uint8, 0x80}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{Int8, 0xdf}},
+		{300, []byte{Uint16, 0x01, 0x2c}},
+		{-300, []byte{Int16, 0xfe, 0xd4}},
+		{1 << 20, []byte{Uint32, 0x00, 0x10, 0x00, 0x00}},
+		{math.MinInt64, []byte{Int64, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+	} {
+		b := AppendInt(nil, test.v)
+		if !bytes.Equal(b, test.encoded) {
+			t.Fatalf("AppendInt(%d) = %x, expected %x", test.v, b, test.encoded)
+		}
+		v, n := ConsumeInt64(b)
+		if n != len(b) || v != test.v {
+			t.Fatalf("ConsumeInt64(%x) = %d, %d", b, v, n)
+		}
+		f, n := ConsumeFloat64(b)
+		if n != len(b) || f != float64(test.v) {
+			t.Fatalf("ConsumeFloat64(%x) = %v, %d", b, f, n)
+		}
+	}
+
+	b := AppendUint(nil, math.MaxUint64)
+	if v, n := ConsumeUint64(b); n != 9 || v != math.MaxUint64 {
+		t.Fatalf("ConsumeUint64 = %d, %d", v, n)
+	}
+	if _, n := ConsumeInt64(b); ParseError(n) != errOverflow {
+		t.Fatalf("ConsumeInt64 of MaxUint64 = %v", ParseError(n))
+	}
+	if _, n := ConsumeUint8(AppendInt(nil, 256)); ParseError(n) != errOverflow {
+		t.Fatalf("ConsumeUint8(256) = %v", ParseError(n))
+	}
+	if _, n := ConsumeUint32(AppendInt(nil, -1)); ParseError(n) != errOverflow {
+		t.Fatalf("ConsumeUint32(-1) = %v", ParseError(n))
+	}
+	if v, n := ConsumeInt8(AppendInt(nil, -128)); n != 2 || v != -128 {
+		t.Fatalf("ConsumeInt8(-128) = %d, %d", v, n)
+	}
+	if _, n := ConsumeInt8(AppendInt(nil, 128)); ParseError(n) != errOverflow {
+		t.Fatalf("ConsumeInt8(128) = %v", ParseError(n))
+	}
+	if _, n := ConsumeInt32([]byte{Uint32, 0}); ParseError(n) != io.ErrUnexpectedEOF {
+		t.Fatalf("truncated = %v", ParseError(n))
+	}
+	if _, n := ConsumeInt32(AppendString(nil, "1")); ParseError(n) != errType {
+		t.Fatalf("string as int = %v", ParseError(n))
+	}
+}
+
+func TestValues(t *testing.T) {
+	var b []byte
+	b = AppendNil(b)
+	b = AppendBool(b, true)
+	b = AppendFloat32(b, 1.5)
+	b = AppendFloat64(b, -2.25)
+	b = AppendString(b, "hello")
+	long := string(bytes.Repeat([]byte("x"), 300))
+	b = AppendString(b, long)
+	b = AppendBytes(b, []byte{1, 2, 3})
+
+	if !IsNil(b) || ConsumeNil(b) != 1 {
+		t.Fatal("nil")
+	}
+	b = b[1:]
+	if v, n := ConsumeBool(b); !v || n != 1 {
+		t.Fatal("bool")
+	}
+	b = b[1:]
+	if v, n := ConsumeFloat32(b); v != 1.5 || n != 5 {
+		t.Fatalf("float32 = %v, %d", v, n)
+	}
+	b = b[5:]
+	if v, n := ConsumeFloat64(b); v != -2.25 || n != 9 {
+		t.Fatalf("float64 = %v, %d", v, n)
+	}
+	b = b[9:]
+	if v, n := ConsumeString(b); v != "hello" || n != 6 {
+		t.Fatalf("string = %q, %d", v, n)
+	}
+	b = b[6:]
+	if b[0] != Str16 {
+		t.Fatalf("long string format = %x", b[0])
+	}
+	if v, n := ConsumeString(b); v != long || n != 303 {
+		t.Fatalf("long string = %d", n)
+	}
+	b = b[303:]
+	if v, n := ConsumeBytes(b); !bytes.Equal(v, []byte{1, 2, 3}) || n != 5 || n != len(b) {
+		t.Fatalf("bytes = %x, %d", v, n)
+	}
+}
+
+func TestConsumeValue(t *testing.T) {
+	var b []byte
+	b = AppendMapHeader(b, 2)
+	b = AppendString(b, "a")
+	b = AppendArrayHeader(b, 20)
+	for i := 0; i < 20; i++ {
+		b = AppendInt(b, int64(i*1000))
+	}
+	b = AppendString(b, "b")
+	b = AppendMapHeader(b, 1)
+	b = AppendString(b, "c")
+	b = append(b, FixExt4, 1, 0, 0, 0, 0)
+	end := len(b)
+	b = AppendBool(b, false)
+
+	if n := ConsumeValue(b); n != end {
+		t.Fatalf("ConsumeValue = %d, expected %d", n, end)
+	}
+	if l, n := ConsumeMapHeader(b); l != 2 || n != 1 {
+		t.Fatalf("ConsumeMapHeader = %d, %d", l, n)
+	}
+	if l, n := ConsumeArrayHeader(b[3:]); l != 20 || n != 3 {
+		t.Fatalf("ConsumeArrayHeader = %d, %d", l, n)
+	}
+	for i := 1; i < end; i++ {
+		if n := ConsumeValue(b[:i]); ParseError(n) != io.ErrUnexpectedEOF {
+			t.Fatalf("ConsumeValue of %d bytes = %d", i, n)
+		}
+	}
+	if n := ConsumeValue([]byte{0xc1}); ParseError(n) != errType {
+		t.Fatalf("ConsumeValue(0xc1) = %d", n)
+	}
+}
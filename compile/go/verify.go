@@ -0,0 +1,186 @@
+package _go
+
+import (
+	"encoding/binary"
+	"fmt"
+	. "github.com/moontrade/proto/schema"
+)
+
+// needsVerify reports whether values of the type carry state that must be checked
+// before they can be trusted.
+func (c *Compiler) needsVerify(t *goType) bool {
+	if t.t.IsVariable() {
+		return true
+	}
+	switch t.t.Kind {
+	case KindEnum, KindStruct, KindList, KindString:
+		return true
+	}
+	return false
+}
+
+// genVerifyValue writes the check for a single value. value is an addressable expression
+// or pointer to the value, addr is a pointer to the value and buf is the expression of
+// the buffer beginning at the value.
+func (c *Compiler) genVerifyValue(b *Builder, t *goType, value, addr, buf, path string, order binary.ByteOrder) {
+	W := b.W
+	switch {
+	case t.t.IsVariable():
+		switch t.t.Kind {
+		case KindList:
+			// The offset of the elements is only needed when they are checked.
+			p := "_"
+			if c.needsVerify(t.list.element) {
+				p = "p"
+			}
+			// VerifyVSlice also checks the elements are aligned so they can be cast.
+			align, _ := variableListLayout(t.t)
+			W("    if %s, n, err := %s.VerifyVSlice(%s, 0, %d); err != nil {", p, t.imp.alias, buf, align)
+			W("        return fmt.Errorf(\"%s: %%w\", err)", path)
+			W("    } else if n%%%d != 0 {", t.t.ItemSize)
+			W("        return fmt.Errorf(\"%s: %%w\", %s.ErrInvalidLength)", path, t.imp.alias)
+			if c.needsVerify(t.list.element) {
+				W("    } else {")
+				W("        b := %s", buf)
+				W("        for i := p; i < p+n; i += %d {", t.t.ItemSize)
+				element := fmt.Sprintf("(*%s)(unsafe.Pointer(&b[i]))", t.list.element.name)
+				c.genVerifyValue(b, t.list.element, element, element, "b[i:]", path+"[]", order)
+				W("        }")
+			}
+			W("    }")
+		default:
+			W("    if _, _, err := %s.VerifyVPointer(%s, 0); err != nil {", t.imp.alias, buf)
+			W("        return fmt.Errorf(\"%s: %%w\", err)", path)
+			W("    }")
+		}
+	case t.t.Kind == KindEnum:
+		if c.swaps(t.t, order) {
+			value = c.decodeLE(t.t, t.name, addr)
+		}
+		W("    if !%s.Valid() {", value)
+		W("        return fmt.Errorf(\"%s: %%w\", %s.ErrInvalidEnum)", path, wapImportAlias)
+		W("    }")
+	case t.t.Kind == KindString:
+		W("    if err := %s.Verify(); err != nil {", value)
+		W("        return fmt.Errorf(\"%s: %%w\", err)", path)
+		W("    }")
+	case t.t.Kind == KindStruct, t.t.Kind == KindList:
+		W("    if err := %s.Verify(%s); err != nil {", value, buf)
+		W("        return fmt.Errorf(\"%s: %%w\", err)", path)
+		W("    }")
+	}
+}
+
+// genVerifyStruct generates VerifyXxx which checks b is safe to reinterpret as the struct
+// and the Verify method which checks the fields.
+func (c *Compiler) genVerifyStruct(b *Builder, t *goType, order binary.ByteOrder) {
+	W := b.W
+	W("// Verify%s checks b holds a well formed %s that is safe to reinterpret.", t.name, t.name)
+	W("func Verify%s(b []byte) error {", t.name)
+	W("    if len(b) < %d {", t.t.Size)
+	W("        return io.ErrShortBuffer")
+	W("    }")
+	W("    if uintptr(unsafe.Pointer(&b[0]))%%unsafe.Alignof(%s{}) != 0 {", t.name)
+	W("        return %s.ErrMisaligned", wapImportAlias)
+	W("    }")
+	W("    return (*%s)(unsafe.Pointer(&b[0])).Verify(b)", t.name)
+	W("}\n")
+
+	W("// Verify checks enum values, string lengths and that every VPointer stays within b.")
+	W("// b is the buffer beginning at s.")
+	W("func (s *%s) Verify(b []byte) error {", t.name)
+	W("    if len(b) < %d {", t.t.Size)
+	W("        return io.ErrShortBuffer")
+	W("    }")
+	for _, field := range t.st.fields {
+		if field.t.t.Kind == KindPad || !c.needsVerify(field.t) {
+			continue
+		}
+		path := fmt.Sprintf("%s.%s", t.name, field.field.Name)
+		value := fmt.Sprintf("s.%s", field.private)
+		buf := fmt.Sprintf("b[%d:]", field.field.Offset)
+		if field.field.Type.Optional {
+			W("    if s.%s[%d]&%d != 0 {", headerFieldName, field.field.OptOffset, field.field.OptMask)
+			c.genVerifyValue(b, field.t, value, "&"+value, buf, path, order)
+			W("    }")
+		} else {
+			c.genVerifyValue(b, field.t, value, "&"+value, buf, path, order)
+		}
+	}
+	W("    return nil")
+	W("}\n")
+
+	W("// Reinterpret%s casts b to a *%s without copying.", t.name, t.name)
+	if c.config.BoundsChecked {
+		W("// b is verified first since bounds checking is enabled.")
+	}
+	W("func Reinterpret%s(b []byte) (*%s, error) {", t.name, t.name)
+	if c.config.BoundsChecked {
+		W("    if err := Verify%s(b); err != nil {", t.name)
+		W("        return nil, err")
+		W("    }")
+	} else {
+		W("    if len(b) < %d {", t.t.Size)
+		W("        return nil, io.ErrShortBuffer")
+		W("    }")
+		W("    if uintptr(unsafe.Pointer(&b[0]))%%unsafe.Alignof(%s{}) != 0 {", t.name)
+		W("        return nil, %s.ErrMisaligned", wapImportAlias)
+		W("    }")
+	}
+	W("    return (*%s)(unsafe.Pointer(&b[0])), nil", t.name)
+	W("}\n")
+}
+
+// genVerifyList generates the Verify method for a fixed length list. length is the
+// expression of the stored length.
+func (c *Compiler) genVerifyList(b *Builder, t *goType, length string, order binary.ByteOrder) {
+	W := b.W
+	W("// Verify checks the length and every element. b is the buffer beginning at s.")
+	W("func (s *%s) Verify(b []byte) error {", t.name)
+	W("    if %s > %d {", length, t.t.Len)
+	W("        return %s.ErrInvalidLength", wapImportAlias)
+	W("    }")
+	if c.needsVerify(t.list.element) {
+		W("    if len(b) < %d {", t.t.Size)
+		W("        return io.ErrShortBuffer")
+		W("    }")
+		W("    for i := 0; i < %s; i++ {", length)
+		c.genVerifyValue(b, t.list.element, "s.b[i]", "&s.b[i]", fmt.Sprintf("b[i*%d:]", t.t.ItemSize), "[]", order)
+		W("    }")
+	}
+	W("    return nil")
+	W("}")
+}
+
+// genVerifyString generates the Verify method for a fixed length string.
+func (c *Compiler) genVerifyString(b *Builder, t *goType, length string, capacity int) {
+	W := b.W
+	W("// Verify checks the length byte does not exceed the capacity.")
+	W("func (s *%s) Verify() error {", t.name)
+	W("    if %s > %d {", length, capacity)
+	W("        return %s.ErrInvalidLength", wapImportAlias)
+	W("    }")
+	W("    return nil")
+	W("}")
+}
+
+// genEnumValid generates the Valid method which reports whether the value is a declared option.
+func (c *Compiler) genEnumValid(b *Builder, t *goType) {
+	W := b.W
+	W("// Valid reports whether v is a declared %s option.", t.name)
+	W("func (v %s) Valid() bool {", t.name)
+	W("    switch v {")
+	seen := make(map[string]struct{})
+	for _, option := range t.enum.options {
+		value := fmt.Sprint(option.option.Value)
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		W("    case %s:", option.name)
+		W("        return true")
+	}
+	W("    }")
+	W("    return false")
+	W("}\n")
+}
@@ -0,0 +1,436 @@
+// Package jsonschema generates JSON Schema (draft 2020-12) describing the JSON rendering
+// of records, for gateways that expose them to clients over HTTP.
+//
+// Every struct, enum and union of a package becomes an entry of the $defs of the
+// package's schema.json. Struct fields are properties under both their long and short
+// name and a value carries exactly one of them. Integers are bounded by their kind,
+// decimals are numbers bounded by their scaled units, fixed strings by their capacity in
+// bytes, fixed bytes by the length of their base64
+// encoding and fixed lists by their length. Enums are their option values and unions an
+// object holding a single member named after the option. The same definitions are
+// written as the components of an OpenAPI document.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/moontrade/proto/compile"
+	. "github.com/moontrade/proto/schema"
+)
+
+func NewCompiler(schema *Schema, config *JSONSchemaConfig) (*Compiler, error) {
+	return &Compiler{
+		schema: schema,
+		config: config,
+	}, nil
+}
+
+// jsPackage is the files of a package and its declarations sorted by name.
+type jsPackage struct {
+	path  string
+	name  string
+	decls []decl
+}
+
+// decl is a struct, enum or union.
+type decl struct {
+	name  string
+	file  *File
+	st    *Struct
+	enum  *Enum
+	union *Union
+}
+
+// refFunc returns the $ref of the declaration name in f.
+type refFunc func(f *File, name string) string
+
+// Generate generates the JSON Schema of every package and the OpenAPI document in
+// memory. Paths are relative to the output directory.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
+	packages := c.packages()
+
+	files := make(compile.Files)
+	schemas := object{}
+	for _, pkg := range packages {
+		dir := pkg.path
+		local := func(f *File, name string) string {
+			path := packagePath(f)
+			if path == dir {
+				return "#/$defs/" + name
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				rel = path
+			}
+			return filepath.ToSlash(filepath.Join(rel, PackageFileName)) + "#/$defs/" + name
+		}
+		defs := object{}
+		for _, d := range pkg.decls {
+			def, err := c.genDecl(d, local)
+			if err != nil {
+				return nil, err
+			}
+			defs.set(d.name, def)
+		}
+		doc := object{}
+		doc.set("$schema", Draft)
+		doc.set("title", pkg.name)
+		doc.set("$defs", defs)
+		data, err := marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(filepath.Join(pkg.path, PackageFileName))] = data
+
+		for _, d := range pkg.decls {
+			def, err := c.genDecl(d, componentRef)
+			if err != nil {
+				return nil, err
+			}
+			schemas.set(componentName(d.file, d.name), def)
+		}
+	}
+
+	title, version := c.config.Title, c.config.Version
+	if len(title) == 0 {
+		title = "moon"
+	}
+	if len(version) == 0 {
+		version = "0.0.0"
+	}
+	info := object{}
+	info.set("title", title)
+	info.set("version", version)
+	components := object{}
+	components.set("schemas", schemas)
+	doc := object{}
+	doc.set("openapi", OpenAPIVersion)
+	doc.set("info", info)
+	doc.set("jsonSchemaDialect", Draft)
+	doc.set("components", components)
+	data, err := marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	files[OpenAPIFileName] = data
+	return files, nil
+}
+
+// Compile generates the documents and writes them to the output directory. Unchanged
+// files are left untouched and generated files that are no longer produced are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
+	}
+	_, err = compile.Write(c.config.Output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the JSON Schema compiler generates.
+func IsGenerated(name string) bool {
+	return name == PackageFileName || name == OpenAPIFileName
+}
+
+// packages returns the packages of the schema sorted by path.
+func (c *Compiler) packages() []*jsPackage {
+	paths := make([]string, 0, len(c.schema.Files))
+	for path := range c.schema.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	byPath := make(map[string]*jsPackage)
+	var packages []*jsPackage
+	for _, path := range paths {
+		f := c.schema.Files[path]
+		pkg := byPath[packagePath(f)]
+		if pkg == nil {
+			pkg = &jsPackage{path: packagePath(f), name: f.Package}
+			byPath[pkg.path] = pkg
+			packages = append(packages, pkg)
+		}
+		for _, st := range f.Structs {
+			pkg.decls = append(pkg.decls, decl{name: st.Name, file: f, st: st})
+		}
+		for _, enum := range f.Enums {
+			pkg.decls = append(pkg.decls, decl{name: enum.Name, file: f, enum: enum})
+		}
+		for _, union := range f.Unions {
+			pkg.decls = append(pkg.decls, decl{name: union.Name, file: f, union: union})
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].path < packages[j].path })
+	for _, pkg := range packages {
+		decls := pkg.decls
+		sort.Slice(decls, func(i, j int) bool { return decls[i].name < decls[j].name })
+	}
+	return packages
+}
+
+// packagePath returns the directory of a file's package relative to the output.
+func packagePath(f *File) string {
+	return filepath.Join(strings.Split(f.Package, ".")...)
+}
+
+// componentName returns the name of a declaration among the OpenAPI components. It is
+// qualified by the package since components share a single namespace.
+func componentName(f *File, name string) string {
+	return f.Package + "." + name
+}
+
+func componentRef(f *File, name string) string {
+	return "#/components/schemas/" + componentName(f, name)
+}
+
+func marshal(doc object) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (c *Compiler) genDecl(d decl, ref refFunc) (object, error) {
+	switch {
+	case d.st != nil:
+		return c.genStruct(d.st, ref)
+	case d.enum != nil:
+		return c.genEnum(d.enum), nil
+	}
+	return c.genUnion(d.union, ref)
+}
+
+func (c *Compiler) genStruct(st *Struct, ref refFunc) (object, error) {
+	def := object{}
+	def.set("type", "object")
+	setDoc(&def, st.Type.Doc())
+	if st.Deprecated {
+		def.set("deprecated", true)
+	}
+	properties := object{}
+	var required []string
+	var alternatives []object
+	for _, field := range st.Fields {
+		if field.Type == nil || field.Type.Kind == KindPad {
+			continue
+		}
+		property, err := c.genType(field.Type, ref)
+		if err != nil {
+			return nil, err
+		}
+		setDoc(&property, field.Type.Doc())
+		if field.Deprecated {
+			property.set("deprecated", true)
+		}
+		properties.set(field.Name, property)
+		if len(field.Short) == 0 {
+			if !field.IsOptional() {
+				required = append(required, field.Name)
+			}
+			continue
+		}
+		properties.set(field.Short, property)
+		// A value holds the field under one of its names. Optional fields may hold
+		// neither.
+		if field.IsOptional() {
+			alternative := object{}
+			alternative.set("not", requires(field.Name, field.Short))
+			alternatives = append(alternatives, alternative)
+		} else {
+			alternative := object{}
+			alternative.set("oneOf", []object{requires(field.Name), requires(field.Short)})
+			alternatives = append(alternatives, alternative)
+		}
+	}
+	def.set("properties", properties)
+	if len(required) > 0 {
+		def.set("required", required)
+	}
+	if len(alternatives) > 0 {
+		def.set("allOf", alternatives)
+	}
+	return def, nil
+}
+
+func requires(names ...string) object {
+	o := object{}
+	o.set("required", names)
+	return o
+}
+
+func (c *Compiler) genEnum(enum *Enum) object {
+	def := object{}
+	def.set("type", "integer")
+	setDoc(&def, enum.Type.Doc())
+	if enum.Deprecated {
+		def.set("deprecated", true)
+	}
+	options := make([]object, 0, len(enum.Options))
+	for _, option := range enum.Options {
+		o := object{}
+		o.set("const", option.Value)
+		o.set("title", option.Name)
+		setDoc(&o, option.Doc())
+		if option.Deprecated {
+			o.set("deprecated", true)
+		}
+		options = append(options, o)
+	}
+	def.set("oneOf", options)
+	return def
+}
+
+func (c *Compiler) genUnion(union *Union, ref refFunc) (object, error) {
+	def := object{}
+	setDoc(&def, union.Type.Doc())
+	options := make([]object, 0, len(union.Options))
+	for _, option := range union.Options {
+		value, err := c.genType(option.Type, ref)
+		if err != nil {
+			return nil, err
+		}
+		properties := object{}
+		properties.set(option.Name, value)
+		o := object{}
+		o.set("type", "object")
+		o.set("title", option.Name)
+		setDoc(&o, option.Type.Doc())
+		o.set("properties", properties)
+		o.set("required", []string{option.Name})
+		o.set("additionalProperties", false)
+		options = append(options, o)
+	}
+	def.set("oneOf", options)
+	return def, nil
+}
+
+// genType returns the schema of a value of type t.
+func (c *Compiler) genType(t *Type, ref refFunc) (object, error) {
+	// Types naming a union are left unresolved by the schema.
+	if t.Kind == KindUnknown {
+		found, err := lookup(t)
+		if err != nil {
+			return nil, err
+		}
+		t = found
+	}
+	s := object{}
+	if b, ok := integers[t.Kind]; ok {
+		s.set("type", "integer")
+		if len(b.format) > 0 {
+			s.set("format", b.format)
+		}
+		s.set("minimum", b.minimum)
+		s.set("maximum", b.maximum)
+		return s, nil
+	}
+	switch t.Kind {
+	case KindBool:
+		s.set("type", "boolean")
+	case KindFloat32:
+		s.set("type", "number")
+		s.set("format", "float")
+		s.set("minimum", -math.MaxFloat32)
+		s.set("maximum", math.MaxFloat32)
+	case KindFloat64:
+		s.set("type", "number")
+		s.set("format", "double")
+	case KindDecimal64, KindDecimal128:
+		// The bounds are written exactly rather than as float64.
+		bits := uint(64)
+		if t.Kind == KindDecimal128 {
+			bits = 128
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+		s.set("type", "number")
+		s.set("minimum", json.Number(Decimal{Units: new(big.Int).Neg(limit), Scale: t.Scale}.String()))
+		s.set("maximum", json.Number(Decimal{Units: limit.Sub(limit, big.NewInt(1)), Scale: t.Scale}.String()))
+	case KindString:
+		s.set("type", "string")
+		if t.Len > 0 {
+			// maxLength counts characters and the capacity is in bytes, so it only
+			// bounds ASCII exactly.
+			s.set("maxLength", stringCapacity(t.Len))
+		}
+	case KindBytes:
+		s.set("type", "string")
+		s.set("contentEncoding", "base64")
+		if t.Len > 0 {
+			s.set("maxLength", (t.Len+2)/3*4)
+		}
+	case KindStruct:
+		s.set("$ref", ref(t.Struct.Type.File, t.Struct.Name))
+	case KindEnum:
+		s.set("$ref", ref(t.Enum.Type.File, t.Enum.Name))
+	case KindUnion:
+		s.set("$ref", ref(t.Union.Type.File, t.Union.Name))
+	case KindList:
+		items, err := c.genType(t.Element, ref)
+		if err != nil {
+			return nil, err
+		}
+		s.set("type", "array")
+		s.set("items", items)
+		if t.Len > 0 {
+			s.set("maxItems", t.Len)
+		}
+	case KindMap:
+		values, err := c.genType(t.Value, ref)
+		if err != nil {
+			return nil, err
+		}
+		s.set("type", "object")
+		s.set("additionalProperties", values)
+	default:
+		return nil, fmt.Errorf("%s:%d %s is not supported by the JSON Schema compiler",
+			t.File.Path, t.Line.Number, t.Name)
+	}
+	return s, nil
+}
+
+// lookup returns the declaration an unresolved type names.
+func lookup(t *Type) (*Type, error) {
+	if t.Import != nil {
+		if t.Import.File != nil {
+			if found := t.Import.File.Types[t.Name]; found != nil {
+				return found, nil
+			}
+		}
+		return nil, fmt.Errorf("%s:%d type not found: %s.%s", t.File.Path, t.Line.Number, t.Import.Alias, t.Name)
+	}
+	if found := t.File.Types[t.Name]; found != nil {
+		return found, nil
+	}
+	return nil, fmt.Errorf("%s:%d type not found: %s", t.File.Path, t.Line.Number, t.Name)
+}
+
+// stringCapacity returns the bytes a fixed string of size holds. Its length is stored
+// in the last byte, or the last two when over 256.
+func stringCapacity(size int) int {
+	if size > 256 {
+		return size - 2
+	}
+	return size - 1
+}
+
+// setDoc sets the description of s to the comments.
+func setDoc(s *object, comments []string) {
+	lines := make([]string, 0, len(comments))
+	for _, line := range comments {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if doc := strings.TrimSpace(strings.Join(lines, "\n")); len(doc) > 0 {
+		s.set("description", doc)
+	}
+}
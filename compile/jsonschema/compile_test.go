@@ -0,0 +1,201 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+func TestNewGenerator(t *testing.T) {
+	s, errs := ParseFiles("", map[string][]byte{
+		"common/schema.moon": []byte(`
+// Price in ticks
+struct Price {
+	value i64
+	scale i8
+}
+`),
+		"model/schema.moon": []byte(`
+import "../common/schema.moon"
+
+enum Side : byte {
+	// Bid
+	Buy = 1
+	Sell = 2 // Ask
+}
+
+union Event {
+	// Quoted price
+	Quote common.Price
+	Cancel i64
+}
+
+struct Order {
+	id|i     u64
+	side     Side
+	price|p  ?common.Price
+	symbol   string8
+	note     string300
+	key      bytes16
+	fills    [4]f32
+	// Free text
+	tags     []i32
+	event    Event
+	qty      decimal64(2)
+}
+`),
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(s, &JSONSchemaConfig{Output: output, Title: "markets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	model := readJSON(t, filepath.Join(output, "model", PackageFileName))
+	if model["$schema"] != Draft || model["title"] != "model" {
+		t.Fatalf("model = %v", model)
+	}
+	defs := model["$defs"].(map[string]interface{})
+	order := defs["Order"].(map[string]interface{})
+	properties := order["properties"].(map[string]interface{})
+	for name, expected := range map[string]string{
+		"i":      `{"maximum":18446744073709551615,"minimum":0,"type":"integer"}`,
+		"side":   `{"$ref":"#/$defs/Side"}`,
+		"p":      `{"$ref":"../common/schema.json#/$defs/Price"}`,
+		"symbol": `{"maxLength":7,"type":"string"}`,
+		"note":   `{"maxLength":298,"type":"string"}`,
+		"key":    `{"contentEncoding":"base64","maxLength":24,"type":"string"}`,
+		"fills":  `{"items":{"format":"float","maximum":3.4028234663852886e+38,"minimum":-3.4028234663852886e+38,"type":"number"},"maxItems":4,"type":"array"}`,
+		"tags":   `{"description":"Free text","items":{"format":"int32","maximum":2147483647,"minimum":-2147483648,"type":"integer"},"type":"array"}`,
+		"event":  `{"$ref":"#/$defs/Event"}`,
+		"qty":    `{"maximum":92233720368547758.07,"minimum":-92233720368547758.08,"type":"number"}`,
+	} {
+		if actual := encode(t, properties[name]); actual != expected {
+			t.Fatalf("%s = %s, expected %s", name, actual, expected)
+		}
+	}
+	if !reflect.DeepEqual(properties["id"], properties["i"]) {
+		t.Fatalf("short name schema %v != %v", properties["i"], properties["id"])
+	}
+	if actual := encode(t, order["required"]); actual != `["side","symbol","note","key","fills","tags","event","qty"]` {
+		t.Fatalf("required = %s", actual)
+	}
+	if actual := encode(t, order["allOf"]); actual != `[{"oneOf":[{"required":["id"]},{"required":["i"]}]},{"not":{"required":["price","p"]}}]` {
+		t.Fatalf("allOf = %s", actual)
+	}
+	if actual := encode(t, defs["Side"]); actual != `{"oneOf":[{"const":1,"description":"Bid","title":"Buy"},{"const":2,"description":"Ask","title":"Sell"}],"type":"integer"}` {
+		t.Fatalf("Side = %s", actual)
+	}
+	event := encode(t, defs["Event"])
+	for _, expected := range []string{
+		`{"additionalProperties":false,"description":"Quoted price","properties":{"Quote":{"$ref":"../common/schema.json#/$defs/Price"}},"required":["Quote"],"title":"Quote","type":"object"}`,
+		`"properties":{"Cancel":{"format":"int64","maximum":9223372036854775807,"minimum":-9223372036854775808,"type":"integer"}}`,
+	} {
+		if !strings.Contains(event, expected) {
+			t.Fatalf("Event = %s, missing %s", event, expected)
+		}
+	}
+
+	common := readJSON(t, filepath.Join(output, "common", PackageFileName))
+	price := common["$defs"].(map[string]interface{})["Price"].(map[string]interface{})
+	if price["description"] != "Price in ticks" {
+		t.Fatalf("Price = %v", price)
+	}
+
+	openapi := readJSON(t, filepath.Join(output, OpenAPIFileName))
+	if openapi["openapi"] != OpenAPIVersion || encode(t, openapi["info"]) != `{"title":"markets","version":"0.0.0"}` {
+		t.Fatalf("openapi = %v", openapi)
+	}
+	schemas := openapi["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"common.Price", "model.Event", "model.Order", "model.Side"} {
+		if _, ok := schemas[name]; !ok {
+			t.Fatalf("components missing %s", name)
+		}
+	}
+	properties = schemas["model.Order"].(map[string]interface{})["properties"].(map[string]interface{})
+	if actual := encode(t, properties["price"]); actual != `{"$ref":"#/components/schemas/common.Price"}` {
+		t.Fatalf("price = %s", actual)
+	}
+
+	// Definitions keep the order of the schema documents.
+	data, err := os.ReadFile(filepath.Join(output, "model", PackageFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Index(string(data), `"$schema"`) > strings.Index(string(data), `"$defs"`) ||
+		strings.Index(string(data), `"Event"`) > strings.Index(string(data), `"Order"`) {
+		t.Fatalf("out of order\n%s", data)
+	}
+}
+
+func TestFailOnDeprecated(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+@deprecated
+struct Old {
+	id i32
+}
+
+struct Note {
+	old Old
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, _ := NewCompiler(s, &JSONSchemaConfig{})
+	files, err := compiler.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(files["model/schema.json"]), `"deprecated": true`) {
+		t.Fatalf("schema.json = %s", files["model/schema.json"])
+	}
+	compiler, _ = NewCompiler(s, &JSONSchemaConfig{FailOnDeprecated: true})
+	if _, err = compiler.Generate(); err == nil || !strings.Contains(err.Error(), "deprecated") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func readJSON(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err = d.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// encode returns v as JSON with sorted keys.
+func encode(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,92 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+// Draft is the JSON Schema dialect of the generated documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// OpenAPIVersion is the OpenAPI version of the components document. OpenAPI 3.1
+// schemas are JSON Schema 2020-12 so both documents share their definitions.
+const OpenAPIVersion = "3.1.0"
+
+// PackageFileName is the JSON Schema document generated for each package.
+const PackageFileName = "schema.json"
+
+// OpenAPIFileName is the OpenAPI document holding the definitions of every package as
+// components. It is written to the root of the output directory.
+const OpenAPIFileName = "openapi.json"
+
+// Configuration for the JSON Schema generator
+type JSONSchemaConfig struct {
+	Output string
+	// Title and Version of the OpenAPI document. They default to "moon" and "0.0.0".
+	Title   string
+	Version string
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
+}
+
+// Generates JSON Schema and OpenAPI documents
+type Compiler struct {
+	schema *Schema
+	config *JSONSchemaConfig
+}
+
+// object is a JSON object which keeps the order its members were added in.
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o *object) set(key string, value interface{}) {
+	*o = append(*o, member{key, value})
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// bounds are the minimum and maximum of a primitive kind.
+type bounds struct {
+	format  string
+	minimum interface{}
+	maximum interface{}
+}
+
+var integers = map[Kind]bounds{
+	KindByte:   {"", uint8(0), uint8(255)},
+	KindInt8:   {"", int8(-128), int8(127)},
+	KindInt16:  {"", int16(-32768), int16(32767)},
+	KindUInt16: {"", uint16(0), uint16(65535)},
+	KindInt32:  {"int32", int32(-2147483648), int32(2147483647)},
+	KindUInt32: {"", uint32(0), uint32(4294967295)},
+	KindInt64:  {"int64", int64(-9223372036854775808), int64(9223372036854775807)},
+	KindUInt64: {"", uint64(0), uint64(18446744073709551615)},
+}
@@ -0,0 +1,116 @@
+// Package compile holds what the language compilers share for emitting generated files.
+package compile
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Files maps a slash separated path relative to the output directory to its content.
+type Files map[string][]byte
+
+// Paths returns the paths in sorted order.
+func (f Files) Paths() []string {
+	paths := make([]string, 0, len(f))
+	for path := range f {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Owns reports whether a file name is generated by a compiler. Generated files that are
+// no longer produced are removed from the output directory.
+type Owns func(name string) bool
+
+// Write writes the files under dir and removes stale files that owns reports as generated.
+// Files with unchanged content are not rewritten so their modification times stay stable.
+// It returns the paths that were written or removed.
+func Write(dir string, files Files, owns Owns) ([]string, error) {
+	existing, err := ReadOwned(dir, owns)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var changed []string
+	for _, path := range files.Paths() {
+		data := files[path]
+		if current, ok := existing[path]; ok && bytes.Equal(current, data) {
+			continue
+		}
+		name := filepath.Join(dir, filepath.FromSlash(path))
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return changed, err
+		}
+		if err = os.WriteFile(name, data, 0644); err != nil {
+			return changed, err
+		}
+		changed = append(changed, path)
+	}
+	for _, path := range Files(existing).Paths() {
+		if _, ok := files[path]; ok {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			return changed, err
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
+
+// Diff compares the files with the generated files under dir. Each difference is reported
+// as "A path" for a file that would be added, "M path" for one that would change and
+// "D path" for one that would be removed.
+func Diff(dir string, files Files, owns Owns) ([]string, error) {
+	existing, err := ReadOwned(dir, owns)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var diffs []string
+	for path, data := range files {
+		current, ok := existing[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, "A "+path)
+		case !bytes.Equal(current, data):
+			diffs = append(diffs, "M "+path)
+		}
+	}
+	for path := range existing {
+		if _, ok := files[path]; !ok {
+			diffs = append(diffs, "D "+path)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i][2:] < diffs[j][2:]
+	})
+	return diffs, nil
+}
+
+// ReadOwned reads every file under dir that owns reports as generated.
+func ReadOwned(dir string, owns Owns) (Files, error) {
+	files := make(Files)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !owns(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
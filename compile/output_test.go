@@ -0,0 +1,74 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	owns := func(name string) bool { return name == "proto.go" }
+	files := Files{
+		"a/proto.go": []byte("package a\n"),
+		"b/proto.go": []byte("package b\n"),
+	}
+	changed, err := Write(dir, files, owns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"a/proto.go", "b/proto.go"}) {
+		t.Fatalf("changed = %v", changed)
+	}
+
+	// Backdate so a rewrite would be visible in the modification time.
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, path := range files.Paths() {
+		if err = os.Chtimes(filepath.Join(dir, path), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "c"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"c/proto.go", "c/user.go"} {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte("package c\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files["b/proto.go"] = []byte("package b // changed\n")
+	diffs, err := Diff(dir, files, owns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diffs, []string{"M b/proto.go", "D c/proto.go"}) {
+		t.Fatalf("diffs = %v", diffs)
+	}
+
+	changed, err = Write(dir, files, owns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"b/proto.go", "c/proto.go"}) {
+		t.Fatalf("changed = %v", changed)
+	}
+	info, err := os.Stat(filepath.Join(dir, "a", "proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Fatalf("unchanged file was rewritten: %v", info.ModTime())
+	}
+	if _, err = os.Stat(filepath.Join(dir, "c", "proto.go")); !os.IsNotExist(err) {
+		t.Fatal("stale generated file was not removed")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "c", "user.go")); err != nil {
+		t.Fatal("file not owned by the compiler was removed")
+	}
+	if diffs, err = Diff(dir, files, owns); err != nil || len(diffs) != 0 {
+		t.Fatalf("diffs after write = %v %v", diffs, err)
+	}
+}
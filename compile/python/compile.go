@@ -0,0 +1,639 @@
+// Package python generates Python for reading stream dumps. Each struct becomes an
+// accessor class over a memoryview and a numpy structured dtype with the offsets of the
+// binary layout, so a block of records loads with numpy.frombuffer without copying.
+package python
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moontrade/proto/compile"
+	. "github.com/moontrade/proto/schema"
+)
+
+// runtimeAlias is the name the generated modules import the runtime as.
+const runtimeAlias = "_moon"
+
+func NewCompiler(schema *Schema, config *PyConfig) (*Compiler, error) {
+	return &Compiler{
+		schema:   schema,
+		config:   config,
+		packages: make(map[string]*pyPackage),
+	}, nil
+}
+
+// Generate generates the Python source of every package in memory. Paths are relative
+// to the output directory.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
+	c.packages = make(map[string]*pyPackage)
+	paths := make([]string, 0, len(c.schema.Files))
+	for path := range c.schema.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f := c.schema.Files[path]
+		pkg := c.packages[packagePath(f)]
+		if pkg == nil {
+			pkg = &pyPackage{
+				path:    packagePath(f),
+				imports: make(map[string]*pyImport),
+				aliases: map[string]struct{}{runtimeAlias: {}, "enum": {}},
+				lists:   make(map[string]*Type),
+			}
+			c.packages[pkg.path] = pkg
+		}
+		pkg.files = append(pkg.files, f)
+	}
+
+	files := compile.Files{
+		RuntimeFileName: []byte(runtime),
+		PackageFileName: []byte("# Code generated by moonc. DO NOT EDIT.\n"),
+	}
+	for _, pkg := range c.packages {
+		b := NewBuilder()
+		if err := c.writePackage(pkg, b); err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(filepath.Join(pkg.path, PackageFileName))] = []byte(b.String())
+	}
+	return files, nil
+}
+
+// Compile generates the Python source and writes it to the output directory. Unchanged
+// files are left untouched and generated files that are no longer produced are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
+	}
+	_, err = compile.Write(c.config.Output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the Python compiler generates.
+func IsGenerated(name string) bool {
+	return name == PackageFileName || name == RuntimeFileName
+}
+
+// packagePath returns the directory of a file's package relative to the output.
+func packagePath(f *File) string {
+	return filepath.Join(strings.Split(f.Package, ".")...)
+}
+
+// relativeImport returns the import statement of a module relative to the package in
+// dir. The output directory is the root package.
+func relativeImport(dir, path, alias string) string {
+	dots := strings.Repeat(".", len(strings.Split(filepath.ToSlash(dir), "/"))+1)
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	name := parts[len(parts)-1]
+	stmt := fmt.Sprintf("from %s%s import %s", dots, strings.Join(parts[:len(parts)-1], "."), name)
+	if alias != name {
+		stmt += " as " + alias
+	}
+	return stmt
+}
+
+func (c *Compiler) writePackage(pkg *pyPackage, b *Builder) error {
+	body := NewBuilder()
+
+	var enums []*Enum
+	var structs []*Struct
+	for _, f := range pkg.files {
+		enums = append(enums, f.Enums...)
+		structs = append(structs, f.Structs...)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+
+	for _, enum := range enums {
+		c.genEnum(enum, body)
+	}
+	for _, f := range pkg.files {
+		if err := c.genConsts(pkg, f, body); err != nil {
+			return err
+		}
+	}
+	for _, st := range structs {
+		if err := c.genStruct(pkg, st, body); err != nil {
+			return err
+		}
+	}
+	// Generating a list can reference another list type.
+	done := make(map[string]bool)
+	for {
+		var names []string
+		for name := range pkg.lists {
+			if !done[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			break
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			done[name] = true
+			if err := c.genList(pkg, name, pkg.lists[name], body); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A dtype nests the dtypes of its fields so they are assigned dependencies first.
+	body.W("")
+	written := make(map[string]bool)
+	for _, st := range structs {
+		if err := c.writeDtype(pkg, st.Type, body, written); err != nil {
+			return err
+		}
+	}
+
+	b.W("# Code generated by moonc. DO NOT EDIT.\n")
+	if len(enums) > 0 {
+		b.W("import enum\n")
+	}
+	b.W("%s", relativeImport(pkg.path, runtimeAlias, runtimeAlias))
+	imports := make([]string, 0, len(pkg.imports))
+	for path := range pkg.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		imp := pkg.imports[path]
+		b.W("%s", relativeImport(pkg.path, imp.path, imp.alias))
+	}
+	b.W("\n")
+	_, _ = b.WriteString(strings.TrimRight(body.String(), "\n"))
+	b.W("")
+	return nil
+}
+
+// importAlias returns the name an imported package is referenced by.
+func (c *Compiler) importAlias(pkg *pyPackage, f *File, alias string) string {
+	path := packagePath(f)
+	if path == pkg.path {
+		return ""
+	}
+	if imp := pkg.imports[path]; imp != nil {
+		return imp.alias
+	}
+	if len(alias) == 0 {
+		alias = filepath.Base(path)
+	}
+	for {
+		if _, ok := pkg.aliases[alias]; !ok && !keywords[alias] {
+			break
+		}
+		alias = alias + "_"
+	}
+	pkg.aliases[alias] = struct{}{}
+	pkg.imports[path] = &pyImport{path: path, alias: alias}
+	return alias
+}
+
+// declName returns the name of a struct or enum, qualified when it is imported.
+func (c *Compiler) declName(pkg *pyPackage, t *Type, name string) string {
+	name = Capitalize(name)
+	if t.Import == nil || t.Import.File == nil {
+		return name
+	}
+	if alias := c.importAlias(pkg, t.Import.File, t.Import.Alias); len(alias) > 0 {
+		return alias + "." + name
+	}
+	return name
+}
+
+// typeName returns the Python type an accessor of t returns.
+func (c *Compiler) typeName(pkg *pyPackage, t *Type) (string, error) {
+	if p, ok := primitives[t.Kind]; ok {
+		return p.pyType, nil
+	}
+	switch t.Kind {
+	case KindEnum:
+		return c.declName(pkg, t, t.Enum.Name), nil
+	case KindStruct:
+		return c.declName(pkg, t, t.Struct.Name), nil
+	case KindString, KindBytes, KindList:
+		if t.Len == 0 {
+			return "", fmt.Errorf("%s:%d variable length %s is not supported by the Python compiler yet",
+				t.File.Path, t.Line.Number, t.Name)
+		}
+		switch t.Kind {
+		case KindString:
+			return "str", nil
+		case KindBytes:
+			return "memoryview", nil
+		}
+		if _, err := c.typeName(pkg, t.Element); err != nil {
+			return "", err
+		}
+		name := Capitalize(t.Name)
+		pkg.lists[name] = t
+		return name, nil
+	}
+	return "", fmt.Errorf("%s:%d type not supported by the Python compiler yet: %s",
+		t.File.Path, t.Line.Number, t.Name)
+}
+
+// valueKind returns the primitive kind a value of t is stored as.
+func valueKind(t *Type) Kind {
+	if t.Kind == KindEnum {
+		return t.Base().Element.Kind
+	}
+	return t.Kind
+}
+
+// format returns the numpy format of t in a dtype.
+func (c *Compiler) format(pkg *pyPackage, t *Type) (string, error) {
+	name, err := c.typeName(pkg, t)
+	if err != nil {
+		return "", err
+	}
+	switch t.Kind {
+	case KindStruct, KindList:
+		return name + ".dtype", nil
+	case KindString:
+		return fmt.Sprintf("%s.string_dtype(%d)", runtimeAlias, t.Size), nil
+	case KindBytes:
+		return fmt.Sprintf("('u1', (%d,))", t.Size), nil
+	}
+	return "'" + primitives[valueKind(t)].dtype + "'", nil
+}
+
+// read returns an expression reading t at offset in mv.
+func (c *Compiler) read(pkg *pyPackage, t *Type, mv, offset string) (string, error) {
+	name, err := c.typeName(pkg, t)
+	if err != nil {
+		return "", err
+	}
+	switch t.Kind {
+	case KindEnum:
+		return fmt.Sprintf("%s.enum(%s, %s)", runtimeAlias, name, unpack(valueKind(t), mv, offset)), nil
+	case KindStruct, KindList:
+		return fmt.Sprintf("%s(%s, %s)", name, mv, offset), nil
+	case KindString:
+		return fmt.Sprintf("%s.read_string(%s, %s, %d)", runtimeAlias, mv, offset, t.Size), nil
+	case KindBytes:
+		return fmt.Sprintf("%s[%s:%s + %d]", mv, offset, offset, t.Size), nil
+	}
+	return unpack(t.Kind, mv, offset), nil
+}
+
+// write returns a statement writing v as t at offset in mv.
+func (c *Compiler) write(pkg *pyPackage, t *Type, mv, offset, v string) (string, error) {
+	if _, err := c.typeName(pkg, t); err != nil {
+		return "", err
+	}
+	switch t.Kind {
+	case KindEnum:
+		return fmt.Sprintf("%s.%s.pack_into(%s, %s, int(%s))", runtimeAlias, primitives[valueKind(t)].packer, mv, offset, v), nil
+	case KindStruct, KindList:
+		return fmt.Sprintf("%s.copy(%s, %s, %s._mv, %d)", runtimeAlias, mv, offset, v, t.Size), nil
+	case KindString:
+		return fmt.Sprintf("%s.write_string(%s, %s, %d, %s)", runtimeAlias, mv, offset, t.Size, v), nil
+	case KindBytes:
+		return fmt.Sprintf("%s.write_bytes(%s, %s, %d, %s)", runtimeAlias, mv, offset, t.Size, v), nil
+	}
+	return fmt.Sprintf("%s.%s.pack_into(%s, %s, %s)", runtimeAlias, primitives[t.Kind].packer, mv, offset, v), nil
+}
+
+func unpack(kind Kind, mv, offset string) string {
+	return fmt.Sprintf("%s.%s.unpack_from(%s, %s)[0]", runtimeAlias, primitives[kind].packer, mv, offset)
+}
+
+// isNumeric reports whether values of t fit a numpy array of numbers.
+func isNumeric(t *Type) bool {
+	_, ok := primitives[valueKind(t)]
+	return ok
+}
+
+func (c *Compiler) writeComments(prefix string, b *Builder, comments []string) {
+	for _, comment := range comments {
+		b.W("%s#%s", prefix, comment)
+	}
+}
+
+// attrName returns the attribute name of a field or enum option.
+func attrName(name string) string {
+	switch name {
+	case "SIZE", "FIELDS", "dtype", "frombuffer":
+		return name + "_"
+	}
+	if keywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (c *Compiler) genEnum(enum *Enum, b *Builder) {
+	c.writeComments("", b, enum.Type.Doc())
+	compile.WriteDeprecated(b, "", "#", enum.Deprecated, enum.DeprecatedMessage)
+	b.W("class %s(enum.IntEnum):", Capitalize(enum.Name))
+	if len(enum.Options) == 0 {
+		b.W("    pass")
+	}
+	for _, option := range enum.Options {
+		c.writeComments("    ", b, option.Doc())
+		compile.WriteDeprecated(b, "    ", "#", option.Deprecated, option.DeprecatedMessage)
+		b.W("    %s = %v", attrName(option.Name), option.Value)
+	}
+	b.W("\n")
+}
+
+// genConsts generates a constant for each const declared in the file.
+func (c *Compiler) genConsts(pkg *pyPackage, f *File, b *Builder) error {
+	for _, cst := range f.Consts {
+		t := cst.Type
+		value := ""
+		switch v := t.Init.(type) {
+		case bool:
+			value = "False"
+			if v {
+				value = "True"
+			}
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case uint64:
+			value = strconv.FormatUint(v, 10)
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+			if !strings.ContainsAny(value, ".en") {
+				value += ".0"
+			}
+		case string:
+			value = strconv.Quote(v)
+		case *EnumOption:
+			value = fmt.Sprintf("%s.%s", c.declName(pkg, t, v.Enum.Name), attrName(v.Name))
+		default:
+			return fmt.Errorf("%s:%d const '%s' has an unsupported value: %v",
+				t.File.Path, t.Line.Number, cst.Name, t.Init)
+		}
+		c.writeComments("", b, t.Doc())
+		b.W("%s = %s", cst.Name, value)
+	}
+	if len(f.Consts) > 0 {
+		b.W("\n")
+	}
+	return nil
+}
+
+func (c *Compiler) genStruct(pkg *pyPackage, st *Struct, b *Builder) error {
+	t := st.Type
+	name := Capitalize(st.Name)
+	W := b.W
+
+	var fields []string
+	for _, field := range st.Fields {
+		if field.Type.Kind != KindPad {
+			fields = append(fields, fmt.Sprintf("'%s'", attrName(field.Name)))
+		}
+	}
+	if len(fields) == 1 {
+		fields[0] += ","
+	}
+
+	c.writeComments("", b, t.Doc())
+	compile.WriteDeprecated(b, "", "#", st.Deprecated, st.DeprecatedMessage)
+	W("class %s:", name)
+	W("    SIZE = %d", t.Size)
+	W("    FIELDS = (%s)", strings.Join(fields, ", "))
+	W("    dtype = None\n")
+	W("    __slots__ = ('_mv',)\n")
+	W("    def __init__(self, buf, offset=0):")
+	W("        self._mv = %s.view(buf, offset, %d)\n", runtimeAlias, t.Size)
+	W("    @classmethod")
+	W("    def frombuffer(cls, buf, offset=0, count=-1):")
+	W("        \"\"\"Returns a numpy structured array viewing count records at offset in buf.\"\"\"")
+	W("        return %s.frombuffer(cls.dtype, buf, offset, count)\n", runtimeAlias)
+	W("    def __repr__(self):")
+	W("        return %s.to_repr(self)", runtimeAlias)
+
+	for _, field := range st.Fields {
+		if field.Type.Kind == KindPad {
+			continue
+		}
+		if err := c.genField(pkg, field, b); err != nil {
+			return err
+		}
+	}
+	W("\n")
+	return nil
+}
+
+func (c *Compiler) genField(pkg *pyPackage, field *StructField, b *Builder) error {
+	W := b.W
+	t := field.Type
+	typeName, err := c.typeName(pkg, t)
+	if err != nil {
+		return err
+	}
+	offset := strconv.Itoa(field.Offset)
+	read, err := c.read(pkg, t, "self._mv", offset)
+	if err != nil {
+		return err
+	}
+	write, err := c.write(pkg, t, "self._mv", offset, "v")
+	if err != nil {
+		return err
+	}
+	name := attrName(field.Name)
+	if t.Optional {
+		typeName += " | None"
+	}
+
+	W("")
+	c.writeComments("    ", b, t.Doc())
+	compile.WriteDeprecated(b, "    ", "#", field.Deprecated, field.DeprecatedMessage)
+	W("    @property")
+	W("    def %s(self) -> '%s':", name, typeName)
+	if t.Optional {
+		W("        if self._mv[%d] & %d == 0:", field.OptOffset, field.OptMask)
+		W("            return None")
+	}
+	W("        return %s", read)
+	if !c.config.Mutable {
+		return nil
+	}
+
+	W("")
+	W("    @%s.setter", name)
+	W("    def %s(self, v: '%s') -> None:", name, typeName)
+	if t.Optional {
+		W("        if v is None:")
+		W("            self._mv[%d] &= %d", field.OptOffset, ^field.OptMask)
+		W("            %s.zero(self._mv, %d, %d)", runtimeAlias, field.Offset, t.Size)
+		W("            return")
+		W("        self._mv[%d] |= %d", field.OptOffset, field.OptMask)
+	}
+	W("        %s", write)
+	return nil
+}
+
+func (c *Compiler) genList(pkg *pyPackage, name string, t *Type, b *Builder) error {
+	element := t.Element
+	elementName, err := c.typeName(pkg, element)
+	if err != nil {
+		return err
+	}
+	offset := fmt.Sprintf("i * %d", t.ItemSize)
+	if t.ItemSize == 1 {
+		offset = "i"
+	}
+	read, err := c.read(pkg, element, "self._mv", offset)
+	if err != nil {
+		return err
+	}
+	write, err := c.write(pkg, element, "self._mv", offset, "v")
+	if err != nil {
+		return err
+	}
+	length := fmt.Sprintf("self._mv[%d]", t.Size-1)
+	setLength := fmt.Sprintf("self._mv[%d] = i + 1", t.Size-1)
+	if t.HeaderSize == 2 {
+		length = unpack(KindUInt16, "self._mv", strconv.Itoa(t.Size-2))
+		setLength = fmt.Sprintf("%s.U16.pack_into(self._mv, %d, i + 1)", runtimeAlias, t.Size-2)
+	}
+
+	W := b.W
+	W("# %s is a fixed list of up to %d elements.", name, t.Len)
+	W("class %s:", name)
+	W("    SIZE = %d", t.Size)
+	W("    CAP = %d", t.Len)
+	W("    dtype = None\n")
+	W("    __slots__ = ('_mv',)\n")
+	W("    def __init__(self, buf, offset=0):")
+	W("        self._mv = %s.view(buf, offset, %d)\n", runtimeAlias, t.Size)
+	W("    def __len__(self) -> int:")
+	W("        return min(%s, %d)\n", length, t.Len)
+	W("    def __getitem__(self, i: int) -> '%s':", elementName)
+	W("        n = len(self)")
+	W("        if i < 0:")
+	W("            i += n")
+	W("        if i < 0 or i >= n:")
+	W("            raise IndexError('%s index out of range')", name)
+	W("        return %s\n", read)
+	W("    def __iter__(self):")
+	W("        for i in range(len(self)):")
+	W("            yield self[i]\n")
+	W("    def __repr__(self):")
+	W("        return '%s(%%r)' %% list(self)", name)
+
+	if isNumeric(element) {
+		W("")
+		W("    def values(self):")
+		W("        \"\"\"Returns the elements as a numpy array sharing memory with the list.\"\"\"")
+		W("        return %s.frombuffer('%s', self._mv, 0, len(self))", runtimeAlias, primitives[valueKind(element)].dtype)
+	}
+
+	if c.config.Mutable {
+		W("")
+		W("    def __setitem__(self, i: int, v: '%s') -> None:", elementName)
+		W("        n = len(self)")
+		W("        if i < 0:")
+		W("            i += n")
+		W("        if i < 0 or i >= n:")
+		W("            raise IndexError('%s index out of range')", name)
+		W("        %s\n", write)
+		W("    def append(self, v: '%s') -> bool:", elementName)
+		W("        i = len(self)")
+		W("        if i == %d:", t.Len)
+		W("            return False")
+		W("        %s", write)
+		W("        %s", setLength)
+		W("        return True\n")
+		W("    def clear(self) -> None:")
+		W("        %s.zero(self._mv, 0, %d)", runtimeAlias, t.Size)
+	}
+	W("\n")
+	return nil
+}
+
+// writeDtype assigns the dtype of a struct or list declared in the package after the
+// dtypes it nests.
+func (c *Compiler) writeDtype(pkg *pyPackage, t *Type, b *Builder, written map[string]bool) error {
+	if t.Kind == KindStruct {
+		t = t.Base()
+	}
+	var names, formats, offsets []string
+	name, err := c.typeName(pkg, t)
+	if err != nil || written[name] {
+		return err
+	}
+	written[name] = true
+	nested := func(ft *Type) error {
+		switch {
+		case ft.Kind == KindList:
+			return c.writeDtype(pkg, ft, b, written)
+		case ft.Kind == KindStruct && ft.Import == nil:
+			return c.writeDtype(pkg, ft, b, written)
+		}
+		return nil
+	}
+
+	switch t.Kind {
+	case KindStruct:
+		if t.HeaderSize > 0 {
+			names = append(names, "'_optionals'")
+			formats = append(formats, fmt.Sprintf("('u1', (%d,))", t.HeaderSize))
+			offsets = append(offsets, "0")
+		}
+		for _, field := range t.Struct.Fields {
+			if field.Type.Kind == KindPad {
+				continue
+			}
+			if err = nested(field.Type); err != nil {
+				return err
+			}
+			format, err := c.format(pkg, field.Type)
+			if err != nil {
+				return err
+			}
+			names = append(names, fmt.Sprintf("'%s'", field.Name))
+			formats = append(formats, format)
+			offsets = append(offsets, strconv.Itoa(field.Offset))
+		}
+
+	case KindList:
+		if err = nested(t.Element); err != nil {
+			return err
+		}
+		format, err := c.format(pkg, t.Element)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(format, "'") {
+			format = strings.Trim(format, "'")
+			format = fmt.Sprintf("('%s', (%d,))", format, t.Len)
+		} else {
+			format = fmt.Sprintf("(%s, (%d,))", format, t.Len)
+		}
+		lengthFormat, lengthOffset := "'u1'", t.Size-1
+		if t.HeaderSize == 2 {
+			lengthFormat, lengthOffset = "'<u2'", t.Size-2
+		}
+		names = []string{"'items'", "'length'"}
+		formats = []string{format, lengthFormat}
+		offsets = []string{"0", strconv.Itoa(lengthOffset)}
+
+	default:
+		return nil
+	}
+
+	W := b.W
+	W("%s.dtype = %s.dtype({", name, runtimeAlias)
+	W("    'names': [%s],", strings.Join(names, ", "))
+	W("    'formats': [%s],", strings.Join(formats, ", "))
+	W("    'offsets': [%s],", strings.Join(offsets, ", "))
+	W("    'itemsize': %d,", t.Size)
+	W("})")
+	return nil
+}
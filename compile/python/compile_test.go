@@ -0,0 +1,189 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+func TestNewGenerator(t *testing.T) {
+	s, errs := ParseFiles("", map[string][]byte{
+		"common/schema.moon": []byte(`
+struct Vec {
+	x f32
+	y f32
+	z f64
+}
+`),
+		"model/schema.moon": []byte(`
+import "../common/schema.moon"
+
+// Default sampling rate
+const RATE i32 = 48000
+const LABEL string8 = "probe"
+const STATE State = Running
+
+enum State : byte {
+	Idle = 0
+	// Taking samples
+	Running = 1
+}
+
+struct Sample {
+	time   i64
+	level  f64
+	state  State
+	pos    ?common.Vec
+	label  string8
+	values [3]f32
+	peaks  [2]Peak
+}
+
+struct Peak {
+	level f64
+	class i16
+	ok    bool
+}
+`),
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(s, &PyConfig{
+		Mutable: true,
+		Output:  output,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{RuntimeFileName, PackageFileName} {
+		if _, err = os.Stat(filepath.Join(output, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "model", PackageFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"import enum\n",
+		"from .. import _moon\nfrom .. import common\n",
+		"# Default sampling rate\nRATE = 48000",
+		`LABEL = "probe"`,
+		"STATE = State.Running",
+		"class State(enum.IntEnum):\n    Idle = 0\n    # Taking samples\n    Running = 1",
+		"class Sample:\n    SIZE = 112",
+		"    def level(self) -> 'float':\n        return _moon.F64.unpack_from(self._mv, 16)[0]",
+		"        return _moon.enum(State, _moon.U8.unpack_from(self._mv, 24)[0])",
+		"    def pos(self) -> 'common.Vec | None':\n        if self._mv[0] & 1 == 0:\n            return None\n        return common.Vec(self._mv, 32)",
+		"        return _moon.read_string(self._mv, 48, 8)",
+		"    @values.setter",
+		"    def class_(self) -> 'int':",
+		"class F323List:",
+		"        return min(self._mv[15], 3)",
+		"        return _moon.frombuffer('<f4', self._mv, 0, len(self))",
+		"    'formats': [(Peak.dtype, (2,)), 'u1'],",
+		"'formats': [('u1', (1,)), '<i8', '<f8', 'u1', common.Vec.dtype, _moon.string_dtype(8), F323List.dtype, Peak2List.dtype],",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
+	// Dependencies are assigned before the dtypes nesting them.
+	if strings.Index(code, "Peak.dtype =") > strings.Index(code, "Peak2List.dtype =") ||
+		strings.Index(code, "Peak2List.dtype =") > strings.Index(code, "Sample.dtype =") {
+		t.Fatalf("dtypes out of order\n%s", code)
+	}
+
+	// The dtype of every struct matches the layout computed by the schema.
+	dtypes := parseDtypes(t, code)
+	for _, st := range s.Files["model/schema.moon"].Structs {
+		dt, ok := dtypes[st.Name]
+		if !ok {
+			t.Fatalf("missing dtype of %s", st.Name)
+		}
+		if dt.itemsize != st.Type.Size {
+			t.Fatalf("%s itemsize %d != %d", st.Name, dt.itemsize, st.Type.Size)
+		}
+		for _, field := range st.Fields {
+			if field.Type.Kind == KindPad {
+				continue
+			}
+			offset, ok := dt.offsets[field.Name]
+			if !ok {
+				t.Fatalf("%s.%s missing from dtype", st.Name, field.Name)
+			}
+			if offset != field.Offset {
+				t.Fatalf("%s.%s offset %d != %d", st.Name, field.Name, offset, field.Offset)
+			}
+		}
+	}
+
+	data, err = os.ReadFile(filepath.Join(output, "common", PackageFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dt := parseDtypes(t, string(data))["Vec"]; dt.itemsize != 16 || dt.offsets["z"] != 8 {
+		t.Fatalf("Vec dtype = %+v", dt)
+	}
+}
+
+type dtype struct {
+	itemsize int
+	offsets  map[string]int
+}
+
+var dtypeRegexp = regexp.MustCompile(`(?s)(\w+)\.dtype = _moon\.dtype\(\{\n    'names': \[(.*?)\],\n.*?'offsets': \[(.*?)\],\n    'itemsize': (\d+),`)
+
+// parseDtypes returns the dtypes assigned in generated code by class name.
+func parseDtypes(t *testing.T, code string) map[string]dtype {
+	dtypes := make(map[string]dtype)
+	for _, m := range dtypeRegexp.FindAllStringSubmatch(code, -1) {
+		names := strings.Split(m[2], ", ")
+		offsets := strings.Split(m[3], ", ")
+		if len(names) != len(offsets) {
+			t.Fatalf("%s has %d names and %d offsets", m[1], len(names), len(offsets))
+		}
+		dt := dtype{offsets: make(map[string]int)}
+		dt.itemsize, _ = strconv.Atoi(m[4])
+		for i, name := range names {
+			dt.offsets[strings.Trim(name, "'")], _ = strconv.Atoi(offsets[i])
+		}
+		dtypes[m[1]] = dt
+	}
+	return dtypes
+}
+
+func TestUnsupported(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Note {
+	text string
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, _ := NewCompiler(s, &PyConfig{})
+	if _, err = compiler.Generate(); err == nil || !strings.Contains(err.Error(), "variable length") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package python
+
+import . "github.com/moontrade/proto/schema"
+
+// PackageFileName is the module generated for each package.
+const PackageFileName = "__init__.py"
+
+// RuntimeFileName is the module of helpers the generated accessors share. It is written
+// to the root of the output directory.
+const RuntimeFileName = "_moon.py"
+
+// Configuration for the Python code generator
+type PyConfig struct {
+	// Generate setters that write through the accessors
+	Mutable bool
+	Output  string
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
+}
+
+// Generates Python code
+type Compiler struct {
+	schema   *Schema
+	config   *PyConfig
+	packages map[string]*pyPackage
+}
+
+type pyPackage struct {
+	files []*File
+	path  string
+
+	// Imports by the path of the imported package
+	imports map[string]*pyImport
+	aliases map[string]struct{}
+	lists   map[string]*Type
+}
+
+type pyImport struct {
+	path  string
+	alias string
+}
+
+// primitive describes how a fixed size primitive is packed.
+type primitive struct {
+	pyType string // Type of the value
+	packer string // struct.Struct in the runtime
+	dtype  string // numpy type string
+}
+
+var primitives = map[Kind]primitive{
+	KindBool:    {"bool", "BOOL", "?"},
+	KindByte:    {"int", "U8", "u1"},
+	KindInt8:    {"int", "I8", "i1"},
+	KindInt16:   {"int", "I16", "<i2"},
+	KindUInt16:  {"int", "U16", "<u2"},
+	KindInt32:   {"int", "I32", "<i4"},
+	KindUInt32:  {"int", "U32", "<u4"},
+	KindInt64:   {"int", "I64", "<i8"},
+	KindUInt64:  {"int", "U64", "<u8"},
+	KindFloat32: {"float", "F32", "<f4"},
+	KindFloat64: {"float", "F64", "<f8"},
+}
+
+// keywords are Python keywords and soft keywords that cannot name an attribute.
+var keywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+	"for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+	"is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
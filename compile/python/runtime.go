@@ -0,0 +1,108 @@
+package python
+
+// runtime is the source of RuntimeFileName. Values are stored little endian and the
+// accessors share memory with the buffer they wrap.
+const runtime = `# Code generated by moonc. DO NOT EDIT.
+"""Helpers shared by the generated accessors. numpy is only needed for dtypes."""
+
+import struct
+
+try:
+    import numpy as np
+except ImportError:
+    np = None
+
+BOOL = struct.Struct('<?')
+U8 = struct.Struct('<B')
+I8 = struct.Struct('<b')
+I16 = struct.Struct('<h')
+U16 = struct.Struct('<H')
+I32 = struct.Struct('<i')
+U32 = struct.Struct('<I')
+I64 = struct.Struct('<q')
+U64 = struct.Struct('<Q')
+F32 = struct.Struct('<f')
+F64 = struct.Struct('<d')
+
+
+def view(buf, offset, size):
+    """Returns a memoryview of size bytes at offset in buf."""
+    mv = memoryview(buf)
+    if mv.format != 'B' or mv.ndim != 1:
+        mv = mv.cast('B')
+    if offset < 0 or offset + size > len(mv):
+        raise ValueError('buffer of %d bytes is too small for %d bytes at %d' % (len(mv), size, offset))
+    return mv[offset:offset + size]
+
+
+def copy(mv, offset, src, size):
+    mv[offset:offset + size] = src[:size]
+
+
+def zero(mv, offset, size):
+    mv[offset:offset + size] = bytes(size)
+
+
+def _string_cap(size):
+    # A fixed string holds its length in the last byte, or the last two when over 256.
+    return size - 2 if size > 256 else size - 1
+
+
+def read_string(mv, offset, size):
+    cap = _string_cap(size)
+    n = U16.unpack_from(mv, offset + cap)[0] if size > 256 else mv[offset + cap]
+    return bytes(mv[offset:offset + min(n, cap)]).decode('utf-8', 'replace')
+
+
+def write_string(mv, offset, size, s):
+    """Writes s truncated to the capacity of the fixed string."""
+    cap = _string_cap(size)
+    data = s.encode('utf-8')[:cap].decode('utf-8', 'ignore').encode('utf-8')
+    mv[offset:offset + cap] = data + bytes(cap - len(data))
+    if size > 256:
+        U16.pack_into(mv, offset + cap, len(data))
+    else:
+        mv[offset + cap] = len(data)
+
+
+def write_bytes(mv, offset, size, b):
+    data = bytes(b[:size])
+    mv[offset:offset + size] = data + bytes(size - len(data))
+
+
+def enum(cls, v):
+    """Returns the option of an enum or the number when it is not an option."""
+    try:
+        return cls(v)
+    except ValueError:
+        return v
+
+
+def dtype(spec):
+    """Returns the numpy dtype of a layout or None when numpy is not installed."""
+    if np is None:
+        return None
+    return np.dtype(spec)
+
+
+def string_dtype(size):
+    cap = _string_cap(size)
+    return dtype({
+        'names': ['data', 'length'],
+        'formats': ['S%d' % cap, '<u2' if size > 256 else 'u1'],
+        'offsets': [0, cap],
+        'itemsize': size,
+    })
+
+
+def frombuffer(dt, buf, offset=0, count=-1):
+    """Returns a numpy array viewing count values of dt at offset in buf."""
+    if np is None:
+        raise ImportError('numpy is required for frombuffer')
+    return np.frombuffer(buf, dtype=dt, count=count, offset=offset)
+
+
+def to_repr(value):
+    fields = ', '.join('%s=%r' % (name, getattr(value, name)) for name in value.FIELDS)
+    return '%s(%s)' % (type(value).__name__, fields)
+`
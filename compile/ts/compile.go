@@ -0,0 +1,622 @@
+// Package ts generates TypeScript for browsers. Each package becomes an ES module of
+// classes viewing the binary layout of structs through a DataView, so reading a field
+// does not copy or decode the record. Enums become const objects and fixed lists of
+// numbers can be viewed as typed arrays.
+package ts
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moontrade/proto/compile"
+	. "github.com/moontrade/proto/schema"
+)
+
+// runtimeAlias is the name the generated modules import the runtime as.
+const runtimeAlias = "moon"
+
+func NewCompiler(schema *Schema, config *TSConfig) (*Compiler, error) {
+	return &Compiler{
+		schema:   schema,
+		config:   config,
+		packages: make(map[string]*tsPackage),
+	}, nil
+}
+
+// Generate generates the TypeScript source of every package in memory. Paths are
+// relative to the output directory.
+func (c *Compiler) Generate() (compile.Files, error) {
+	if c.config.FailOnDeprecated {
+		if err := compile.CheckDeprecated(c.schema); err != nil {
+			return nil, err
+		}
+	}
+	c.packages = make(map[string]*tsPackage)
+	paths := make([]string, 0, len(c.schema.Files))
+	for path := range c.schema.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f := c.schema.Files[path]
+		pkg := c.packages[packagePath(f)]
+		if pkg == nil {
+			pkg = &tsPackage{
+				path:    packagePath(f),
+				imports: make(map[string]*tsImport),
+				aliases: map[string]struct{}{runtimeAlias: {}},
+				lists:   make(map[string]*Type),
+			}
+			c.packages[pkg.path] = pkg
+		}
+		pkg.files = append(pkg.files, f)
+	}
+
+	files := compile.Files{RuntimeFileName: []byte(runtime)}
+	for _, pkg := range c.packages {
+		b := NewBuilder()
+		if err := c.writePackage(pkg, b); err != nil {
+			return nil, err
+		}
+		files[filepath.ToSlash(filepath.Join(pkg.path, TSFileName))] = []byte(b.String())
+	}
+	return files, nil
+}
+
+// Compile generates the TypeScript source and writes it to the output directory.
+// Unchanged files are left untouched and generated files that are no longer produced
+// are removed.
+func (c *Compiler) Compile() error {
+	files, err := c.Generate()
+	if err != nil {
+		return err
+	}
+	_, err = compile.Write(c.config.Output, files, IsGenerated)
+	return err
+}
+
+// IsGenerated reports whether a file name is one the TypeScript compiler generates.
+func IsGenerated(name string) bool {
+	return name == TSFileName || name == RuntimeFileName
+}
+
+// packagePath returns the directory of a file's module relative to the output.
+func packagePath(f *File) string {
+	return filepath.Join(strings.Split(f.Package, ".")...)
+}
+
+// modulePath returns the import path of a module relative to the module in dir.
+func modulePath(dir, path string) (string, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
+
+func (c *Compiler) writePackage(pkg *tsPackage, b *Builder) error {
+	body := NewBuilder()
+
+	var enums []*Enum
+	var structs []*Struct
+	for _, f := range pkg.files {
+		enums = append(enums, f.Enums...)
+		structs = append(structs, f.Structs...)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+
+	for _, enum := range enums {
+		c.genEnum(enum, body)
+	}
+	for _, f := range pkg.files {
+		if err := c.genConsts(pkg, f, body); err != nil {
+			return err
+		}
+	}
+	for _, st := range structs {
+		if err := c.genStruct(pkg, st, body); err != nil {
+			return err
+		}
+		if err := c.genBlock(pkg, st, body); err != nil {
+			return err
+		}
+	}
+	// Generating a list can reference another list type.
+	done := make(map[string]bool)
+	for {
+		var names []string
+		for name := range pkg.lists {
+			if !done[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			break
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			done[name] = true
+			if err := c.genList(pkg, name, pkg.lists[name], body); err != nil {
+				return err
+			}
+		}
+	}
+
+	b.W("// Code generated by moonc. DO NOT EDIT.\n")
+	runtimePath, err := modulePath(pkg.path, RuntimeFileName)
+	if err != nil {
+		return err
+	}
+	b.W("import * as %s from '%s'", runtimeAlias, strings.TrimSuffix(runtimePath, ".ts")+".js")
+	imports := make([]string, 0, len(pkg.imports))
+	for path := range pkg.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		imp := pkg.imports[path]
+		modPath, err := modulePath(pkg.path, filepath.Join(imp.path, TSFileName))
+		if err != nil {
+			return err
+		}
+		b.W("import * as %s from '%s'", imp.alias, strings.TrimSuffix(modPath, ".ts")+".js")
+	}
+	b.W("")
+	_, _ = b.WriteString(strings.TrimRight(body.String(), "\n"))
+	b.W("")
+	return nil
+}
+
+// importAlias returns the name an imported package is referenced by.
+func (c *Compiler) importAlias(pkg *tsPackage, f *File, alias string) string {
+	path := packagePath(f)
+	if path == pkg.path {
+		return ""
+	}
+	if imp := pkg.imports[path]; imp != nil {
+		return imp.alias
+	}
+	if len(alias) == 0 {
+		alias = filepath.Base(path)
+	}
+	for {
+		if _, ok := pkg.aliases[alias]; !ok {
+			break
+		}
+		alias = "_" + alias
+	}
+	pkg.aliases[alias] = struct{}{}
+	pkg.imports[path] = &tsImport{path: path, alias: alias}
+	return alias
+}
+
+// declName returns the name of a struct or enum, qualified when it is imported.
+func (c *Compiler) declName(pkg *tsPackage, t *Type, name string) string {
+	name = Capitalize(name)
+	if t.Import == nil || t.Import.File == nil {
+		return name
+	}
+	if alias := c.importAlias(pkg, t.Import.File, t.Import.Alias); len(alias) > 0 {
+		return alias + "." + name
+	}
+	return name
+}
+
+// typeName returns the TypeScript type a getter of t returns.
+func (c *Compiler) typeName(pkg *tsPackage, t *Type) (string, error) {
+	if p, ok := primitives[t.Kind]; ok {
+		return p.tsType, nil
+	}
+	switch t.Kind {
+	case KindEnum:
+		return c.declName(pkg, t, t.Enum.Name), nil
+	case KindStruct:
+		return c.declName(pkg, t, t.Struct.Name), nil
+	case KindString, KindBytes, KindList:
+		if t.Len == 0 {
+			return "", fmt.Errorf("%s:%d variable length %s is not supported by the TypeScript compiler yet",
+				t.File.Path, t.Line.Number, t.Name)
+		}
+		switch t.Kind {
+		case KindString:
+			return "string", nil
+		case KindBytes:
+			return "Uint8Array", nil
+		}
+		if _, err := c.typeName(pkg, t.Element); err != nil {
+			return "", err
+		}
+		name := Capitalize(t.Name)
+		pkg.lists[name] = t
+		return name, nil
+	}
+	return "", fmt.Errorf("%s:%d type not supported by the TypeScript compiler yet: %s",
+		t.File.Path, t.Line.Number, t.Name)
+}
+
+// valueKind returns the primitive kind a value of t is stored as.
+func valueKind(t *Type) Kind {
+	if t.Kind == KindEnum {
+		return t.Base().Element.Kind
+	}
+	return t.Kind
+}
+
+// read returns an expression reading t at offset in view.
+func (c *Compiler) read(pkg *tsPackage, t *Type, view, offset string) (string, error) {
+	name, err := c.typeName(pkg, t)
+	if err != nil {
+		return "", err
+	}
+	switch t.Kind {
+	case KindBool:
+		return fmt.Sprintf("%s.getUint8(%s) !== 0", view, offset), nil
+	case KindEnum:
+		return fmt.Sprintf("%s as %s", getter(valueKind(t), view, offset), name), nil
+	case KindStruct, KindList:
+		return fmt.Sprintf("new %s(%s.sub(%s, %s, %d))", name, runtimeAlias, view, offset, t.Size), nil
+	case KindString:
+		return fmt.Sprintf("%s.readString(%s, %s, %d)", runtimeAlias, view, offset, t.Size), nil
+	case KindBytes:
+		return fmt.Sprintf("new Uint8Array(%s.buffer, %s.byteOffset + %s, %d)", view, view, offset, t.Size), nil
+	}
+	return getter(t.Kind, view, offset), nil
+}
+
+// write returns a statement writing v as t at offset in view.
+func (c *Compiler) write(pkg *tsPackage, t *Type, view, offset, v string) (string, error) {
+	if _, err := c.typeName(pkg, t); err != nil {
+		return "", err
+	}
+	switch t.Kind {
+	case KindBool:
+		return fmt.Sprintf("%s.setUint8(%s, %s ? 1 : 0)", view, offset, v), nil
+	case KindStruct, KindList:
+		return fmt.Sprintf("%s.copy(%s, %s, %s.view, %d)", runtimeAlias, view, offset, v, t.Size), nil
+	case KindString:
+		return fmt.Sprintf("%s.writeString(%s, %s, %d, %s)", runtimeAlias, view, offset, t.Size, v), nil
+	case KindBytes:
+		return fmt.Sprintf("%s.writeBytes(%s, %s, %d, %s)", runtimeAlias, view, offset, t.Size, v), nil
+	}
+	kind := valueKind(t)
+	if t.Size > 1 {
+		return fmt.Sprintf("%s.set%s(%s, %s, true)", view, primitives[kind].method, offset, v), nil
+	}
+	return fmt.Sprintf("%s.set%s(%s, %s)", view, primitives[kind].method, offset, v), nil
+}
+
+func getter(kind Kind, view, offset string) string {
+	p := primitives[kind]
+	if kind.Size() > 1 {
+		return fmt.Sprintf("%s.get%s(%s, true)", view, p.method, offset)
+	}
+	return fmt.Sprintf("%s.get%s(%s)", view, p.method, offset)
+}
+
+// isNumeric reports whether values of t fit a typed array.
+func isNumeric(t *Type) bool {
+	_, ok := primitives[valueKind(t)]
+	return ok
+}
+
+func (c *Compiler) writeComments(prefix string, b *Builder, comments []string) {
+	for _, comment := range comments {
+		b.W("%s//%s", prefix, comment)
+	}
+}
+
+func (c *Compiler) fieldName(f string) string {
+	f = Uncapitalize(f)
+	switch f {
+	case "view", "constructor":
+		return f + "_"
+	}
+	return f
+}
+
+// literal formats an integer enum or const value, as a bigint for 64-bit kinds.
+func literal(kind Kind, v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	switch kind {
+	case KindInt64, KindUInt64:
+		return s + "n"
+	}
+	return s
+}
+
+func (c *Compiler) genEnum(enum *Enum, b *Builder) {
+	name := Capitalize(enum.Name)
+	kind := valueKind(enum.Type)
+	c.writeComments("", b, enum.Type.Doc())
+	compile.WriteJSDocDeprecated(b, "", enum.Deprecated, enum.DeprecatedMessage)
+	b.W("export const %s = {", name)
+	for _, option := range enum.Options {
+		c.writeComments("    ", b, option.Doc())
+		compile.WriteJSDocDeprecated(b, "    ", option.Deprecated, option.DeprecatedMessage)
+		b.W("    %s: %s,", option.Name, literal(kind, option.Value))
+	}
+	b.W("} as const")
+	compile.WriteJSDocDeprecated(b, "", enum.Deprecated, enum.DeprecatedMessage)
+	b.W("export type %s = typeof %s[keyof typeof %s]\n", name, name, name)
+}
+
+// genConsts generates a constant for each const declared in the file.
+func (c *Compiler) genConsts(pkg *tsPackage, f *File, b *Builder) error {
+	for _, cst := range f.Consts {
+		t := cst.Type
+		value := ""
+		switch v := t.Init.(type) {
+		case bool:
+			value = strconv.FormatBool(v)
+		case int64, uint64:
+			value = literal(t.Kind, v)
+		case float64:
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+		case string:
+			value = strconv.Quote(v)
+		case *EnumOption:
+			value = fmt.Sprintf("%s.%s", c.declName(pkg, t, v.Enum.Name), v.Name)
+		default:
+			return fmt.Errorf("%s:%d const '%s' has an unsupported value: %v",
+				t.File.Path, t.Line.Number, cst.Name, t.Init)
+		}
+		c.writeComments("", b, t.Doc())
+		b.W("export const %s = %s", cst.Name, value)
+	}
+	if len(f.Consts) > 0 {
+		b.W("")
+	}
+	return nil
+}
+
+func (c *Compiler) genStruct(pkg *tsPackage, st *Struct, b *Builder) error {
+	t := st.Type
+	name := Capitalize(st.Name)
+	W := b.W
+	c.writeComments("", b, t.Doc())
+	compile.WriteJSDocDeprecated(b, "", st.Deprecated, st.DeprecatedMessage)
+	W("export class %s {", name)
+	W("    static readonly SIZE = %d\n", t.Size)
+	W("    readonly view: DataView\n")
+	W("    constructor(view: DataView) {")
+	W("        this.view = view")
+	W("    }\n")
+	W("    // wrap returns a view of the %s at offset in buf.", name)
+	W("    static wrap(buf: %s.Buffer, offset = 0): %s {", runtimeAlias, name)
+	W("        return new %s(%s.view(buf, offset, %d))", name, runtimeAlias, t.Size)
+	W("    }\n")
+	W("    static alloc(): %s {", name)
+	W("        return new %s(new DataView(new ArrayBuffer(%d)))", name, t.Size)
+	W("    }")
+
+	for _, field := range st.Fields {
+		if field.Type.Kind == KindPad {
+			continue
+		}
+		if err := c.genField(pkg, field, b); err != nil {
+			return err
+		}
+	}
+	W("}\n")
+	return nil
+}
+
+func (c *Compiler) genField(pkg *tsPackage, field *StructField, b *Builder) error {
+	W := b.W
+	t := field.Type
+	typeName, err := c.typeName(pkg, t)
+	if err != nil {
+		return err
+	}
+	offset := strconv.Itoa(field.Offset)
+	read, err := c.read(pkg, t, "this.view", offset)
+	if err != nil {
+		return err
+	}
+	write, err := c.write(pkg, t, "this.view", offset, "v")
+	if err != nil {
+		return err
+	}
+	name := c.fieldName(field.Name)
+
+	W("")
+	c.writeComments("    ", b, t.Doc())
+	compile.WriteJSDocDeprecated(b, "    ", field.Deprecated, field.DeprecatedMessage)
+	if !t.Optional {
+		W("    get %s(): %s {", name, typeName)
+		W("        return %s", read)
+		W("    }")
+		if c.config.Mutable {
+			W("    set %s(v: %s) {", name, typeName)
+			W("        %s", write)
+			W("    }")
+		}
+		return nil
+	}
+
+	W("    get %s(): %s | null {", name, typeName)
+	W("        if ((this.view.getUint8(%d) & %d) === 0) {", field.OptOffset, field.OptMask)
+	W("            return null")
+	W("        }")
+	W("        return %s", read)
+	W("    }")
+	if c.config.Mutable {
+		W("    set %s(v: %s | null) {", name, typeName)
+		W("        if (v === null) {")
+		W("            this.view.setUint8(%d, this.view.getUint8(%d) & %d)", field.OptOffset, field.OptOffset, ^field.OptMask)
+		W("            %s.zero(this.view, %d, %d)", runtimeAlias, field.Offset, t.Size)
+		W("            return")
+		W("        }")
+		W("        this.view.setUint8(%d, this.view.getUint8(%d) | %d)", field.OptOffset, field.OptOffset, field.OptMask)
+		W("        %s", write)
+		W("    }")
+	}
+	return nil
+}
+
+// genBlock generates a view of a series of records stored back to back, with a method
+// per numeric field returning the column as a typed array. Typed arrays have no stride
+// so the column is a view over the block only when the record is that one field.
+func (c *Compiler) genBlock(pkg *tsPackage, st *Struct, b *Builder) error {
+	t := st.Type
+	name := Capitalize(st.Name)
+	block := name + "Block"
+	W := b.W
+	W("// %s is a series of %s records stored back to back.", block, name)
+	W("export class %s {", block)
+	W("    readonly view: DataView")
+	W("    readonly length: number\n")
+	W("    constructor(view: DataView, length = Math.floor(view.byteLength / %d)) {", t.Size)
+	W("        this.view = view")
+	W("        this.length = length")
+	W("    }\n")
+	W("    // wrap returns a view of length records at offset in buf.")
+	W("    static wrap(buf: %s.Buffer, offset: number, length: number): %s {", runtimeAlias, block)
+	W("        return new %s(%s.view(buf, offset, length * %d), length)", block, runtimeAlias, t.Size)
+	W("    }\n")
+	W("    at(i: number): %s {", name)
+	W("        if (i < 0 || i >= this.length) {")
+	W("            throw new RangeError(`index ${i} out of range [0, ${this.length})`)")
+	W("        }")
+	W("        return new %s(%s.sub(this.view, i * %d, %d))", name, runtimeAlias, t.Size, t.Size)
+	W("    }\n")
+	W("    *[Symbol.iterator](): IterableIterator<%s> {", name)
+	W("        for (let i = 0; i < this.length; i++) {")
+	W("            yield this.at(i)")
+	W("        }")
+	W("    }")
+
+	for _, field := range st.Fields {
+		ft := field.Type
+		if ft.Kind == KindPad || ft.Optional || !isNumeric(ft) {
+			continue
+		}
+		array := primitives[valueKind(ft)].array
+		W("")
+		if t.Size == ft.Size {
+			// The records hold nothing but the field so the column is contiguous.
+			W("    // %sColumn returns the %s of every record. Unless out is given it shares", c.fieldName(field.Name), field.Name)
+			W("    // memory with the block when the block is aligned for the array.")
+			W("    %sColumn(out?: %s): %s {", c.fieldName(field.Name), array, array)
+			W("        if (out === undefined) {")
+			W("            if (%s.aligned(this.view, 0, %d)) {", runtimeAlias, ft.Size)
+			W("                return new %s(this.view.buffer, this.view.byteOffset, this.length)", array)
+			W("            }")
+			W("            out = new %s(this.length)", array)
+			W("        }")
+		} else {
+			W("    // %sColumn copies the %s of every record into out. The records hold other", c.fieldName(field.Name), field.Name)
+			W("    // fields too so typed arrays cannot view the column in place.")
+			W("    %sColumn(out = new %s(this.length)): %s {", c.fieldName(field.Name), array, array)
+		}
+		W("        for (let i = 0; i < this.length; i++) {")
+		W("            out[i] = %s", getter(valueKind(ft), "this.view", fmt.Sprintf("i * %d + %d", t.Size, field.Offset)))
+		W("        }")
+		W("        return out")
+		W("    }")
+	}
+	W("}\n")
+	return nil
+}
+
+func (c *Compiler) genList(pkg *tsPackage, name string, t *Type, b *Builder) error {
+	element := t.Element
+	elementName, err := c.typeName(pkg, element)
+	if err != nil {
+		return err
+	}
+	itemSize := t.ItemSize
+	offset := fmt.Sprintf("i * %d", itemSize)
+	if itemSize == 1 {
+		offset = "i"
+	}
+	read, err := c.read(pkg, element, "this.view", offset)
+	if err != nil {
+		return err
+	}
+	write, err := c.write(pkg, element, "this.view", offset, "v")
+	if err != nil {
+		return err
+	}
+	length := fmt.Sprintf("this.view.getUint8(%d)", t.Size-1)
+	setLength := fmt.Sprintf("this.view.setUint8(%d, n)", t.Size-1)
+	if t.HeaderSize == 2 {
+		length = fmt.Sprintf("this.view.getUint16(%d, true)", t.Size-2)
+		setLength = fmt.Sprintf("this.view.setUint16(%d, n, true)", t.Size-2)
+	}
+
+	W := b.W
+	W("// %s is a fixed list of up to %d elements.", name, t.Len)
+	W("export class %s {", name)
+	W("    static readonly SIZE = %d", t.Size)
+	W("    static readonly CAP = %d\n", t.Len)
+	W("    readonly view: DataView\n")
+	W("    constructor(view: DataView) {")
+	W("        this.view = view")
+	W("    }\n")
+	W("    get length(): number {")
+	W("        return Math.min(%s, %d)", length, t.Len)
+	W("    }\n")
+	W("    at(i: number): %s {", elementName)
+	W("        if (i < 0 || i >= this.length) {")
+	W("            throw new RangeError(`index ${i} out of range [0, ${this.length})`)")
+	W("        }")
+	W("        return %s", read)
+	W("    }\n")
+	W("    *[Symbol.iterator](): IterableIterator<%s> {", elementName)
+	W("        for (let i = 0; i < this.length; i++) {")
+	W("            yield this.at(i)")
+	W("        }")
+	W("    }")
+
+	if isNumeric(element) {
+		array := primitives[valueKind(element)].array
+		W("")
+		W("    // values returns the elements as a typed array. It shares memory with the list")
+		W("    // unless the list is not aligned for the array.")
+		W("    values(): %s {", array)
+		W("        const n = this.length")
+		W("        if (%s.aligned(this.view, 0, %d)) {", runtimeAlias, itemSize)
+		W("            return new %s(this.view.buffer, this.view.byteOffset, n)", array)
+		W("        }")
+		W("        const out = new %s(n)", array)
+		W("        for (let i = 0; i < n; i++) {")
+		W("            out[i] = %s", getter(valueKind(element), "this.view", offset))
+		W("        }")
+		W("        return out")
+		W("    }")
+	}
+
+	if c.config.Mutable {
+		W("")
+		W("    set(i: number, v: %s): void {", elementName)
+		W("        if (i < 0 || i >= this.length) {")
+		W("            throw new RangeError(`index ${i} out of range [0, ${this.length})`)")
+		W("        }")
+		W("        %s", write)
+		W("    }\n")
+		W("    push(v: %s): boolean {", elementName)
+		W("        const i = this.length")
+		W("        if (i === %d) {", t.Len)
+		W("            return false")
+		W("        }")
+		W("        %s", write)
+		W("        const n = i + 1")
+		W("        %s", setLength)
+		W("        return true")
+		W("    }\n")
+		W("    clear(): void {")
+		W("        %s.zero(this.view, 0, %d)", runtimeAlias, t.Size)
+		W("    }")
+	}
+	W("}\n")
+	return nil
+}
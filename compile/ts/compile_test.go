@@ -0,0 +1,129 @@
+package ts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/moontrade/proto/schema"
+)
+
+func TestNewGenerator(t *testing.T) {
+	s, errs := ParseFiles("", map[string][]byte{
+		"common/schema.moon": []byte(`
+struct Price {
+	value f64
+	scale i32
+}
+`),
+		"model/schema.moon": []byte(`
+import "../common/schema.moon"
+
+// Highest value
+const HIGH i64 = 1000
+const NAME string8 = "HELLO"
+const CODE Code = Close
+
+enum Code : byte {
+	Open = 0
+	// Closed for the day
+	Close = 1
+}
+
+struct Candle {
+	open  f64
+	close f64
+	code  Code
+	last  ?common.Price
+	name  string8
+	ticks [4]i32
+}
+
+struct Tick {
+	price f64
+}
+`),
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	compiler, err := NewCompiler(s, &TSConfig{
+		Mutable: true,
+		Output:  output,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(output, RuntimeFileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "model", TSFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, expected := range []string{
+		"import * as moon from '../_moon.js'",
+		"import * as common from '../common/index.js'",
+		"// Highest value\nexport const HIGH = 1000n",
+		`export const NAME = "HELLO"`,
+		"export const CODE = Code.Close",
+		"export const Code = {\n    Open: 0,\n    // Closed for the day\n    Close: 1,\n} as const",
+		"export type Code = typeof Code[keyof typeof Code]",
+		"export class Candle {",
+		"    get open(): number {\n        return this.view.getFloat64(8, true)\n    }",
+		"        return this.view.getUint8(24) as Code",
+		"    get last(): common.Price | null {\n        if ((this.view.getUint8(0) & 1) === 0) {",
+		"        return new common.Price(moon.sub(this.view, 32, 16))",
+		"        return moon.readString(this.view, 48, 8)",
+		"    set ticks(v: I324List) {",
+		"export class CandleBlock {",
+		"    closeColumn(out = new Float64Array(this.length)): Float64Array {",
+		"            out[i] = this.view.getFloat64(i * 80 + 16, true)",
+		"    priceColumn(out?: Float64Array): Float64Array {",
+		"            if (moon.aligned(this.view, 0, 8)) {\n                return new Float64Array(this.view.buffer, this.view.byteOffset, this.length)",
+		"export class I324List {",
+		"    values(): Int32Array {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("generated code missing: %s\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "lastColumn") {
+		t.Fatalf("optional fields should not have columns\n%s", code)
+	}
+	candles := code[strings.Index(code, "export class CandleBlock {"):]
+	candles = candles[:strings.Index(candles, "\n}\n")]
+	if strings.Contains(candles, "this.view.buffer") {
+		t.Fatalf("strided columns should be copied\n%s", candles)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Note {
+	text string
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Schema{Files: map[string]*File{"model/schema.moon": f}}
+	if err = s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	compiler, _ := NewCompiler(s, &TSConfig{})
+	if _, err = compiler.Generate(); err == nil || !strings.Contains(err.Error(), "variable length") {
+		t.Fatalf("err = %v", err)
+	}
+}
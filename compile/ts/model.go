@@ -0,0 +1,62 @@
+package ts
+
+import . "github.com/moontrade/proto/schema"
+
+const TSFileName = "index.ts"
+
+// RuntimeFileName is the module of helpers the generated views share. It is written to
+// the root of the output directory.
+const RuntimeFileName = "_moon.ts"
+
+// Configuration for the TypeScript code generator
+type TSConfig struct {
+	// Generate setters that write through the views
+	Mutable bool
+	Output  string
+	// FailOnDeprecated fails compilation on uses of deprecated definitions, see
+	// compile.CheckDeprecated.
+	FailOnDeprecated bool
+}
+
+// Generates TypeScript code
+type Compiler struct {
+	schema   *Schema
+	config   *TSConfig
+	packages map[string]*tsPackage
+}
+
+type tsPackage struct {
+	files []*File
+	path  string
+
+	// Imports by the path of the imported package
+	imports map[string]*tsImport
+	aliases map[string]struct{}
+	lists   map[string]*Type
+}
+
+type tsImport struct {
+	path  string
+	alias string
+}
+
+// primitive describes how a fixed size primitive is read from a DataView.
+type primitive struct {
+	tsType string // Type of the value
+	method string // DataView method suffix, e.g. "Float64" for getFloat64
+	array  string // Typed array holding a column of values
+}
+
+var primitives = map[Kind]primitive{
+	KindBool:    {"boolean", "Uint8", "Uint8Array"},
+	KindByte:    {"number", "Uint8", "Uint8Array"},
+	KindInt8:    {"number", "Int8", "Int8Array"},
+	KindInt16:   {"number", "Int16", "Int16Array"},
+	KindUInt16:  {"number", "Uint16", "Uint16Array"},
+	KindInt32:   {"number", "Int32", "Int32Array"},
+	KindUInt32:  {"number", "Uint32", "Uint32Array"},
+	KindInt64:   {"bigint", "BigInt64", "BigInt64Array"},
+	KindUInt64:  {"bigint", "BigUint64", "BigUint64Array"},
+	KindFloat32: {"number", "Float32", "Float32Array"},
+	KindFloat64: {"number", "Float64", "Float64Array"},
+}
@@ -0,0 +1,79 @@
+package ts
+
+// runtime is the source of RuntimeFileName. Values are stored little endian and views
+// share memory with the buffer they wrap.
+const runtime = `// Code generated by moonc. DO NOT EDIT.
+
+export type Buffer = ArrayBuffer | ArrayBufferView
+
+// littleEndian is true when typed arrays can view the little endian values in place.
+export const littleEndian = new Uint8Array(new Uint16Array([1]).buffer)[0] === 1
+
+const decoder = new TextDecoder()
+const encoder = new TextEncoder()
+
+// view returns a DataView of size bytes at offset in buf.
+export function view(buf: Buffer, offset: number, size: number): DataView {
+    if (ArrayBuffer.isView(buf)) {
+        return new DataView(buf.buffer, buf.byteOffset + offset, size)
+    }
+    return new DataView(buf, offset, size)
+}
+
+// sub returns a DataView of size bytes at offset in v.
+export function sub(v: DataView, offset: number, size: number): DataView {
+    return new DataView(v.buffer, v.byteOffset + offset, size)
+}
+
+// aligned reports whether a typed array of elements of the given size can view the
+// bytes at offset in v without copying.
+export function aligned(v: DataView, offset: number, size: number): boolean {
+    return littleEndian && (v.byteOffset + offset) % size === 0
+}
+
+// copy copies size bytes from src to offset in dst.
+export function copy(dst: DataView, offset: number, src: DataView, size: number): void {
+    new Uint8Array(dst.buffer, dst.byteOffset + offset, size).set(
+        new Uint8Array(src.buffer, src.byteOffset, Math.min(size, src.byteLength)))
+}
+
+// zero clears size bytes at offset in v.
+export function zero(v: DataView, offset: number, size: number): void {
+    new Uint8Array(v.buffer, v.byteOffset + offset, size).fill(0)
+}
+
+// A fixed string of size bytes holds its length in the last byte, or in the last two
+// bytes when size is over 256.
+function stringCap(size: number): number {
+    return size > 256 ? size - 2 : size - 1
+}
+
+export function readString(v: DataView, offset: number, size: number): string {
+    const cap = stringCap(size)
+    let n = size > 256 ? v.getUint16(offset + cap, true) : v.getUint8(offset + cap)
+    if (n > cap) {
+        n = cap
+    }
+    return decoder.decode(new Uint8Array(v.buffer, v.byteOffset + offset, n))
+}
+
+// writeString writes s truncated to the capacity of the fixed string.
+export function writeString(v: DataView, offset: number, size: number, s: string): void {
+    const cap = stringCap(size)
+    const dst = new Uint8Array(v.buffer, v.byteOffset + offset, cap)
+    dst.fill(0)
+    const n = encoder.encodeInto(s, dst).written ?? 0
+    if (size > 256) {
+        v.setUint16(offset + cap, n, true)
+    } else {
+        v.setUint8(offset + cap, n)
+    }
+}
+
+// writeBytes writes b truncated to size bytes and clears the rest.
+export function writeBytes(v: DataView, offset: number, size: number, b: Uint8Array): void {
+    const dst = new Uint8Array(v.buffer, v.byteOffset + offset, size)
+    dst.fill(0)
+    dst.set(b.subarray(0, size))
+}
+`
@@ -0,0 +1,893 @@
+package wap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	MaxDecimal64Scale  = 18
+	MaxDecimal128Scale = 38
+)
+
+var (
+	ErrDecimalOverflow  = errors.New("decimal overflow")
+	ErrDecimalPrecision = errors.New("decimal has more digits than its scale")
+	ErrDecimalDivision  = errors.New("decimal division by zero")
+	ErrDecimalSyntax    = errors.New("invalid decimal")
+	ErrDecimalScale     = errors.New("decimal scale out of range")
+)
+
+var pow10 = func() (p [20]uint64) {
+	p[0] = 1
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] * 10
+	}
+	return
+}()
+
+// Decimal64 is the decimal Units/10^Scale. Schema decimal64(scale) fields store Units as
+// an int64.
+//
+// Arithmetic is exact or rounds half to even and fails with ErrDecimalOverflow instead
+// of wrapping. Results have the larger scale of the operands.
+type Decimal64 struct {
+	Units int64
+	Scale int
+}
+
+// ParseDecimal64 parses s as a decimal of scale. s is a JSON number and may have an
+// exponent. It fails with ErrDecimalPrecision when s has more decimals than scale
+// rather than rounding.
+func ParseDecimal64(s string, scale int) (Decimal64, error) {
+	if scale < 0 || scale > MaxDecimal64Scale {
+		return Decimal64{}, ErrDecimalScale
+	}
+	neg, m, err := parseDecimal(s, scale)
+	if err != nil {
+		return Decimal64{}, err
+	}
+	if m.hi != 0 {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return decimal64Of(neg, m.lo, scale)
+}
+
+func decimal64Of(neg bool, m uint64, scale int) (Decimal64, error) {
+	if neg {
+		if m > 1<<63 {
+			return Decimal64{}, ErrDecimalOverflow
+		}
+		return Decimal64{Units: -int64(m), Scale: scale}, nil
+	}
+	if m > math.MaxInt64 {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return Decimal64{Units: int64(m), Scale: scale}, nil
+}
+
+func (d Decimal64) magnitude() (bool, uint64) {
+	if d.Units < 0 {
+		return true, uint64(-d.Units)
+	}
+	return false, uint64(d.Units)
+}
+
+func (d Decimal64) check(scale int) error {
+	if d.Scale < 0 || d.Scale > MaxDecimal64Scale || scale < 0 || scale > MaxDecimal64Scale {
+		return ErrDecimalScale
+	}
+	return nil
+}
+
+// Rescale returns d with scale decimals. It fails with ErrDecimalPrecision when that
+// drops non-zero digits.
+func (d Decimal64) Rescale(scale int) (Decimal64, error) {
+	if err := d.check(scale); err != nil {
+		return Decimal64{}, err
+	}
+	neg, m := d.magnitude()
+	m, err := rescale64(m, d.Scale, scale, false)
+	if err != nil {
+		return Decimal64{}, err
+	}
+	return decimal64Of(neg, m, scale)
+}
+
+// Round returns d with scale decimals, rounding half to even.
+func (d Decimal64) Round(scale int) (Decimal64, error) {
+	if err := d.check(scale); err != nil {
+		return Decimal64{}, err
+	}
+	neg, m := d.magnitude()
+	m, err := rescale64(m, d.Scale, scale, true)
+	if err != nil {
+		return Decimal64{}, err
+	}
+	return decimal64Of(neg, m, scale)
+}
+
+// rescale64 multiplies or divides m by a power of 10 to move it from one scale to
+// another.
+func rescale64(m uint64, from, to int, round bool) (uint64, error) {
+	if to >= from {
+		hi, lo := bits.Mul64(m, pow10[to-from])
+		if hi != 0 {
+			return 0, ErrDecimalOverflow
+		}
+		return lo, nil
+	}
+	p := pow10[from-to]
+	q, r := m/p, m%p
+	if r != 0 {
+		if !round {
+			return 0, ErrDecimalPrecision
+		}
+		if r > p-r || (r == p-r && q&1 == 1) {
+			q++
+		}
+	}
+	return q, nil
+}
+
+// align returns the units of d and v at the larger of their scales.
+func (d Decimal64) align(v Decimal64) (int64, int64, int, error) {
+	scale := d.Scale
+	if v.Scale > scale {
+		scale = v.Scale
+	}
+	a, err := d.Rescale(scale)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	b, err := v.Rescale(scale)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return a.Units, b.Units, scale, nil
+}
+
+func (d Decimal64) Add(v Decimal64) (Decimal64, error) {
+	a, b, scale, err := d.align(v)
+	if err != nil {
+		return Decimal64{}, err
+	}
+	r := a + b
+	if (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0) {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return Decimal64{Units: r, Scale: scale}, nil
+}
+
+func (d Decimal64) Sub(v Decimal64) (Decimal64, error) {
+	a, b, scale, err := d.align(v)
+	if err != nil {
+		return Decimal64{}, err
+	}
+	r := a - b
+	if (a >= 0 && b < 0 && r < 0) || (a < 0 && b > 0 && r >= 0) {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return Decimal64{Units: r, Scale: scale}, nil
+}
+
+// Mul returns d*v rounded half to even.
+func (d Decimal64) Mul(v Decimal64) (Decimal64, error) {
+	if err := d.check(v.Scale); err != nil {
+		return Decimal64{}, err
+	}
+	scale, drop := d.Scale, v.Scale
+	if drop > scale {
+		scale, drop = drop, scale
+	}
+	negA, a := d.magnitude()
+	negB, b := v.magnitude()
+	m, ok := mulDiv(a, b, pow10[drop])
+	if !ok {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return decimal64Of(negA != negB, m, scale)
+}
+
+// Div returns d/v rounded half to even.
+func (d Decimal64) Div(v Decimal64) (Decimal64, error) {
+	if err := d.check(v.Scale); err != nil {
+		return Decimal64{}, err
+	}
+	if v.Units == 0 {
+		return Decimal64{}, ErrDecimalDivision
+	}
+	scale := d.Scale
+	if v.Scale > scale {
+		scale = v.Scale
+	}
+	negA, a := d.magnitude()
+	negB, b := v.magnitude()
+	// d/v at scale is a*10^(scale-d.Scale+v.Scale)/b.
+	shift := scale - d.Scale + v.Scale
+	var m uint64
+	if shift < len(pow10) {
+		var ok bool
+		if m, ok = mulDiv(a, pow10[shift], b); !ok {
+			return Decimal64{}, ErrDecimalOverflow
+		}
+	} else {
+		n := new(big.Int).Mul(new(big.Int).SetUint64(a), bigPow10(shift))
+		q := quoRound(n, new(big.Int).SetUint64(b))
+		if !q.IsUint64() {
+			return Decimal64{}, ErrDecimalOverflow
+		}
+		m = q.Uint64()
+	}
+	return decimal64Of(negA != negB, m, scale)
+}
+
+// mulDiv returns a*b/c rounded half to even. It reports false when the result does not
+// fit 64 bits.
+func mulDiv(a, b, c uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return 0, false
+	}
+	q, r := bits.Div64(hi, lo, c)
+	if r > c-r || (r == c-r && q&1 == 1) {
+		if q == math.MaxUint64 {
+			return 0, false
+		}
+		q++
+	}
+	return q, true
+}
+
+func (d Decimal64) Neg() (Decimal64, error) {
+	if d.Units == math.MinInt64 {
+		return Decimal64{}, ErrDecimalOverflow
+	}
+	return Decimal64{Units: -d.Units, Scale: d.Scale}, nil
+}
+
+// Cmp compares the values of d and v whatever their scales.
+func (d Decimal64) Cmp(v Decimal64) int {
+	if d.Scale == v.Scale {
+		switch {
+		case d.Units < v.Units:
+			return -1
+		case d.Units > v.Units:
+			return 1
+		}
+		return 0
+	}
+	if d.Sign() != v.Sign() {
+		if d.Sign() < v.Sign() {
+			return -1
+		}
+		return 1
+	}
+	// The magnitudes at the larger scale fit 128 bits.
+	scale := d.Scale
+	if v.Scale > scale {
+		scale = v.Scale
+	}
+	neg, a := d.magnitude()
+	_, b := v.magnitude()
+	c := scaleUp128(a, scale-d.Scale).cmp(scaleUp128(b, scale-v.Scale))
+	if neg {
+		return -c
+	}
+	return c
+}
+
+func scaleUp128(m uint64, digits int) uint128 {
+	if digits >= len(pow10) {
+		digits = len(pow10) - 1
+	}
+	hi, lo := bits.Mul64(m, pow10[digits])
+	return uint128{hi, lo}
+}
+
+func (d Decimal64) Sign() int {
+	switch {
+	case d.Units < 0:
+		return -1
+	case d.Units > 0:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal64) IsZero() bool {
+	return d.Units == 0
+}
+
+// Float64 returns the nearest float64. It is for display and statistics, not for
+// arithmetic.
+func (d Decimal64) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(big.NewInt(d.Units), bigPow10(d.Scale)).Float64()
+	return f
+}
+
+// String returns d with exactly Scale decimals and no exponent.
+func (d Decimal64) String() string {
+	return string(d.AppendString(nil))
+}
+
+// AppendString appends d with exactly Scale decimals and no exponent. The result is a
+// valid JSON number.
+func (d Decimal64) AppendString(b []byte) []byte {
+	neg, m := d.magnitude()
+	var digits [20]byte
+	return appendDecimal(b, neg, strconv.AppendUint(digits[:0], m, 10), d.Scale)
+}
+
+func (d Decimal64) MarshalJSON() ([]byte, error) {
+	return d.AppendString(nil), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, at the scale of d.
+func (d *Decimal64) UnmarshalJSON(b []byte) error {
+	s, ok := jsonDecimal(b)
+	if !ok {
+		return nil
+	}
+	v, err := ParseDecimal64(s, d.Scale)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Int128 is a two's complement 128-bit integer.
+type Int128 struct {
+	Hi int64
+	Lo uint64
+}
+
+func Int128FromInt64(v int64) Int128 {
+	return Int128{Hi: v >> 63, Lo: uint64(v)}
+}
+
+// Int128FromBytes decodes the little-endian bytes of an integer.
+func Int128FromBytes(b [16]byte) Int128 {
+	return Int128{
+		Hi: int64(binary.LittleEndian.Uint64(b[8:])),
+		Lo: binary.LittleEndian.Uint64(b[:8]),
+	}
+}
+
+// Int128FromBig returns b and false when it does not fit 128 bits.
+func Int128FromBig(b *big.Int) (Int128, bool) {
+	m := new(big.Int).Abs(b)
+	if m.BitLen() > 128 {
+		return Int128{}, false
+	}
+	lo := new(big.Int).And(m, new(big.Int).SetUint64(math.MaxUint64)).Uint64()
+	hi := new(big.Int).Rsh(m, 64).Uint64()
+	return int128Of(b.Sign() < 0, uint128{hi, lo})
+}
+
+// Bytes returns the little-endian bytes of x.
+func (x Int128) Bytes() (b [16]byte) {
+	binary.LittleEndian.PutUint64(b[:8], x.Lo)
+	binary.LittleEndian.PutUint64(b[8:], uint64(x.Hi))
+	return
+}
+
+func (x Int128) Big() *big.Int {
+	neg, m := x.magnitude()
+	b := new(big.Int).Lsh(new(big.Int).SetUint64(m.hi), 64)
+	b.Or(b, new(big.Int).SetUint64(m.lo))
+	if neg {
+		b.Neg(b)
+	}
+	return b
+}
+
+func (x Int128) Sign() int {
+	switch {
+	case x.Hi < 0:
+		return -1
+	case x.Hi == 0 && x.Lo == 0:
+		return 0
+	}
+	return 1
+}
+
+func (x Int128) Cmp(y Int128) int {
+	switch {
+	case x.Hi < y.Hi:
+		return -1
+	case x.Hi > y.Hi:
+		return 1
+	case x.Lo < y.Lo:
+		return -1
+	case x.Lo > y.Lo:
+		return 1
+	}
+	return 0
+}
+
+// Add returns x+y and false when it overflows.
+func (x Int128) Add(y Int128) (Int128, bool) {
+	lo, carry := bits.Add64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Add64(uint64(x.Hi), uint64(y.Hi), carry)
+	r := Int128{Hi: int64(hi), Lo: lo}
+	if (x.Hi < 0) == (y.Hi < 0) && (r.Hi < 0) != (x.Hi < 0) {
+		return Int128{}, false
+	}
+	return r, true
+}
+
+// Sub returns x-y and false when it overflows.
+func (x Int128) Sub(y Int128) (Int128, bool) {
+	lo, borrow := bits.Sub64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Sub64(uint64(x.Hi), uint64(y.Hi), borrow)
+	r := Int128{Hi: int64(hi), Lo: lo}
+	if (x.Hi < 0) != (y.Hi < 0) && (r.Hi < 0) != (x.Hi < 0) {
+		return Int128{}, false
+	}
+	return r, true
+}
+
+func (x Int128) String() string {
+	neg, m := x.magnitude()
+	b := m.appendDecimal(nil)
+	if neg {
+		return "-" + string(b)
+	}
+	return string(b)
+}
+
+func (x Int128) magnitude() (bool, uint128) {
+	if x.Hi >= 0 {
+		return false, uint128{uint64(x.Hi), x.Lo}
+	}
+	lo, borrow := bits.Sub64(0, x.Lo, 0)
+	hi, _ := bits.Sub64(0, uint64(x.Hi), borrow)
+	return true, uint128{hi, lo}
+}
+
+// int128Of returns the integer of a sign and magnitude and false when it overflows.
+func int128Of(neg bool, m uint128) (Int128, bool) {
+	if m.hi > 1<<63 || (m.hi == 1<<63 && (!neg || m.lo != 0)) {
+		return Int128{}, false
+	}
+	if !neg {
+		return Int128{Hi: int64(m.hi), Lo: m.lo}, true
+	}
+	lo, borrow := bits.Sub64(0, m.lo, 0)
+	hi, _ := bits.Sub64(0, m.hi, borrow)
+	return Int128{Hi: int64(hi), Lo: lo}, true
+}
+
+// Decimal128 is the decimal Units/10^Scale. Schema decimal128(scale) fields store Units
+// as 16 little-endian bytes. Arithmetic behaves as for Decimal64.
+type Decimal128 struct {
+	Units Int128
+	Scale int
+}
+
+// ParseDecimal128 parses s as a decimal of scale. See ParseDecimal64.
+func ParseDecimal128(s string, scale int) (Decimal128, error) {
+	if scale < 0 || scale > MaxDecimal128Scale {
+		return Decimal128{}, ErrDecimalScale
+	}
+	neg, m, err := parseDecimal(s, scale)
+	if err != nil {
+		return Decimal128{}, err
+	}
+	return decimal128Of(neg, m, scale)
+}
+
+func decimal128Of(neg bool, m uint128, scale int) (Decimal128, error) {
+	units, ok := int128Of(neg, m)
+	if !ok {
+		return Decimal128{}, ErrDecimalOverflow
+	}
+	return Decimal128{Units: units, Scale: scale}, nil
+}
+
+func decimal128OfBig(b *big.Int, scale int) (Decimal128, error) {
+	units, ok := Int128FromBig(b)
+	if !ok {
+		return Decimal128{}, ErrDecimalOverflow
+	}
+	return Decimal128{Units: units, Scale: scale}, nil
+}
+
+func (d Decimal128) check(scale int) error {
+	if d.Scale < 0 || d.Scale > MaxDecimal128Scale || scale < 0 || scale > MaxDecimal128Scale {
+		return ErrDecimalScale
+	}
+	return nil
+}
+
+// Rescale returns d with scale decimals. It fails with ErrDecimalPrecision when that
+// drops non-zero digits.
+func (d Decimal128) Rescale(scale int) (Decimal128, error) {
+	if err := d.check(scale); err != nil {
+		return Decimal128{}, err
+	}
+	neg, m := d.Units.magnitude()
+	for s := d.Scale; s < scale; {
+		digits := scale - s
+		if digits >= len(pow10) {
+			digits = len(pow10) - 1
+		}
+		var ok bool
+		if m, ok = m.mul64(pow10[digits]); !ok {
+			return Decimal128{}, ErrDecimalOverflow
+		}
+		s += digits
+	}
+	for s := d.Scale; s > scale; {
+		digits := s - scale
+		if digits >= len(pow10) {
+			digits = len(pow10) - 1
+		}
+		var r uint64
+		if m, r = m.divmod64(pow10[digits]); r != 0 {
+			return Decimal128{}, ErrDecimalPrecision
+		}
+		s -= digits
+	}
+	return decimal128Of(neg, m, scale)
+}
+
+// Round returns d with scale decimals, rounding half to even.
+func (d Decimal128) Round(scale int) (Decimal128, error) {
+	if err := d.check(scale); err != nil {
+		return Decimal128{}, err
+	}
+	if scale >= d.Scale {
+		return d.Rescale(scale)
+	}
+	b := d.Units.Big()
+	q := quoRound(new(big.Int).Abs(b), bigPow10(d.Scale-scale))
+	if b.Sign() < 0 {
+		q.Neg(q)
+	}
+	return decimal128OfBig(q, scale)
+}
+
+func (d Decimal128) align(v Decimal128) (Int128, Int128, int, error) {
+	scale := d.Scale
+	if v.Scale > scale {
+		scale = v.Scale
+	}
+	a, err := d.Rescale(scale)
+	if err != nil {
+		return Int128{}, Int128{}, 0, err
+	}
+	b, err := v.Rescale(scale)
+	if err != nil {
+		return Int128{}, Int128{}, 0, err
+	}
+	return a.Units, b.Units, scale, nil
+}
+
+func (d Decimal128) Add(v Decimal128) (Decimal128, error) {
+	a, b, scale, err := d.align(v)
+	if err != nil {
+		return Decimal128{}, err
+	}
+	r, ok := a.Add(b)
+	if !ok {
+		return Decimal128{}, ErrDecimalOverflow
+	}
+	return Decimal128{Units: r, Scale: scale}, nil
+}
+
+func (d Decimal128) Sub(v Decimal128) (Decimal128, error) {
+	a, b, scale, err := d.align(v)
+	if err != nil {
+		return Decimal128{}, err
+	}
+	r, ok := a.Sub(b)
+	if !ok {
+		return Decimal128{}, ErrDecimalOverflow
+	}
+	return Decimal128{Units: r, Scale: scale}, nil
+}
+
+// Mul returns d*v rounded half to even.
+func (d Decimal128) Mul(v Decimal128) (Decimal128, error) {
+	if err := d.check(v.Scale); err != nil {
+		return Decimal128{}, err
+	}
+	scale, drop := d.Scale, v.Scale
+	if drop > scale {
+		scale, drop = drop, scale
+	}
+	a, b := d.Units.Big(), v.Units.Big()
+	n := new(big.Int).Mul(a, b)
+	q := quoRound(n.Abs(n), bigPow10(drop))
+	if a.Sign()*b.Sign() < 0 {
+		q.Neg(q)
+	}
+	return decimal128OfBig(q, scale)
+}
+
+// Div returns d/v rounded half to even.
+func (d Decimal128) Div(v Decimal128) (Decimal128, error) {
+	if err := d.check(v.Scale); err != nil {
+		return Decimal128{}, err
+	}
+	if v.Units.Sign() == 0 {
+		return Decimal128{}, ErrDecimalDivision
+	}
+	scale := d.Scale
+	if v.Scale > scale {
+		scale = v.Scale
+	}
+	a, b := d.Units.Big(), v.Units.Big()
+	n := new(big.Int).Mul(new(big.Int).Abs(a), bigPow10(scale-d.Scale+v.Scale))
+	q := quoRound(n, new(big.Int).Abs(b))
+	if a.Sign()*b.Sign() < 0 {
+		q.Neg(q)
+	}
+	return decimal128OfBig(q, scale)
+}
+
+func (d Decimal128) Neg() (Decimal128, error) {
+	r, ok := Int128{}.Sub(d.Units)
+	if !ok {
+		return Decimal128{}, ErrDecimalOverflow
+	}
+	return Decimal128{Units: r, Scale: d.Scale}, nil
+}
+
+// Cmp compares the values of d and v whatever their scales.
+func (d Decimal128) Cmp(v Decimal128) int {
+	if d.Scale == v.Scale {
+		return d.Units.Cmp(v.Units)
+	}
+	a := new(big.Int).Mul(d.Units.Big(), bigPow10(v.Scale))
+	return a.Cmp(new(big.Int).Mul(v.Units.Big(), bigPow10(d.Scale)))
+}
+
+func (d Decimal128) Sign() int {
+	return d.Units.Sign()
+}
+
+func (d Decimal128) IsZero() bool {
+	return d.Units.Sign() == 0
+}
+
+// Float64 returns the nearest float64. It is for display and statistics, not for
+// arithmetic.
+func (d Decimal128) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.Units.Big(), bigPow10(d.Scale)).Float64()
+	return f
+}
+
+// String returns d with exactly Scale decimals and no exponent.
+func (d Decimal128) String() string {
+	return string(d.AppendString(nil))
+}
+
+// AppendString appends d with exactly Scale decimals and no exponent. The result is a
+// valid JSON number.
+func (d Decimal128) AppendString(b []byte) []byte {
+	neg, m := d.Units.magnitude()
+	var digits [40]byte
+	return appendDecimal(b, neg, m.appendDecimal(digits[:0]), d.Scale)
+}
+
+func (d Decimal128) MarshalJSON() ([]byte, error) {
+	return d.AppendString(nil), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, at the scale of d.
+func (d *Decimal128) UnmarshalJSON(b []byte) error {
+	s, ok := jsonDecimal(b)
+	if !ok {
+		return nil
+	}
+	v, err := ParseDecimal128(s, d.Scale)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// jsonDecimal returns the number of a JSON value and false for null.
+func jsonDecimal(b []byte) (string, bool) {
+	s := string(b)
+	if s == "null" {
+		return "", false
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s, true
+}
+
+// appendDecimal appends the decimal digits of a magnitude with a point before its last
+// scale digits.
+func appendDecimal(b []byte, neg bool, digits []byte, scale int) []byte {
+	if neg {
+		b = append(b, '-')
+	}
+	if len(digits) <= scale {
+		b = append(b, '0', '.')
+		for i := len(digits); i < scale; i++ {
+			b = append(b, '0')
+		}
+		return append(b, digits...)
+	}
+	point := len(digits) - scale
+	b = append(b, digits[:point]...)
+	if scale > 0 {
+		b = append(b, '.')
+		b = append(b, digits[point:]...)
+	}
+	return b
+}
+
+// parseDecimal parses a JSON number into the sign and magnitude of its units at scale.
+func parseDecimal(s string, scale int) (bool, uint128, error) {
+	syntax := func() (bool, uint128, error) {
+		return false, uint128{}, fmt.Errorf("%w: %q", ErrDecimalSyntax, s)
+	}
+	i := 0
+	neg := false
+	if i < len(s) && s[i] == '-' {
+		neg = true
+		i++
+	}
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	integer := s[start:i]
+	var fraction string
+	if i < len(s) && s[i] == '.' {
+		i++
+		start = i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		fraction = s[start:i]
+	}
+	if len(integer)+len(fraction) == 0 {
+		return syntax()
+	}
+	exp := 0
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || len(s[i+1:]) > 6 {
+			return syntax()
+		}
+		exp = e
+		i = len(s)
+	}
+	if i != len(s) {
+		return syntax()
+	}
+
+	// The units are the digits times 10^shift.
+	digits := integer + fraction
+	shift := exp - len(fraction) + scale
+	if shift < 0 {
+		keep := len(digits) + shift
+		if keep < 0 {
+			keep = 0
+		}
+		for _, c := range digits[keep:] {
+			if c != '0' {
+				return false, uint128{}, ErrDecimalPrecision
+			}
+		}
+		digits, shift = digits[:keep], 0
+	}
+	var m uint128
+	for _, c := range digits {
+		var ok bool
+		if m, ok = m.mul64(10); ok {
+			m, ok = m.add64(uint64(c - '0'))
+		}
+		if !ok {
+			return false, uint128{}, ErrDecimalOverflow
+		}
+	}
+	for ; shift > 0 && !m.isZero(); shift-- {
+		var ok bool
+		if m, ok = m.mul64(10); !ok {
+			return false, uint128{}, ErrDecimalOverflow
+		}
+	}
+	return neg && !m.isZero(), m, nil
+}
+
+// uint128 is the magnitude of an Int128.
+type uint128 struct {
+	hi, lo uint64
+}
+
+func (u uint128) isZero() bool {
+	return u.hi == 0 && u.lo == 0
+}
+
+func (u uint128) cmp(v uint128) int {
+	switch {
+	case u.hi < v.hi:
+		return -1
+	case u.hi > v.hi:
+		return 1
+	case u.lo < v.lo:
+		return -1
+	case u.lo > v.lo:
+		return 1
+	}
+	return 0
+}
+
+func (u uint128) mul64(v uint64) (uint128, bool) {
+	carry, lo := bits.Mul64(u.lo, v)
+	overflow, hi := bits.Mul64(u.hi, v)
+	if overflow != 0 {
+		return uint128{}, false
+	}
+	hi, c := bits.Add64(hi, carry, 0)
+	return uint128{hi, lo}, c == 0
+}
+
+func (u uint128) add64(v uint64) (uint128, bool) {
+	lo, carry := bits.Add64(u.lo, v, 0)
+	hi, c := bits.Add64(u.hi, 0, carry)
+	return uint128{hi, lo}, c == 0
+}
+
+func (u uint128) divmod64(v uint64) (uint128, uint64) {
+	hi, r := bits.Div64(0, u.hi, v)
+	lo, r := bits.Div64(r, u.lo, v)
+	return uint128{hi, lo}, r
+}
+
+func (u uint128) appendDecimal(b []byte) []byte {
+	if u.hi == 0 {
+		return strconv.AppendUint(b, u.lo, 10)
+	}
+	// At most 39 digits: the leading ones and two chunks of 19.
+	var chunks [2]uint64
+	n := 0
+	for u.hi != 0 {
+		u, chunks[n] = u.divmod64(pow10[19])
+		n++
+	}
+	b = strconv.AppendUint(b, u.lo, 10)
+	for n--; n >= 0; n-- {
+		var digits [19]byte
+		s := strconv.AppendUint(digits[:0], chunks[n], 10)
+		for i := len(s); i < 19; i++ {
+			b = append(b, '0')
+		}
+		b = append(b, s...)
+	}
+	return b
+}
+
+func bigPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// quoRound returns n/d rounded half to even for non-negative n and positive d.
+func quoRound(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	switch r.Lsh(r, 1).Cmp(d) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
@@ -0,0 +1,219 @@
+package wap
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDecimal64Parse(t *testing.T) {
+	for _, c := range []struct {
+		s      string
+		scale  int
+		units  int64
+		string string
+		err    error
+	}{
+		{"0", 8, 0, "0.00000000", nil},
+		{"-0.0", 2, 0, "0.00", nil},
+		{"1", 0, 1, "1", nil},
+		{"0.1", 8, 10000000, "0.10000000", nil},
+		{"-12.345", 3, -12345, "-12.345", nil},
+		{"1.50", 1, 15, "1.5", nil},
+		{"1.5e-7", 8, 15, "0.00000015", nil},
+		{"25E2", 2, 250000, "2500.00", nil},
+		{"92233720368.54775807", 8, math.MaxInt64, "92233720368.54775807", nil},
+		{"-92233720368.54775808", 8, math.MinInt64, "-92233720368.54775808", nil},
+		{"92233720368.54775808", 8, 0, "", ErrDecimalOverflow},
+		{"1e30", 0, 0, "", ErrDecimalOverflow},
+		{"0.123", 2, 0, "", ErrDecimalPrecision},
+		{"1e-9", 8, 0, "", ErrDecimalPrecision},
+		{"0e-40", 8, 0, "0.00000000", nil},
+		{"", 2, 0, "", ErrDecimalSyntax},
+		{"-", 2, 0, "", ErrDecimalSyntax},
+		{"1.2.3", 2, 0, "", ErrDecimalSyntax},
+		{"1e", 2, 0, "", ErrDecimalSyntax},
+		{"NaN", 2, 0, "", ErrDecimalSyntax},
+		{"1", 19, 0, "", ErrDecimalScale},
+	} {
+		d, err := ParseDecimal64(c.s, c.scale)
+		if !errors.Is(err, c.err) {
+			t.Fatalf("%q: err = %v, expected %v", c.s, err, c.err)
+		}
+		if err != nil {
+			continue
+		}
+		if d.Units != c.units || d.Scale != c.scale || d.String() != c.string {
+			t.Fatalf("%q = %d (%s), expected %d (%s)", c.s, d.Units, d, c.units, c.string)
+		}
+	}
+}
+
+func TestDecimal64Arithmetic(t *testing.T) {
+	parse := func(s string, scale int) Decimal64 {
+		d, err := ParseDecimal64(s, scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	check := func(name string, d Decimal64, err error, expected string) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if d.String() != expected {
+			t.Fatalf("%s = %s, expected %s", name, d, expected)
+		}
+	}
+
+	// 0.1 added ten times is exactly 1.
+	sum := parse("0", 8)
+	for i := 0; i < 10; i++ {
+		var err error
+		if sum, err = sum.Add(parse("0.1", 8)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check("sum", sum, nil, "1.00000000")
+
+	d, err := parse("1.5", 1).Add(parse("0.25", 2))
+	check("add", d, err, "1.75")
+	d, err = parse("1.5", 1).Sub(parse("2.25", 2))
+	check("sub", d, err, "-0.75")
+	d, err = parse("2.5", 2).Mul(parse("0.125", 3))
+	check("mul", d, err, "0.312")
+	d, err = parse("2.5", 2).Mul(parse("0.3", 3))
+	check("mul", d, err, "0.750")
+	d, err = parse("-0.5", 1).Mul(parse("0.5", 1))
+	check("mul half even", d, err, "-0.2")
+	d, err = parse("10", 8).Div(parse("3", 8))
+	check("div", d, err, "3.33333333")
+	d, err = parse("-2", 0).Div(parse("3", 0))
+	check("div", d, err, "-1")
+	d, err = parse("1", 18).Div(parse("3", 18))
+	check("div big", d, err, "0.333333333333333333")
+	d, err = parse("1.005", 3).Round(2)
+	check("round", d, err, "1.00")
+	d, err = parse("1.015", 3).Round(2)
+	check("round", d, err, "1.02")
+	d, err = parse("1.5", 1).Rescale(4)
+	check("rescale", d, err, "1.5000")
+
+	if _, err = parse("1.25", 2).Rescale(1); !errors.Is(err, ErrDecimalPrecision) {
+		t.Fatalf("rescale err = %v", err)
+	}
+	if _, err = (Decimal64{Units: math.MaxInt64}).Add(Decimal64{Units: 1}); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("add err = %v", err)
+	}
+	if _, err = (Decimal64{Units: math.MinInt64}).Sub(Decimal64{Units: 1}); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("sub err = %v", err)
+	}
+	if _, err = (Decimal64{Units: math.MaxInt64}).Mul(Decimal64{Units: 2}); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("mul err = %v", err)
+	}
+	if _, err = (Decimal64{Units: math.MinInt64}).Neg(); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("neg err = %v", err)
+	}
+	if _, err = parse("1", 2).Div(parse("0", 2)); !errors.Is(err, ErrDecimalDivision) {
+		t.Fatalf("div err = %v", err)
+	}
+	if _, err = (Decimal64{Units: 1, Scale: 18}).Rescale(0); !errors.Is(err, ErrDecimalPrecision) {
+		t.Fatalf("rescale err = %v", err)
+	}
+
+	if parse("1.5", 1).Cmp(parse("1.50", 2)) != 0 || parse("-1", 0).Cmp(parse("0.5", 1)) != -1 ||
+		(Decimal64{Units: math.MaxInt64}).Cmp(Decimal64{Units: math.MaxInt64, Scale: 18}) != 1 ||
+		(Decimal64{Units: -1}).Cmp(Decimal64{Units: math.MinInt64, Scale: 18}) != 1 {
+		t.Fatal("cmp")
+	}
+	if f := parse("0.1", 8).Float64(); f != 0.1 {
+		t.Fatalf("float = %v", f)
+	}
+}
+
+func TestDecimal64JSON(t *testing.T) {
+	var v struct {
+		Qty   Decimal64  `json:"qty"`
+		Price Decimal128 `json:"price"`
+	}
+	v.Qty.Scale, v.Price.Scale = 8, 2
+	if err := json.Unmarshal([]byte(`{"qty":1e-8,"price":"12.5"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"qty":0.00000001,"price":12.50}` {
+		t.Fatalf("json = %s", data)
+	}
+	if err = json.Unmarshal([]byte(`{"qty":0.000000001}`), &v); !errors.Is(err, ErrDecimalPrecision) {
+		t.Fatalf("err = %v", err)
+	}
+	if err = json.Unmarshal([]byte(`{"qty":null}`), &v); err != nil || v.Qty.Units != 1 {
+		t.Fatalf("null: %v %v", v.Qty, err)
+	}
+}
+
+func TestDecimal128(t *testing.T) {
+	max := "170141183460469231731687303715884105727"
+	d, err := ParseDecimal128(max, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Units.Hi != math.MaxInt64 || d.Units.Lo != math.MaxUint64 || d.String() != max {
+		t.Fatalf("max = %+v %s", d.Units, d)
+	}
+	if _, err = d.Add(Decimal128{Units: Int128FromInt64(1)}); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("add err = %v", err)
+	}
+	min, err := ParseDecimal128("-1701411834604692317316873037158841057.28", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min.Units.Hi != math.MinInt64 || min.Units.Lo != 0 || min.String() != "-1701411834604692317316873037158841057.28" {
+		t.Fatalf("min = %+v %s", min.Units, min)
+	}
+	if _, err = min.Neg(); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("neg err = %v", err)
+	}
+	if _, err = ParseDecimal128("170141183460469231731687303715884105728", 0); !errors.Is(err, ErrDecimalOverflow) {
+		t.Fatalf("parse err = %v", err)
+	}
+	if Int128FromBytes(min.Units.Bytes()) != min.Units || min.Units.Big().String() != "-170141183460469231731687303715884105728" {
+		t.Fatal("bytes")
+	}
+
+	a, _ := ParseDecimal128("12345678901234567890.123456789", 9)
+	b, _ := ParseDecimal128("-0.000000001", 9)
+	for _, c := range []struct {
+		name     string
+		op       func(Decimal128) (Decimal128, error)
+		expected string
+	}{
+		{"add", a.Add, "12345678901234567890.123456788"},
+		{"sub", a.Sub, "12345678901234567890.123456790"},
+		{"mul", a.Mul, "-12345678901.234567890"},
+		{"div", a.Div, "-12345678901234567890123456789.000000000"},
+	} {
+		d, err := c.op(b)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if d.String() != c.expected {
+			t.Fatalf("%s = %s, expected %s", c.name, d, c.expected)
+		}
+	}
+	if r, err := a.Round(2); err != nil || r.String() != "12345678901234567890.12" {
+		t.Fatalf("round = %s, %v", r, err)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(a) != 0 {
+		t.Fatal("cmp")
+	}
+	small, _ := ParseDecimal128("0.05", 2)
+	if s := small.String(); s != "0.05" {
+		t.Fatalf("small = %s", s)
+	}
+}
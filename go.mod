@@ -2,4 +2,7 @@ module github.com/moontrade/proto
 
 go 1.17
 
-require github.com/moontrade/nogc v0.1.3
+require (
+	github.com/moontrade/nogc v0.1.3
+	gopkg.in/yaml.v3 v3.0.1
+)
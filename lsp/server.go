@@ -0,0 +1,303 @@
+// Package lsp implements a language server for .moon schema files. It reports parse and
+// resolve errors as diagnostics and provides go to definition across imports, hover with
+// the computed layout of fields, completion of type names and rename of declarations.
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/moontrade/proto/schema"
+)
+
+// Server is a language server speaking JSON-RPC over a stream such as stdin and stdout.
+type Server struct {
+	conn      *conn
+	ws        *workspace
+	published map[string]bool // files that have diagnostics published
+	shutdown  bool
+	Log       *log.Logger
+}
+
+// NewServer returns a server reading requests from r and writing responses to w.
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn:      newConn(r, w),
+		published: make(map[string]bool),
+		Log:       log.New(io.Discard, "", 0),
+	}
+}
+
+// Run serves requests until the client sends exit or closes the stream.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if _, ok := err.(*responseError); ok {
+				s.Log.Printf("invalid message: %s", err)
+				continue
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			if err != nil {
+				s.Log.Printf("%s: %s", msg.Method, err)
+			}
+			continue
+		}
+		if err = s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+
+	case "initialized":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(params.TextDocument.URI, &params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.didChange(params.TextDocument.URI, &text)
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(params.TextDocument.URI, nil)
+
+	case "textDocument/didSave":
+		return nil, s.publish()
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(&params), nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(&params), nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(&params), nil
+
+	case "textDocument/rename":
+		var params RenameParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.rename(&params)
+	}
+	if msg.ID == nil {
+		// Notifications that are not implemented are ignored.
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func unmarshalParams(msg *message, v interface{}) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize(params *InitializeParams) *InitializeResult {
+	root := uriToPath(params.RootURI)
+	if len(root) == 0 && len(params.WorkspaceFolders) > 0 {
+		root = uriToPath(params.WorkspaceFolders[0].URI)
+	}
+	if len(root) == 0 {
+		root = params.RootPath
+	}
+	if len(root) == 0 {
+		root, _ = os.Getwd()
+	}
+	s.ws = newWorkspace(filepath.Clean(root))
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:   syncFull,
+			HoverProvider:      true,
+			DefinitionProvider: true,
+			CompletionProvider: &CompletionOptions{TriggerCharacters: []string{"."}},
+			RenameProvider:     true,
+		},
+		ServerInfo: ServerInfo{Name: "moonls"},
+	}
+}
+
+// didChange replaces the content of an open document, or closes it when text is nil,
+// and publishes the diagnostics of the workspace.
+func (s *Server) didChange(uri string, text *string) error {
+	if s.ws == nil {
+		return fmt.Errorf("server is not initialized")
+	}
+	path := uriToPath(uri)
+	if text == nil {
+		delete(s.ws.overlays, path)
+	} else {
+		s.ws.overlays[path] = *text
+	}
+	return s.publish()
+}
+
+// publish analyzes the workspace and publishes the diagnostics of every file, clearing
+// those of files that no longer have any.
+func (s *Server) publish() error {
+	if s.ws == nil {
+		return nil
+	}
+	diagnostics := s.ws.analyze()
+	paths := make([]string, 0, len(diagnostics))
+	for path := range diagnostics {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		list := diagnostics[path]
+		if len(list) == 0 && !s.published[path] {
+			continue
+		}
+		s.published[path] = len(list) > 0
+		if err := s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         pathToURI(path),
+			Diagnostics: list,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyzed makes sure the workspace has been analyzed before answering a request.
+func (s *Server) analyzed() *workspace {
+	if s.ws == nil {
+		return nil
+	}
+	if s.ws.schema == nil {
+		s.ws.analyze()
+	}
+	return s.ws
+}
+
+func (s *Server) definition(params *TextDocumentPositionParams) interface{} {
+	ws := s.analyzed()
+	if ws == nil {
+		return nil
+	}
+	sym := ws.lookup(uriToPath(params.TextDocument.URI), params.Position)
+	if sym == nil || sym.decl == nil {
+		return nil
+	}
+	return ws.definition(sym.decl)
+}
+
+func (s *Server) hover(params *TextDocumentPositionParams) interface{} {
+	ws := s.analyzed()
+	if ws == nil {
+		return nil
+	}
+	path := uriToPath(params.TextDocument.URI)
+	sym := ws.lookup(path, params.Position)
+	if sym == nil {
+		return nil
+	}
+	r := ws.rangeOf(path, sym.line, sym.token.start, sym.token.end)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: ws.hover(sym)},
+		Range:    &r,
+	}
+}
+
+func (s *Server) completion(params *TextDocumentPositionParams) interface{} {
+	ws := s.analyzed()
+	if ws == nil {
+		return []CompletionItem{}
+	}
+	return ws.completion(uriToPath(params.TextDocument.URI), params.Position)
+}
+
+func (s *Server) rename(params *RenameParams) (interface{}, error) {
+	ws := s.analyzed()
+	if ws == nil {
+		return nil, nil
+	}
+	sym := ws.lookup(uriToPath(params.TextDocument.URI), params.Position)
+	if sym == nil || sym.decl == nil {
+		return nil, &responseError{Code: codeRequestFailed, Message: "only structs, enums, unions and consts can be renamed"}
+	}
+	if !schema.IsValidName(params.NewName) {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("'%s' is not a valid name", params.NewName)}
+	}
+	if existing := sym.decl.File.Types[params.NewName]; existing != nil {
+		return nil, &responseError{Code: codeRequestFailed, Message: fmt.Sprintf("'%s' is already declared on line %d", params.NewName, existing.Line.Number)}
+	}
+	edit := &WorkspaceEdit{Changes: make(map[string][]TextEdit)}
+	for path, ranges := range ws.references(sym.decl) {
+		uri := pathToURI(path)
+		for _, r := range ranges {
+			edit.Changes[uri] = append(edit.Changes[uri], TextEdit{Range: r, NewText: params.NewName})
+		}
+	}
+	return edit, nil
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
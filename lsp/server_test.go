@@ -0,0 +1,263 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const commonSchema = `struct Price {
+	value f64
+	scale i32
+}
+`
+
+const orderSchema = `import "../common/schema.moon"
+
+struct Order {
+	id    i64
+	price common.Price
+	qty   common.Price
+}
+`
+
+// client drives a server through pipes.
+type client struct {
+	t     *testing.T
+	conn  *conn
+	in    io.WriteCloser
+	id    int
+	notes []*message
+	msgs  chan *message
+	done  chan error
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{
+		t:    t,
+		conn: newConn(clientIn, clientOut),
+		in:   clientOut,
+		msgs: make(chan *message, 64),
+		done: make(chan error, 1),
+	}
+	go func() {
+		c.done <- NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+	// Messages are read concurrently since the server may publish diagnostics while
+	// the client is writing.
+	go func() {
+		defer close(c.msgs)
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.msgs <- msg
+		}
+	}()
+	return c
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends a request and returns its response, collecting the notifications sent
+// before it.
+func (c *client) call(method string, params interface{}, result interface{}) *responseError {
+	c.t.Helper()
+	c.id++
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	id := json.RawMessage(mustMarshal(c.id))
+	if err = c.conn.write(&message{ID: &id, Method: method, Params: data}); err != nil {
+		c.t.Fatal(err)
+	}
+	for msg := range c.msgs {
+		if msg.ID == nil {
+			c.notes = append(c.notes, msg)
+			continue
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			if err = json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+	c.t.Fatal("server closed the connection")
+	return nil
+}
+
+func (c *client) close() {
+	c.t.Helper()
+	c.notify("exit", nil)
+	c.in.Close()
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func writeWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, src := range map[string]string{
+		"common/schema.moon": commonSchema,
+		"order/schema.moon":  orderSchema,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func at(uri string, line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := writeWorkspace(t)
+	common := pathToURI(filepath.Join(dir, "common", "schema.moon"))
+	order := pathToURI(filepath.Join(dir, "order", "schema.moon"))
+
+	c := newClient(t)
+	defer c.close()
+
+	var init InitializeResult
+	if err := c.call("initialize", &InitializeParams{RootURI: pathToURI(dir)}, &init); err != nil {
+		t.Fatal(err)
+	}
+	if !init.Capabilities.DefinitionProvider || !init.Capabilities.RenameProvider {
+		t.Fatalf("capabilities = %+v", init.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+
+	// An unknown type is reported on the line it is used.
+	broken := strings.Replace(orderSchema, "i64", "Missing", 1)
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: order, LanguageID: "moon", Text: broken},
+	})
+	if err := c.call("textDocument/hover", at(order, 0, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	var diagnostics PublishDiagnosticsParams
+	for _, note := range c.notes {
+		if note.Method == "textDocument/publishDiagnostics" {
+			_ = json.Unmarshal(note.Params, &diagnostics)
+		}
+	}
+	if diagnostics.URI != order || len(diagnostics.Diagnostics) == 0 {
+		t.Fatalf("diagnostics = %+v", diagnostics)
+	}
+	if d := diagnostics.Diagnostics[0]; d.Range.Start.Line != 3 || d.Severity != SeverityError {
+		t.Fatalf("diagnostic = %+v", d)
+	}
+
+	// Fixing the file clears them.
+	c.notes = nil
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: order},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: orderSchema}},
+	})
+	if err := c.call("textDocument/hover", at(order, 0, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.notes) != 1 || !strings.Contains(string(c.notes[0].Params), `"diagnostics":[]`) {
+		t.Fatalf("notes = %d", len(c.notes))
+	}
+
+	// Definition of common.Price is in the imported file.
+	var location Location
+	if err := c.call("textDocument/definition", at(order, 4, 15), &location); err != nil {
+		t.Fatal(err)
+	}
+	if location.URI != common || location.Range.Start != (Position{0, 7}) || location.Range.End != (Position{0, 12}) {
+		t.Fatalf("definition = %+v", location)
+	}
+
+	// Hover on a field shows its layout.
+	var hover Hover
+	if err := c.call("textDocument/hover", at(order, 5, 2), &hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hover.Contents.Value, "offset 24") || !strings.Contains(hover.Contents.Value, "size 16") {
+		t.Fatalf("hover = %s", hover.Contents.Value)
+	}
+
+	// Completion offers primitives, aliases and imported declarations.
+	var items []CompletionItem
+	if err := c.call("textDocument/completion", at(order, 3, 7), &items); err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]bool{}
+	for _, item := range items {
+		labels[item.Label] = true
+	}
+	for _, label := range []string{"i64", "double", "string", "Order", "common"} {
+		if !labels[label] {
+			t.Fatalf("completion is missing %s", label)
+		}
+	}
+	items = nil
+	if err := c.call("textDocument/completion", at(order, 4, 14), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Label != "Price" {
+		t.Fatalf("completion after alias = %+v", items)
+	}
+
+	// Renaming Price edits the declaration and both qualified references.
+	var edit WorkspaceEdit
+	if err := c.call("textDocument/rename", &RenameParams{
+		TextDocument: TextDocumentIdentifier{URI: common},
+		Position:     Position{Line: 0, Character: 9},
+		NewName:      "Quote",
+	}, &edit); err != nil {
+		t.Fatal(err)
+	}
+	if len(edit.Changes[common]) != 1 || len(edit.Changes[order]) != 2 {
+		t.Fatalf("rename = %+v", edit.Changes)
+	}
+	if r := edit.Changes[order][0].Range; r.Start.Character != 14 || r.End.Character != 19 {
+		t.Fatalf("rename range = %+v", r)
+	}
+	if err := c.call("textDocument/rename", &RenameParams{
+		TextDocument: TextDocumentIdentifier{URI: order},
+		Position:     Position{Line: 2, Character: 8},
+		NewName:      "9x",
+	}, nil); err == nil || err.Code != codeInvalidParams {
+		t.Fatalf("rename to invalid name = %v", err)
+	}
+
+	if err := c.call("shutdown", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,535 @@
+package lsp
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/moontrade/proto/schema"
+)
+
+// workspace holds the schema files under a root directory. Open documents replace the
+// files on disk until they are closed.
+type workspace struct {
+	root     string
+	overlays map[string]string // open documents by absolute path
+	texts    map[string]string // content of every analyzed file by absolute path
+	files    map[string]*schema.File
+	schema   *schema.Schema
+}
+
+func newWorkspace(root string) *workspace {
+	return &workspace{
+		root:     root,
+		overlays: make(map[string]string),
+		texts:    make(map[string]string),
+		files:    make(map[string]*schema.File),
+	}
+}
+
+// errorPrefix matches the "path:line " prefix of schema errors.
+var errorPrefix = regexp.MustCompile(`^(.+?\.(?:moon|wap)):(\d+) `)
+
+// analyze parses and resolves every schema file and returns the diagnostics of each file
+// by absolute path. Files without problems map to an empty slice.
+func (w *workspace) analyze() map[string][]Diagnostic {
+	sources := make(map[string][]byte)
+	w.texts = make(map[string]string)
+	_ = filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != w.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !schema.IsSchemaFile(path) {
+			return nil
+		}
+		text, ok := w.overlays[path]
+		if !ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			text = string(data)
+		}
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return nil
+		}
+		sources[rel] = []byte(text)
+		w.texts[path] = text
+		return nil
+	})
+
+	diagnostics := make(map[string][]Diagnostic, len(w.texts))
+	for path := range w.texts {
+		diagnostics[path] = []Diagnostic{}
+	}
+	report := func(err error, path string, severity int) {
+		msg := err.Error()
+		line := 0
+		for {
+			m := errorPrefix.FindStringSubmatch(msg)
+			if m == nil {
+				break
+			}
+			path = m[1]
+			line, _ = strconv.Atoi(m[2])
+			msg = msg[len(m[0]):]
+		}
+		if _, ok := diagnostics[path]; !ok {
+			return
+		}
+		diagnostics[path] = append(diagnostics[path], Diagnostic{
+			Range:    w.lineRange(path, line-1),
+			Severity: severity,
+			Source:   "moon",
+			Message:  msg,
+		})
+	}
+
+	s, errs := schema.ParseFiles(w.root, sources)
+	for _, err := range errs {
+		report(err, "", SeverityError)
+	}
+	w.schema = s
+	w.files = make(map[string]*schema.File, len(s.Files))
+	for _, f := range s.Files {
+		w.files[f.Path] = f
+	}
+	if len(s.Files) == 0 {
+		return diagnostics
+	}
+	_ = s.Resolve()
+	for path, f := range w.files {
+		if f.Err != nil {
+			report(f.Err, path, SeverityError)
+		}
+	}
+	for _, err := range s.DeprecatedUses() {
+		report(err, "", SeverityWarning)
+	}
+	return diagnostics
+}
+
+// lines returns the lines of an analyzed file.
+func (w *workspace) lines(path string) []string {
+	return strings.Split(strings.ReplaceAll(w.texts[path], "\r\n", "\n"), "\n")
+}
+
+func (w *workspace) line(path string, line int) string {
+	lines := w.lines(path)
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return lines[line]
+}
+
+// lineRange returns the range of a whole line or the start of the file for line -1.
+func (w *workspace) lineRange(path string, line int) Range {
+	if line < 0 {
+		return Range{}
+	}
+	text := w.line(path, line)
+	return Range{
+		Start: Position{Line: line},
+		End:   Position{Line: line, Character: utf16Len(text)},
+	}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// byteOffset converts a UTF-16 character offset in a line to a byte offset.
+func byteOffset(line string, character int) int {
+	n := 0
+	for i, r := range line {
+		if n >= character {
+			return i
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// token is an identifier in a line. Qualified names such as pricing.Candle are a single
+// token.
+type token struct {
+	start, end int // byte offsets
+	text       string
+}
+
+func isIdent(c byte) bool {
+	return schema.IsLetter(c) || schema.IsNumeral(c) || c == '_' || c == '.'
+}
+
+// tokens returns the identifiers of a line before any comment, skipping string literals.
+func tokens(line string) []token {
+	var result []token
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return result
+		case isIdent(c):
+			start := i
+			for i < len(line) && isIdent(line[i]) {
+				i++
+			}
+			result = append(result, token{start, i, line[start:i]})
+			i--
+		}
+	}
+	return result
+}
+
+func (w *workspace) rangeOf(path string, line int, start, end int) Range {
+	text := w.line(path, line)
+	return Range{
+		Start: Position{Line: line, Character: utf16Len(text[:start])},
+		End:   Position{Line: line, Character: utf16Len(text[:end])},
+	}
+}
+
+// symbol is what a position in a schema file refers to.
+type symbol struct {
+	file  *schema.File
+	token token
+	line  int
+	decl  *schema.Type        // struct, enum, union or const declaration
+	field *schema.StructField // field whose name is at the position
+	kind  schema.Kind         // primitive type
+}
+
+// lookup returns the symbol at a position or nil.
+func (w *workspace) lookup(path string, pos Position) *symbol {
+	f := w.files[path]
+	if f == nil {
+		return nil
+	}
+	text := w.line(path, pos.Line)
+	offset := byteOffset(text, pos.Character)
+	for _, tok := range tokens(text) {
+		if offset < tok.start || offset > tok.end {
+			continue
+		}
+		sym := &symbol{file: f, token: tok, line: pos.Line}
+		if field := fieldAt(f, pos.Line+1); field != nil && (tok.text == field.Name || tok.text == field.Short) {
+			sym.field = field
+			return sym
+		}
+		if sym.decl = w.resolveName(f, tok.text); sym.decl != nil {
+			return sym
+		}
+		if sym.kind = schema.KindOf(tok.text); sym.kind != schema.KindUnknown {
+			return sym
+		}
+		return nil
+	}
+	return nil
+}
+
+// resolveName returns the declaration a type name refers to in a file.
+func (w *workspace) resolveName(f *schema.File, name string) *schema.Type {
+	if dot := strings.IndexByte(name, '.'); dot > -1 {
+		imported := w.importedFile(f, name[:dot])
+		if imported == nil {
+			return nil
+		}
+		return declaration(imported, name[dot+1:])
+	}
+	return declaration(f, name)
+}
+
+func declaration(f *schema.File, name string) *schema.Type {
+	t := f.Types[name]
+	if t == nil {
+		return nil
+	}
+	switch {
+	case t.Struct != nil, t.Enum != nil, t.Union != nil, t.Const != nil:
+		return t
+	}
+	return nil
+}
+
+// importedFile returns the file imported under an alias.
+func (w *workspace) importedFile(f *schema.File, alias string) *schema.File {
+	for _, imports := range f.Imports {
+		for _, imp := range imports.List {
+			if imp.Alias != alias {
+				continue
+			}
+			if imp.File != nil {
+				return imp.File
+			}
+			return w.schema.Files[schema.RelativePath(schema.Join(f.Dir, f.Name), imp.Path)]
+		}
+	}
+	return nil
+}
+
+func fieldAt(f *schema.File, line int) *schema.StructField {
+	for _, st := range f.Structs {
+		for _, field := range st.Fields {
+			if field.Type.Kind != schema.KindPad && field.Type.Line.Number == line {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+func declName(t *schema.Type) string {
+	switch {
+	case t.Struct != nil:
+		return t.Struct.Name
+	case t.Enum != nil:
+		return t.Enum.Name
+	case t.Union != nil:
+		return t.Union.Name
+	case t.Const != nil:
+		return t.Const.Name
+	}
+	return t.Name
+}
+
+// definition returns the location of the name of a declaration.
+func (w *workspace) definition(t *schema.Type) Location {
+	line := t.Line.Number - 1
+	name := declName(t)
+	r := w.lineRange(t.File.Path, line)
+	for i, tok := range tokens(w.line(t.File.Path, line)) {
+		if i > 0 && tok.text == name {
+			r = w.rangeOf(t.File.Path, line, tok.start, tok.end)
+			break
+		}
+	}
+	return Location{URI: pathToURI(t.File.Path), Range: r}
+}
+
+// hover describes a symbol in markdown.
+func (w *workspace) hover(sym *symbol) string {
+	var b strings.Builder
+	code := func(s string) {
+		b.WriteString("```moon\n")
+		b.WriteString(s)
+		b.WriteString("\n```\n")
+	}
+	doc := func(lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString("\n")
+		for _, line := range lines {
+			b.WriteString(strings.TrimSpace(line))
+			b.WriteString("\n")
+		}
+	}
+	switch {
+	case sym.field != nil:
+		field := sym.field
+		code(strings.TrimSpace(codeOf(w.line(sym.file.Path, sym.line))))
+		if field.Struct.Type.Resolved {
+			b.WriteString("\n")
+			b.WriteString(layout(field.Offset, field.Type.Size))
+			b.WriteString("\n")
+		}
+		doc(field.Type.Doc())
+
+	case sym.decl != nil:
+		t := sym.decl
+		code(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(codeOf(w.line(t.File.Path, t.Line.Number-1))), "{")))
+		if t.Const == nil && t.Resolved {
+			b.WriteString("\n")
+			b.WriteString(layout(-1, t.Size))
+			b.WriteString("\n")
+		}
+		doc(t.Doc())
+
+	default:
+		code(sym.token.text)
+		if size := sym.kind.Size(); size > 0 {
+			b.WriteString("\n")
+			b.WriteString(layout(-1, size))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// codeOf returns a line without its comment.
+func codeOf(line string) string {
+	if i := strings.Index(line, "//"); i > -1 {
+		return line[:i]
+	}
+	return line
+}
+
+// layout formats an offset, size and alignment. A negative offset is left out.
+func layout(offset, size int) string {
+	align := strconv.Itoa(schema.FieldAlign(size))
+	if offset < 0 {
+		return "size " + strconv.Itoa(size) + " · align " + align
+	}
+	return "offset " + strconv.Itoa(offset) + " · size " + strconv.Itoa(size) + " · align " + align
+}
+
+// completion returns the type names that can be written at a position: primitives,
+// the declarations of the file and of its imports.
+func (w *workspace) completion(path string, pos Position) []CompletionItem {
+	f := w.files[path]
+	text := w.line(path, pos.Line)
+	offset := byteOffset(text, pos.Character)
+	start := offset
+	for start > 0 && isIdent(text[start-1]) {
+		start--
+	}
+	prefix := text[start:offset]
+
+	var items []CompletionItem
+	addDecls := func(f *schema.File, consts bool) {
+		for _, name := range sortedNames(f) {
+			t := declaration(f, name)
+			item := CompletionItem{Label: name, Documentation: strings.TrimSpace(strings.Join(t.Doc(), "\n"))}
+			switch {
+			case t.Const != nil:
+				if !consts {
+					continue
+				}
+				item.Kind = completionConstant
+			case t.Enum != nil:
+				item.Kind = completionEnum
+			default:
+				item.Kind = completionStruct
+			}
+			items = append(items, item)
+		}
+	}
+	if dot := strings.LastIndexByte(prefix, '.'); dot > -1 {
+		if f != nil {
+			if imported := w.importedFile(f, prefix[:dot]); imported != nil {
+				addDecls(imported, false)
+			}
+		}
+		return items
+	}
+	for _, name := range schema.PrimitiveNames {
+		items = append(items, CompletionItem{Label: name, Kind: completionKeyword, Detail: "primitive"})
+	}
+	if f == nil {
+		return items
+	}
+	addDecls(f, true)
+	for _, imports := range f.Imports {
+		for _, imp := range imports.List {
+			items = append(items, CompletionItem{Label: imp.Alias, Kind: completionModule, Detail: imp.Path})
+		}
+	}
+	return items
+}
+
+// sortedNames returns the names of the declarations in a file.
+func sortedNames(f *schema.File) []string {
+	var names []string
+	for name := range f.Types {
+		if declaration(f, name) != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// references returns the ranges of the declared name and every reference to a
+// declaration by absolute path. Qualified references cover only the name after the
+// import alias.
+func (w *workspace) references(t *schema.Type) map[string][]Range {
+	name := declName(t)
+	result := make(map[string][]Range)
+	for path, f := range w.files {
+		refs := make(map[string]bool)
+		if f == t.File {
+			refs[name] = true
+		}
+		for _, imports := range f.Imports {
+			for _, imp := range imports.List {
+				if w.importedFile(f, imp.Alias) == t.File {
+					refs[imp.Alias+"."+name] = true
+				}
+			}
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		for line, skip := range declarationLines(f) {
+			for i, tok := range tokens(w.line(path, line-1)) {
+				if i < skip || !refs[tok.text] {
+					continue
+				}
+				start := tok.start
+				if dot := strings.LastIndexByte(tok.text, '.'); dot > -1 {
+					start += dot + 1
+				}
+				result[path] = append(result[path], w.rangeOf(path, line-1, start, tok.end))
+			}
+		}
+		sort.Slice(result[path], func(i, j int) bool {
+			a, b := result[path][i].Start, result[path][j].Start
+			return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+		})
+	}
+	return result
+}
+
+// declarationLines returns the lines that may refer to a type mapped to the number of
+// leading tokens that cannot, such as the keyword of a declaration or a field name.
+func declarationLines(f *schema.File) map[int]int {
+	lines := make(map[int]int)
+	for _, cst := range f.Consts {
+		lines[cst.Type.Line.Number] = 1
+	}
+	for _, enum := range f.Enums {
+		lines[enum.Type.Line.Number] = 1
+	}
+	for _, union := range f.Unions {
+		lines[union.Type.Line.Number] = 1
+		for _, option := range union.Options {
+			lines[option.Type.Line.Number] = 1
+		}
+	}
+	for _, st := range f.Structs {
+		lines[st.Type.Line.Number] = 1
+		for _, field := range st.Fields {
+			if field.Type.Kind == schema.KindPad {
+				continue
+			}
+			skip := 1
+			if field.Number > 0 {
+				skip++
+			}
+			if len(field.Short) > 0 {
+				skip++
+			}
+			lines[field.Type.Line.Number] = skip
+		}
+	}
+	return lines
+}
@@ -0,0 +1,128 @@
+package intern
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+const tableShards = 16
+
+// Table is a bounded intern table. Unlike the package functions it keeps strings until
+// they are evicted, so decoders sharing a Table return the same string for the same
+// bytes across decodes.
+//
+// Each shard keeps two generations of strings. Lookups promote strings found in the
+// previous generation to the current one and a full current generation replaces the
+// previous one, evicting the strings that were not used during a whole generation.
+// A Table is safe for concurrent use.
+type Table struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	shards    [tableShards]tableShard
+}
+
+type tableShard struct {
+	mu       sync.Mutex
+	capacity int
+	current  map[string]string
+	previous map[string]string
+}
+
+// Stats are the counters of a Table.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Len       int // Strings currently held
+}
+
+// HitRate returns the share of lookups that found their string.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewTable returns a Table holding up to about capacity strings.
+func NewTable(capacity int) *Table {
+	t := &Table{}
+	perShard := capacity / tableShards / 2
+	if perShard < 1 {
+		perShard = 1
+	}
+	for i := range t.shards {
+		t.shards[i].capacity = perShard
+		t.shards[i].current = make(map[string]string, perShard)
+	}
+	return t
+}
+
+// Bytes returns b converted to a string, interned. b is copied the first time it is
+// seen and may be reused by the caller.
+func (t *Table) Bytes(b []byte) string {
+	return t.intern(*(*string)(unsafe.Pointer(&b)), true)
+}
+
+// String returns s, interned.
+func (t *Table) String(s string) string {
+	return t.intern(s, false)
+}
+
+func (t *Table) intern(s string, clone bool) string {
+	shard := &t.shards[hash(s)%tableShards]
+	shard.mu.Lock()
+	if v, ok := shard.current[s]; ok {
+		shard.mu.Unlock()
+		atomic.AddUint64(&t.hits, 1)
+		return v
+	}
+	v, ok := shard.previous[s]
+	if ok {
+		atomic.AddUint64(&t.hits, 1)
+		delete(shard.previous, s)
+	} else {
+		atomic.AddUint64(&t.misses, 1)
+		if clone {
+			v = string([]byte(s))
+		} else {
+			v = s
+		}
+	}
+	if len(shard.current) >= shard.capacity {
+		atomic.AddUint64(&t.evictions, uint64(len(shard.previous)))
+		shard.previous = shard.current
+		shard.current = make(map[string]string, shard.capacity)
+	}
+	shard.current[v] = v
+	shard.mu.Unlock()
+	return v
+}
+
+// Stats returns the counters of the table.
+func (t *Table) Stats() Stats {
+	s := Stats{
+		Hits:      atomic.LoadUint64(&t.hits),
+		Misses:    atomic.LoadUint64(&t.misses),
+		Evictions: atomic.LoadUint64(&t.evictions),
+	}
+	for i := range t.shards {
+		shard := &t.shards[i]
+		shard.mu.Lock()
+		s.Len += len(shard.current) + len(shard.previous)
+		shard.mu.Unlock()
+	}
+	return s
+}
+
+// hash is FNV-1a.
+func hash(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
@@ -0,0 +1,58 @@
+package intern
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	table := NewTable(64)
+	b := []byte("BTC-USD")
+	s := table.Bytes(b)
+	b[0] = 'X'
+	if s != "BTC-USD" {
+		t.Fatalf("interned string aliases its bytes: %q", s)
+	}
+	if v := table.String("BTC-USD"); v != s {
+		t.Fatal("not interned")
+	}
+	if stats := table.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Len != 1 || stats.HitRate() != 0.5 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// Strings used in every generation survive while the others are evicted.
+	for i := 0; i < 1000; i++ {
+		table.String(fmt.Sprintf("symbol-%d", i))
+		table.String("BTC-USD")
+	}
+	stats := table.Stats()
+	if stats.Len > 64 || stats.Evictions == 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if stats.Misses != 1001 || stats.Hits != 1001 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestTableConcurrent(t *testing.T) {
+	table := NewTable(128)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("k%d", (i*g)%200)
+				if v := table.String(key); v != key {
+					t.Errorf("String(%q) = %q", key, v)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if stats := table.Stats(); stats.Hits+stats.Misses != 8000 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
@@ -45,6 +45,9 @@ type token struct {
 // JsonLexer is a JSON lexer: it iterates over JSON tokens in a byte slice.
 type JsonLexer struct {
 	Data []byte // Input data given to the lexer.
+	// Base is the offset of Data in the whole input. It is added to GetPos and the
+	// error offsets when Data is a window of a stream.
+	Base int
 
 	start int   // Start of the current token.
 	pos   int   // Current unscanned position in the input stream.
@@ -53,6 +56,10 @@ type JsonLexer struct {
 	firstElement bool // Whether current element is the first in array or an object.
 	wantSep      byte // A comma or a colon character, which need to occur before a token.
 
+	// Intern is the table StringIntern interns strings in. The best effort package
+	// pool of intern is used when nil.
+	Intern *intern.Table
+
 	UseMultipleErrors bool          // If we want to use multiple errors.
 	fatalError        error         // Fatal error occurred during lexing. It is usually a syntax error.
 	multipleErrors    []*LexerError // Semantic errors occurred during lexing. Marshalling will be continued after finding this errors.
@@ -424,7 +431,7 @@ func (r *JsonLexer) errParse(what string) {
 		}
 		r.fatalError = &LexerError{
 			Reason: what,
-			Offset: r.pos,
+			Offset: r.Base + r.pos,
 			Data:   str,
 		}
 	}
@@ -452,7 +459,7 @@ func (r *JsonLexer) errInvalidToken(expected string) {
 		}
 		r.addNonfatalError(&LexerError{
 			Reason: fmt.Sprintf("expected %s", expected),
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Data:   string(r.Data[r.start:r.pos]),
 		})
 		return
@@ -466,13 +473,14 @@ func (r *JsonLexer) errInvalidToken(expected string) {
 	}
 	r.fatalError = &LexerError{
 		Reason: fmt.Sprintf("expected %s", expected),
-		Offset: r.pos,
+		Offset: r.Base + r.pos,
 		Data:   str,
 	}
 }
 
+// GetPos returns the position of the lexer in the input, counting from Base.
 func (r *JsonLexer) GetPos() int {
-	return r.pos
+	return r.Base + r.pos
 }
 
 // Delim consumes a token and verifies that it is the given delimiter.
@@ -561,7 +569,7 @@ func (r *JsonLexer) SkipRecursive() {
 					r.pos = len(r.Data)
 					r.fatalError = &LexerError{
 						Reason: "skipped array/object json value is invalid",
-						Offset: r.pos,
+						Offset: r.Base + r.pos,
 						Data:   string(r.Data[r.pos:]),
 					}
 				}
@@ -580,7 +588,7 @@ func (r *JsonLexer) SkipRecursive() {
 	r.pos = len(r.Data)
 	r.fatalError = &LexerError{
 		Reason: "EOF reached while skipping array/object or token",
-		Offset: r.pos,
+		Offset: r.Base + r.pos,
 		Data:   string(r.Data[r.pos:]),
 	}
 }
@@ -611,7 +619,7 @@ func (r *JsonLexer) Consumed() {
 		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
 			r.AddError(&LexerError{
 				Reason: "invalid character '" + string(c) + "' after top-level value",
-				Offset: r.pos,
+				Offset: r.Base + r.pos,
 				Data:   string(r.Data[r.pos:]),
 			})
 			return
@@ -700,7 +708,12 @@ func (r *JsonLexer) StringIntern() string {
 		r.errInvalidToken("string")
 		return ""
 	}
-	ret := intern.Bytes(r.token.byteValue)
+	var ret string
+	if r.Intern != nil {
+		ret = r.Intern.Bytes(r.token.byteValue)
+	} else {
+		ret = intern.Bytes(r.token.byteValue)
+	}
 	r.consume()
 	return ret
 }
@@ -796,7 +809,7 @@ func (r *JsonLexer) Uint8() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -813,7 +826,7 @@ func (r *JsonLexer) Uint8Any() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -830,7 +843,7 @@ func (r *JsonLexer) Uint16() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -847,7 +860,7 @@ func (r *JsonLexer) Uint16Any() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -864,7 +877,7 @@ func (r *JsonLexer) Uint32() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -881,7 +894,7 @@ func (r *JsonLexer) Uint32Any() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -898,7 +911,7 @@ func (r *JsonLexer) Uint64() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -915,7 +928,7 @@ func (r *JsonLexer) Uint64Any() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -940,7 +953,7 @@ func (r *JsonLexer) Int8() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -957,7 +970,7 @@ func (r *JsonLexer) Int8Any() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -974,7 +987,7 @@ func (r *JsonLexer) Int16() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -991,7 +1004,7 @@ func (r *JsonLexer) Int16Any() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1008,7 +1021,7 @@ func (r *JsonLexer) Int32() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1025,7 +1038,7 @@ func (r *JsonLexer) Int32Any() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1042,7 +1055,7 @@ func (r *JsonLexer) Int64() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1059,7 +1072,7 @@ func (r *JsonLexer) Int64Any() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1084,7 +1097,7 @@ func (r *JsonLexer) Uint8Str() uint8 {
 	n, err := strconv.ParseUint(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1101,7 +1114,7 @@ func (r *JsonLexer) Uint16Str() uint16 {
 	n, err := strconv.ParseUint(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1118,7 +1131,7 @@ func (r *JsonLexer) Uint32Str() uint32 {
 	n, err := strconv.ParseUint(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1135,7 +1148,7 @@ func (r *JsonLexer) Uint64Str() uint64 {
 	n, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1160,7 +1173,7 @@ func (r *JsonLexer) Int8Str() int8 {
 	n, err := strconv.ParseInt(s, 10, 8)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1177,7 +1190,7 @@ func (r *JsonLexer) Int16Str() int16 {
 	n, err := strconv.ParseInt(s, 10, 16)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1194,7 +1207,7 @@ func (r *JsonLexer) Int32Str() int32 {
 	n, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1211,7 +1224,7 @@ func (r *JsonLexer) Int64Str() int64 {
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1232,7 +1245,7 @@ func (r *JsonLexer) Float32() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1249,7 +1262,7 @@ func (r *JsonLexer) Float32Any() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1265,7 +1278,7 @@ func (r *JsonLexer) Float32Str() float32 {
 	n, err := strconv.ParseFloat(s, 32)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1282,7 +1295,7 @@ func (r *JsonLexer) Float64() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1299,7 +1312,7 @@ func (r *JsonLexer) Float64Any() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   s,
 		})
@@ -1315,7 +1328,7 @@ func (r *JsonLexer) Float64Str() float64 {
 	n, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		r.addNonfatalError(&LexerError{
-			Offset: r.start,
+			Offset: r.Base + r.start,
 			Reason: err.Error(),
 			Data:   string(b),
 		})
@@ -1335,7 +1348,7 @@ func (r *JsonLexer) AddError(e error) {
 
 func (r *JsonLexer) AddNonFatalError(e error) {
 	r.addNonfatalError(&LexerError{
-		Offset: r.start,
+		Offset: r.Base + r.start,
 		Data:   string(r.Data[r.start:r.pos]),
 		Reason: e.Error(),
 	})
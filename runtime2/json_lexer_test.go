@@ -6,6 +6,9 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"unsafe"
+
+	"github.com/moontrade/proto/runtime2/intern"
 )
 
 func TestString(t *testing.T) {
@@ -82,6 +85,45 @@ func TestStringIntern(t *testing.T) {
 	}
 }
 
+func TestStringInternTable(t *testing.T) {
+	table := intern.NewTable(64)
+	data := []byte(`["BTC-USD","ETH-USD","BTC-USD"]`)
+	l := JsonLexer{Data: data, Intern: table}
+	var symbols []string
+	l.Delim('[')
+	for !l.IsDelim(']') {
+		symbols = append(symbols, l.StringIntern())
+		l.WantComma()
+	}
+	l.Delim(']')
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 3 || symbols[0] != "BTC-USD" || symbols[1] != "ETH-USD" {
+		t.Fatalf("symbols = %v", symbols)
+	}
+	if (*reflect.StringHeader)(unsafe.Pointer(&symbols[0])).Data != (*reflect.StringHeader)(unsafe.Pointer(&symbols[2])).Data {
+		t.Fatal("BTC-USD not interned")
+	}
+	// The interned strings do not alias the input.
+	copy(data, `["XXX`)
+	if symbols[0] != "BTC-USD" {
+		t.Fatalf("interned string changed to %q", symbols[0])
+	}
+	if stats := table.Stats(); stats.Hits != 1 || stats.Misses != 2 || stats.Len != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	data = []byte(`"ETH-USD"`)
+	allocsPerRun := testing.AllocsPerRun(1000, func() {
+		l = JsonLexer{Data: data, Intern: table}
+		_ = l.StringIntern()
+	})
+	if allocsPerRun != 0 {
+		t.Fatalf("expected 0 allocs, got %f", allocsPerRun)
+	}
+}
+
 func TestNumber2(t *testing.T) {
 	for i, test := range []struct {
 		toParse   string
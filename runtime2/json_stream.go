@@ -0,0 +1,291 @@
+package runtime2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/moontrade/proto/runtime2/intern"
+)
+
+// JsonStream reads JSON values one at a time from an io.Reader so unbounded inputs,
+// such as newline delimited dumps, decode in memory bounded by their largest value.
+//
+// Next iterates values separated by whitespace, which covers NDJSON. NextElement
+// iterates the elements of a top-level array. After either returns true, Lexer
+// returns a JsonLexer over the value whose positions and errors are offsets in the
+// whole stream.
+type JsonStream struct {
+	// MaxValueSize bounds the size of a single value. Zero means no bound.
+	MaxValueSize int
+	// Intern is given to the lexers of the values.
+	Intern *intern.Table
+
+	r      io.Reader
+	buf    []byte
+	start  int // Start of the unread data in buf
+	end    int // End of the data in buf
+	base   int // Offset of buf[0] in the stream
+	eof    bool
+	err    error
+	value  []byte
+	offset int
+	array  byte // 0 before the array, '[' within it and ']' after it
+	lexer  JsonLexer
+}
+
+// NewJsonStream returns a stream reading r through a buffer of size bytes which grows
+// when a value does not fit.
+func NewJsonStream(r io.Reader, size int) *JsonStream {
+	if size < 512 {
+		size = 512
+	}
+	return &JsonStream{r: r, buf: make([]byte, size)}
+}
+
+// Next advances to the next value of a whitespace separated sequence of values. It
+// returns false at the end of the stream or on error.
+func (s *JsonStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	if !s.skipSpace() {
+		return false
+	}
+	return s.scan()
+}
+
+// NextElement advances to the next element of the array the stream consists of. It
+// returns false after the closing bracket or on error.
+func (s *JsonStream) NextElement() bool {
+	if s.err != nil || s.array == ']' {
+		return false
+	}
+	if !s.skipSpace() {
+		if s.err == nil {
+			s.fail(s.position(), "unexpected end of data")
+		}
+		return false
+	}
+	c := s.buf[s.start]
+	switch s.array {
+	case 0:
+		if c != '[' {
+			s.fail(s.position(), "expected [")
+			return false
+		}
+		s.start++
+		s.array = '['
+		if !s.skipSpace() {
+			s.fail(s.position(), "unexpected end of data")
+			return false
+		}
+		if s.buf[s.start] == ']' {
+			s.start++
+			s.array = ']'
+			return false
+		}
+	case '[':
+		switch c {
+		case ']':
+			s.start++
+			s.array = ']'
+			return false
+		case ',':
+			s.start++
+			if !s.skipSpace() {
+				s.fail(s.position(), "unexpected end of data")
+				return false
+			}
+		default:
+			s.fail(s.position(), "expected , or ]")
+			return false
+		}
+	}
+	return s.scan()
+}
+
+// Lexer returns the lexer over the current value. The value is only valid until the
+// next call to Next or NextElement.
+func (s *JsonStream) Lexer() *JsonLexer {
+	s.lexer = JsonLexer{Data: s.value, Base: s.offset, Intern: s.Intern}
+	return &s.lexer
+}
+
+// Offset returns the offset of the current value in the stream.
+func (s *JsonStream) Offset() int {
+	return s.offset
+}
+
+// Err returns the error that stopped the iteration, nil at the end of the stream.
+func (s *JsonStream) Err() error {
+	return s.err
+}
+
+func (s *JsonStream) position() int {
+	return s.base + s.start
+}
+
+func (s *JsonStream) fail(offset int, reason string) {
+	var data string
+	if s.start < s.end {
+		end := s.end
+		if end-s.start > maxErrorContextLen {
+			end = s.start + maxErrorContextLen
+		}
+		data = string(s.buf[s.start:end])
+	}
+	s.err = &LexerError{Reason: reason, Offset: offset, Data: data}
+}
+
+// skipSpace skips whitespace and reports whether data follows it.
+func (s *JsonStream) skipSpace() bool {
+	for {
+		for ; s.start < s.end; s.start++ {
+			switch s.buf[s.start] {
+			case ' ', '\t', '\r', '\n':
+			default:
+				return true
+			}
+		}
+		if !s.fill() {
+			return false
+		}
+	}
+}
+
+// fill reads more data, compacting or growing the buffer to make room. It returns false
+// when no more data can be read.
+func (s *JsonStream) fill() bool {
+	if s.eof || s.err != nil {
+		return false
+	}
+	if s.start > 0 {
+		copy(s.buf, s.buf[s.start:s.end])
+		s.base += s.start
+		s.end -= s.start
+		s.start = 0
+	}
+	if s.end == len(s.buf) {
+		if s.MaxValueSize > 0 && len(s.buf) >= s.MaxValueSize {
+			s.fail(s.position(), fmt.Sprintf("value exceeds %d bytes", s.MaxValueSize))
+			return false
+		}
+		buf := make([]byte, len(s.buf)*2)
+		copy(buf, s.buf[:s.end])
+		s.buf = buf
+	}
+	for {
+		n, err := s.r.Read(s.buf[s.end:])
+		s.end += n
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				s.eof = true
+			} else {
+				s.err = err
+			}
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
+
+// scan finds the end of the value starting at s.start, reading as much as needed.
+func (s *JsonStream) scan() bool {
+	var scanner jsonValueScanner
+	for {
+		n, done, reason := scanner.scan(s.buf[s.start:s.end], s.eof)
+		if len(reason) > 0 {
+			s.fail(s.base+s.start+n, reason)
+			return false
+		}
+		if done {
+			s.value = s.buf[s.start : s.start+n]
+			s.offset = s.position()
+			s.start += n
+			return true
+		}
+		// fill moves the data to the start of the buffer which the scanner position
+		// is relative to.
+		if !s.fill() && !s.eof {
+			if s.err == nil {
+				s.fail(s.position(), "unexpected end of data")
+			}
+			return false
+		}
+	}
+}
+
+// jsonValueScanner finds the end of a JSON value without decoding it. Brackets are only
+// counted; the lexer reports mismatched ones.
+type jsonValueScanner struct {
+	pos      int
+	depth    int
+	started  bool
+	scalar   bool
+	inString bool
+	escape   bool
+}
+
+// scan returns the length of the value at the start of b once it is complete. eof
+// reports that b holds the rest of the stream.
+func (v *jsonValueScanner) scan(b []byte, eof bool) (int, bool, string) {
+	for ; v.pos < len(b); v.pos++ {
+		c := b[v.pos]
+		if v.inString {
+			switch {
+			case v.escape:
+				v.escape = false
+			case c == '\\':
+				v.escape = true
+			case c == '"':
+				v.inString = false
+				if v.depth == 0 {
+					return v.pos + 1, true, ""
+				}
+			}
+			continue
+		}
+		if !v.started {
+			v.started = true
+			switch c {
+			case '{', '[':
+				v.depth = 1
+			case '"':
+				v.inString = true
+			case '}', ']', ',', ':':
+				return v.pos, false, "syntax error"
+			default:
+				v.scalar = true
+			}
+			continue
+		}
+		if v.scalar {
+			switch c {
+			case ' ', '\t', '\r', '\n', ',', ':', '[', ']', '{', '}', '"':
+				return v.pos, true, ""
+			}
+			continue
+		}
+		switch c {
+		case '{', '[':
+			v.depth++
+		case '}', ']':
+			v.depth--
+			if v.depth == 0 {
+				return v.pos + 1, true, ""
+			}
+		case '"':
+			v.inString = true
+		}
+	}
+	if eof {
+		if v.scalar {
+			return len(b), true, ""
+		}
+		return len(b), false, "unexpected end of data"
+	}
+	return 0, false, ""
+}
@@ -0,0 +1,147 @@
+package runtime2
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type streamTrade struct {
+	id     int64
+	symbol string
+	price  float64
+}
+
+func readStreamTrade(l *JsonLexer) streamTrade {
+	var t streamTrade
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeString()
+		l.WantColon()
+		switch key {
+		case "id":
+			t.id = l.Int64()
+		case "symbol":
+			t.symbol = l.StringIntern()
+		case "price":
+			t.price = l.Float64()
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+	return t
+}
+
+func TestJsonStreamNDJSON(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 200; i++ {
+		input.WriteString(`{"id":` + strconv.Itoa(i) + `,"symbol":"BTC-USD","note":"` +
+			strings.Repeat("x", i*10) + `","price":` + strconv.Itoa(i) + `.5}` + "\n")
+	}
+	s := NewJsonStream(iotest.OneByteReader(strings.NewReader(input.String())), 0)
+	count := 0
+	for s.Next() {
+		l := s.Lexer()
+		trade := readStreamTrade(l)
+		if err := l.Error(); err != nil {
+			t.Fatal(err)
+		}
+		if trade.id != int64(count) || trade.symbol != "BTC-USD" || trade.price != float64(count)+0.5 {
+			t.Fatalf("trade %d = %+v", count, trade)
+		}
+		if !strings.HasPrefix(input.String()[s.Offset():], `{"id":`+strconv.Itoa(count)+",") {
+			t.Fatalf("value %d at offset %d", count, s.Offset())
+		}
+		count++
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 200 {
+		t.Fatalf("%d values", count)
+	}
+}
+
+func TestJsonStreamArray(t *testing.T) {
+	input := ` [ 1, "two" ,{"three":[3,"]"]},null,4.5e1 ] `
+	expected := []string{`1`, `"two"`, `{"three":[3,"]"]}`, `null`, `4.5e1`}
+	s := NewJsonStream(iotest.HalfReader(strings.NewReader(input)), 0)
+	var values []string
+	for s.NextElement() {
+		values = append(values, string(s.Lexer().Raw()))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(values, "|") != strings.Join(expected, "|") {
+		t.Fatalf("values = %q", values)
+	}
+	if s.NextElement() {
+		t.Fatal("element after ]")
+	}
+
+	s = NewJsonStream(strings.NewReader(`[]`), 0)
+	if s.NextElement() || s.Err() != nil {
+		t.Fatalf("empty array: %v", s.Err())
+	}
+	s = NewJsonStream(strings.NewReader(`[1 2]`), 0)
+	if !s.NextElement() || s.NextElement() {
+		t.Fatal("missing comma accepted")
+	}
+	var lexerError *LexerError
+	if err := s.Err(); !errors.As(err, &lexerError) || lexerError.Offset != 3 {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestJsonStreamOffsets(t *testing.T) {
+	prefix := strings.Repeat(`{"id":1}`+"\n", 100)
+	input := prefix + `{"id":"x"}` + "\n" + `{"id":[1,2}`
+	s := NewJsonStream(strings.NewReader(input), 0)
+	for i := 0; i < 100; i++ {
+		if !s.Next() {
+			t.Fatal(s.Err())
+		}
+	}
+	if !s.Next() {
+		t.Fatal(s.Err())
+	}
+	// Errors are reported at the offset of a lexer over the value alone, moved by the
+	// offset of the value in the stream.
+	alone := JsonLexer{Data: []byte(`{"id":"x"}`)}
+	readStreamTrade(&alone)
+	var expected *LexerError
+	if !errors.As(alone.Error(), &expected) {
+		t.Fatalf("value alone err = %v", alone.Error())
+	}
+	expected.Offset += len(prefix)
+	l := s.Lexer()
+	readStreamTrade(l)
+	var lexerError *LexerError
+	if err := l.Error(); !errors.As(err, &lexerError) || lexerError.Offset != expected.Offset {
+		t.Fatalf("err = %v, expected offset %d", err, expected.Offset)
+	}
+
+	// The value is unterminated at the end of the stream.
+	if s.Next() {
+		t.Fatal("unterminated value")
+	}
+	if err := s.Err(); !errors.As(err, &lexerError) || !strings.Contains(err.Error(), "unexpected end of data") {
+		t.Fatalf("err = %v", err)
+	}
+
+	s = NewJsonStream(bytes.NewReader(bytes.Repeat([]byte(" "), 4000)), 0)
+	if s.Next() || s.Err() != nil {
+		t.Fatalf("whitespace: %v", s.Err())
+	}
+	s = NewJsonStream(strings.NewReader(`"`+strings.Repeat("x", 5000)+`"`), 0)
+	s.MaxValueSize = 1024
+	if s.Next() || s.Err() == nil || !strings.Contains(s.Err().Error(), "exceeds 1024 bytes") {
+		t.Fatalf("err = %v", s.Err())
+	}
+}
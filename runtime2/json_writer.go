@@ -2,9 +2,14 @@
 package runtime2
 
 import (
-	"github.com/moontrade/nogc"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
 	"unicode/utf8"
 	"unsafe"
+
+	"github.com/moontrade/nogc"
 )
 
 // JsonWriterFlags describe various encoding options. The behavior may be actually implemented in the encoder, but
@@ -16,12 +21,45 @@ const (
 	NilSliceAsEmpty                             // Encode nil slice as '[]' rather than 'null'.
 )
 
+// NonFinitePolicy is how a JsonWriter writes NaN and infinite floats which JSON cannot
+// represent.
+type NonFinitePolicy byte
+
+const (
+	NonFiniteNull   NonFinitePolicy = iota // Write null.
+	NonFiniteString                        // Write "NaN", "+Inf" or "-Inf".
+	NonFiniteError                         // Write null and set Error.
+)
+
+// UnsupportedValueError is set as the Error of a JsonWriter given a value JSON cannot
+// represent.
+type UnsupportedValueError struct {
+	Value float64
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return fmt.Sprintf("json: unsupported value: %v", e.Value)
+}
+
 // JsonWriter is a JSON writer.
 type JsonWriter struct {
 	W            nogc.Bytes
 	Flags        JsonWriterFlags
 	Error        error
 	NoEscapeHTML bool
+
+	// FloatDecimals writes floats with a fixed number of decimals when positive and
+	// with the shortest representation that round trips otherwise.
+	FloatDecimals int
+	// NonFinite is the policy for NaN and infinite floats.
+	NonFinite NonFinitePolicy
+	// Indent pretty-prints the output of Flush and FlushTo with one Indent per level
+	// of nesting. The buffer itself stays compact.
+	Indent string
+	// FlushThreshold is the size the buffer must reach before FlushTo writes it out.
+	FlushThreshold int
+
+	indent jsonIndenter
 }
 
 // Size returns the size of the data that was written out.
@@ -81,129 +119,195 @@ func (w *JsonWriter) Base64Bytes(data []byte) {
 }
 
 func (w *JsonWriter) Uint8(n uint8) {
-	w.W.AppendUInt8String(n)
+	w.uint(uint64(n), false)
 }
 
 func (w *JsonWriter) Uint16(n uint16) {
-	w.W.AppendUInt16String(n)
+	w.uint(uint64(n), false)
 }
 
 func (w *JsonWriter) Uint32(n uint32) {
-	w.W.AppendUInt32String(n)
+	w.uint(uint64(n), false)
 }
 
 func (w *JsonWriter) Uint(n uint) {
-	w.W.AppendUIntString(n)
+	w.uint(uint64(n), false)
 }
 
 func (w *JsonWriter) Uint64(n uint64) {
-	w.W.AppendUInt64String(n)
+	w.uint(n, false)
 }
 
 func (w *JsonWriter) Int8(n int8) {
-	w.W.AppendInt8String(n)
+	w.int(int64(n), false)
 }
 
 func (w *JsonWriter) Int16(n int16) {
-	w.W.AppendInt16String(n)
+	w.int(int64(n), false)
 }
 
 func (w *JsonWriter) Int32(n int32) {
-	w.W.AppendInt32String(n)
+	w.int(int64(n), false)
 }
 
 func (w *JsonWriter) Int(n int) {
-	w.W.AppendIntString(n)
+	w.int(int64(n), false)
 }
 
 func (w *JsonWriter) Int64(n int64) {
-	w.W.AppendInt64String(n)
+	w.int(n, false)
 }
 
 func (w *JsonWriter) Uint8Str(n uint8) {
-	w.W.AppendByte('"')
-	w.W.AppendUInt8String(n)
-	w.W.AppendByte('"')
+	w.uint(uint64(n), true)
 }
 
 func (w *JsonWriter) Uint16Str(n uint16) {
-	w.W.AppendByte('"')
-	w.W.AppendUInt16String(n)
-	w.W.AppendByte('"')
+	w.uint(uint64(n), true)
 }
 
 func (w *JsonWriter) Uint32Str(n uint32) {
-	w.W.AppendByte('"')
-	w.W.AppendUInt32String(n)
-	w.W.AppendByte('"')
+	w.uint(uint64(n), true)
 }
 
 func (w *JsonWriter) UintStr(n uint) {
-	w.W.AppendByte('"')
-	w.W.AppendUIntString(n)
-	w.W.AppendByte('"')
+	w.uint(uint64(n), true)
 }
 
 func (w *JsonWriter) Uint64Str(n uint64) {
-	w.W.AppendByte('"')
-	w.W.AppendUInt64String(n)
-	w.W.AppendByte('"')
+	w.uint(n, true)
 }
 
 func (w *JsonWriter) UintptrStr(n uintptr) {
-	w.W.AppendByte('"')
-	w.W.AppendUInt64String(uint64(n))
-	w.W.AppendByte('"')
+	w.uint(uint64(n), true)
 }
 
 func (w *JsonWriter) Int8Str(n int8) {
-	w.W.AppendByte('"')
-	w.W.AppendInt8String(n)
-	w.W.AppendByte('"')
+	w.int(int64(n), true)
 }
 
 func (w *JsonWriter) Int16Str(n int16) {
-	w.W.AppendByte('"')
-	w.W.AppendInt16String(n)
-	w.W.AppendByte('"')
+	w.int(int64(n), true)
 }
 
 func (w *JsonWriter) Int32Str(n int32) {
-	w.W.AppendByte('"')
-	w.W.AppendInt32String(n)
-	w.W.AppendByte('"')
+	w.int(int64(n), true)
 }
 
 func (w *JsonWriter) IntStr(n int) {
-	w.W.AppendByte('"')
-	w.W.AppendIntString(n)
-	w.W.AppendByte('"')
+	w.int(int64(n), true)
 }
 
 func (w *JsonWriter) Int64Str(n int64) {
-	w.W.AppendByte('"')
-	w.W.AppendInt64String(n)
-	w.W.AppendByte('"')
+	w.int(n, true)
+}
+
+func (w *JsonWriter) int(n int64, quote bool) {
+	var b [24]byte
+	w.number(strconv.AppendInt(b[:0], n, 10), quote)
+}
+
+func (w *JsonWriter) uint(n uint64, quote bool) {
+	var b [24]byte
+	w.number(strconv.AppendUint(b[:0], n, 10), quote)
+}
+
+func (w *JsonWriter) number(b []byte, quote bool) {
+	if quote {
+		w.W.AppendByte('"')
+		w.W.AppendBytes(b)
+		w.W.AppendByte('"')
+	} else {
+		w.W.AppendBytes(b)
+	}
 }
 
 func (w *JsonWriter) Float32(n float32) {
-	w.W.AppendFloat32String(n)
+	w.float(float64(n), 32, w.decimals(), false)
 }
 
 func (w *JsonWriter) Float32Str(n float32) {
-	w.W.AppendByte('"')
-	w.W.AppendFloat32String(n)
-	w.W.AppendByte('"')
+	w.float(float64(n), 32, w.decimals(), true)
 }
 
 func (w *JsonWriter) Float64(n float64) {
-	w.W.AppendFloat64String(n)
+	w.float(n, 64, w.decimals(), false)
 }
 
 func (w *JsonWriter) Float64Str(n float64) {
-	w.W.AppendByte('"')
-	w.W.AppendFloat64String(n)
-	w.W.AppendByte('"')
+	w.float(n, 64, w.decimals(), true)
+}
+
+// Float64Fixed writes n with exactly decimals digits after the decimal point, as prices
+// are usually displayed.
+func (w *JsonWriter) Float64Fixed(n float64, decimals int) {
+	if decimals < 0 {
+		decimals = 0
+	}
+	w.float(n, 64, decimals, false)
+}
+
+// shortestDecimals asks float for the shortest representation of a float.
+const shortestDecimals = -1
+
+// decimals returns the decimals of floats written per FloatDecimals.
+func (w *JsonWriter) decimals() int {
+	if w.FloatDecimals > 0 {
+		return w.FloatDecimals
+	}
+	return shortestDecimals
+}
+
+// float writes n with decimals digits after the decimal point or, when decimals is
+// shortestDecimals, the shortest representation that parses back to n. Like
+// encoding/json, the exponent form is only used for very small or large magnitudes.
+func (w *JsonWriter) float(n float64, bits int, decimals int, quote bool) {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		w.nonFinite(n)
+		return
+	}
+	var b [64]byte
+	var out []byte
+	if decimals >= 0 {
+		out = strconv.AppendFloat(b[:0], n, 'f', decimals, bits)
+	} else {
+		format := byte('f')
+		if abs := math.Abs(n); abs != 0 {
+			if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+				format = 'e'
+			}
+		}
+		out = strconv.AppendFloat(b[:0], n, format, -1, bits)
+		if format == 'e' {
+			// Clean up e-09 to e-9.
+			if l := len(out); l >= 4 && out[l-4] == 'e' && out[l-3] == '-' && out[l-2] == '0' {
+				out[l-2] = out[l-1]
+				out = out[:l-1]
+			}
+		}
+	}
+	w.number(out, quote)
+}
+
+func (w *JsonWriter) nonFinite(n float64) {
+	switch w.NonFinite {
+	case NonFiniteString:
+		switch {
+		case math.IsNaN(n):
+			w.W.AppendString(`"NaN"`)
+		case n > 0:
+			w.W.AppendString(`"+Inf"`)
+		default:
+			w.W.AppendString(`"-Inf"`)
+		}
+	case NonFiniteError:
+		if w.Error == nil {
+			w.Error = &UnsupportedValueError{Value: n}
+		}
+		w.W.AppendString("null")
+	default:
+		w.W.AppendString("null")
+	}
 }
 
 func (w *JsonWriter) Bool(v bool) {
@@ -354,3 +458,100 @@ func (w *JsonWriter) base64(in []byte) {
 		w.W.AppendByte(byte(padChar))
 	}
 }
+
+// FlushTo writes the buffer to out and empties it once it holds at least
+// FlushThreshold bytes. Calling it between values streams large outputs in chunks of
+// about FlushThreshold bytes.
+func (w *JsonWriter) FlushTo(out io.Writer) (int, error) {
+	if w.W.Len() < w.FlushThreshold {
+		return 0, nil
+	}
+	return w.Flush(out)
+}
+
+// Flush writes the buffer to out and empties it.
+func (w *JsonWriter) Flush(out io.Writer) (int, error) {
+	if w.Error != nil {
+		return 0, w.Error
+	}
+	if w.W.Len() == 0 {
+		return 0, nil
+	}
+	data := w.W.Bytes()
+	if len(w.Indent) > 0 {
+		data = w.indent.indent(data, w.Indent)
+	}
+	n, err := out.Write(data)
+	w.W.Reset()
+	return n, err
+}
+
+// jsonIndenter pretty-prints compact JSON. Its state carries over between calls so the
+// input may be split anywhere.
+type jsonIndenter struct {
+	out      []byte
+	depth    int
+	inString bool
+	escape   bool
+	// open is set after '{' or '[' until the next byte shows whether the value is
+	// empty.
+	open bool
+}
+
+func (d *jsonIndenter) newline(indent string) {
+	d.out = append(d.out, '\n')
+	for i := 0; i < d.depth; i++ {
+		d.out = append(d.out, indent...)
+	}
+}
+
+func (d *jsonIndenter) indent(data []byte, indent string) []byte {
+	d.out = d.out[:0]
+	for _, c := range data {
+		if d.inString {
+			d.out = append(d.out, c)
+			switch {
+			case d.escape:
+				d.escape = false
+			case c == '\\':
+				d.escape = true
+			case c == '"':
+				d.inString = false
+			}
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			continue
+		}
+		if d.open {
+			d.open = false
+			if c == '}' || c == ']' {
+				d.depth--
+				d.out = append(d.out, c)
+				continue
+			}
+			d.newline(indent)
+		}
+		switch c {
+		case '{', '[':
+			d.out = append(d.out, c)
+			d.depth++
+			d.open = true
+		case '}', ']':
+			d.depth--
+			d.newline(indent)
+			d.out = append(d.out, c)
+		case ',':
+			d.out = append(d.out, c)
+			d.newline(indent)
+		case ':':
+			d.out = append(d.out, ':', ' ')
+		case '"':
+			d.out = append(d.out, c)
+			d.inString = true
+		default:
+			d.out = append(d.out, c)
+		}
+	}
+	return d.out
+}
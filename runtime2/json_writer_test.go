@@ -1,17 +1,23 @@
 package runtime2
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"testing"
 	"unsafe"
+
+	"github.com/moontrade/nogc"
 )
 
 func TestJsonWriter(t *testing.T) {
 	w := JsonWriter{
-		W: Buffer{
-			b: make([]byte, 1024),
-		},
+		W: nogc.AllocBytes(1024),
 	}
+	defer w.W.Free()
 
 	w.RawByte('{')
 	w.RawByte('"')
@@ -21,8 +27,88 @@ func TestJsonWriter(t *testing.T) {
 	w.Int64(10)
 	w.RawByte('}')
 
-	r := JsonLexer{Data: w.W.Take()}
+	r := JsonLexer{Data: w.W.Bytes()}
 	fmt.Println(*(*string)(unsafe.Pointer(&r.Data)))
 
 	r.Delim('{')
 }
+
+func TestJsonWriterNumbers(t *testing.T) {
+	w := JsonWriter{W: nogc.AllocBytes(64)}
+	defer w.W.Free()
+	w.RawByte('[')
+	w.Int64(-10)
+	w.RawByte(',')
+	w.Uint64Str(18446744073709551615)
+	w.RawByte(',')
+	w.Float64(0.1)
+	w.RawByte(',')
+	w.Float64(1e21)
+	w.RawByte(',')
+	w.Float64(0.000000123)
+	w.RawByte(',')
+	w.Float32(1.1)
+	w.RawByte(',')
+	w.Float64Fixed(100.5, 2)
+	w.RawByte(',')
+	w.Float64Fixed(1.75, 0)
+	w.RawByte(',')
+	w.Float64(math.NaN())
+	w.RawByte(']')
+	if got, expected := string(w.W.Bytes()), `[-10,"18446744073709551615",0.1,1e+21,1.23e-7,1.1,100.50,2,null]`; got != expected {
+		t.Fatalf("got %s, expected %s", got, expected)
+	}
+	if w.Error != nil {
+		t.Fatal(w.Error)
+	}
+
+	w.W.Reset()
+	w.FloatDecimals = 3
+	w.NonFinite = NonFiniteString
+	w.Float64(2)
+	w.RawByte(',')
+	w.Float64Str(math.Inf(-1))
+	if got := string(w.W.Bytes()); got != `2.000,"-Inf"` {
+		t.Fatalf("got %s", got)
+	}
+
+	w.W.Reset()
+	w.NonFinite = NonFiniteError
+	w.Float32(float32(math.Inf(1)))
+	var unsupported *UnsupportedValueError
+	if !errors.As(w.Error, &unsupported) {
+		t.Fatalf("error = %v", w.Error)
+	}
+	if _, err := w.Flush(io.Discard); err != w.Error {
+		t.Fatalf("Flush = %v", err)
+	}
+}
+
+func TestJsonWriterIndent(t *testing.T) {
+	w := JsonWriter{W: nogc.AllocBytes(64), Indent: "  ", FlushThreshold: 16}
+	defer w.W.Free()
+	var out bytes.Buffer
+	w.RawString(`{"a":[1,2],"b":{},"c":[],"d":"x, {y}: \"z\"","e":[{"f":null}]}`)
+	// Flushing in chunks splits tokens and strings.
+	data := append([]byte(nil), w.W.Bytes()...)
+	w.W.Reset()
+	for _, c := range data {
+		w.RawByte(c)
+		if _, err := w.FlushTo(&out); err != nil {
+			t.Fatal(err)
+		}
+		if w.Size() >= 16 {
+			t.Fatalf("buffer of %d bytes not flushed", w.Size())
+		}
+	}
+	if _, err := w.Flush(&out); err != nil {
+		t.Fatal(err)
+	}
+	var expected bytes.Buffer
+	if err := json.Indent(&expected, data, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected.String() {
+		t.Fatalf("got\n%s\nexpected\n%s", out.String(), expected.String())
+	}
+}
@@ -47,21 +47,16 @@ func (s *String32) Clone() *String32 {
 func (s *String32) Mut() *String32Mut {
 	return *(**String32Mut)(unsafe.Pointer(&s))
 }
-func (s *String32) ReadFrom(r io.Reader) error {
-	n, err := io.ReadFull(r, (*(*[32]byte)(unsafe.Pointer(&s)))[0:])
-	if err != nil {
-		return err
-	}
-	if n != 32 {
-		return io.ErrShortBuffer
-	}
-	return nil
+func (s *String32) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
 }
-func (s *String32) WriteTo(w io.Writer) (n int, err error) {
-	return w.Write((*(*[32]byte)(unsafe.Pointer(&s)))[0:])
+func (s *String32) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
 }
 func (s *String32) MarshalBinaryTo(b []byte) []byte {
-	return append(b, (*(*[32]byte)(unsafe.Pointer(&s)))[0:]...)
+	return append(b, s[0:]...)
 }
 func (s *String32) MarshalBinary() ([]byte, error) {
 	return s[0:s.Len()], nil
@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	AnnotationDeprecated = "deprecated"
+	// AnnotationIntern marks string fields whose values repeat, such as symbols, so
+	// readers decode them through an intern table.
+	AnnotationIntern = "intern"
+)
+
+// parseAnnotation parses an annotation line such as @deprecated or @deprecated("use Bar").
+// The annotation is held until the next declaration, field or option takes it.
+func (p *Parser) parseAnnotation(line string) error {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || line[0] != '@' {
+		return p.error("expected '@'")
+	}
+	line = line[1:]
+	end := 0
+	for end < len(line) && (IsLetter(line[end]) || IsNumeral(line[end]) || line[end] == '_') {
+		end++
+	}
+	if end == 0 {
+		return p.error("expected annotation name")
+	}
+	a := &Annotation{
+		Line: p.lineCount,
+		Name: line[:end],
+	}
+	line = strings.TrimSpace(line[end:])
+	if len(line) > 0 && line[0] == '(' {
+		close := strings.LastIndexByte(line, ')')
+		if close < 0 {
+			return p.error("expected ')' after annotation value")
+		}
+		value := strings.TrimSpace(line[1:close])
+		switch {
+		case len(value) == 0:
+		case value[0] == '"':
+			s, err := strconv.Unquote(value)
+			if err != nil {
+				return p.error("invalid annotation string %s: %s", value, err.Error())
+			}
+			a.Value = s
+		default:
+			a.Value = Expression(value)
+		}
+		line = strings.TrimSpace(line[close+1:])
+	}
+	if len(line) > 0 && !strings.HasPrefix(line, "//") {
+		return p.error("expected EOL after annotation '@%s'", a.Name)
+	}
+	p.annotations = append(p.annotations, a)
+	return nil
+}
+
+// takeAnnotations returns the pending annotations and clears them.
+func (p *Parser) takeAnnotations() []*Annotation {
+	annotations := p.annotations
+	p.annotations = nil
+	return annotations
+}
+
+// deprecation returns whether the annotations include @deprecated and its message.
+func deprecation(annotations []*Annotation) (bool, string) {
+	for _, a := range annotations {
+		if a.Name != AnnotationDeprecated {
+			continue
+		}
+		msg, _ := a.Value.(string)
+		return true, msg
+	}
+	return false, ""
+}
+
+// interning returns whether the annotations include @intern.
+func interning(annotations []*Annotation) bool {
+	for _, a := range annotations {
+		if a.Name == AnnotationIntern {
+			return true
+		}
+	}
+	return false
+}
+
+// internable returns whether t is a string or a list of strings.
+func internable(t *Type) bool {
+	for t.Kind == KindList && t.Element != nil {
+		t = t.Element
+	}
+	return t.Kind == KindString
+}
+
+// DeprecatedUses returns the deprecated uses of every file in the schema ordered by path.
+func (s *Schema) DeprecatedUses() []error {
+	paths := make([]string, 0, len(s.Files))
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var errs []error
+	for _, path := range paths {
+		if f := s.Files[path]; f != nil {
+			errs = append(errs, f.DeprecatedUses()...)
+		}
+	}
+	return errs
+}
+
+// DeprecatedUses returns an error for each definition in the file that is not itself
+// deprecated but refers to a deprecated struct, enum or enum option.
+func (f *File) DeprecatedUses() []error {
+	var errs []error
+	for _, st := range f.Structs {
+		if st.Deprecated {
+			continue
+		}
+		for _, field := range st.Fields {
+			if field.Deprecated || field.Type.Kind == KindPad {
+				continue
+			}
+			if err := deprecatedUse(field.Type, fmt.Sprintf("field '%s.%s'", st.Name, field.Name)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, cst := range f.Consts {
+		if err := deprecatedUse(cst.Type, fmt.Sprintf("const '%s'", cst.Name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func deprecatedUse(t *Type, what string) error {
+	used := func(kind, name, msg string) error {
+		if len(msg) > 0 {
+			return fmt.Errorf("%s:%d %s uses deprecated %s '%s': %s", t.File.Path, t.Line.Number, what, kind, name, msg)
+		}
+		return fmt.Errorf("%s:%d %s uses deprecated %s '%s'", t.File.Path, t.Line.Number, what, kind, name)
+	}
+	if option, ok := t.Init.(*EnumOption); ok && option.Deprecated {
+		return used("enum option", option.Enum.Name+"."+option.Name, option.DeprecatedMessage)
+	}
+	for e := t; e != nil; e = e.Element {
+		switch e.Kind {
+		case KindStruct:
+			if e.Struct != nil && e.Struct.Deprecated {
+				return used("struct", e.Struct.Name, e.Struct.DeprecatedMessage)
+			}
+		case KindEnum:
+			if e.Enum != nil && e.Enum.Deprecated {
+				return used("enum", e.Enum.Name, e.Enum.DeprecatedMessage)
+			}
+		}
+		if e.Kind != KindList {
+			break
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ func resolveEnumInit(t *Type, init interface{}) error {
 	}
 	option = enum.GetOption(name)
 	if option != nil {
+		t.Init = option
 		return nil
 	}
 	return fmt.Errorf("%s:%d invalid enum option: %s:%d %s does not have an option named: %s",
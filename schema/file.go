@@ -12,10 +12,16 @@ var (
 )
 
 const (
-	maxDepth   = 10
-	FileSuffix = ".wap"
+	maxDepth       = 10
+	FileSuffix     = ".wap"
+	MoonFileSuffix = ".moon"
 )
 
+// IsSchemaFile reports whether the path names a schema file.
+func IsSchemaFile(path string) bool {
+	return strings.HasSuffix(path, MoonFileSuffix) || strings.HasSuffix(path, FileSuffix)
+}
+
 type File struct {
 	Dir          string
 	Name         string
@@ -35,23 +41,28 @@ type File struct {
 	Types        map[string]*Type
 	ImportMap    map[string]*Import
 	Strings      map[string][]*Type
+	EndTrivia    []string // Comment and blank lines after the last declaration
 }
 
 type Imports struct {
-	Line     Line
-	Comments []string
-	List     []*Import
+	Line      Line
+	Comments  []string
+	List      []*Import
+	Trivia    []string
+	EndTrivia []string // Comment and blank lines before the closing parenthesis
 }
 
 type Import struct {
-	Imports  *Imports
-	Parent   *File
-	Path     string
-	Name     string
-	Alias    string
-	File     *File
-	Comments []string
-	Line     Line
+	Imports     *Imports
+	Parent      *File
+	Path        string
+	Name        string
+	Alias       string
+	File        *File
+	Comments    []string
+	Description string
+	Trivia      []string
+	Line        Line
 }
 
 func (f *File) uniqueName(n string) string {
@@ -90,13 +101,28 @@ func (f *File) createTypeName(t *Type, cycle int) string {
 		return "F32"
 	case KindFloat64:
 		return "F64"
+	case KindDecimal64, KindDecimal128:
+		return DecimalTypeName(t.Kind, t.Scale)
 	case KindString:
+		if t.Len == 0 {
+			return "String"
+		}
 		return fmt.Sprintf("String%d", t.Len)
 	case KindBytes:
+		if t.Len == 0 {
+			return "Bytes"
+		}
 		return fmt.Sprintf("Bytes%d", t.Len)
 	case KindStruct, KindEnum, KindUnion, KindUnknown:
 		return strings.ReplaceAll(Capitalize(t.Name), ".", "_")
 	case KindList:
+		if t.Len == 0 {
+			return fmt.Sprintf("%sList", f.createTypeName(t.Element, cycle+1))
+		}
+		if t.Element.Kind == KindDecimal64 || t.Element.Kind == KindDecimal128 {
+			// Decimal names end in their scale, so the length is set apart from it.
+			return fmt.Sprintf("%s_%dList", f.createTypeName(t.Element, cycle+1), t.Len)
+		}
 		return fmt.Sprintf("%s%dList", f.createTypeName(t.Element, cycle+1), t.Len)
 	case KindMap:
 		return f.uniqueName(fmt.Sprintf("%s%sMap", f.createTypeName(t.Element, cycle+1), f.createTypeName(t.Value, cycle+1)))
@@ -128,12 +154,25 @@ func (f *File) resolveType(t *Type, cycle int) error {
 		t.Name = f.createTypeName(t, 0)
 		t.Size = 4
 		t.Resolved = true
-	case KindInt64, KindUInt64, KindFloat64:
+	case KindInt64, KindUInt64, KindFloat64, KindDecimal64:
 		t.Name = f.createTypeName(t, 0)
 		t.Size = 8
 		t.Resolved = true
+	case KindDecimal128:
+		t.Name = f.createTypeName(t, 0)
+		t.Size = 16
+		t.Resolved = true
 	case KindString, KindBytes:
 		t.Name = f.createTypeName(t, 0)
+		if t.Len == 0 {
+			// Variable length strings are stored in the heap portion of the buffer.
+			if t.Optional {
+				return fmt.Errorf("%s:%d variable length %s cannot be optional", f.Path, t.Line.Number, t.Name)
+			}
+			t.Size = VPointerSize
+			t.Resolved = true
+			return nil
+		}
 		t.Size = t.Len
 		t.Resolved = true
 		if f.Strings == nil {
@@ -154,8 +193,24 @@ func (f *File) resolveType(t *Type, cycle int) error {
 		if err != nil {
 			return err
 		}
+		// The elements are copied into the list which would break their VPointers.
+		if t.Element.IsVariable() {
+			return fmt.Errorf("%s:%d lists cannot contain variable length elements", f.Path, t.Line.Number)
+		}
+		if t.Element.HasVariable() {
+			return fmt.Errorf("%s:%d lists cannot contain '%s' which has variable length fields",
+				f.Path, t.Line.Number, t.Element.Name)
+		}
 		if t.Len == 0 {
-			return fmt.Errorf("%s:%d lists must specify a length greater than 0", f.Path, t.Line.Number)
+			// Variable length list '[]T' stored in the heap portion of the buffer.
+			if t.Optional {
+				return fmt.Errorf("%s:%d variable length lists cannot be optional", f.Path, t.Line.Number)
+			}
+			t.Name = f.createTypeName(t, cycle+1)
+			t.Size = VPointerSize
+			t.ItemSize = t.Element.Size
+			t.Resolved = true
+			return nil
 		}
 
 		t.Name = f.createTypeName(t, cycle+1)
@@ -241,17 +296,7 @@ func (f *File) resolveType(t *Type, cycle int) error {
 			return fmt.Errorf("%s:%d invalid state: type of struct had a nil struct", f.Path, t.Line.Number)
 		}
 		for _, field := range t.Struct.Fields {
-			// Is type imported?
-			if field.Type.Import != nil {
-				if field.Type.Import.File != nil {
-					if err := field.Type.Import.File.resolve(); err != nil {
-						return err
-					}
-					if err := field.Type.Import.File.resolveType(field.Type, cycle+1); err != nil {
-						return err
-					}
-				}
-			} else if err := field.Type.File.resolveType(field.Type, cycle+1); err != nil {
+			if err := field.Type.File.resolveType(field.Type, cycle+1); err != nil {
 				return err
 			}
 		}
@@ -384,17 +429,13 @@ func (f *File) resolveType(t *Type, cycle int) error {
 	case KindUnknown:
 		var found *Type
 		if t.Import != nil {
-			// Look for import
-			imp := f.ImportMap[t.Import.Name]
-			if imp == nil {
-				return fmt.Errorf("could not find import for package: %s", t.Import.Name)
+			// The imported file is only known once the schema resolves imports.
+			if t.Import.File == nil {
+				return fmt.Errorf("%s:%d import '%s' is not resolved", t.File.Path, t.Line.Number, t.Import.Path)
 			}
-			if imp.Parent == nil {
-				return errNotFound
-			}
-			found = imp.Parent.Types[t.Name]
+			found = t.Import.File.Types[t.Name]
 			if found == nil {
-				return fmt.Errorf("%s:%d type not found: %s.%s", t.File.Path, t.Line.Number, imp.Alias, t.Name)
+				return fmt.Errorf("%s:%d type not found: %s.%s", t.File.Path, t.Line.Number, t.Import.Alias, t.Name)
 			}
 		} else {
 			found = f.Types[t.Name]
@@ -412,10 +453,18 @@ func (f *File) resolveType(t *Type, cycle int) error {
 		init := t.Init
 		imp := t.Import
 		file := t.File
+		line := t.Line
+		field := t.Field
+		comments, description := t.Comments, t.Description
+		expr, initExpr, trivia := t.Expr, t.InitExpr, t.Trivia
 		*t = *found
 		t.File = file
+		t.Line = line
+		t.Field = field
 		t.Optional = optional
 		t.Import = imp
+		t.Comments, t.Description = comments, description
+		t.Expr, t.InitExpr, t.Trivia = expr, initExpr, trivia
 
 		if init != nil {
 			switch t.Kind {
@@ -435,6 +484,23 @@ func (f *File) resolveType(t *Type, cycle int) error {
 	return nil
 }
 
+// resolveConstInit replaces an initial value naming a const with the *Const.
+func (f *File) resolveConstInit(t *Type) error {
+	name, ok := t.Init.(Expression)
+	if !ok {
+		return nil
+	}
+	found := f.Types[string(name)]
+	if found == nil || found.Const == nil {
+		return fmt.Errorf("%s:%d const not found: %s", f.Path, t.Line.Number, name)
+	}
+	if found.Kind != t.Kind {
+		return fmt.Errorf("%s:%d const '%s' type mismatch: %s <> %s", f.Path, t.Line.Number, name, found.Name, t.Name)
+	}
+	t.Init = found.Const
+	return nil
+}
+
 func (f *File) resolve() error {
 	var errs []error
 	for _, t := range f.Types {
@@ -447,6 +513,14 @@ func (f *File) resolve() error {
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	// Resolve value literals referencing consts
+	for _, st := range f.Structs {
+		for _, field := range st.Fields {
+			if err := f.resolveConstInit(field.Type); err != nil {
+				return err
+			}
+		}
+	}
 	// Consts
 	//	// Resolve value literals as consts
 	//	for resolveType := range f.resolveTypes {
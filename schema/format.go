@@ -0,0 +1,290 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format returns the canonical source of a parsed file. Declarations are written in
+// source order with tab indentation, and the fields, options and consts of a block are
+// aligned in columns. Comments are kept where they were written and runs of blank lines
+// are collapsed into one.
+func Format(f *File) []byte {
+	type decl struct {
+		line  int
+		block bool
+		write func(w *formatter)
+	}
+	var decls []decl
+	for _, imports := range f.Imports {
+		imports := imports
+		decls = append(decls, decl{imports.Line.Number, true, func(w *formatter) { w.imports(imports) }})
+	}
+	for _, cst := range f.Consts {
+		cst := cst
+		decls = append(decls, decl{cst.Type.Line.Number, false, func(w *formatter) { w.constant(cst) }})
+	}
+	for _, enum := range f.Enums {
+		enum := enum
+		decls = append(decls, decl{enum.Type.Line.Number, true, func(w *formatter) { w.enum(enum) }})
+	}
+	for _, union := range f.Unions {
+		union := union
+		decls = append(decls, decl{union.Type.Line.Number, true, func(w *formatter) { w.union(union) }})
+	}
+	for _, st := range f.Structs {
+		st := st
+		decls = append(decls, decl{st.Type.Line.Number, true, func(w *formatter) { w.structure(st) }})
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return decls[i].line < decls[j].line
+	})
+
+	w := &formatter{blank: true}
+	for i, d := range decls {
+		// Blocks are always separated by a blank line, consts only when they were.
+		if i > 0 && (d.block || decls[i-1].block) {
+			w.blankLine()
+		}
+		d.write(w)
+	}
+	w.trivia("", f.EndTrivia, true)
+	w.flush()
+	return w.buf.Bytes()
+}
+
+// FormatSource parses a schema file and returns its canonical source.
+func FormatSource(path string, src []byte) ([]byte, error) {
+	f, err := ParseFile(path, path, src)
+	if err != nil {
+		return nil, err
+	}
+	return Format(f), nil
+}
+
+// formatter writes lines and aligns the cells of consecutive rows in columns until a
+// blank line ends the section.
+type formatter struct {
+	buf   bytes.Buffer
+	rows  []formatRow
+	blank bool // Whether the last line is blank or the start of a file or block
+}
+
+type formatRow struct {
+	indent string
+	cells  []string // nil for a line that is written as is
+	text   string
+}
+
+func (w *formatter) line(indent, text string) {
+	w.rows = append(w.rows, formatRow{indent: indent, text: text})
+	w.blank = false
+}
+
+func (w *formatter) row(indent string, cells ...string) {
+	w.rows = append(w.rows, formatRow{indent: indent, cells: cells})
+	w.blank = false
+}
+
+// blankLine ends the current section with a single blank line.
+func (w *formatter) blankLine() {
+	if w.blank {
+		return
+	}
+	w.flush()
+	w.buf.WriteByte('\n')
+	w.blank = true
+}
+
+// open writes the first line of a block.
+func (w *formatter) open(text string) {
+	w.line("", text)
+	w.blank = true
+}
+
+func (w *formatter) close(text string) {
+	w.line("", text)
+	w.flush()
+}
+
+// trivia writes comment lines and blank lines. Blank lines at the end are dropped
+// when end is set since the block or file ends there.
+func (w *formatter) trivia(indent string, trivia []string, end bool) {
+	if end {
+		for len(trivia) > 0 && len(trivia[len(trivia)-1]) == 0 {
+			trivia = trivia[:len(trivia)-1]
+		}
+	}
+	for _, t := range trivia {
+		if len(t) == 0 {
+			w.blankLine()
+			continue
+		}
+		w.line(indent, strings.TrimRight(t, " \t"))
+	}
+}
+
+func (w *formatter) annotations(indent string, annotations []*Annotation) {
+	for _, a := range annotations {
+		switch v := a.Value.(type) {
+		case string:
+			w.line(indent, fmt.Sprintf("@%s(%s)", a.Name, strconv.Quote(v)))
+		case Expression:
+			w.line(indent, fmt.Sprintf("@%s(%s)", a.Name, v))
+		default:
+			w.line(indent, "@"+a.Name)
+		}
+	}
+}
+
+// flush writes the pending rows. A column is as wide as its widest cell followed by a
+// cell that is not empty, and columns that are empty in every such row are dropped.
+func (w *formatter) flush() {
+	var widths []int
+	for _, r := range w.rows {
+		last := lastCell(r.cells)
+		for i := 0; i < last; i++ {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if len(r.cells[i]) > widths[i] {
+				widths[i] = len(r.cells[i])
+			}
+		}
+	}
+	for _, r := range w.rows {
+		w.buf.WriteString(r.indent)
+		if r.cells == nil {
+			w.buf.WriteString(r.text)
+			w.buf.WriteByte('\n')
+			continue
+		}
+		last := lastCell(r.cells)
+		for i := 0; i < last; i++ {
+			if widths[i] == 0 {
+				continue
+			}
+			w.buf.WriteString(r.cells[i])
+			w.buf.WriteString(strings.Repeat(" ", widths[i]-len(r.cells[i])+1))
+		}
+		if last >= 0 {
+			w.buf.WriteString(r.cells[last])
+		}
+		w.buf.WriteByte('\n')
+	}
+	w.rows = w.rows[:0]
+}
+
+// lastCell returns the index of the last cell that is not empty or -1.
+func lastCell(cells []string) int {
+	for i := len(cells) - 1; i >= 0; i-- {
+		if len(cells[i]) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *formatter) imports(imports *Imports) {
+	w.trivia("", imports.Trivia, false)
+	if len(imports.List) == 1 && imports.Line.Number == imports.List[0].Line.Number {
+		imp := imports.List[0]
+		w.row("", "import "+importSpec(imp), comment(imp.Description))
+		w.flush()
+		return
+	}
+	w.open("import (")
+	for _, imp := range imports.List {
+		w.trivia("\t", imp.Trivia, false)
+		w.row("\t", importSpec(imp), comment(imp.Description))
+	}
+	w.trivia("\t", imports.EndTrivia, true)
+	w.close(")")
+}
+
+func importSpec(imp *Import) string {
+	if len(imp.Alias) > 0 && imp.Alias != imp.Name {
+		return fmt.Sprintf("%s \"%s\"", imp.Alias, imp.Path)
+	}
+	return fmt.Sprintf("\"%s\"", imp.Path)
+}
+
+func (w *formatter) constant(cst *Const) {
+	w.trivia("", cst.Type.Trivia, false)
+	w.row("", "const", cst.Name, formatExpr(cst.Type.Expr), initCell(cst.Type.InitExpr), comment(cst.Type.Description))
+}
+
+func (w *formatter) enum(enum *Enum) {
+	w.trivia("", enum.Type.Trivia, false)
+	w.annotations("", enum.Annotations)
+	w.open(fmt.Sprintf("enum %s : %s {", enum.Name, enum.Type.Expr))
+	for _, option := range enum.Options {
+		w.trivia("\t", option.Trivia, false)
+		w.annotations("\t", option.Annotations)
+		w.row("\t", option.Name, fmt.Sprintf("= %v", option.Value), comment(option.Description))
+	}
+	w.trivia("\t", enum.EndTrivia, true)
+	w.close("}")
+}
+
+func (w *formatter) union(union *Union) {
+	w.trivia("", union.Type.Trivia, false)
+	w.open(fmt.Sprintf("union %s {", union.Name))
+	for _, option := range union.Options {
+		w.trivia("\t", option.Type.Trivia, false)
+		w.row("\t", option.Name, formatExpr(option.Type.Expr), comment(option.Type.Description))
+	}
+	w.trivia("\t", union.EndTrivia, true)
+	w.close("}")
+}
+
+func (w *formatter) structure(st *Struct) {
+	w.trivia("", st.Type.Trivia, false)
+	w.annotations("", st.Annotations)
+	w.open(fmt.Sprintf("struct %s {", st.Name))
+	for _, field := range st.Fields {
+		// Skip the padding added when the struct was resolved.
+		if field.Type.Kind == KindPad && len(field.Name) == 0 {
+			continue
+		}
+		w.trivia("\t", field.Type.Trivia, false)
+		w.annotations("\t", field.Annotations)
+		number := ""
+		if field.Number > 0 {
+			number = strconv.Itoa(field.Number)
+		}
+		name := field.Name
+		if len(field.Short) > 0 {
+			name += "|" + field.Short
+		}
+		w.row("\t", number, name, formatExpr(field.Type.Expr), initCell(field.Type.InitExpr), comment(field.Type.Description))
+	}
+	w.trivia("\t", st.EndTrivia, true)
+	w.close("}")
+}
+
+func initCell(init string) string {
+	if len(init) == 0 {
+		return ""
+	}
+	return "= " + init
+}
+
+func comment(text string) string {
+	text = strings.TrimRight(text, " \t")
+	if len(text) == 0 {
+		return ""
+	}
+	return "//" + text
+}
+
+// formatExpr writes a type expression without spaces inside the list prefix and with
+// single spaces around a map arrow, e.g. "[8] i64->i64" becomes "[8]i64 -> i64".
+func formatExpr(expr string) string {
+	expr = strings.Join(strings.Fields(expr), "")
+	expr = strings.ReplaceAll(expr, "->", " -> ")
+	return expr
+}
@@ -0,0 +1,199 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// CacheLineSize is the cache line size assumed by layout reports.
+const CacheLineSize = 64
+
+// Layout is a report of the memory layout of a resolved struct.
+type Layout struct {
+	File       string            `json:"file"`
+	Struct     string            `json:"struct"`
+	Size       int               `json:"size"`
+	Align      int               `json:"align"`
+	HeaderSize int               `json:"headerSize"`
+	Padding    int               `json:"padding"`            // Bytes of padding between and after the fields
+	CacheLines int               `json:"cacheLines"`         // Cache lines spanned by one value
+	Crossings  int               `json:"cacheLineCrossings"` // Fields that span a cache line boundary
+	Fields     []LayoutField     `json:"fields"`
+	Suggested  *LayoutSuggestion `json:"suggested,omitempty"`
+}
+
+// LayoutField is a field, the optionals header or padding in a Layout.
+type LayoutField struct {
+	Kind      string `json:"kind"` // "field", "optionals" or "padding"
+	Name      string `json:"name,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Offset    int    `json:"offset"`
+	Size      int    `json:"size"`
+	Align     int    `json:"align"`
+	CacheLine int    `json:"cacheLine"`
+	Crosses   bool   `json:"crossesCacheLine,omitempty"`
+}
+
+// LayoutSuggestion is a field order that makes the struct smaller. Reordering fields
+// changes the binary layout so it is only a suggestion for new structs or versions.
+type LayoutSuggestion struct {
+	Fields []string `json:"fields"`
+	Size   int      `json:"size"`
+	Saves  int      `json:"saves"`
+}
+
+const (
+	LayoutKindField     = "field"
+	LayoutKindOptionals = "optionals"
+	LayoutKindPadding   = "padding"
+)
+
+// Layout reports the offset, size and alignment of every field of the struct, the
+// padding and cache lines it uses and a smaller field order if there is one.
+func (st *Struct) Layout() (*Layout, error) {
+	t := st.Type
+	if !t.Resolved {
+		return nil, fmt.Errorf("%s:%d struct '%s' is not resolved", t.File.Path, t.Line.Number, st.Name)
+	}
+	l := &Layout{
+		File:       Join(t.File.Dir, t.File.Name),
+		Struct:     st.Name,
+		Size:       t.Size,
+		Align:      FieldAlign(t.Size),
+		HeaderSize: t.HeaderSize,
+		CacheLines: (t.Size + CacheLineSize - 1) / CacheLineSize,
+	}
+	if t.HeaderSize > 0 {
+		l.add(LayoutField{Kind: LayoutKindOptionals, Offset: 0, Size: t.HeaderSize, Align: 1})
+	}
+
+	var fields []*StructField
+	for _, field := range st.Fields {
+		if field.Type.Kind == KindPad && len(field.Name) == 0 {
+			l.Padding += field.Type.Size
+			l.add(LayoutField{Kind: LayoutKindPadding, Offset: field.Offset, Size: field.Type.Size, Align: 1})
+			continue
+		}
+		fields = append(fields, field)
+		typeName := field.Type.Name
+		if field.Type.Optional {
+			typeName = "?" + typeName
+		}
+		l.add(LayoutField{
+			Kind:   LayoutKindField,
+			Name:   field.Name,
+			Type:   typeName,
+			Offset: field.Offset,
+			Size:   field.Type.Size,
+			Align:  FieldAlign(field.Type.Size),
+		})
+	}
+	for _, f := range l.Fields {
+		if f.Crosses && f.Kind == LayoutKindField {
+			l.Crossings++
+		}
+	}
+	l.suggest(fields)
+	return l, nil
+}
+
+func (l *Layout) add(f LayoutField) {
+	f.CacheLine = f.Offset / CacheLineSize
+	f.Crosses = f.Size > 0 && (f.Offset+f.Size-1)/CacheLineSize != f.CacheLine
+	l.Fields = append(l.Fields, f)
+}
+
+// suggest looks for a field order that needs less padding. Fields are placed greedily
+// taking the most aligned field that needs no padding at the current offset, which
+// fills the gap after the optionals header with small fields before the larger ones.
+// Ordering by descending alignment is tried as well and the smaller order wins.
+func (l *Layout) suggest(fields []*StructField) {
+	if len(fields) < 2 {
+		return
+	}
+	byAlign := append([]*StructField(nil), fields...)
+	sort.SliceStable(byAlign, func(i, j int) bool {
+		return FieldAlign(byAlign[i].Type.Size) > FieldAlign(byAlign[j].Type.Size)
+	})
+
+	best, bestSize := byAlign, structSize(l.HeaderSize, byAlign)
+	if greedy := greedyOrder(l.HeaderSize, fields); structSize(l.HeaderSize, greedy) < bestSize {
+		best, bestSize = greedy, structSize(l.HeaderSize, greedy)
+	}
+	if bestSize >= l.Size {
+		return
+	}
+	names := make([]string, len(best))
+	for i, field := range best {
+		names[i] = field.Name
+	}
+	l.Suggested = &LayoutSuggestion{Fields: names, Size: bestSize, Saves: l.Size - bestSize}
+}
+
+func greedyOrder(header int, fields []*StructField) []*StructField {
+	remaining := append([]*StructField(nil), fields...)
+	order := make([]*StructField, 0, len(fields))
+	offset := header
+	for len(remaining) > 0 {
+		next, nextPad := -1, 0
+		for i, field := range remaining {
+			align := FieldAlign(field.Type.Size)
+			pad := (align - offset%align) % align
+			if next == -1 || pad < nextPad ||
+				(pad == nextPad && align > FieldAlign(remaining[next].Type.Size)) {
+				next, nextPad = i, pad
+			}
+		}
+		field := remaining[next]
+		offset += nextPad + field.Type.Size
+		order = append(order, field)
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	return order
+}
+
+// structSize computes the size of a struct with the fields in order the same way the
+// struct is laid out when it is resolved.
+func structSize(header int, fields []*StructField) int {
+	size := header
+	for _, field := range fields {
+		if field.Type.Kind != KindPad {
+			align := FieldAlign(field.Type.Size)
+			size += (align - size%align) % align
+		}
+		size += field.Type.Size
+	}
+	return Align(&Type{Kind: KindStruct, Size: size})
+}
+
+// WriteText writes the layout as a table.
+func (l *Layout) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s %s size %d align %d padding %d cache lines %d\n",
+		l.File, l.Struct, l.Size, l.Align, l.Padding, l.CacheLines)
+	fmt.Fprintf(tw, "  offset\tsize\talign\tline\tfield\ttype\n")
+	for _, f := range l.Fields {
+		name := f.Name
+		switch f.Kind {
+		case LayoutKindOptionals, LayoutKindPadding:
+			name = "(" + f.Kind + ")"
+		}
+		line := fmt.Sprintf("%d", f.CacheLine)
+		if f.Crosses {
+			line += "+"
+		}
+		fmt.Fprintf(tw, "  %d\t%d\t%d\t%s\t%s\t%s\n", f.Offset, f.Size, f.Align, line, name, f.Type)
+	}
+	if l.Crossings > 0 {
+		fmt.Fprintf(tw, "  cache line crossings: %d (+)\n", l.Crossings)
+	}
+	if l.Suggested != nil {
+		fmt.Fprintf(tw, "  suggested order: %s (size %d, saves %d)\n",
+			strings.Join(l.Suggested.Fields, ", "), l.Suggested.Size, l.Suggested.Saves)
+	}
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
@@ -1,10 +1,16 @@
 package schema
 
-import "strings"
+import (
+	"math/big"
+	"strings"
+)
 
 const (
 	MapHeaderSize     = 4
 	MapItemHeaderSize = 4
+	// VPointerSize is the size of a relative pointer into the heap portion of a buffer
+	// used by variable length strings, bytes and lists.
+	VPointerSize = 4
 )
 
 type Kind byte
@@ -24,13 +30,24 @@ const (
 	KindFloat64 = Kind(11)
 	KindString  = Kind(12)
 	KindBytes   = Kind(13)
-	KindStruct  = Kind(30) // User-defined structure
-	KindEnum    = Kind(31) // User-defined enum
-	KindUnion   = Kind(33) // User-defined union
-	KindMessage = Kind(40) // User-defined message
-	KindList    = Kind(50)
-	KindMap     = Kind(60)
-	KindPad     = Kind(100) // struct alignment padding
+	// Decimals are integer units of 10^-Scale, an int64 for decimal64(scale) and a
+	// little-endian two's complement int128 for decimal128(scale).
+	KindDecimal64  = Kind(14)
+	KindDecimal128 = Kind(15)
+	KindStruct     = Kind(30) // User-defined structure
+	KindEnum       = Kind(31) // User-defined enum
+	KindUnion      = Kind(33) // User-defined union
+	KindMessage    = Kind(40) // User-defined message
+	KindList       = Kind(50)
+	KindMap        = Kind(60)
+	KindPad        = Kind(100) // struct alignment padding
+)
+
+const (
+	// MaxDecimal64Scale and MaxDecimal128Scale are the largest scales whose units of 1
+	// still fit the integer.
+	MaxDecimal64Scale  = 18
+	MaxDecimal128Scale = 38
 )
 
 type ProtoBufKind byte
@@ -43,8 +60,10 @@ func (k Kind) Size() int {
 		return 2
 	case KindInt32, KindUInt32, KindFloat32:
 		return 4
-	case KindInt64, KindUInt64, KindFloat64:
+	case KindInt64, KindUInt64, KindFloat64, KindDecimal64:
 		return 8
+	case KindDecimal128:
+		return 16
 	}
 	return -1
 }
@@ -67,6 +86,28 @@ var (
 type Nil struct{}
 type ConstVal string
 
+// Decimal is the initial value of a decimal, Units/10^Scale.
+type Decimal struct {
+	Units *big.Int
+	Scale int
+}
+
+// String returns the decimal with exactly Scale decimals.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.Units).String()
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	s := digits
+	if d.Scale > 0 {
+		s = digits[:len(digits)-d.Scale] + "." + digits[len(digits)-d.Scale:]
+	}
+	if d.Units.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
 type Package struct {
 	Name  string
 	Files []*File
@@ -90,6 +131,11 @@ type Enum struct {
 	Type      *Type
 	Options   []*EnumOption
 	optionMap map[string]*EnumOption
+	EndTrivia []string // Comment and blank lines before the closing brace
+
+	Annotations       []*Annotation
+	Deprecated        bool
+	DeprecatedMessage string
 }
 
 func (e *Enum) OptionMap() map[string]*EnumOption {
@@ -107,14 +153,26 @@ func (e *Enum) GetOption(name string) *EnumOption {
 	return e.OptionMap()[name]
 }
 
+// Doc returns the comments above the option followed by the description to the right of it.
+func (o *EnumOption) Doc() []string {
+	if len(o.Description) == 0 {
+		return o.Comments
+	}
+	doc := make([]string, 0, len(o.Comments)+1)
+	return append(append(doc, o.Comments...), o.Description)
+}
+
 type EnumOption struct {
 	Enum              *Enum
 	Name              string
 	Comments          []string
+	Description       string
+	Trivia            []string
 	Value             interface{}
 	Line              Line
 	Deprecated        bool
 	DeprecatedMessage string
+	Annotations       []*Annotation
 }
 
 type Const struct {
@@ -162,10 +220,11 @@ type Map struct {
 }
 
 type Union struct {
-	Name     string
-	Comments []string
-	Type     *Type
-	Options  []*UnionOption
+	Name      string
+	Comments  []string
+	Type      *Type
+	Options   []*UnionOption
+	EndTrivia []string // Comment and blank lines before the closing brace
 }
 
 type UnionOption struct {
@@ -180,6 +239,20 @@ type ImportedName struct {
 	Name    string
 }
 
+// PrimitiveNames are the type names KindOf recognizes. The string and bytes names also
+// take a fixed length suffix such as string16 and the decimal names require a scale
+// such as decimal64(8).
+var PrimitiveNames = []string{
+	"bool", "boolean",
+	"byte", "u8", "uint8", "i8", "int8",
+	"i16", "int16", "short", "u16", "uint16", "ushort",
+	"i32", "int32", "int", "u32", "uint32", "uint",
+	"i64", "int64", "long", "u64", "uint64", "ulong",
+	"f32", "float32", "float", "f64", "float64", "double",
+	"decimal64", "decimal128",
+	"string", "bytes",
+}
+
 func KindOf(name string) Kind {
 	if strings.Index(name, "string") == 0 {
 		return KindString
@@ -187,6 +260,12 @@ func KindOf(name string) Kind {
 	if strings.Index(name, "bytes") == 0 {
 		return KindBytes
 	}
+	if strings.Index(name, "decimal128") == 0 {
+		return KindDecimal128
+	}
+	if strings.Index(name, "decimal64") == 0 {
+		return KindDecimal64
+	}
 
 	switch name {
 	case "i8", "int8":
@@ -208,7 +287,7 @@ func KindOf(name string) Kind {
 
 	case "f32", "float32", "float":
 		return KindFloat32
-	case "f64", "float64", "double", "decimal":
+	case "f64", "float64", "double":
 		return KindFloat64
 
 	case "bool", "boolean":
@@ -24,6 +24,9 @@ type Parser struct {
 	content   string
 	comments  []string
 	file      *File
+
+	annotations []*Annotation // Pending annotations for the next declaration
+	trivia      []string      // Pending comment and blank lines for the next declaration
 }
 
 type Expression string
@@ -45,7 +48,7 @@ func loadFromFS(dirOrFile string) (*Schema, error) {
 			if info.IsDir() {
 				return nil
 			}
-			if !strings.HasSuffix(path, FileSuffix) {
+			if !IsSchemaFile(path) {
 				return nil
 			}
 
@@ -142,7 +145,7 @@ func loadFromFS(dirOrFile string) (*Schema, error) {
 		}); err != nil {
 			return nil, err
 		}
-	} else if strings.HasSuffix(dirOrFile, FileSuffix) {
+	} else if IsSchemaFile(dirOrFile) {
 		var (
 			file *File
 			data []byte
@@ -151,16 +154,16 @@ func loadFromFS(dirOrFile string) (*Schema, error) {
 		if err != nil {
 			return nil, err
 		}
-		file, err = ParseFile(dirOrFile, dirOrFile, data)
-		result.Files[dirOrFile] = file
+		file, err = ParseFile(dirOrFile, filepath.Base(dirOrFile), data)
+		result.Files = map[string]*File{filepath.Base(dirOrFile): file}
 		if err != nil {
 			if file != nil {
 				file.Err = err
 			}
 			result.Errors = append(result.Errors, err)
-		} else {
-			result.Files = make(map[string]*File)
 		}
+	} else {
+		return nil, fmt.Errorf("%s is not a schema file", dirOrFile)
 	}
 
 	if len(result.Errors) > 0 {
@@ -210,6 +213,13 @@ func (p *Parser) nextLine() (string, error) {
 	return line, nil
 }
 
+// takeTrivia returns the pending comment and blank lines and clears them.
+func (p *Parser) takeTrivia() []string {
+	trivia := p.trivia
+	p.trivia = nil
+	return trivia
+}
+
 func (p *Parser) error(msg string, args ...interface{}) error {
 	return fmt.Errorf("%s:%d %s", p.file.Path, p.lineCount, fmt.Sprintf(msg, args...))
 }
@@ -222,11 +232,20 @@ func (p *Parser) Parse() (*File, error) {
 		line, err := p.nextLine()
 		if err != nil {
 			if err == io.EOF {
+				if len(p.annotations) > 0 {
+					return nil, fmt.Errorf("%s:%d annotation '@%s' is not followed by a declaration",
+						f.Path, p.annotations[0].Line, p.annotations[0].Name)
+				}
+				f.EndTrivia = p.takeTrivia()
 				_ = f.resolve()
 				return f, nil
 			}
 			return nil, err
 		}
+		if len(strings.TrimSpace(line)) == 0 {
+			p.trivia = append(p.trivia, "")
+			continue
+		}
 		mark := 0
 
 	loop:
@@ -243,6 +262,14 @@ func (p *Parser) Parse() (*File, error) {
 					return nil, p.error("expected '/' after first '/'")
 				}
 				comments = append(comments, line[2:])
+				p.trivia = append(p.trivia, strings.TrimSpace(line))
+				break loop
+
+			// annotation
+			case '@':
+				if err := p.parseAnnotation(line); err != nil {
+					return nil, err
+				}
 				break loop
 
 			// package
@@ -256,6 +283,9 @@ func (p *Parser) Parse() (*File, error) {
 
 			// import
 			case 'i':
+				if len(p.annotations) > 0 {
+					return nil, p.error("annotations are not supported on imports")
+				}
 				err := p.parseImports(line, comments)
 				if err != nil {
 					return nil, err
@@ -267,6 +297,9 @@ func (p *Parser) Parse() (*File, error) {
 
 			// const
 			case 'c':
+				if len(p.annotations) > 0 {
+					return nil, p.error("annotations are not supported on consts")
+				}
 				cst, err := p.parseConst(line, comments)
 				if err != nil {
 					return nil, err
@@ -304,6 +337,9 @@ func (p *Parser) Parse() (*File, error) {
 
 			// union
 			case 'u':
+				if len(p.annotations) > 0 {
+					return nil, p.error("annotations are not supported on unions")
+				}
 				union, err := p.parseUnion(line, comments)
 				if err != nil {
 					return nil, err
@@ -377,6 +413,7 @@ func (p *Parser) parseImport(line string, comments []string) (*Import, error) {
 	name := ""
 	path := ""
 	alias := ""
+	description := ""
 
 loop:
 	for i := 0; i < len(line); i++ {
@@ -482,7 +519,7 @@ loop:
 		case StateComment:
 			switch c {
 			case '/':
-				comments = append(comments, line[i+1:])
+				description = line[i+1:]
 				break loop
 			default:
 				return nil, p.error("expected comment")
@@ -510,10 +547,12 @@ loop:
 			Begin:  p.mark,
 			End:    p.index,
 		},
-		Name:     name,
-		Path:     path,
-		Alias:    alias,
-		Comments: comments,
+		Name:        name,
+		Path:        path,
+		Alias:       alias,
+		Comments:    comments,
+		Description: description,
+		Trivia:      p.takeTrivia(),
 	}, nil
 }
 
@@ -533,6 +572,7 @@ func (p *Parser) parseImports(line string, comments []string) error {
 			Begin:  p.mark,
 			End:    p.index,
 		},
+		Trivia: p.takeTrivia(),
 	}
 	p.file.Imports = append(p.file.Imports, imports)
 
@@ -579,7 +619,8 @@ func (p *Parser) parseImports(line string, comments []string) error {
 			return err
 		}
 
-		if len(line) == 0 {
+		if len(strings.TrimSpace(line)) == 0 {
+			p.trivia = append(p.trivia, "")
 			continue
 		}
 
@@ -601,6 +642,7 @@ func (p *Parser) parseImports(line string, comments []string) error {
 					if importCount == 0 {
 						return p.error("empty import declaration")
 					}
+					imports.EndTrivia = p.takeTrivia()
 					return nil
 				default:
 					if err := addImport(line[mark:], comments); err != nil {
@@ -615,6 +657,7 @@ func (p *Parser) parseImports(line string, comments []string) error {
 				switch c {
 				case '/':
 					comments = append(comments, line[i+1:])
+					p.trivia = append(p.trivia, strings.TrimSpace(line))
 					break loop
 				default:
 					return p.error("expected comment")
@@ -690,6 +733,26 @@ func parseStringLen(s string) (int, error) {
 	return strconv.Atoi(strings.TrimSpace(s))
 }
 
+// splitComment splits a line into the code and the text after a "//" that is not
+// inside a string literal.
+func splitComment(line string) (code, comment string, ok bool) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i], line[i+2:], true
+		}
+	}
+	return line, "", false
+}
+
 func (p *Parser) parseType(line string, comments []string) (t *Type, err error) {
 	mark := 0
 	count := 0
@@ -703,6 +766,12 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 			End:    p.index,
 		},
 	}
+	code, _, _ := splitComment(line)
+	if eq := strings.IndexByte(code, '='); eq > -1 {
+		t.Expr, t.InitExpr = strings.TrimSpace(code[:eq]), strings.TrimSpace(code[eq+1:])
+	} else {
+		t.Expr = strings.TrimSpace(code)
+	}
 
 	type stateCode int
 	const (
@@ -805,6 +874,38 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 				t.Len = length
 			}
 			state = StateAfterName
+		} else if kind := KindOf(name); name == "decimal" || kind == KindDecimal64 || kind == KindDecimal128 {
+			kind, scale, err := parseDecimalType(name)
+			if err != nil {
+				return p.error("invalid decimal declaration: %s", err.Error())
+			}
+
+			switch t.Kind {
+			case KindList:
+				if t.Optional {
+					return p.error("list elements or map keys cannot be optional")
+				}
+				t.Element = &Type{
+					File:  p.file,
+					Name:  name,
+					Kind:  kind,
+					Scale: scale,
+				}
+
+			case KindMap:
+				t.Value = &Type{
+					File:  p.file,
+					Name:  name,
+					Kind:  kind,
+					Scale: scale,
+				}
+
+			default:
+				t.Name = name
+				t.Kind = kind
+				t.Scale = scale
+			}
+			state = StateAfterName
 		} else {
 			kind := KindOf(name)
 
@@ -895,6 +996,13 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 			case ' ', '\t', '\n':
 			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 			case ']':
+				// '[]' declares a variable length list
+				if len(strings.TrimSpace(line[mark:i])) == 0 {
+					t.Kind = KindList
+					mark = i + 1
+					state = StateName
+					continue
+				}
 				length, err := strconv.Atoi(strings.TrimSpace(line[mark:i]))
 				if err != nil {
 					return nil, p.error(fmt.Sprintf("invalid list length value %s", err.Error()))
@@ -943,7 +1051,7 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 			if c != '/' {
 				return nil, p.error("expected second comment character '/'")
 			}
-			t.Comments = append(t.Comments, line[i+1:])
+			t.Description = line[i+1:]
 			return t, nil
 
 		case StateMapKeyword:
@@ -1005,6 +1113,17 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 			case '/':
 				return nil, p.error("expected value declaration not comment '//'")
 
+			case '-':
+				// Decimals are scaled exactly so negative values are literals rather
+				// than expressions.
+				if t.Kind == KindDecimal64 || t.Kind == KindDecimal128 {
+					state = StateNumberLiteral
+				} else {
+					state = StateValueLiteral
+				}
+				mark = i
+				count = 0
+
 			default:
 				state = StateValueLiteral
 				mark = i
@@ -1143,7 +1262,13 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 			switch c {
 			case ' ', '\t', '\r':
 				literal := line[mark:i]
-				if strings.Index(literal, ".") > -1 {
+				if t.Kind == KindDecimal64 || t.Kind == KindDecimal128 {
+					val, err := ParseDecimal(t.Kind, t.Scale, literal)
+					if err != nil {
+						return nil, p.error(fmt.Sprintf("failed to parse decimal literal: %s", err.Error()))
+					}
+					t.Init = val
+				} else if strings.Index(literal, ".") > -1 {
 					switch t.Kind {
 					case KindFloat32, KindFloat64:
 						val, err := strconv.ParseFloat(literal, 64)
@@ -1175,6 +1300,7 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 						return nil, p.error("type cannot be set to an integer")
 					}
 				}
+				state = StateMaybeComment
 			}
 
 		case StateMaybeComment:
@@ -1195,10 +1321,9 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 		case StateValueLiteralMaybeComment:
 			switch c {
 			case '/':
-				t.Init = Expression(line[mark:i])
-				state = StateComment
-				mark = i + 1
-				count = 0
+				t.Init = Expression(strings.TrimSpace(line[mark : i-1]))
+				t.Description = line[i+1:]
+				return t, nil
 			default:
 				state = StateValueLiteral
 			}
@@ -1239,7 +1364,13 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 	case StateNumberLiteral:
 		literal := line[mark:]
 		t.Init = literal
-		if strings.Index(literal, ".") > -1 {
+		if t.Kind == KindDecimal64 || t.Kind == KindDecimal128 {
+			val, err := ParseDecimal(t.Kind, t.Scale, literal)
+			if err != nil {
+				return nil, p.error(fmt.Sprintf("failed to parse decimal literal: %s", err.Error()))
+			}
+			t.Init = val
+		} else if strings.Index(literal, ".") > -1 {
 			switch t.Kind {
 			case KindFloat32, KindFloat64:
 				val, err := strconv.ParseFloat(literal, 64)
@@ -1260,7 +1391,7 @@ func (p *Parser) parseType(line string, comments []string) (t *Type, err error)
 		}
 
 	case StateValueLiteral, StateValueLiteralMaybeComment:
-		t.Init = Expression(line[mark:])
+		t.Init = Expression(strings.TrimSpace(line[mark:]))
 
 	}
 
@@ -1346,6 +1477,7 @@ func (p *Parser) parseConst(line string, comments []string) (*Const, error) {
 				}
 				cst.Type = t
 				cst.Type.Const = cst
+				cst.Type.Trivia = p.takeTrivia()
 				return cst, nil
 			}
 		}
@@ -1394,6 +1526,9 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 		},
 	}
 	st.Type.Struct = st
+	st.Type.Trivia = p.takeTrivia()
+	st.Annotations = p.takeAnnotations()
+	st.Deprecated, st.DeprecatedMessage = deprecation(st.Annotations)
 
 	for i, c := range line {
 		switch state {
@@ -1448,6 +1583,7 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 
 		line = strings.TrimSpace(line)
 		if len(line) == 0 {
+			p.trivia = append(p.trivia, "")
 			continue
 		}
 		switch line[0] {
@@ -1456,11 +1592,21 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 				return nil, p.error("comment expected")
 			}
 			comments = append(comments, line[2:])
+			p.trivia = append(p.trivia, line)
 
-		case '[', '@':
+		case '[':
 			return nil, p.error("attributes not supported yet")
 
+		case '@':
+			if err = p.parseAnnotation(line); err != nil {
+				return nil, err
+			}
+
 		case '}':
+			if len(p.annotations) > 0 {
+				return nil, p.error("annotation '@%s' is not followed by a field", p.annotations[0].Name)
+			}
+			st.EndTrivia = p.takeTrivia()
 			return st, nil
 
 		default:
@@ -1468,8 +1614,12 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 			mark = 0
 			count = 0
 			field := &StructField{
-				Struct: st,
+				Struct:      st,
+				Annotations: p.takeAnnotations(),
 			}
+			trivia := p.takeTrivia()
+			field.Deprecated, field.DeprecatedMessage = deprecation(field.Annotations)
+			field.Intern = interning(field.Annotations)
 		loop:
 			for i := 0; i < len(line); i++ {
 				c := line[i]
@@ -1478,6 +1628,8 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 					switch c {
 					case ' ', '\t', '\r':
 					case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+						state = StateNumber
+						mark = i
 					default:
 						if !IsLetter(c) {
 							return nil, p.error("expected field number or name")
@@ -1495,8 +1647,11 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 							return nil, p.error("invalid field number '%s': %s", line[mark:i], err.Error())
 						}
 						field.Number = int(num)
-						state = StateName
+						state = StateNumberAfter
 						mark = i + 1
+					case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+					default:
+						return nil, p.error("invalid field number '%s'", line[mark:i+1])
 					}
 
 				case StateNumberAfter:
@@ -1539,6 +1694,7 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 						}
 
 						field.Type = t
+						t.Trivia = trivia
 						st.Fields = append(st.Fields, field)
 						t.Field = field
 
@@ -1567,6 +1723,7 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 						}
 
 						field.Type = t
+						t.Trivia = trivia
 						st.Fields = append(st.Fields, field)
 						t.Field = field
 
@@ -1583,6 +1740,9 @@ func (p *Parser) parseStruct(line string, comments []string) (*Struct, error) {
 					break loop
 				}
 			}
+			if field.Intern && field.Type != nil && !internable(field.Type) {
+				return nil, p.error("@%s field '%s' is not a string", AnnotationIntern, field.Name)
+			}
 		}
 	}
 }
@@ -1624,7 +1784,10 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 		Kind:     KindEnum,
 		Enum:     enum,
 		Comments: comments,
+		Trivia:   p.takeTrivia(),
 	}
+	enum.Annotations = p.takeAnnotations()
+	enum.Deprecated, enum.DeprecatedMessage = deprecation(enum.Annotations)
 
 	for i, c := range line {
 		switch state {
@@ -1679,6 +1842,7 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 					continue
 				}
 				name := line[mark:i]
+				enum.Type.Expr = name
 
 				if strings.HasPrefix(name, "string") {
 					length, err := parseStringLen(name[6:])
@@ -1690,6 +1854,7 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 						File: p.file,
 						Name: name,
 						Kind: KindString,
+						Len:  length,
 					}
 				} else {
 					kind := KindOf(name)
@@ -1745,6 +1910,7 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 		line = strings.TrimSpace(line)
 
 		if len(line) == 0 {
+			p.trivia = append(p.trivia, "")
 			continue
 		}
 		switch line[0] {
@@ -1753,11 +1919,21 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 				return nil, p.error("comment expected")
 			}
 			comments = append(comments, line[2:])
+			p.trivia = append(p.trivia, line)
 
 		case '[':
 			return nil, p.error("attributes not supported yet")
 
+		case '@':
+			if err = p.parseAnnotation(line); err != nil {
+				return nil, err
+			}
+
 		case '}':
+			if len(p.annotations) > 0 {
+				return nil, p.error("annotation '@%s' is not followed by an option", p.annotations[0].Name)
+			}
+			enum.EndTrivia = p.takeTrivia()
 			return enum, nil
 
 		default:
@@ -1772,7 +1948,11 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 					Begin:  p.mark,
 					End:    p.index,
 				},
+				Comments:    comments,
+				Trivia:      p.takeTrivia(),
+				Annotations: p.takeAnnotations(),
 			}
+			option.Deprecated, option.DeprecatedMessage = deprecation(option.Annotations)
 		loop:
 			for i, c := range line {
 				switch state {
@@ -1789,6 +1969,9 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 						count = 0
 
 					case '=':
+						if count > 0 {
+							option.Name = line[mark:i]
+						}
 						state = StateValue
 						mark = i + 1
 						count = 0
@@ -1801,7 +1984,7 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 					if c != '/' {
 						return nil, p.error("expected comment")
 					}
-					comments = append(comments, line[i+2:])
+					option.Description = line[i+1:]
 					state = StateEnd
 					break loop
 
@@ -1868,7 +2051,6 @@ func (p *Parser) parseEnum(line string, comments []string) (*Enum, error) {
 				if count == 0 {
 					return nil, p.error("expected a value for option")
 				}
-				option.Comments = comments
 				option.Value, err = ParseInt(enum.Type.Element.Kind, line[mark:])
 				if err != nil {
 					return nil, p.error("invalid integer type for option")
@@ -1907,6 +2089,7 @@ func (p *Parser) parseUnion(line string, comments []string) (*Union, error) {
 	union := &Union{
 		Comments: comments,
 	}
+	trivia := p.takeTrivia()
 
 	for i, c := range line {
 		switch state {
@@ -1928,6 +2111,7 @@ func (p *Parser) parseUnion(line string, comments []string) (*Union, error) {
 					Kind:     KindUnion,
 					Name:     union.Name,
 					Comments: comments,
+					Trivia:   trivia,
 					Union:    union,
 				}
 				comments = nil
@@ -1973,6 +2157,7 @@ func (p *Parser) parseUnion(line string, comments []string) (*Union, error) {
 		line = strings.TrimSpace(line)
 
 		if len(line) == 0 {
+			p.trivia = append(p.trivia, "")
 			continue
 		}
 		switch line[0] {
@@ -1981,11 +2166,13 @@ func (p *Parser) parseUnion(line string, comments []string) (*Union, error) {
 				return nil, p.error("comment expected")
 			}
 			comments = append(comments, line[2:])
+			p.trivia = append(p.trivia, line)
 
 		case '[':
 			return nil, p.error("attributes not supported yet")
 
 		case '}':
+			union.EndTrivia = p.takeTrivia()
 			return union, nil
 
 		default:
@@ -2043,6 +2230,7 @@ func (p *Parser) parseUnion(line string, comments []string) (*Union, error) {
 						}
 						option.Type = t
 						option.Type.UnionOption = option
+						option.Type.Trivia = p.takeTrivia()
 
 						if t.Init != nil {
 							return nil, p.error("union option cannot have an initializer")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -87,6 +88,188 @@ enum Code : byte {
 	fmt.Println(file)
 }
 
+func TestVariableLength(t *testing.T) {
+	file, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Order {
+	id      i64
+	symbol  string
+	data    bytes
+	fills   [] f64
+	code    string8
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := file.Types["Order"]
+	if order == nil || order.Struct == nil {
+		t.Fatal("Order not found")
+	}
+	fields := make(map[string]*StructField)
+	for _, field := range order.Struct.Fields {
+		fields[field.Name] = field
+	}
+	for _, name := range []string{"symbol", "data", "fills"} {
+		field := fields[name]
+		if field == nil {
+			t.Fatalf("field %s not found", name)
+		}
+		if !field.Type.IsVariable() {
+			t.Fatalf("field %s expected to be variable length", name)
+		}
+		if field.Type.Size != VPointerSize {
+			t.Fatalf("field %s size = %d, expected = %d", name, field.Type.Size, VPointerSize)
+		}
+	}
+	if fills := fields["fills"].Type; fills.ItemSize != 8 {
+		t.Fatalf("fills item size = %d, expected = 8", fills.ItemSize)
+	}
+	if code := fields["code"].Type; code.IsVariable() {
+		t.Fatal("string8 should not be variable length")
+	}
+}
+
+func TestVariableListElements(t *testing.T) {
+	resolve := func(source string) error {
+		s, errs := ParseFiles("", map[string][]byte{"model/schema.moon": []byte(source)})
+		if len(errs) > 0 {
+			return errs[0]
+		}
+		return s.Resolve()
+	}
+	err := resolve(`
+struct Order {
+	legs    [] Leg
+}
+
+struct Leg {
+	price   f64
+	detail  Detail
+}
+
+struct Detail {
+	venue   string
+}
+`)
+	if err == nil || !strings.Contains(err.Error(), "cannot contain 'Leg' which has variable length fields") {
+		t.Fatalf("expected struct elements with variable length fields to be rejected, got %v", err)
+	}
+	err = resolve(`
+struct Order {
+	legs    [4] Leg
+}
+
+struct Leg {
+	venue   string
+}
+`)
+	if err == nil || !strings.Contains(err.Error(), "cannot contain 'Leg' which has variable length fields") {
+		t.Fatalf("expected fixed length lists of them to be rejected too, got %v", err)
+	}
+
+	if err = resolve(`
+struct Order {
+	legs    [] Leg
+}
+
+struct Leg {
+	price   f64
+	venue   string8
+}
+`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	file, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Fill {
+	qty     decimal64(8)  = 0.5
+	price   ?decimal128(2) = -12.5
+	fees    [4] decimal64(2)
+	zero    decimal64(0)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]*StructField)
+	for _, field := range file.Types["Fill"].Struct.Fields {
+		fields[field.Name] = field
+	}
+	qty, price, fees := fields["qty"].Type, fields["price"].Type, fields["fees"].Type
+	if qty.Kind != KindDecimal64 || qty.Scale != 8 || qty.Size != 8 || qty.Name != "Decimal64S8" {
+		t.Fatalf("qty = %+v", qty)
+	}
+	if init, ok := qty.Init.(Decimal); !ok || init.Units.Int64() != 50000000 || init.String() != "0.50000000" {
+		t.Fatalf("qty init = %#v", qty.Init)
+	}
+	if price.Kind != KindDecimal128 || price.Scale != 2 || price.Size != 16 || !price.Optional {
+		t.Fatalf("price = %+v", price)
+	}
+	if init, ok := price.Init.(Decimal); !ok || init.String() != "-12.50" {
+		t.Fatalf("price init = %#v", price.Init)
+	}
+	if fees.Element.Kind != KindDecimal64 || fees.Element.Scale != 2 || fees.ItemSize != 8 {
+		t.Fatalf("fees = %+v", fees.Element)
+	}
+	if zero := fields["zero"].Type; zero.Name != "Decimal64S0" {
+		t.Fatalf("zero = %+v", zero)
+	}
+
+	for source, expected := range map[string]string{
+		"qty decimal":                    "decimal requires a size and scale",
+		"qty decimal64":                  "decimal64 requires a scale",
+		"qty decimal64(19)":              "decimal64 scale must be between 0 and 18",
+		"qty decimal128(x)":              "decimal128 scale must be between 0 and 38",
+		"qty decimal64(2) = 0.125":       "0.125 has more than 2 decimals",
+		"qty decimal64(8) = 92233720369": "overflows decimal64(8)",
+	} {
+		_, err = ParseFile("model/schema.moon", "schema.moon", []byte("struct Fill {\n\t"+source+"\n}\n"))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: err = %v, expected %s", source, err, expected)
+		}
+	}
+}
+
+func TestDecimalListNames(t *testing.T) {
+	s, errs := ParseFiles("", map[string][]byte{"model/schema.moon": []byte(`
+struct Book {
+	a [23] decimal64(1)
+	b [3] decimal64(12)
+	c [] decimal64(18)
+	d [8] decimal64(1)
+	e [2] decimal128(11)
+	f [12] decimal128(1)
+}
+`)})
+	if len(errs) > 0 {
+		t.Fatal(errs[0])
+	}
+	if err := s.Resolve(); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range s.Files["model/schema.moon"].Types["Book"].Struct.Fields {
+		if field.Type.Kind == KindPad {
+			continue
+		}
+		expected := map[string]struct {
+			name       string
+			len, scale int
+		}{
+			"a": {"Decimal64S1_23List", 23, 1},
+			"b": {"Decimal64S12_3List", 3, 12},
+			"c": {"Decimal64S18List", 0, 18},
+			"d": {"Decimal64S1_8List", 8, 1},
+			"e": {"Decimal128S11_2List", 2, 11},
+			"f": {"Decimal128S1_12List", 12, 1},
+		}[field.Name]
+		if ft := field.Type; ft.Name != expected.name || ft.Len != expected.len || ft.Element.Scale != expected.scale {
+			t.Fatalf("%s = %s [%d] scale %d, expected %+v", field.Name, ft.Name, ft.Len, ft.Element.Scale, expected)
+		}
+	}
+}
+
 //func BenchmarkAccess(b *testing.B) {
 //	buffer := &BarMut{}
 //	rawStruct := (*BarStruct)(unsafe.Pointer(&buffer.Bar[0]))
@@ -114,3 +297,237 @@ enum Code : byte {
 //		}
 //	})
 //}
+
+func TestAnnotations(t *testing.T) {
+	file, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+@deprecated("use Quote")
+enum Side : byte {
+	Buy = 1
+	@deprecated
+	Sell = 2 // comment
+}
+
+@deprecated("use Trade")
+struct Fill {
+	price f64
+}
+
+struct Order {
+	@deprecated("use sides")
+	side  Side
+	@deprecated("always empty")
+	fills [4]Fill
+	price f64
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := file.Types["Side"].Enum
+	if !side.Deprecated || side.DeprecatedMessage != "use Quote" {
+		t.Fatalf("enum deprecation = %v %q", side.Deprecated, side.DeprecatedMessage)
+	}
+	if side.Options[0].Deprecated || !side.Options[1].Deprecated || side.Options[1].DeprecatedMessage != "" {
+		t.Fatal("enum option deprecation not parsed")
+	}
+	fill := file.Types["Fill"].Struct
+	if !fill.Deprecated || fill.DeprecatedMessage != "use Trade" || len(fill.Annotations) != 1 {
+		t.Fatal("struct deprecation not parsed")
+	}
+	order := file.Types["Order"].Struct
+	if order.Deprecated {
+		t.Fatal("Order should not be deprecated")
+	}
+	fields := make(map[string]*StructField)
+	for _, field := range order.Fields {
+		fields[field.Name] = field
+	}
+	if !fields["side"].Deprecated || fields["side"].DeprecatedMessage != "use sides" || fields["price"].Deprecated {
+		t.Fatal("field deprecation not parsed")
+	}
+	if errs := file.DeprecatedUses(); len(errs) != 0 {
+		t.Fatalf("deprecated fields should not report uses: %v", errs)
+	}
+
+	fields["fills"].Deprecated = false
+	errs := file.DeprecatedUses()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "field 'Order.fills' uses deprecated struct 'Fill': use Trade") {
+		t.Fatalf("unexpected deprecated uses: %v", errs)
+	}
+
+	if _, err = ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Order {
+	price f64
+	@deprecated
+}
+`)); err == nil {
+		t.Fatal("expected an error for an annotation without a field")
+	}
+}
+
+func TestInternAnnotation(t *testing.T) {
+	file, err := ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Trade {
+	@intern
+	symbol string
+	@intern
+	venues [4]string16
+	price  f64
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]*StructField)
+	for _, field := range file.Types["Trade"].Struct.Fields {
+		fields[field.Name] = field
+	}
+	if !fields["symbol"].Intern || !fields["venues"].Intern || fields["price"].Intern {
+		t.Fatal("@intern not parsed")
+	}
+	if _, err = ParseFile("model/schema.moon", "schema.moon", []byte(`
+struct Trade {
+	@intern
+	price f64
+}
+`)); err == nil || !strings.Contains(err.Error(), "@intern field 'price' is not a string") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	src := `
+
+// Default quantity
+const LIMIT   i32 =   100  // per order
+const NAME string8 = "moon"
+enum Side  :  byte {
+
+	Buy=1
+	// Selling side
+	Sell  =  2 // short
+
+
+}
+// Quote
+@deprecated("use Book")
+struct Quote {
+	1 bid f64 // best bid
+	2   ask|a   ?f64
+	3 levels [ 4 ] i64
+	side    Side =Buy
+
+	// Totals
+	qty i32 = LIMIT   // defaults to LIMIT
+	tags [8] i32  ->  i64
+	// unused
+}
+
+
+// end
+`
+	expected := `// Default quantity
+const LIMIT i32     = 100 // per order
+const NAME  string8 = "moon"
+
+enum Side : byte {
+	Buy  = 1
+	// Selling side
+	Sell = 2 // short
+}
+
+// Quote
+@deprecated("use Book")
+struct Quote {
+	1 bid    f64  // best bid
+	2 ask|a  ?f64
+	3 levels [4]i64
+	  side   Side = Buy
+
+	// Totals
+	qty  i32 = LIMIT // defaults to LIMIT
+	tags [8]i32 -> i64
+	// unused
+}
+
+// end
+`
+	file, err := ParseFile("model/schema.moon", "schema.moon", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sell := file.Types["Side"].Enum.GetOption("Sell")
+	if len(sell.Comments) != 1 || sell.Comments[0] != " Selling side" || sell.Description != " short" {
+		t.Fatalf("option comments = %q description = %q", sell.Comments, sell.Description)
+	}
+	var bid *StructField
+	for _, field := range file.Types["Quote"].Struct.Fields {
+		if field.Name == "bid" {
+			bid = field
+		}
+	}
+	if bid.Number != 1 || len(bid.Type.Comments) != 0 || bid.Type.Description != " best bid" {
+		t.Fatalf("field number = %d comments = %q description = %q", bid.Number, bid.Type.Comments, bid.Type.Description)
+	}
+
+	formatted := string(Format(file))
+	if formatted != expected {
+		t.Fatalf("formatted:\n%s\nexpected:\n%s", formatted, expected)
+	}
+	again, err := FormatSource("model/schema.moon", []byte(formatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != formatted {
+		t.Fatalf("formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	f, err := ParseFile("model/schema.moon", "model/schema.moon", []byte(`struct Tick {
+	flag  bool
+	price f64
+	kind  byte
+	name  string60
+	qty   i32
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := f.Types["Tick"].Struct.Layout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout.Size != 88 || layout.Padding != 14 || layout.CacheLines != 2 {
+		t.Fatalf("layout = %+v", layout)
+	}
+	var name LayoutField
+	for _, field := range layout.Fields {
+		if field.Name == "name" {
+			name = field
+		}
+	}
+	if name.Offset != 24 || name.Align != 8 || name.CacheLine != 0 || !name.Crosses || layout.Crossings != 1 {
+		t.Fatalf("name = %+v", name)
+	}
+	if layout.Suggested == nil || layout.Suggested.Size != 80 ||
+		strings.Join(layout.Suggested.Fields, " ") != "price name qty flag kind" {
+		t.Fatalf("suggested = %+v", layout.Suggested)
+	}
+
+	var text strings.Builder
+	if err = layout.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"model/schema.moon Tick size 88 align 8 padding 14 cache lines 2",
+		"24      60    8      0+    name       String60",
+		"cache line crossings: 1 (+)",
+		"suggested order: price, name, qty, flag, kind (size 80, saves 8)",
+	} {
+		if !strings.Contains(text.String(), expected) {
+			t.Fatalf("text missing %q\n%s", expected, text.String())
+		}
+	}
+}
@@ -3,6 +3,8 @@ package schema
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 )
 
 type Schema struct {
@@ -95,6 +97,30 @@ func LoadFromFS(dirOrFile string, resolve bool) (*Schema, error) {
 	return schema, nil
 }
 
+// ParseFiles parses sources keyed by their path relative to root. Files that fail to
+// parse are left out and their errors returned so the remaining files can still be
+// resolved, which is what an editor needs while a file is being edited.
+func ParseFiles(root string, sources map[string][]byte) (*Schema, []error) {
+	result := &Schema{Files: make(map[string]*File, len(sources))}
+	var errs []error
+	for rel, data := range sources {
+		file, err := ParseFile(filepath.Join(root, rel), filepath.Base(rel), data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		file.Dir = filepath.Dir(rel)
+		if file.Dir == "." {
+			file.Dir = ""
+		}
+		result.Files[rel] = file
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	return result, errs
+}
+
 func (pa *Schema) Resolve() error {
 	if len(pa.Errors) > 0 {
 		return pa.Errors[0]
@@ -115,7 +141,7 @@ func (pa *Schema) Resolve() error {
 		OUTER:
 			for _, imps := range f.Imports {
 				for _, imp := range imps.List {
-					p := RelativePath(f.Path, imp.Path)
+					p := RelativePath(Join(f.Dir, f.Name), imp.Path)
 					if len(p) == 0 {
 						f.Err = fmt.Errorf("import '%s' could not be resolved", imp.Path)
 						pa.Errors = append(pa.Errors, f.Err)
@@ -136,7 +162,10 @@ func (pa *Schema) Resolve() error {
 					imp.Parent = f
 				}
 			}
-
+		}
+		if f != nil {
+			// Files without imports were resolved when parsed but their errors
+			// are only reported here.
 			sorted = append(sorted, f)
 		}
 	}
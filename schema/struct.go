@@ -12,6 +12,11 @@ type Struct struct {
 	Optionals []*StructField
 	Version   int64
 	Compact   bool
+	EndTrivia []string // Comment and blank lines before the closing brace
+
+	Annotations       []*Annotation
+	Deprecated        bool
+	DeprecatedMessage string
 }
 
 type StructField struct {
@@ -23,6 +28,11 @@ type StructField struct {
 	Offset    int
 	OptOffset int
 	OptMask   byte
+
+	Annotations       []*Annotation
+	Deprecated        bool
+	DeprecatedMessage string
+	Intern            bool // Values are read through an intern table (@intern)
 }
 
 func (st *Struct) setOptionals() {
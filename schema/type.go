@@ -8,6 +8,7 @@ type Type struct {
 	Resolved     bool
 	Size         int
 	Len          int // Max length if collection (list or map) or string
+	Scale        int // Decimal places of a decimal
 	HeaderSize   int
 	HeaderOffset int
 	Padding      int
@@ -26,6 +27,9 @@ type Type struct {
 	Enum         *Enum        // Enum for 'KindEnum'
 	EnumOption   *EnumOption  // EnumOption if type represents a single enum option
 	Init         interface{}  // Initial value
+	Expr         string       // Type as written in the schema
+	InitExpr     string       // Initial value as written in the schema
+	Trivia       []string     // Comment and blank ("") lines written before the declaration
 }
 
 func (t *Type) Base() *Type {
@@ -39,3 +43,60 @@ func (t *Type) Base() *Type {
 	}
 	return t
 }
+
+// IsVariable reports whether the type is a variable length string, bytes or list
+// that is stored out of line behind a VPointer.
+func (t *Type) IsVariable() bool {
+	switch t.Kind {
+	case KindString, KindBytes, KindList:
+		return t.Len == 0
+	}
+	return false
+}
+
+// HasVariable reports whether the type is variable length or is a struct, fixed length
+// list or union holding one. Copying such a value on its own breaks the VPointers into
+// the heap portion of its buffer.
+func (t *Type) HasVariable() bool {
+	return t.hasVariable(0)
+}
+
+func (t *Type) hasVariable(depth int) bool {
+	if t == nil || depth >= maxDepth {
+		return false
+	}
+	if t.IsVariable() {
+		return true
+	}
+	switch t.Kind {
+	case KindStruct:
+		if t.Struct != nil {
+			for _, field := range t.Struct.Fields {
+				if field.Type.hasVariable(depth + 1) {
+					return true
+				}
+			}
+		}
+	case KindList:
+		return t.Element.hasVariable(depth + 1)
+	case KindUnion:
+		if t.Union != nil {
+			for _, option := range t.Union.Options {
+				if option.Type.hasVariable(depth + 1) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Doc returns the comments above the declaration followed by the description to the
+// right of it.
+func (t *Type) Doc() []string {
+	if len(t.Description) == 0 {
+		return t.Comments
+	}
+	doc := make([]string, 0, len(t.Comments)+1)
+	return append(append(doc, t.Comments...), t.Description)
+}
@@ -3,6 +3,7 @@ package schema
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -293,6 +294,75 @@ func ParseInt(kind Kind, v string) (interface{}, error) {
 	}
 }
 
+// ParseDecimal parses a number literal as a decimal of kind and scale. Literals with more
+// decimals than scale or out of the range of kind are errors rather than rounded.
+func ParseDecimal(kind Kind, scale int, v string) (Decimal, error) {
+	digits := strings.TrimPrefix(v, "-")
+	fraction := ""
+	if dot := strings.IndexByte(digits, '.'); dot > -1 {
+		digits, fraction = digits[:dot], digits[dot+1:]
+	}
+	if len(digits)+len(fraction) == 0 || strings.IndexFunc(digits+fraction, func(r rune) bool {
+		return r < '0' || r > '9'
+	}) > -1 {
+		return Decimal{}, fmt.Errorf("invalid decimal %s", v)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > scale {
+		return Decimal{}, fmt.Errorf("%s has more than %d decimals", v, scale)
+	}
+	fraction += strings.Repeat("0", scale-len(fraction))
+	units, _ := new(big.Int).SetString("0"+digits+fraction, 10)
+	if strings.HasPrefix(v, "-") {
+		units.Neg(units)
+	}
+	bits := 64
+	if kind == KindDecimal128 {
+		bits = 128
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	if units.Cmp(limit) >= 0 || units.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return Decimal{}, fmt.Errorf("%s overflows decimal%d(%d)", v, bits, scale)
+	}
+	return Decimal{Units: units, Scale: scale}, nil
+}
+
+// parseDecimalType parses the kind and scale of a decimal64(scale) or decimal128(scale)
+// type name.
+func parseDecimalType(name string) (Kind, int, error) {
+	var (
+		kind     Kind
+		maxScale int
+		prefix   string
+	)
+	switch {
+	case strings.HasPrefix(name, "decimal128"):
+		kind, maxScale, prefix = KindDecimal128, MaxDecimal128Scale, "decimal128"
+	case strings.HasPrefix(name, "decimal64"):
+		kind, maxScale, prefix = KindDecimal64, MaxDecimal64Scale, "decimal64"
+	default:
+		return KindUnknown, 0, errors.New("decimal requires a size and scale, e.g. decimal64(8)")
+	}
+	args := name[len(prefix):]
+	if len(args) < 2 || args[0] != '(' || args[len(args)-1] != ')' {
+		return KindUnknown, 0, fmt.Errorf("%s requires a scale, e.g. %s(8)", prefix, prefix)
+	}
+	scale, err := strconv.Atoi(args[1 : len(args)-1])
+	if err != nil || scale < 0 || scale > maxScale {
+		return KindUnknown, 0, fmt.Errorf("%s scale must be between 0 and %d", prefix, maxScale)
+	}
+	return kind, scale, nil
+}
+
+// DecimalTypeName returns the name of a decimal64(scale) or decimal128(scale) type, such
+// as Decimal64S8.
+func DecimalTypeName(kind Kind, scale int) string {
+	if kind == KindDecimal128 {
+		return fmt.Sprintf("Decimal128S%d", scale)
+	}
+	return fmt.Sprintf("Decimal64S%d", scale)
+}
+
 //// fnv1 incorporates the list of bytes into the hash x using the FNV-1 hash function.
 //func fnv1(x uint32, list ...byte) uint32 {
 //	for _, b := range list {
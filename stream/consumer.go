@@ -0,0 +1,355 @@
+package stream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+var (
+	ErrConsumerJoined     = errors.New("consumer already joined the group")
+	ErrConsumerClosed     = errors.New("consumer closed")
+	ErrSavepointRegressed = errors.New("savepoint is before the committed savepoint")
+	ErrSavepointStream    = errors.New("savepoint belongs to another stream")
+	ErrCheckpointCorrupt  = errors.New("checkpoint corrupt")
+)
+
+// Checkpoint is the committed state of a consumer: the Savepoint of the last record it
+// processed and the output block it produced from the records up to it.
+type Checkpoint struct {
+	Savepoint Savepoint
+	Output    []byte
+}
+
+// CheckpointStore persists the checkpoints of the consumers of a group. Save must
+// replace the previous checkpoint atomically: a Load after a crash returns either the
+// previous or the new checkpoint, never a mix of both.
+type CheckpointStore interface {
+	// Load returns the last saved checkpoint or nil if the consumer never saved one.
+	Load(group, consumer string) (*Checkpoint, error)
+	// Save replaces the checkpoint of the consumer.
+	Save(group, consumer string, checkpoint *Checkpoint) error
+}
+
+// SavepointOf returns the Savepoint after the record of h.
+func SavepointOf(h *RecordHeader, writerID int64) *Savepoint {
+	var s Savepoint
+	s.Mut().
+		SetTimestamp(h.Timestamp()).
+		SetWriterID(writerID).
+		RecordID().
+		SetStreamID(h.StreamID()).
+		SetBlockID(h.BlockID()).
+		SetId(h.Id())
+	return &s
+}
+
+// compareRecordID orders the records of a stream by block and then by id.
+func compareRecordID(a, b *RecordID) int {
+	switch {
+	case a.BlockID() < b.BlockID():
+		return -1
+	case a.BlockID() > b.BlockID():
+		return 1
+	case a.Id() < b.Id():
+		return -1
+	case a.Id() > b.Id():
+		return 1
+	}
+	return 0
+}
+
+// ConsumerGroup is a named set of consumers sharing a CheckpointStore. Each consumer
+// of the group keeps its own checkpoint and at most one instance of a consumer may be
+// joined at a time.
+type ConsumerGroup struct {
+	name      string
+	store     CheckpointStore
+	mu        sync.Mutex
+	consumers map[string]*Consumer
+}
+
+func NewConsumerGroup(name string, store CheckpointStore) *ConsumerGroup {
+	return &ConsumerGroup{
+		name:      name,
+		store:     store,
+		consumers: make(map[string]*Consumer),
+	}
+}
+
+func (g *ConsumerGroup) Name() string {
+	return g.name
+}
+
+// Join returns the consumer with the given name restored from its last checkpoint.
+func (g *ConsumerGroup) Join(name string) (*Consumer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.consumers[name]; ok {
+		return nil, fmt.Errorf("%s/%s: %w", g.name, name, ErrConsumerJoined)
+	}
+	checkpoint, err := g.store.Load(g.name, name)
+	if err != nil {
+		return nil, err
+	}
+	c := &Consumer{group: g, name: name, checkpoint: checkpoint}
+	g.consumers[name] = c
+	return c, nil
+}
+
+// Consumer is a named member of a ConsumerGroup. It commits a Savepoint after
+// processing records and, once restarted, resumes right after the last committed one.
+type Consumer struct {
+	group      *ConsumerGroup
+	name       string
+	mu         sync.Mutex
+	checkpoint *Checkpoint
+	closed     bool
+}
+
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+// Savepoint returns the last committed Savepoint or nil if none was committed.
+func (c *Consumer) Savepoint() *Savepoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checkpoint == nil {
+		return nil
+	}
+	s := c.checkpoint.Savepoint
+	return &s
+}
+
+// Output returns the output block committed with the last Savepoint.
+func (c *Consumer) Output() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checkpoint == nil {
+		return nil
+	}
+	return c.checkpoint.Output
+}
+
+// Resume returns the RecordID of the last committed record. Reading must resume with
+// the record following it. ok is false when the consumer starts from the beginning.
+func (c *Consumer) Resume() (id RecordID, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checkpoint == nil {
+		return id, false
+	}
+	return *c.checkpoint.Savepoint.RecordID(), true
+}
+
+// Processed reports whether the record id is at or before the last committed
+// Savepoint. Readers that cannot seek use it to skip the records replayed after a
+// restart.
+func (c *Consumer) Processed(id *RecordID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checkpoint == nil {
+		return false
+	}
+	return compareRecordID(id, c.checkpoint.Savepoint.RecordID()) <= 0
+}
+
+// Commit records that every record up to and including the one of savepoint has been
+// processed. The output block of the previous commit is kept.
+func (c *Consumer) Commit(savepoint *Savepoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var output []byte
+	if c.checkpoint != nil {
+		output = c.checkpoint.Output
+	}
+	return c.commit(savepoint, output)
+}
+
+// CommitBlock commits savepoint together with the output block the consumer produced
+// from the records up to it. Both are stored in a single checkpoint so a restarted
+// consumer never sees an output that does not match its savepoint.
+func (c *Consumer) CommitBlock(savepoint *Savepoint, output []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.commit(savepoint, append([]byte(nil), output...))
+}
+
+func (c *Consumer) commit(savepoint *Savepoint, output []byte) error {
+	if c.closed {
+		return ErrConsumerClosed
+	}
+	if c.checkpoint != nil {
+		last := c.checkpoint.Savepoint.RecordID()
+		if savepoint.RecordID().StreamID() != last.StreamID() {
+			return fmt.Errorf("%s/%s: stream %d: %w",
+				c.group.name, c.name, savepoint.RecordID().StreamID(), ErrSavepointStream)
+		}
+		if compareRecordID(savepoint.RecordID(), last) < 0 {
+			return fmt.Errorf("%s/%s: %s: %w",
+				c.group.name, c.name, savepoint.RecordID().String(), ErrSavepointRegressed)
+		}
+	}
+	checkpoint := &Checkpoint{Savepoint: *savepoint, Output: output}
+	if err := c.group.store.Save(c.group.name, c.name, checkpoint); err != nil {
+		return err
+	}
+	c.checkpoint = checkpoint
+	return nil
+}
+
+// Close leaves the group. The checkpoint is kept and restored by the next Join.
+func (c *Consumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.group.mu.Lock()
+	delete(c.group.consumers, c.name)
+	c.group.mu.Unlock()
+	return nil
+}
+
+const (
+	checkpointMagic   = "MSP1"
+	checkpointExt     = ".savepoint"
+	savepointSize     = int(unsafe.Sizeof(Savepoint{}))
+	checkpointMinSize = len(checkpointMagic) + savepointSize + 4 + 4
+)
+
+// FileStore is a CheckpointStore keeping one file per consumer under
+// dir/<group>/<consumer>.savepoint. Checkpoints are written to a temporary file that is
+// synced and renamed over the previous one.
+//
+// A checkpoint file holds the magic "MSP1", the Savepoint, the little endian u32 length
+// of the output block, the output block and the little endian u32 CRC-32 of everything
+// before it.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(group, consumer string) (string, error) {
+	for _, name := range []string{group, consumer} {
+		if len(name) == 0 || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("invalid checkpoint name '%s'", name)
+		}
+	}
+	return filepath.Join(s.dir, group, consumer+checkpointExt), nil
+}
+
+func (s *FileStore) Load(group, consumer string) (*Checkpoint, error) {
+	path, err := s.path(group, consumer)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	checkpoint, err := decodeCheckpoint(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+func (s *FileStore) Save(group, consumer string, checkpoint *Checkpoint) error {
+	path, err := s.path(group, consumer)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, consumer+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(encodeCheckpoint(checkpoint))
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if e := d.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+func encodeCheckpoint(checkpoint *Checkpoint) []byte {
+	b := make([]byte, 0, checkpointMinSize+len(checkpoint.Output))
+	b = append(b, checkpointMagic...)
+	b = checkpoint.Savepoint.MarshalBinaryTo(b)
+	b = appendUint32(b, uint32(len(checkpoint.Output)))
+	b = append(b, checkpoint.Output...)
+	return appendUint32(b, crc32.ChecksumIEEE(b))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func decodeCheckpoint(b []byte) (*Checkpoint, error) {
+	if len(b) < checkpointMinSize || string(b[:len(checkpointMagic)]) != checkpointMagic {
+		return nil, ErrCheckpointCorrupt
+	}
+	body := b[:len(b)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(b[len(body):]) {
+		return nil, ErrCheckpointCorrupt
+	}
+	body = body[len(checkpointMagic):]
+	checkpoint := &Checkpoint{}
+	if err := checkpoint.Savepoint.UnmarshalBinary(body[:savepointSize]); err != nil {
+		return nil, err
+	}
+	body = body[savepointSize:]
+	if int(binary.LittleEndian.Uint32(body)) != len(body)-4 {
+		return nil, ErrCheckpointCorrupt
+	}
+	if len(body) > 4 {
+		checkpoint.Output = append([]byte(nil), body[4:]...)
+	}
+	return checkpoint, nil
+}
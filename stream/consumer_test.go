@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSavepoint(block, id int64) *Savepoint {
+	var h RecordHeader
+	m := h.Mut()
+	m.SetStreamID(7).SetBlockID(block).SetId(id).SetTimestamp(id * 1000)
+	return SavepointOf(&h, 1)
+}
+
+func TestConsumerResume(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := NewConsumerGroup("bars", store)
+	c, err := group.Join("candles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Resume(); ok {
+		t.Fatal("new consumer resumed")
+	}
+	if _, err = group.Join("candles"); !errors.Is(err, ErrConsumerJoined) {
+		t.Fatalf("second Join = %v", err)
+	}
+
+	if err = c.CommitBlock(newSavepoint(1, 10), []byte("block 1")); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Commit(newSavepoint(2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Commit(newSavepoint(1, 11)); !errors.Is(err, ErrSavepointRegressed) {
+		t.Fatalf("regressed Commit = %v", err)
+	}
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Commit(newSavepoint(2, 4)); err != ErrConsumerClosed {
+		t.Fatalf("Commit after Close = %v", err)
+	}
+
+	// A restarted consumer resumes after the last committed record with the output
+	// committed alongside it.
+	store, _ = NewFileStore(dir)
+	c, err = NewConsumerGroup("bars", store).Join("candles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, ok := c.Resume()
+	if !ok || id.StreamID() != 7 || id.BlockID() != 2 || id.Id() != 3 {
+		t.Fatalf("Resume = %s, %v", id.String(), ok)
+	}
+	if s := c.Savepoint(); s.Timestamp() != 3000 || s.WriterID() != 1 {
+		t.Fatalf("Savepoint = %s", s.String())
+	}
+	if !bytes.Equal(c.Output(), []byte("block 1")) {
+		t.Fatalf("Output = %q", c.Output())
+	}
+	if !c.Processed(newSavepoint(2, 3).RecordID()) || c.Processed(newSavepoint(2, 4).RecordID()) {
+		t.Fatal("Processed")
+	}
+	if err = c.CommitBlock(newSavepoint(3, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.Output() != nil {
+		t.Fatalf("Output = %q", c.Output())
+	}
+	var other Savepoint
+	other.Mut().RecordID().SetStreamID(8).SetBlockID(4)
+	if err = c.Commit(&other); !errors.Is(err, ErrSavepointStream) {
+		t.Fatalf("Commit of other stream = %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "bars", "*"))
+	if len(matches) != 1 || filepath.Base(matches[0]) != "candles"+checkpointExt {
+		t.Fatalf("files = %v", matches)
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Save("g", "c", &Checkpoint{Savepoint: *newSavepoint(1, 1), Output: []byte("out")}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "g", "c"+checkpointExt)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(checkpointMagic)+1] ^= 1
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Load("g", "c"); !errors.Is(err, ErrCheckpointCorrupt) {
+		t.Fatalf("Load = %v", err)
+	}
+	if err = store.Save("g", "../c", &Checkpoint{}); err == nil {
+		t.Fatal("Save accepted a path")
+	}
+}
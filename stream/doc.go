@@ -0,0 +1,9 @@
+// Package stream implements the runtime of moon streams: the records, blocks and
+// control messages declared in schema.moon and the consumers reading them.
+//
+// Consumers checkpoint their position as a Savepoint holding the RecordID of the last
+// record they processed. The savepoint ids of BlockHeader and RecordHeader are left
+// out: Writer does not set them and Consumer does not read them.
+package stream
+
+//go:generate go run ../cmd/moonc -project moon.yaml gen go
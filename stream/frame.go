@@ -0,0 +1,214 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// RecordHeaderSize is the size of the RecordHeader starting every record frame.
+const RecordHeaderSize = int(unsafe.Sizeof(RecordHeader{}))
+
+var (
+	ErrFrameCorrupt = errors.New("record frame corrupt")
+	ErrFrameGap     = errors.New("record frame missing")
+	ErrBlockInvalid = errors.New("block does not match its record frames")
+)
+
+// FrameEncoder frames the records of a stream for real-time push. A frame is the
+// RecordHeader followed by the payload, compressed when the encoder compresses and it
+// makes the payload smaller.
+//
+// The encoder numbers frames with seq, a counter wrapping at 65536 that runs across
+// blocks so receivers detect lost frames. The header of the last record of a block has
+// eob set.
+type FrameEncoder struct {
+	compression Compression
+	seq         uint16
+	scratch     []byte
+}
+
+func NewFrameEncoder(compression Compression) *FrameEncoder {
+	return &FrameEncoder{compression: compression}
+}
+
+// AppendFrame appends the frame of the record with header and payload to dst. eob marks
+// the last record of its block.
+func (e *FrameEncoder) AppendFrame(dst []byte, header *RecordHeader, payload []byte, eob bool) ([]byte, error) {
+	if len(payload) > 0xffff {
+		return dst, fmt.Errorf("record %d: payload of %d bytes exceeds 65535", header.Id(), len(payload))
+	}
+	h := *header
+	m := h.Mut().
+		SetSeq(e.seq).
+		SetSize(uint16(len(payload))).
+		SetSizeU(uint16(len(payload))).
+		SetSizeX(0).
+		SetCompression(Compression_None).
+		SetEob(eob)
+	e.seq++
+	body := payload
+	if e.compression == Compression_LZ4 {
+		e.scratch = lz4Compress(e.scratch[:0], payload)
+		if len(e.scratch) < len(payload) {
+			m.SetCompression(Compression_LZ4).SetSizeX(uint16(len(e.scratch)))
+			body = e.scratch
+		}
+	}
+	dst = h.MarshalBinaryTo(dst)
+	return append(dst, body...), nil
+}
+
+// Frame is a decoded record frame.
+type Frame struct {
+	Header RecordHeader
+	// Payload is the uncompressed payload. It aliases the frame unless the payload
+	// was compressed.
+	Payload []byte
+}
+
+// DecodeFrame decodes the frame at the start of b and returns it with the number of
+// bytes it used.
+func DecodeFrame(b []byte) (*Frame, int, error) {
+	f := &Frame{}
+	if err := f.Header.UnmarshalBinary(b); err != nil {
+		return nil, 0, ErrFrameCorrupt
+	}
+	h := &f.Header
+	n := RecordHeaderSize
+	switch h.Compression() {
+	case Compression_None:
+		if h.SizeX() != 0 || h.SizeU() != h.Size() || len(b)-n < int(h.Size()) {
+			return nil, 0, ErrFrameCorrupt
+		}
+		f.Payload = b[n : n+int(h.Size())]
+		n += int(h.Size())
+	case Compression_LZ4:
+		if len(b)-n < int(h.SizeX()) {
+			return nil, 0, ErrFrameCorrupt
+		}
+		payload, err := lz4Decompress(nil, b[n:n+int(h.SizeX())], int(h.SizeU()))
+		if err != nil {
+			return nil, 0, fmt.Errorf("record %d: %w", h.Id(), err)
+		}
+		f.Payload = payload
+		n += int(h.SizeX())
+	default:
+		return nil, 0, fmt.Errorf("record %d: compression %d: %w", h.Id(), h.Compression(), ErrFrameCorrupt)
+	}
+	return f, n, nil
+}
+
+// AssembledBlock is a block reassembled from its record frames. Data is the
+// concatenation of the uncompressed payloads.
+type AssembledBlock struct {
+	Records []RecordHeader
+	Data    []byte
+}
+
+// Header returns the BlockHeader describing the block.
+func (b *AssembledBlock) Header() *BlockHeader {
+	var head BlockHeader
+	first, last := &b.Records[0], &b.Records[len(b.Records)-1]
+	start, end := first.Timestamp(), first.Timestamp()
+	for i := range b.Records {
+		if ts := b.Records[i].Timestamp(); ts < start {
+			start = ts
+		} else if ts > end {
+			end = ts
+		}
+	}
+	head.Mut().
+		SetStreamID(first.StreamID()).
+		SetId(first.BlockID()).
+		SetMin(first.Id()).
+		SetMax(last.Id()).
+		SetStart(start).
+		SetEnd(end).
+		SetCount(int32(len(b.Records))).
+		SetSeq(int32(first.Seq())).
+		SetSize(int32(len(b.Data))).
+		SetSizeU(int32(len(b.Data)))
+	return &head
+}
+
+// Validate checks the block against the Block message with head and body that later
+// confirms it.
+func (b *AssembledBlock) Validate(head *BlockHeader, body []byte) error {
+	expected := b.Header()
+	if head.StreamID() != expected.StreamID() ||
+		head.Id() != expected.Id() ||
+		head.Min() != expected.Min() ||
+		head.Max() != expected.Max() ||
+		head.Start() != expected.Start() ||
+		head.End() != expected.End() ||
+		head.Count() != expected.Count() ||
+		head.Seq() != expected.Seq() ||
+		head.SizeU() != expected.SizeU() {
+		return fmt.Errorf("block %d: %s expected %s: %w",
+			head.Id(), head.String(), expected.String(), ErrBlockInvalid)
+	}
+	if int(head.Size()) > len(body) {
+		return fmt.Errorf("block %d: body of %d bytes is shorter than %d: %w",
+			head.Id(), len(body), head.Size(), ErrBlockInvalid)
+	}
+	data := body[:head.Size()]
+	switch head.Compression() {
+	case Compression_None:
+	case Compression_LZ4:
+		var err error
+		if data, err = lz4Decompress(nil, data, int(head.SizeU())); err != nil {
+			return fmt.Errorf("block %d: %w", head.Id(), err)
+		}
+	default:
+		return fmt.Errorf("block %d: compression %d: %w", head.Id(), head.Compression(), ErrBlockInvalid)
+	}
+	if !bytes.Equal(data, b.Data) {
+		return fmt.Errorf("block %d: data differs: %w", head.Id(), ErrBlockInvalid)
+	}
+	return nil
+}
+
+// BlockAssembler reassembles blocks from the record frames of a stream. Receivers must
+// start at the first record of a block; Validate rejects a block assembled from a
+// partial sequence of frames.
+type BlockAssembler struct {
+	started bool
+	skip    bool
+	next    uint16
+	block   AssembledBlock
+}
+
+// Add adds the next frame of the stream. It returns the assembled block when the frame
+// ends one. After an error the partial block is dropped and frames are skipped up to
+// the end of the block being received.
+func (a *BlockAssembler) Add(f *Frame) (*AssembledBlock, error) {
+	h := &f.Header
+	var err error
+	if a.started && h.Seq() != a.next {
+		err = fmt.Errorf("record %d: seq %d expected %d: %w", h.Id(), h.Seq(), a.next, ErrFrameGap)
+	} else if len(a.block.Records) > 0 {
+		if first := &a.block.Records[0]; h.StreamID() != first.StreamID() || h.BlockID() != first.BlockID() {
+			err = fmt.Errorf("record %d: block %d expected %d: %w", h.Id(), h.BlockID(), first.BlockID(), ErrFrameGap)
+		}
+	}
+	if err != nil {
+		a.block = AssembledBlock{}
+		a.skip = true
+	}
+	a.started = true
+	a.next = h.Seq() + 1
+	if a.skip {
+		a.skip = !h.Eob()
+		return nil, err
+	}
+	a.block.Records = append(a.block.Records, *h)
+	a.block.Data = append(a.block.Data, f.Payload...)
+	if !h.Eob() {
+		return nil, nil
+	}
+	block := a.block
+	a.block = AssembledBlock{}
+	return &block, nil
+}
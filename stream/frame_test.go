@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestLZ4(t *testing.T) {
+	random := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(random)
+	for _, src := range [][]byte{
+		nil,
+		[]byte("short"),
+		bytes.Repeat([]byte("BTC-USD,"), 200),
+		bytes.Repeat([]byte{0}, 70000),
+		random,
+		append(bytes.Repeat([]byte("abcdefgh"), 40), random[:300]...),
+	} {
+		compressed := lz4Compress(nil, src)
+		decompressed, err := lz4Decompress(nil, compressed, len(src))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", len(src), err)
+		}
+		if !bytes.Equal(decompressed, src) {
+			t.Fatalf("%d bytes: round trip differs", len(src))
+		}
+		if len(src) > 0 {
+			if _, err = lz4Decompress(nil, compressed[:len(compressed)-1], len(src)); err == nil {
+				t.Fatalf("%d bytes: truncated block accepted", len(src))
+			}
+		}
+	}
+	if _, err := lz4Decompress(nil, []byte{0x10, 'a', 5, 0}, 10); err != ErrLZ4Corrupt {
+		t.Fatalf("offset before start = %v", err)
+	}
+}
+
+func encodeBlock(t *testing.T, e *FrameEncoder, block int64, payloads ...string) []byte {
+	var b []byte
+	for i, payload := range payloads {
+		var h RecordHeader
+		h.Mut().SetStreamID(9).SetBlockID(block).SetId(block*10 + int64(i)).SetTimestamp(int64(100 + i))
+		var err error
+		if b, err = e.AppendFrame(b, &h, []byte(payload), i == len(payloads)-1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+func TestFrames(t *testing.T) {
+	e := NewFrameEncoder(Compression_LZ4)
+	long := string(bytes.Repeat([]byte("price=100.25;"), 20))
+	b := encodeBlock(t, e, 1, "a", long, "c")
+	b = append(b, encodeBlock(t, e, 2, "d")...)
+
+	var a BlockAssembler
+	var blocks []*AssembledBlock
+	compressed := 0
+	for len(b) > 0 {
+		f, n, err := DecodeFrame(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Header.Compression() == Compression_LZ4 {
+			compressed++
+		}
+		b = b[n:]
+		block, err := a.Add(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	if compressed != 1 {
+		t.Fatalf("%d compressed frames, expected 1", compressed)
+	}
+	if len(blocks) != 2 || len(blocks[0].Records) != 3 || string(blocks[0].Data) != "a"+long+"c" {
+		t.Fatalf("blocks = %+v", blocks)
+	}
+	if blocks[1].Records[0].Seq() != 3 {
+		t.Fatalf("seq = %d", blocks[1].Records[0].Seq())
+	}
+
+	// The Block message confirming the first block carries a compressed body.
+	head := blocks[0].Header()
+	body := lz4Compress(nil, blocks[0].Data)
+	head.Mut().SetCompression(Compression_LZ4).SetSize(int32(len(body))).SetSizeX(int32(len(body)))
+	if err := blocks[0].Validate(head, body); err != nil {
+		t.Fatal(err)
+	}
+	if head.Min() != 10 || head.Max() != 12 || head.Start() != 100 || head.End() != 102 {
+		t.Fatalf("head = %s", head.String())
+	}
+	head.Mut().SetCount(2)
+	if err := blocks[0].Validate(head, body); !errors.Is(err, ErrBlockInvalid) {
+		t.Fatalf("Validate with wrong count = %v", err)
+	}
+	head = blocks[1].Header()
+	if err := blocks[1].Validate(head, []byte("x")); !errors.Is(err, ErrBlockInvalid) {
+		t.Fatalf("Validate with wrong data = %v", err)
+	}
+}
+
+func TestFrameGap(t *testing.T) {
+	e := NewFrameEncoder(Compression_None)
+	var frames []*Frame
+	b := encodeBlock(t, e, 1, "a", "b", "c")
+	b = append(b, encodeBlock(t, e, 2, "d", "e")...)
+	for len(b) > 0 {
+		f, n, err := DecodeFrame(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+		b = b[n:]
+	}
+	if _, _, err := DecodeFrame(frames[0].Payload); err != ErrFrameCorrupt {
+		t.Fatalf("short frame = %v", err)
+	}
+
+	// Losing the second frame drops the first block but not the next one.
+	var a BlockAssembler
+	if block, err := a.Add(frames[0]); block != nil || err != nil {
+		t.Fatal(block, err)
+	}
+	if _, err := a.Add(frames[2]); !errors.Is(err, ErrFrameGap) {
+		t.Fatalf("gap = %v", err)
+	}
+	if block, err := a.Add(frames[3]); block != nil || err != nil {
+		t.Fatal(block, err)
+	}
+	block, err := a.Add(frames[4])
+	if err != nil || block == nil || string(block.Data) != "de" {
+		t.Fatalf("block = %+v, %v", block, err)
+	}
+}
@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// now returns the current Unix timestamp in nanoseconds. Tests replace it.
+var now = func() int64 {
+	return time.Now().UnixNano()
+}
+
+// Message is a record or control message delivered to the readers of a stream. Only
+// the field matching Type is set.
+type Message struct {
+	Type     MessageType
+	Record   *RecordHeader
+	Data     []byte
+	Started  *Started
+	Stopped  *Stopped
+	Progress *Progress
+}
+
+// Hub delivers the messages of in-process streams to their subscribers. Messages of a
+// stream are delivered in the order they are published.
+type Hub struct {
+	mu          sync.Mutex
+	next        int
+	subscribers map[int64]map[int]func(*Message)
+	fences      map[int64]*sync.Mutex
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[int64]map[int]func(*Message)),
+		fences:      make(map[int64]*sync.Mutex),
+	}
+}
+
+// fence returns the lock writers of the stream hold from checking their lease until
+// their messages are published, so a writer losing the lease cannot publish after the
+// new writer announced itself.
+func (h *Hub) fence(streamID int64) *sync.Mutex {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fence := h.fences[streamID]
+	if fence == nil {
+		fence = &sync.Mutex{}
+		h.fences[streamID] = fence
+	}
+	return fence
+}
+
+// Subscribe calls fn for every message published to the stream until cancel is called.
+// fn runs with the hub locked and must not call back into the hub.
+func (h *Hub) Subscribe(streamID int64, fn func(*Message)) (cancel func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subscribers := h.subscribers[streamID]
+	if subscribers == nil {
+		subscribers = make(map[int]func(*Message))
+		h.subscribers[streamID] = subscribers
+	}
+	id := h.next
+	h.next++
+	subscribers[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(subscribers, id)
+	}
+}
+
+// Publish delivers m to the subscribers of the stream. The hub lock is held while
+// delivering so concurrent publishers cannot reorder the messages of a stream.
+func (h *Hub) Publish(streamID int64, m *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, fn := range h.subscribers[streamID] {
+		fn(m)
+	}
+}
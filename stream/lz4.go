@@ -0,0 +1,149 @@
+package stream
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrLZ4Corrupt = errors.New("lz4: corrupt block")
+
+const (
+	lz4MinMatch = 4
+	lz4HashLog  = 12
+	// The last 5 bytes of a block are always literals and the last match starts at
+	// least 12 bytes before the end.
+	lz4LastLiterals = 5
+	lz4MatchLimit   = 12
+	lz4MaxOffset    = 65535
+)
+
+// lz4Compress appends src compressed in the LZ4 block format to dst.
+func lz4Compress(dst, src []byte) []byte {
+	var table [1 << lz4HashLog]int32
+	anchor := 0
+	for i := 0; i < len(src)-lz4MatchLimit; {
+		v := binary.LittleEndian.Uint32(src[i:])
+		h := (v * 2654435761) >> (32 - lz4HashLog)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != v {
+			i++
+			continue
+		}
+		end := i + lz4MinMatch
+		for r := ref + lz4MinMatch; end < len(src)-lz4LastLiterals && src[end] == src[r]; r++ {
+			end++
+		}
+		dst = lz4Sequence(dst, src[anchor:i], i-ref, end-i)
+		i, anchor = end, end
+	}
+	return lz4Sequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4Sequence appends a sequence of literals followed by a match. The last sequence of
+// a block has no match.
+func lz4Sequence(dst, literals []byte, offset, match int) []byte {
+	var token byte
+	if len(literals) >= 15 {
+		token = 0xf0
+	} else {
+		token = byte(len(literals) << 4)
+	}
+	match -= lz4MinMatch
+	if offset > 0 {
+		if match >= 15 {
+			token |= 0x0f
+		} else {
+			token |= byte(match)
+		}
+	}
+	dst = append(dst, token)
+	if len(literals) >= 15 {
+		dst = lz4AppendLength(dst, len(literals)-15)
+	}
+	dst = append(dst, literals...)
+	if offset == 0 {
+		return dst
+	}
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if match >= 15 {
+		dst = lz4AppendLength(dst, match-15)
+	}
+	return dst
+}
+
+func lz4AppendLength(dst []byte, n int) []byte {
+	for ; n >= 255; n -= 255 {
+		dst = append(dst, 255)
+	}
+	return append(dst, byte(n))
+}
+
+// lz4ConsumeLength returns an extended length and the number of bytes it used or -1
+// when b is truncated.
+func lz4ConsumeLength(b []byte) (int, int) {
+	n := 0
+	for i, c := range b {
+		n += int(c)
+		if c != 255 {
+			return n, i + 1
+		}
+	}
+	return 0, -1
+}
+
+// lz4Decompress appends the size bytes decompressed from the LZ4 block src to dst.
+func lz4Decompress(dst, src []byte, size int) ([]byte, error) {
+	start := len(dst)
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+		literals := int(token >> 4)
+		if literals == 15 {
+			n, k := lz4ConsumeLength(src[i:])
+			if k < 0 {
+				return dst, ErrLZ4Corrupt
+			}
+			literals += n
+			i += k
+		}
+		if literals > len(src)-i || len(dst)-start+literals > size {
+			return dst, ErrLZ4Corrupt
+		}
+		dst = append(dst, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			break
+		}
+		if i+2 > len(src) {
+			return dst, ErrLZ4Corrupt
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst)-start {
+			return dst, ErrLZ4Corrupt
+		}
+		match := int(token & 0x0f)
+		if match == 15 {
+			n, k := lz4ConsumeLength(src[i:])
+			if k < 0 {
+				return dst, ErrLZ4Corrupt
+			}
+			match += n
+			i += k
+		}
+		match += lz4MinMatch
+		if len(dst)-start+match > size {
+			return dst, ErrLZ4Corrupt
+		}
+		// Matches may overlap the bytes they produce.
+		p := len(dst) - offset
+		for j := 0; j < match; j++ {
+			dst = append(dst, dst[p+j])
+		}
+	}
+	if len(dst)-start != size {
+		return dst, ErrLZ4Corrupt
+	}
+	return dst, nil
+}
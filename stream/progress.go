@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"time"
+)
+
+// ProgressFunc is called with every Progress of a replay and its estimated time to
+// completion, or -1 while unknown.
+type ProgressFunc func(p *Progress, eta time.Duration)
+
+// ProgressOptions configures the Progress messages of a replay.
+type ProgressOptions struct {
+	// Interval is the minimum time between Progress messages. Defaults to one second.
+	Interval time.Duration
+	// Hub receives the Progress messages when set.
+	Hub *Hub
+	// Func is called with every Progress when set.
+	Func ProgressFunc
+}
+
+// ETA estimates the time left to replay the remaining records at the rate of the
+// records replayed so far. It returns -1 before the rate is known.
+func ETA(p *Progress) time.Duration {
+	if p.Remaining() <= 0 {
+		return 0
+	}
+	elapsed := p.Timestamp() - p.Started()
+	if p.Count() <= 0 || elapsed <= 0 {
+		return -1
+	}
+	return time.Duration(float64(elapsed) / float64(p.Count()) * float64(p.Remaining()))
+}
+
+// ProgressTracker reports the progress of a reader catching up from an old RecordID
+// to the head of the stream. Counts are taken from the cumulative records counter of
+// the block headers so no record has to be counted while replaying.
+type ProgressTracker struct {
+	streamID int64
+	writerID int64
+	options  ProgressOptions
+	started  int64
+	last     int64
+	base     int64
+	head     int64
+	count    int64
+	done     bool
+}
+
+// NewProgressTracker starts tracking a replay up to the block with head.
+func NewProgressTracker(head *BlockHeader, writerID int64, options ProgressOptions) *ProgressTracker {
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	started := now()
+	return &ProgressTracker{
+		streamID: head.StreamID(),
+		writerID: writerID,
+		options:  options,
+		started:  started,
+		// The first block is reported right away.
+		last: started - int64(options.Interval),
+		base: -1,
+		head: head.Records(),
+	}
+}
+
+// SetHead moves the target of the replay when the stream grows while catching up.
+func (t *ProgressTracker) SetHead(head *BlockHeader) {
+	if head.Records() > t.head {
+		t.head = head.Records()
+	}
+}
+
+// Block records that the reader replayed the block with h. A Progress is emitted when
+// the interval elapsed since the previous one and when the head is reached.
+func (t *ProgressTracker) Block(h *BlockHeader) {
+	if t.done {
+		return
+	}
+	if t.base < 0 {
+		t.base = h.Records() - int64(h.Count())
+	}
+	t.count = h.Records() - t.base
+	timestamp := now()
+	if h.Records() < t.head && timestamp-t.last < int64(t.options.Interval) {
+		return
+	}
+	t.last = timestamp
+	t.done = h.Records() >= t.head
+
+	var p Progress
+	p.Mut().
+		SetTimestamp(timestamp).
+		SetWriterID(t.writerID).
+		SetStarted(t.started).
+		SetCount(t.count).
+		SetRemaining(t.head - h.Records()).
+		RecordID().
+		SetStreamID(t.streamID).
+		SetBlockID(h.Id()).
+		SetId(h.Max())
+	if t.options.Hub != nil {
+		t.options.Hub.Publish(t.streamID, &Message{Type: MessageType_Progress, Progress: &p})
+	}
+	if t.options.Func != nil {
+		t.options.Func(&p, ETA(&p))
+	}
+}
+
+// Done reports whether the replay reached the head.
+func (t *ProgressTracker) Done() bool {
+	return t.done
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func blockHeader(id, records int64, count int32) *BlockHeader {
+	var h BlockHeader
+	h.Mut().SetStreamID(3).SetId(id).SetMax(records).SetRecords(records).SetBlocks(id).SetCount(count)
+	return &h
+}
+
+func TestProgress(t *testing.T) {
+	clock := int64(0)
+	defer func(n func() int64) { now = n }(now)
+	now = func() int64 { return clock }
+
+	hub := NewHub()
+	published := 0
+	hub.Subscribe(3, func(m *Message) {
+		if m.Type == MessageType_Progress {
+			published++
+		}
+	})
+	type report struct {
+		count, remaining int64
+		eta              time.Duration
+	}
+	var reports []report
+	tracker := NewProgressTracker(blockHeader(10, 1000, 100), 7, ProgressOptions{
+		Hub: hub,
+		Func: func(p *Progress, eta time.Duration) {
+			if p.WriterID() != 7 || p.RecordID().StreamID() != 3 {
+				t.Fatalf("progress = %s", p.String())
+			}
+			reports = append(reports, report{p.Count(), p.Remaining(), eta})
+		},
+	})
+
+	// Replay from block 6 which starts at record 600, one block per 500ms.
+	for id := int64(6); id <= 10; id++ {
+		clock += int64(500 * time.Millisecond)
+		if id == 9 {
+			tracker.SetHead(blockHeader(11, 1100, 100))
+		}
+		tracker.Block(blockHeader(id, id*100, 100))
+	}
+	if tracker.Done() {
+		t.Fatal("done before the new head")
+	}
+	clock += int64(500 * time.Millisecond)
+	tracker.Block(blockHeader(11, 1100, 100))
+	if !tracker.Done() {
+		t.Fatal("not done at the head")
+	}
+
+	expected := []report{
+		{100, 400, 2 * time.Second},
+		{300, 200, time.Second},
+		{500, 100, 500 * time.Millisecond},
+		{600, 0, 0},
+	}
+	if len(reports) != len(expected) || published != len(expected) {
+		t.Fatalf("reports = %v, published %d", reports, published)
+	}
+	for i := range expected {
+		if reports[i] != expected[i] {
+			t.Fatalf("report %d = %v, expected %v", i, reports[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,4353 @@
+//go:build ppc64 || s390x || mips || mips64
+// +build ppc64 s390x mips mips64
+
+package stream
+
+import (
+	"encoding/binary"
+	"fmt"
+	wap "github.com/moontrade/proto"
+	"io"
+	"unsafe"
+)
+
+type Compression byte
+
+const (
+	Compression_None = Compression(0)
+	Compression_LZ4  = Compression(1)
+)
+
+// Valid reports whether v is a declared Compression option.
+func (v Compression) Valid() bool {
+	switch v {
+	case Compression_None:
+		return true
+	case Compression_LZ4:
+		return true
+	}
+	return false
+}
+
+type MessageType byte
+
+const (
+	MessageType_Record    = MessageType(1)
+	MessageType_Block     = MessageType(2)
+	MessageType_EOS       = MessageType(3)
+	MessageType_EOB       = MessageType(4)
+	MessageType_Savepoint = MessageType(5)
+	MessageType_Starting  = MessageType(6)
+	MessageType_Progress  = MessageType(7)
+	MessageType_Started   = MessageType(8)
+	MessageType_Stopped   = MessageType(9)
+)
+
+// Valid reports whether v is a declared MessageType option.
+func (v MessageType) Valid() bool {
+	switch v {
+	case MessageType_Record:
+		return true
+	case MessageType_Block:
+		return true
+	case MessageType_EOS:
+		return true
+	case MessageType_EOB:
+		return true
+	case MessageType_Savepoint:
+		return true
+	case MessageType_Starting:
+		return true
+	case MessageType_Progress:
+		return true
+	case MessageType_Started:
+		return true
+	case MessageType_Stopped:
+		return true
+	}
+	return false
+}
+
+type SchemaKind byte
+
+const (
+	// Raw bytes
+	SchemaKind_Bytes = SchemaKind(0)
+	// MoonBuf Structure
+	SchemaKind_MoonStruct = SchemaKind(1)
+	// MoonBuf Message
+	SchemaKind_MoonMessage = SchemaKind(1)
+	// Protocol buffers
+	SchemaKind_ProtoBuf = SchemaKind(2)
+	// FlatBuffers
+	SchemaKind_FlatBuf = SchemaKind(3)
+	// Json
+	SchemaKind_Json = SchemaKind(4)
+	// MessagePack
+	SchemaKind_MessagePack = SchemaKind(5)
+)
+
+// Valid reports whether v is a declared SchemaKind option.
+func (v SchemaKind) Valid() bool {
+	switch v {
+	case SchemaKind_Bytes:
+		return true
+	case SchemaKind_MoonStruct:
+		return true
+	case SchemaKind_ProtoBuf:
+		return true
+	case SchemaKind_FlatBuf:
+		return true
+	case SchemaKind_Json:
+		return true
+	case SchemaKind_MessagePack:
+		return true
+	}
+	return false
+}
+
+type StopReason byte
+
+const (
+	// Stream is composed from another stream or external datasource and it stopped
+	StopReason_Source = StopReason(1)
+	// Stream has been paused
+	StopReason_Paused = StopReason(2)
+	// Stream is being migrated to a new writer
+	StopReason_Migrate = StopReason(3)
+	// Stream has stopped unexpectedly
+	StopReason_Error = StopReason(4)
+)
+
+// Valid reports whether v is a declared StopReason option.
+func (v StopReason) Valid() bool {
+	switch v {
+	case StopReason_Source:
+		return true
+	case StopReason_Paused:
+		return true
+	case StopReason_Migrate:
+		return true
+	case StopReason_Error:
+		return true
+	}
+	return false
+}
+
+type StreamKind byte
+
+const (
+	StreamKind_Log        = StreamKind(0)
+	StreamKind_TimeSeries = StreamKind(1)
+	StreamKind_Table      = StreamKind(2)
+)
+
+// Valid reports whether v is a declared StreamKind option.
+func (v StreamKind) Valid() bool {
+	switch v {
+	case StreamKind_Log:
+		return true
+	case StreamKind_TimeSeries:
+		return true
+	case StreamKind_Table:
+		return true
+	}
+	return false
+}
+
+type AccountStats struct {
+	id       int64
+	storage  Stats
+	appender Stats
+	streams  int64
+}
+
+func (s *AccountStats) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *AccountStats) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["id"] = s.Id()
+	m["storage"] = s.Storage().MarshalMap(nil)
+	m["appender"] = s.Appender().MarshalMap(nil)
+	m["streams"] = s.Streams()
+	return m
+}
+
+func (s *AccountStats) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[64]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 64 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *AccountStats) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[64]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *AccountStats) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[64]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *AccountStats) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[64]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *AccountStats) Read(b []byte) (n int, err error) {
+	if len(b) < 64 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*AccountStats)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 64, nil
+}
+func (s *AccountStats) UnmarshalBinary(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	v := (*AccountStats)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *AccountStats) Clone() *AccountStats {
+	v := &AccountStats{}
+	*v = *s
+	return v
+}
+func (s *AccountStats) Bytes() []byte {
+	return (*(*[64]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *AccountStats) Mut() *AccountStatsMut {
+	return (*AccountStatsMut)(unsafe.Pointer(s))
+}
+
+// VerifyAccountStats checks b holds a well formed AccountStats that is safe to reinterpret.
+func VerifyAccountStats(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(AccountStats{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*AccountStats)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *AccountStats) Verify(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	if err := s.storage.Verify(b[8:]); err != nil {
+		return fmt.Errorf("AccountStats.storage: %w", err)
+	}
+	if err := s.appender.Verify(b[32:]); err != nil {
+		return fmt.Errorf("AccountStats.appender: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretAccountStats casts b to a *AccountStats without copying.
+func ReinterpretAccountStats(b []byte) (*AccountStats, error) {
+	if len(b) < 64 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(AccountStats{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*AccountStats)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *AccountStats) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+func (s *AccountStats) Storage() *Stats {
+	return &s.storage
+}
+func (s *AccountStats) Appender() *Stats {
+	return &s.appender
+}
+func (s *AccountStats) Streams() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.streams))[:]))
+}
+
+type AccountStatsMut struct {
+	AccountStats
+}
+
+func (s *AccountStatsMut) Clone() *AccountStatsMut {
+	v := &AccountStatsMut{}
+	*v = *s
+	return v
+}
+func (s *AccountStatsMut) Freeze() *AccountStats {
+	return (*AccountStats)(unsafe.Pointer(s))
+}
+func (s *AccountStatsMut) SetId(v int64) *AccountStatsMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+func (s *AccountStatsMut) Storage() *StatsMut {
+	return s.storage.Mut()
+}
+func (s *AccountStatsMut) SetStorage(v *Stats) *AccountStatsMut {
+	s.storage = *v
+	return s
+}
+func (s *AccountStatsMut) Appender() *StatsMut {
+	return s.appender.Mut()
+}
+func (s *AccountStatsMut) SetAppender(v *Stats) *AccountStatsMut {
+	s.appender = *v
+	return s
+}
+func (s *AccountStatsMut) SetStreams(v int64) *AccountStatsMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.streams))[:], uint64(v))
+	return s
+}
+
+// Block1
+type Block1 struct {
+	head BlockHeader
+	body Bytes944
+}
+
+func (s *Block1) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block1) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block1) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[1056]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 1056 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block1) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[1056]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block1) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[1056]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block1) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[1056]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block1) Read(b []byte) (n int, err error) {
+	if len(b) < 1056 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block1)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 1056, nil
+}
+func (s *Block1) UnmarshalBinary(b []byte) error {
+	if len(b) < 1056 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block1)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block1) Clone() *Block1 {
+	v := &Block1{}
+	*v = *s
+	return v
+}
+func (s *Block1) Bytes() []byte {
+	return (*(*[1056]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block1) Mut() *Block1Mut {
+	return (*Block1Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock1 checks b holds a well formed Block1 that is safe to reinterpret.
+func VerifyBlock1(b []byte) error {
+	if len(b) < 1056 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block1{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block1)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block1) Verify(b []byte) error {
+	if len(b) < 1056 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block1.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock1 casts b to a *Block1 without copying.
+func ReinterpretBlock1(b []byte) (*Block1, error) {
+	if len(b) < 1056 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block1{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block1)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block1) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block1) Body() *Bytes944 {
+	return &s.body
+}
+
+// Block1
+type Block1Mut struct {
+	Block1
+}
+
+func (s *Block1Mut) Clone() *Block1Mut {
+	v := &Block1Mut{}
+	*v = *s
+	return v
+}
+func (s *Block1Mut) Freeze() *Block1 {
+	return (*Block1)(unsafe.Pointer(s))
+}
+func (s *Block1Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block1Mut) SetHead(v *BlockHeader) *Block1Mut {
+	s.head = *v
+	return s
+}
+func (s *Block1Mut) SetBody(v Bytes944) *Block1Mut {
+	s.body = v
+	return s
+}
+
+// Block16
+type Block16 struct {
+	head BlockHeader
+	body Bytes16306
+	_    [6]byte // Padding
+}
+
+func (s *Block16) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block16) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block16) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[16424]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 16424 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block16) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[16424]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block16) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[16424]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block16) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[16424]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block16) Read(b []byte) (n int, err error) {
+	if len(b) < 16424 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block16)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 16424, nil
+}
+func (s *Block16) UnmarshalBinary(b []byte) error {
+	if len(b) < 16424 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block16)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block16) Clone() *Block16 {
+	v := &Block16{}
+	*v = *s
+	return v
+}
+func (s *Block16) Bytes() []byte {
+	return (*(*[16424]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block16) Mut() *Block16Mut {
+	return (*Block16Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock16 checks b holds a well formed Block16 that is safe to reinterpret.
+func VerifyBlock16(b []byte) error {
+	if len(b) < 16424 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block16{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block16)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block16) Verify(b []byte) error {
+	if len(b) < 16424 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block16.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock16 casts b to a *Block16 without copying.
+func ReinterpretBlock16(b []byte) (*Block16, error) {
+	if len(b) < 16424 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block16{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block16)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block16) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block16) Body() *Bytes16306 {
+	return &s.body
+}
+
+// Block16
+type Block16Mut struct {
+	Block16
+}
+
+func (s *Block16Mut) Clone() *Block16Mut {
+	v := &Block16Mut{}
+	*v = *s
+	return v
+}
+func (s *Block16Mut) Freeze() *Block16 {
+	return (*Block16)(unsafe.Pointer(s))
+}
+func (s *Block16Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block16Mut) SetHead(v *BlockHeader) *Block16Mut {
+	s.head = *v
+	return s
+}
+func (s *Block16Mut) SetBody(v Bytes16306) *Block16Mut {
+	s.body = v
+	return s
+}
+
+// Block2
+type Block2 struct {
+	head BlockHeader
+	body Bytes1968
+}
+
+func (s *Block2) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block2) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block2) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[2080]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 2080 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block2) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[2080]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block2) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[2080]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block2) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[2080]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block2) Read(b []byte) (n int, err error) {
+	if len(b) < 2080 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block2)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 2080, nil
+}
+func (s *Block2) UnmarshalBinary(b []byte) error {
+	if len(b) < 2080 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block2)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block2) Clone() *Block2 {
+	v := &Block2{}
+	*v = *s
+	return v
+}
+func (s *Block2) Bytes() []byte {
+	return (*(*[2080]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block2) Mut() *Block2Mut {
+	return (*Block2Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock2 checks b holds a well formed Block2 that is safe to reinterpret.
+func VerifyBlock2(b []byte) error {
+	if len(b) < 2080 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block2{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block2)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block2) Verify(b []byte) error {
+	if len(b) < 2080 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block2.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock2 casts b to a *Block2 without copying.
+func ReinterpretBlock2(b []byte) (*Block2, error) {
+	if len(b) < 2080 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block2{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block2)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block2) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block2) Body() *Bytes1968 {
+	return &s.body
+}
+
+// Block2
+type Block2Mut struct {
+	Block2
+}
+
+func (s *Block2Mut) Clone() *Block2Mut {
+	v := &Block2Mut{}
+	*v = *s
+	return v
+}
+func (s *Block2Mut) Freeze() *Block2 {
+	return (*Block2)(unsafe.Pointer(s))
+}
+func (s *Block2Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block2Mut) SetHead(v *BlockHeader) *Block2Mut {
+	s.head = *v
+	return s
+}
+func (s *Block2Mut) SetBody(v Bytes1968) *Block2Mut {
+	s.body = v
+	return s
+}
+
+// Block32
+type Block32 struct {
+	head BlockHeader
+	body Bytes32688
+}
+
+func (s *Block32) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block32) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block32) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[32800]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 32800 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block32) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[32800]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block32) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[32800]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block32) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[32800]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block32) Read(b []byte) (n int, err error) {
+	if len(b) < 32800 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block32)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 32800, nil
+}
+func (s *Block32) UnmarshalBinary(b []byte) error {
+	if len(b) < 32800 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block32)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block32) Clone() *Block32 {
+	v := &Block32{}
+	*v = *s
+	return v
+}
+func (s *Block32) Bytes() []byte {
+	return (*(*[32800]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block32) Mut() *Block32Mut {
+	return (*Block32Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock32 checks b holds a well formed Block32 that is safe to reinterpret.
+func VerifyBlock32(b []byte) error {
+	if len(b) < 32800 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block32{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block32)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block32) Verify(b []byte) error {
+	if len(b) < 32800 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block32.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock32 casts b to a *Block32 without copying.
+func ReinterpretBlock32(b []byte) (*Block32, error) {
+	if len(b) < 32800 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block32{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block32)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block32) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block32) Body() *Bytes32688 {
+	return &s.body
+}
+
+// Block32
+type Block32Mut struct {
+	Block32
+}
+
+func (s *Block32Mut) Clone() *Block32Mut {
+	v := &Block32Mut{}
+	*v = *s
+	return v
+}
+func (s *Block32Mut) Freeze() *Block32 {
+	return (*Block32)(unsafe.Pointer(s))
+}
+func (s *Block32Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block32Mut) SetHead(v *BlockHeader) *Block32Mut {
+	s.head = *v
+	return s
+}
+func (s *Block32Mut) SetBody(v Bytes32688) *Block32Mut {
+	s.body = v
+	return s
+}
+
+// Block4
+type Block4 struct {
+	head BlockHeader
+	body Bytes4016
+}
+
+func (s *Block4) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block4) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block4) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[4128]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 4128 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block4) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[4128]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block4) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[4128]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block4) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[4128]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block4) Read(b []byte) (n int, err error) {
+	if len(b) < 4128 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block4)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 4128, nil
+}
+func (s *Block4) UnmarshalBinary(b []byte) error {
+	if len(b) < 4128 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block4)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block4) Clone() *Block4 {
+	v := &Block4{}
+	*v = *s
+	return v
+}
+func (s *Block4) Bytes() []byte {
+	return (*(*[4128]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block4) Mut() *Block4Mut {
+	return (*Block4Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock4 checks b holds a well formed Block4 that is safe to reinterpret.
+func VerifyBlock4(b []byte) error {
+	if len(b) < 4128 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block4{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block4)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block4) Verify(b []byte) error {
+	if len(b) < 4128 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block4.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock4 casts b to a *Block4 without copying.
+func ReinterpretBlock4(b []byte) (*Block4, error) {
+	if len(b) < 4128 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block4{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block4)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block4) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block4) Body() *Bytes4016 {
+	return &s.body
+}
+
+// Block4
+type Block4Mut struct {
+	Block4
+}
+
+func (s *Block4Mut) Clone() *Block4Mut {
+	v := &Block4Mut{}
+	*v = *s
+	return v
+}
+func (s *Block4Mut) Freeze() *Block4 {
+	return (*Block4)(unsafe.Pointer(s))
+}
+func (s *Block4Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block4Mut) SetHead(v *BlockHeader) *Block4Mut {
+	s.head = *v
+	return s
+}
+func (s *Block4Mut) SetBody(v Bytes4016) *Block4Mut {
+	s.body = v
+	return s
+}
+
+// Block64
+type Block64 struct {
+	head BlockHeader
+	body Bytes65456
+}
+
+func (s *Block64) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block64) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block64) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[65568]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 65568 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block64) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[65568]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block64) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[65568]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block64) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[65568]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block64) Read(b []byte) (n int, err error) {
+	if len(b) < 65568 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block64)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 65568, nil
+}
+func (s *Block64) UnmarshalBinary(b []byte) error {
+	if len(b) < 65568 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block64)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block64) Clone() *Block64 {
+	v := &Block64{}
+	*v = *s
+	return v
+}
+func (s *Block64) Bytes() []byte {
+	return (*(*[65568]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block64) Mut() *Block64Mut {
+	return (*Block64Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock64 checks b holds a well formed Block64 that is safe to reinterpret.
+func VerifyBlock64(b []byte) error {
+	if len(b) < 65568 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block64{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block64)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block64) Verify(b []byte) error {
+	if len(b) < 65568 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block64.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock64 casts b to a *Block64 without copying.
+func ReinterpretBlock64(b []byte) (*Block64, error) {
+	if len(b) < 65568 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block64{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block64)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block64) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block64) Body() *Bytes65456 {
+	return &s.body
+}
+
+// Block64
+type Block64Mut struct {
+	Block64
+}
+
+func (s *Block64Mut) Clone() *Block64Mut {
+	v := &Block64Mut{}
+	*v = *s
+	return v
+}
+func (s *Block64Mut) Freeze() *Block64 {
+	return (*Block64)(unsafe.Pointer(s))
+}
+func (s *Block64Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block64Mut) SetHead(v *BlockHeader) *Block64Mut {
+	s.head = *v
+	return s
+}
+func (s *Block64Mut) SetBody(v Bytes65456) *Block64Mut {
+	s.body = v
+	return s
+}
+
+// Block8
+type Block8 struct {
+	head BlockHeader
+	body Bytes8112
+}
+
+func (s *Block8) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Block8) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["head"] = s.Head().MarshalMap(nil)
+	m["body"] = s.Body()
+	return m
+}
+
+func (s *Block8) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[8224]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 8224 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Block8) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[8224]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Block8) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[8224]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Block8) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[8224]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Block8) Read(b []byte) (n int, err error) {
+	if len(b) < 8224 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Block8)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 8224, nil
+}
+func (s *Block8) UnmarshalBinary(b []byte) error {
+	if len(b) < 8224 {
+		return io.ErrShortBuffer
+	}
+	v := (*Block8)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Block8) Clone() *Block8 {
+	v := &Block8{}
+	*v = *s
+	return v
+}
+func (s *Block8) Bytes() []byte {
+	return (*(*[8224]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Block8) Mut() *Block8Mut {
+	return (*Block8Mut)(unsafe.Pointer(s))
+}
+
+// VerifyBlock8 checks b holds a well formed Block8 that is safe to reinterpret.
+func VerifyBlock8(b []byte) error {
+	if len(b) < 8224 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block8{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Block8)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Block8) Verify(b []byte) error {
+	if len(b) < 8224 {
+		return io.ErrShortBuffer
+	}
+	if err := s.head.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Block8.head: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretBlock8 casts b to a *Block8 without copying.
+func ReinterpretBlock8(b []byte) (*Block8, error) {
+	if len(b) < 8224 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Block8{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Block8)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Block8) Head() *BlockHeader {
+	return &s.head
+}
+func (s *Block8) Body() *Bytes8112 {
+	return &s.body
+}
+
+// Block8
+type Block8Mut struct {
+	Block8
+}
+
+func (s *Block8Mut) Clone() *Block8Mut {
+	v := &Block8Mut{}
+	*v = *s
+	return v
+}
+func (s *Block8Mut) Freeze() *Block8 {
+	return (*Block8)(unsafe.Pointer(s))
+}
+func (s *Block8Mut) Head() *BlockHeaderMut {
+	return s.head.Mut()
+}
+func (s *Block8Mut) SetHead(v *BlockHeader) *Block8Mut {
+	s.head = *v
+	return s
+}
+func (s *Block8Mut) SetBody(v Bytes8112) *Block8Mut {
+	s.body = v
+	return s
+}
+
+// BlockHeader
+type BlockHeader struct {
+	streamID    int64
+	id          int64
+	created     int64
+	completed   int64
+	min         int64
+	max         int64
+	start       int64
+	end         int64
+	savepoint   int64
+	blocks      int64
+	records     int64
+	count       int32
+	seq         int32
+	size        int32
+	sizeU       int32
+	sizeX       int32
+	compression Compression
+	_           [3]byte // Padding
+}
+
+func (s *BlockHeader) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *BlockHeader) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["streamID"] = s.StreamID()
+	m["id"] = s.Id()
+	m["created"] = s.Created()
+	m["completed"] = s.Completed()
+	m["min"] = s.Min()
+	m["max"] = s.Max()
+	m["start"] = s.Start()
+	m["end"] = s.End()
+	m["savepoint"] = s.Savepoint()
+	m["blocks"] = s.Blocks()
+	m["records"] = s.Records()
+	m["count"] = s.Count()
+	m["seq"] = s.Seq()
+	m["size"] = s.Size()
+	m["sizeU"] = s.SizeU()
+	m["sizeX"] = s.SizeX()
+	m["compression"] = s.Compression()
+	return m
+}
+
+func (s *BlockHeader) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[112]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 112 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *BlockHeader) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[112]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *BlockHeader) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[112]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *BlockHeader) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[112]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *BlockHeader) Read(b []byte) (n int, err error) {
+	if len(b) < 112 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*BlockHeader)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 112, nil
+}
+func (s *BlockHeader) UnmarshalBinary(b []byte) error {
+	if len(b) < 112 {
+		return io.ErrShortBuffer
+	}
+	v := (*BlockHeader)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *BlockHeader) Clone() *BlockHeader {
+	v := &BlockHeader{}
+	*v = *s
+	return v
+}
+func (s *BlockHeader) Bytes() []byte {
+	return (*(*[112]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *BlockHeader) Mut() *BlockHeaderMut {
+	return (*BlockHeaderMut)(unsafe.Pointer(s))
+}
+
+// VerifyBlockHeader checks b holds a well formed BlockHeader that is safe to reinterpret.
+func VerifyBlockHeader(b []byte) error {
+	if len(b) < 112 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(BlockHeader{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*BlockHeader)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *BlockHeader) Verify(b []byte) error {
+	if len(b) < 112 {
+		return io.ErrShortBuffer
+	}
+	if !s.compression.Valid() {
+		return fmt.Errorf("BlockHeader.compression: %w", wap.ErrInvalidEnum)
+	}
+	return nil
+}
+
+// ReinterpretBlockHeader casts b to a *BlockHeader without copying.
+func ReinterpretBlockHeader(b []byte) (*BlockHeader, error) {
+	if len(b) < 112 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(BlockHeader{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*BlockHeader)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *BlockHeader) StreamID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:]))
+}
+func (s *BlockHeader) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+func (s *BlockHeader) Created() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.created))[:]))
+}
+func (s *BlockHeader) Completed() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.completed))[:]))
+}
+func (s *BlockHeader) Min() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.min))[:]))
+}
+func (s *BlockHeader) Max() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.max))[:]))
+}
+func (s *BlockHeader) Start() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.start))[:]))
+}
+func (s *BlockHeader) End() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.end))[:]))
+}
+func (s *BlockHeader) Savepoint() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:]))
+}
+func (s *BlockHeader) Blocks() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.blocks))[:]))
+}
+func (s *BlockHeader) Records() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.records))[:]))
+}
+func (s *BlockHeader) Count() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.count))[:]))
+}
+func (s *BlockHeader) Seq() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.seq))[:]))
+}
+func (s *BlockHeader) Size() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.size))[:]))
+}
+func (s *BlockHeader) SizeU() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.sizeU))[:]))
+}
+func (s *BlockHeader) SizeX() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.sizeX))[:]))
+}
+func (s *BlockHeader) Compression() Compression {
+	return s.compression
+}
+
+// BlockHeader
+type BlockHeaderMut struct {
+	BlockHeader
+}
+
+func (s *BlockHeaderMut) Clone() *BlockHeaderMut {
+	v := &BlockHeaderMut{}
+	*v = *s
+	return v
+}
+func (s *BlockHeaderMut) Freeze() *BlockHeader {
+	return (*BlockHeader)(unsafe.Pointer(s))
+}
+func (s *BlockHeaderMut) SetStreamID(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetId(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetCreated(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.created))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetCompleted(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.completed))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetMin(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.min))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetMax(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.max))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetStart(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.start))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetEnd(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.end))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetSavepoint(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetBlocks(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.blocks))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetRecords(v int64) *BlockHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.records))[:], uint64(v))
+	return s
+}
+func (s *BlockHeaderMut) SetCount(v int32) *BlockHeaderMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.count))[:], uint32(v))
+	return s
+}
+func (s *BlockHeaderMut) SetSeq(v int32) *BlockHeaderMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.seq))[:], uint32(v))
+	return s
+}
+func (s *BlockHeaderMut) SetSize(v int32) *BlockHeaderMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.size))[:], uint32(v))
+	return s
+}
+func (s *BlockHeaderMut) SetSizeU(v int32) *BlockHeaderMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.sizeU))[:], uint32(v))
+	return s
+}
+func (s *BlockHeaderMut) SetSizeX(v int32) *BlockHeaderMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.sizeX))[:], uint32(v))
+	return s
+}
+func (s *BlockHeaderMut) SetCompression(v Compression) *BlockHeaderMut {
+	s.compression = v
+	return s
+}
+
+// BlockID represents a globally unique ID of a single page of a single stream.
+// String representation
+type BlockID struct {
+	streamID int64
+	id       int64
+}
+
+func (s *BlockID) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *BlockID) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["streamID"] = s.StreamID()
+	m["id"] = s.Id()
+	return m
+}
+
+func (s *BlockID) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[16]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 16 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *BlockID) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[16]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *BlockID) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[16]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *BlockID) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[16]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *BlockID) Read(b []byte) (n int, err error) {
+	if len(b) < 16 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*BlockID)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 16, nil
+}
+func (s *BlockID) UnmarshalBinary(b []byte) error {
+	if len(b) < 16 {
+		return io.ErrShortBuffer
+	}
+	v := (*BlockID)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *BlockID) Clone() *BlockID {
+	v := &BlockID{}
+	*v = *s
+	return v
+}
+func (s *BlockID) Bytes() []byte {
+	return (*(*[16]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *BlockID) Mut() *BlockIDMut {
+	return (*BlockIDMut)(unsafe.Pointer(s))
+}
+
+// VerifyBlockID checks b holds a well formed BlockID that is safe to reinterpret.
+func VerifyBlockID(b []byte) error {
+	if len(b) < 16 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(BlockID{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*BlockID)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *BlockID) Verify(b []byte) error {
+	if len(b) < 16 {
+		return io.ErrShortBuffer
+	}
+	return nil
+}
+
+// ReinterpretBlockID casts b to a *BlockID without copying.
+func ReinterpretBlockID(b []byte) (*BlockID, error) {
+	if len(b) < 16 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(BlockID{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*BlockID)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *BlockID) StreamID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:]))
+}
+func (s *BlockID) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+
+// BlockID represents a globally unique ID of a single page of a single stream.
+// String representation
+type BlockIDMut struct {
+	BlockID
+}
+
+func (s *BlockIDMut) Clone() *BlockIDMut {
+	v := &BlockIDMut{}
+	*v = *s
+	return v
+}
+func (s *BlockIDMut) Freeze() *BlockID {
+	return (*BlockID)(unsafe.Pointer(s))
+}
+func (s *BlockIDMut) SetStreamID(v int64) *BlockIDMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:], uint64(v))
+	return s
+}
+func (s *BlockIDMut) SetId(v int64) *BlockIDMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+
+// End of Block
+type EOB struct {
+	recordID  RecordID
+	timestamp int64
+	savepoint int64
+}
+
+func (s *EOB) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *EOB) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["savepoint"] = s.Savepoint()
+	return m
+}
+
+func (s *EOB) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 40 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *EOB) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *EOB) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *EOB) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *EOB) Read(b []byte) (n int, err error) {
+	if len(b) < 40 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*EOB)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 40, nil
+}
+func (s *EOB) UnmarshalBinary(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	v := (*EOB)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *EOB) Clone() *EOB {
+	v := &EOB{}
+	*v = *s
+	return v
+}
+func (s *EOB) Bytes() []byte {
+	return (*(*[40]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *EOB) Mut() *EOBMut {
+	return (*EOBMut)(unsafe.Pointer(s))
+}
+
+// VerifyEOB checks b holds a well formed EOB that is safe to reinterpret.
+func VerifyEOB(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(EOB{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*EOB)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *EOB) Verify(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("EOB.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretEOB casts b to a *EOB without copying.
+func ReinterpretEOB(b []byte) (*EOB, error) {
+	if len(b) < 40 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(EOB{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*EOB)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *EOB) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *EOB) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *EOB) Savepoint() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:]))
+}
+
+// End of Block
+type EOBMut struct {
+	EOB
+}
+
+func (s *EOBMut) Clone() *EOBMut {
+	v := &EOBMut{}
+	*v = *s
+	return v
+}
+func (s *EOBMut) Freeze() *EOB {
+	return (*EOB)(unsafe.Pointer(s))
+}
+func (s *EOBMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *EOBMut) SetRecordID(v *RecordID) *EOBMut {
+	s.recordID = *v
+	return s
+}
+func (s *EOBMut) SetTimestamp(v int64) *EOBMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *EOBMut) SetSavepoint(v int64) *EOBMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:], uint64(v))
+	return s
+}
+
+// End of Stream
+// The reader is caught up on the stream.
+type EOS struct {
+	recordID  RecordID
+	timestamp int64
+	writerID  int64
+	closed    bool
+	waiting   bool
+	_         [6]byte // Padding
+}
+
+func (s *EOS) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *EOS) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["writerID"] = s.WriterID()
+	m["closed"] = s.Closed()
+	m["waiting"] = s.Waiting()
+	return m
+}
+
+func (s *EOS) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 48 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *EOS) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *EOS) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *EOS) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *EOS) Read(b []byte) (n int, err error) {
+	if len(b) < 48 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*EOS)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 48, nil
+}
+func (s *EOS) UnmarshalBinary(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	v := (*EOS)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *EOS) Clone() *EOS {
+	v := &EOS{}
+	*v = *s
+	return v
+}
+func (s *EOS) Bytes() []byte {
+	return (*(*[48]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *EOS) Mut() *EOSMut {
+	return (*EOSMut)(unsafe.Pointer(s))
+}
+
+// VerifyEOS checks b holds a well formed EOS that is safe to reinterpret.
+func VerifyEOS(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(EOS{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*EOS)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *EOS) Verify(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("EOS.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretEOS casts b to a *EOS without copying.
+func ReinterpretEOS(b []byte) (*EOS, error) {
+	if len(b) < 48 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(EOS{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*EOS)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *EOS) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *EOS) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *EOS) WriterID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:]))
+}
+func (s *EOS) Closed() bool {
+	return s.closed
+}
+func (s *EOS) Waiting() bool {
+	return s.waiting
+}
+
+// End of Stream
+// The reader is caught up on the stream.
+type EOSMut struct {
+	EOS
+}
+
+func (s *EOSMut) Clone() *EOSMut {
+	v := &EOSMut{}
+	*v = *s
+	return v
+}
+func (s *EOSMut) Freeze() *EOS {
+	return (*EOS)(unsafe.Pointer(s))
+}
+func (s *EOSMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *EOSMut) SetRecordID(v *RecordID) *EOSMut {
+	s.recordID = *v
+	return s
+}
+func (s *EOSMut) SetTimestamp(v int64) *EOSMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *EOSMut) SetWriterID(v int64) *EOSMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:], uint64(v))
+	return s
+}
+func (s *EOSMut) SetClosed(v bool) *EOSMut {
+	s.closed = v
+	return s
+}
+func (s *EOSMut) SetWaiting(v bool) *EOSMut {
+	s.waiting = v
+	return s
+}
+
+type Progress struct {
+	recordID  RecordID
+	timestamp int64
+	writerID  int64
+	started   int64
+	count     int64
+	remaining int64
+}
+
+func (s *Progress) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Progress) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["writerID"] = s.WriterID()
+	m["started"] = s.Started()
+	m["count"] = s.Count()
+	m["remaining"] = s.Remaining()
+	return m
+}
+
+func (s *Progress) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[64]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 64 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Progress) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[64]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Progress) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[64]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Progress) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[64]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Progress) Read(b []byte) (n int, err error) {
+	if len(b) < 64 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Progress)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 64, nil
+}
+func (s *Progress) UnmarshalBinary(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	v := (*Progress)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Progress) Clone() *Progress {
+	v := &Progress{}
+	*v = *s
+	return v
+}
+func (s *Progress) Bytes() []byte {
+	return (*(*[64]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Progress) Mut() *ProgressMut {
+	return (*ProgressMut)(unsafe.Pointer(s))
+}
+
+// VerifyProgress checks b holds a well formed Progress that is safe to reinterpret.
+func VerifyProgress(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Progress{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Progress)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Progress) Verify(b []byte) error {
+	if len(b) < 64 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Progress.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretProgress casts b to a *Progress without copying.
+func ReinterpretProgress(b []byte) (*Progress, error) {
+	if len(b) < 64 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Progress{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Progress)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Progress) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *Progress) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *Progress) WriterID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:]))
+}
+func (s *Progress) Started() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.started))[:]))
+}
+func (s *Progress) Count() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.count))[:]))
+}
+func (s *Progress) Remaining() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.remaining))[:]))
+}
+
+type ProgressMut struct {
+	Progress
+}
+
+func (s *ProgressMut) Clone() *ProgressMut {
+	v := &ProgressMut{}
+	*v = *s
+	return v
+}
+func (s *ProgressMut) Freeze() *Progress {
+	return (*Progress)(unsafe.Pointer(s))
+}
+func (s *ProgressMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *ProgressMut) SetRecordID(v *RecordID) *ProgressMut {
+	s.recordID = *v
+	return s
+}
+func (s *ProgressMut) SetTimestamp(v int64) *ProgressMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *ProgressMut) SetWriterID(v int64) *ProgressMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:], uint64(v))
+	return s
+}
+func (s *ProgressMut) SetStarted(v int64) *ProgressMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.started))[:], uint64(v))
+	return s
+}
+func (s *ProgressMut) SetCount(v int64) *ProgressMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.count))[:], uint64(v))
+	return s
+}
+func (s *ProgressMut) SetRemaining(v int64) *ProgressMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.remaining))[:], uint64(v))
+	return s
+}
+
+type RecordHeader struct {
+	streamID    int64
+	blockID     int64
+	id          int64
+	timestamp   int64
+	start       int64
+	end         int64
+	savepoint   int64
+	savepointR  int64
+	seq         uint16
+	size        uint16
+	sizeU       uint16
+	sizeX       uint16
+	compression Compression
+	eob         bool
+	_           [6]byte // Padding
+}
+
+func (s *RecordHeader) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *RecordHeader) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["streamID"] = s.StreamID()
+	m["blockID"] = s.BlockID()
+	m["id"] = s.Id()
+	m["timestamp"] = s.Timestamp()
+	m["start"] = s.Start()
+	m["end"] = s.End()
+	m["savepoint"] = s.Savepoint()
+	m["savepointR"] = s.SavepointR()
+	m["seq"] = s.Seq()
+	m["size"] = s.Size()
+	m["sizeU"] = s.SizeU()
+	m["sizeX"] = s.SizeX()
+	m["compression"] = s.Compression()
+	m["eob"] = s.Eob()
+	return m
+}
+
+func (s *RecordHeader) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[80]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 80 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *RecordHeader) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[80]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *RecordHeader) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[80]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *RecordHeader) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[80]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *RecordHeader) Read(b []byte) (n int, err error) {
+	if len(b) < 80 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*RecordHeader)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 80, nil
+}
+func (s *RecordHeader) UnmarshalBinary(b []byte) error {
+	if len(b) < 80 {
+		return io.ErrShortBuffer
+	}
+	v := (*RecordHeader)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *RecordHeader) Clone() *RecordHeader {
+	v := &RecordHeader{}
+	*v = *s
+	return v
+}
+func (s *RecordHeader) Bytes() []byte {
+	return (*(*[80]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *RecordHeader) Mut() *RecordHeaderMut {
+	return (*RecordHeaderMut)(unsafe.Pointer(s))
+}
+
+// VerifyRecordHeader checks b holds a well formed RecordHeader that is safe to reinterpret.
+func VerifyRecordHeader(b []byte) error {
+	if len(b) < 80 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(RecordHeader{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*RecordHeader)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *RecordHeader) Verify(b []byte) error {
+	if len(b) < 80 {
+		return io.ErrShortBuffer
+	}
+	if !s.compression.Valid() {
+		return fmt.Errorf("RecordHeader.compression: %w", wap.ErrInvalidEnum)
+	}
+	return nil
+}
+
+// ReinterpretRecordHeader casts b to a *RecordHeader without copying.
+func ReinterpretRecordHeader(b []byte) (*RecordHeader, error) {
+	if len(b) < 80 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(RecordHeader{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*RecordHeader)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *RecordHeader) StreamID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:]))
+}
+func (s *RecordHeader) BlockID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.blockID))[:]))
+}
+func (s *RecordHeader) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+func (s *RecordHeader) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *RecordHeader) Start() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.start))[:]))
+}
+func (s *RecordHeader) End() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.end))[:]))
+}
+func (s *RecordHeader) Savepoint() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:]))
+}
+func (s *RecordHeader) SavepointR() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.savepointR))[:]))
+}
+func (s *RecordHeader) Seq() uint16 {
+	return uint16(binary.LittleEndian.Uint16((*[2]byte)(unsafe.Pointer(&s.seq))[:]))
+}
+func (s *RecordHeader) Size() uint16 {
+	return uint16(binary.LittleEndian.Uint16((*[2]byte)(unsafe.Pointer(&s.size))[:]))
+}
+func (s *RecordHeader) SizeU() uint16 {
+	return uint16(binary.LittleEndian.Uint16((*[2]byte)(unsafe.Pointer(&s.sizeU))[:]))
+}
+func (s *RecordHeader) SizeX() uint16 {
+	return uint16(binary.LittleEndian.Uint16((*[2]byte)(unsafe.Pointer(&s.sizeX))[:]))
+}
+func (s *RecordHeader) Compression() Compression {
+	return s.compression
+}
+func (s *RecordHeader) Eob() bool {
+	return s.eob
+}
+
+type RecordHeaderMut struct {
+	RecordHeader
+}
+
+func (s *RecordHeaderMut) Clone() *RecordHeaderMut {
+	v := &RecordHeaderMut{}
+	*v = *s
+	return v
+}
+func (s *RecordHeaderMut) Freeze() *RecordHeader {
+	return (*RecordHeader)(unsafe.Pointer(s))
+}
+func (s *RecordHeaderMut) SetStreamID(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetBlockID(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.blockID))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetId(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetTimestamp(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetStart(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.start))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetEnd(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.end))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSavepoint(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.savepoint))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSavepointR(v int64) *RecordHeaderMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.savepointR))[:], uint64(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSeq(v uint16) *RecordHeaderMut {
+	binary.LittleEndian.PutUint16((*[2]byte)(unsafe.Pointer(&s.seq))[:], uint16(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSize(v uint16) *RecordHeaderMut {
+	binary.LittleEndian.PutUint16((*[2]byte)(unsafe.Pointer(&s.size))[:], uint16(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSizeU(v uint16) *RecordHeaderMut {
+	binary.LittleEndian.PutUint16((*[2]byte)(unsafe.Pointer(&s.sizeU))[:], uint16(v))
+	return s
+}
+func (s *RecordHeaderMut) SetSizeX(v uint16) *RecordHeaderMut {
+	binary.LittleEndian.PutUint16((*[2]byte)(unsafe.Pointer(&s.sizeX))[:], uint16(v))
+	return s
+}
+func (s *RecordHeaderMut) SetCompression(v Compression) *RecordHeaderMut {
+	s.compression = v
+	return s
+}
+func (s *RecordHeaderMut) SetEob(v bool) *RecordHeaderMut {
+	s.eob = v
+	return s
+}
+
+type RecordID struct {
+	streamID int64
+	blockID  int64
+	id       int64
+}
+
+func (s *RecordID) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *RecordID) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["streamID"] = s.StreamID()
+	m["blockID"] = s.BlockID()
+	m["id"] = s.Id()
+	return m
+}
+
+func (s *RecordID) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[24]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 24 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *RecordID) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[24]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *RecordID) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[24]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *RecordID) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[24]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *RecordID) Read(b []byte) (n int, err error) {
+	if len(b) < 24 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*RecordID)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 24, nil
+}
+func (s *RecordID) UnmarshalBinary(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	v := (*RecordID)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *RecordID) Clone() *RecordID {
+	v := &RecordID{}
+	*v = *s
+	return v
+}
+func (s *RecordID) Bytes() []byte {
+	return (*(*[24]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *RecordID) Mut() *RecordIDMut {
+	return (*RecordIDMut)(unsafe.Pointer(s))
+}
+
+// VerifyRecordID checks b holds a well formed RecordID that is safe to reinterpret.
+func VerifyRecordID(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(RecordID{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*RecordID)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *RecordID) Verify(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	return nil
+}
+
+// ReinterpretRecordID casts b to a *RecordID without copying.
+func ReinterpretRecordID(b []byte) (*RecordID, error) {
+	if len(b) < 24 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(RecordID{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*RecordID)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *RecordID) StreamID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:]))
+}
+func (s *RecordID) BlockID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.blockID))[:]))
+}
+func (s *RecordID) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+
+type RecordIDMut struct {
+	RecordID
+}
+
+func (s *RecordIDMut) Clone() *RecordIDMut {
+	v := &RecordIDMut{}
+	*v = *s
+	return v
+}
+func (s *RecordIDMut) Freeze() *RecordID {
+	return (*RecordID)(unsafe.Pointer(s))
+}
+func (s *RecordIDMut) SetStreamID(v int64) *RecordIDMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.streamID))[:], uint64(v))
+	return s
+}
+func (s *RecordIDMut) SetBlockID(v int64) *RecordIDMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.blockID))[:], uint64(v))
+	return s
+}
+func (s *RecordIDMut) SetId(v int64) *RecordIDMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+
+type Savepoint struct {
+	recordID  RecordID
+	timestamp int64
+	writerID  int64
+}
+
+func (s *Savepoint) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Savepoint) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["writerID"] = s.WriterID()
+	return m
+}
+
+func (s *Savepoint) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 40 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Savepoint) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Savepoint) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Savepoint) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Savepoint) Read(b []byte) (n int, err error) {
+	if len(b) < 40 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Savepoint)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 40, nil
+}
+func (s *Savepoint) UnmarshalBinary(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	v := (*Savepoint)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Savepoint) Clone() *Savepoint {
+	v := &Savepoint{}
+	*v = *s
+	return v
+}
+func (s *Savepoint) Bytes() []byte {
+	return (*(*[40]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Savepoint) Mut() *SavepointMut {
+	return (*SavepointMut)(unsafe.Pointer(s))
+}
+
+// VerifySavepoint checks b holds a well formed Savepoint that is safe to reinterpret.
+func VerifySavepoint(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Savepoint{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Savepoint)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Savepoint) Verify(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Savepoint.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretSavepoint casts b to a *Savepoint without copying.
+func ReinterpretSavepoint(b []byte) (*Savepoint, error) {
+	if len(b) < 40 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Savepoint{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Savepoint)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Savepoint) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *Savepoint) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *Savepoint) WriterID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:]))
+}
+
+type SavepointMut struct {
+	Savepoint
+}
+
+func (s *SavepointMut) Clone() *SavepointMut {
+	v := &SavepointMut{}
+	*v = *s
+	return v
+}
+func (s *SavepointMut) Freeze() *Savepoint {
+	return (*Savepoint)(unsafe.Pointer(s))
+}
+func (s *SavepointMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *SavepointMut) SetRecordID(v *RecordID) *SavepointMut {
+	s.recordID = *v
+	return s
+}
+func (s *SavepointMut) SetTimestamp(v int64) *SavepointMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *SavepointMut) SetWriterID(v int64) *SavepointMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:], uint64(v))
+	return s
+}
+
+type Started struct {
+	recordID  RecordID
+	timestamp int64
+	writerID  int64
+	stops     int64
+}
+
+func (s *Started) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Started) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["writerID"] = s.WriterID()
+	m["stops"] = s.Stops()
+	return m
+}
+
+func (s *Started) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 48 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Started) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Started) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Started) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Started) Read(b []byte) (n int, err error) {
+	if len(b) < 48 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Started)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 48, nil
+}
+func (s *Started) UnmarshalBinary(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	v := (*Started)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Started) Clone() *Started {
+	v := &Started{}
+	*v = *s
+	return v
+}
+func (s *Started) Bytes() []byte {
+	return (*(*[48]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Started) Mut() *StartedMut {
+	return (*StartedMut)(unsafe.Pointer(s))
+}
+
+// VerifyStarted checks b holds a well formed Started that is safe to reinterpret.
+func VerifyStarted(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Started{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Started)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Started) Verify(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Started.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretStarted casts b to a *Started without copying.
+func ReinterpretStarted(b []byte) (*Started, error) {
+	if len(b) < 48 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Started{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Started)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Started) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *Started) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *Started) WriterID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:]))
+}
+func (s *Started) Stops() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.stops))[:]))
+}
+
+type StartedMut struct {
+	Started
+}
+
+func (s *StartedMut) Clone() *StartedMut {
+	v := &StartedMut{}
+	*v = *s
+	return v
+}
+func (s *StartedMut) Freeze() *Started {
+	return (*Started)(unsafe.Pointer(s))
+}
+func (s *StartedMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *StartedMut) SetRecordID(v *RecordID) *StartedMut {
+	s.recordID = *v
+	return s
+}
+func (s *StartedMut) SetTimestamp(v int64) *StartedMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *StartedMut) SetWriterID(v int64) *StartedMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:], uint64(v))
+	return s
+}
+func (s *StartedMut) SetStops(v int64) *StartedMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.stops))[:], uint64(v))
+	return s
+}
+
+type Starting struct {
+	recordID  RecordID
+	timestamp int64
+	writerID  int64
+}
+
+func (s *Starting) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Starting) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["writerID"] = s.WriterID()
+	return m
+}
+
+func (s *Starting) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 40 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Starting) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[40]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Starting) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Starting) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[40]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Starting) Read(b []byte) (n int, err error) {
+	if len(b) < 40 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Starting)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 40, nil
+}
+func (s *Starting) UnmarshalBinary(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	v := (*Starting)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Starting) Clone() *Starting {
+	v := &Starting{}
+	*v = *s
+	return v
+}
+func (s *Starting) Bytes() []byte {
+	return (*(*[40]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Starting) Mut() *StartingMut {
+	return (*StartingMut)(unsafe.Pointer(s))
+}
+
+// VerifyStarting checks b holds a well formed Starting that is safe to reinterpret.
+func VerifyStarting(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Starting{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Starting)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Starting) Verify(b []byte) error {
+	if len(b) < 40 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Starting.recordID: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretStarting casts b to a *Starting without copying.
+func ReinterpretStarting(b []byte) (*Starting, error) {
+	if len(b) < 40 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Starting{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Starting)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Starting) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *Starting) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *Starting) WriterID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:]))
+}
+
+type StartingMut struct {
+	Starting
+}
+
+func (s *StartingMut) Clone() *StartingMut {
+	v := &StartingMut{}
+	*v = *s
+	return v
+}
+func (s *StartingMut) Freeze() *Starting {
+	return (*Starting)(unsafe.Pointer(s))
+}
+func (s *StartingMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *StartingMut) SetRecordID(v *RecordID) *StartingMut {
+	s.recordID = *v
+	return s
+}
+func (s *StartingMut) SetTimestamp(v int64) *StartingMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *StartingMut) SetWriterID(v int64) *StartingMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.writerID))[:], uint64(v))
+	return s
+}
+
+type Stats struct {
+	size   int64
+	count  int64
+	blocks int64
+}
+
+func (s *Stats) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Stats) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["size"] = s.Size()
+	m["count"] = s.Count()
+	m["blocks"] = s.Blocks()
+	return m
+}
+
+func (s *Stats) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[24]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 24 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Stats) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[24]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Stats) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[24]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Stats) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[24]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Stats) Read(b []byte) (n int, err error) {
+	if len(b) < 24 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Stats)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 24, nil
+}
+func (s *Stats) UnmarshalBinary(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	v := (*Stats)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Stats) Clone() *Stats {
+	v := &Stats{}
+	*v = *s
+	return v
+}
+func (s *Stats) Bytes() []byte {
+	return (*(*[24]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Stats) Mut() *StatsMut {
+	return (*StatsMut)(unsafe.Pointer(s))
+}
+
+// VerifyStats checks b holds a well formed Stats that is safe to reinterpret.
+func VerifyStats(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stats{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Stats)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Stats) Verify(b []byte) error {
+	if len(b) < 24 {
+		return io.ErrShortBuffer
+	}
+	return nil
+}
+
+// ReinterpretStats casts b to a *Stats without copying.
+func ReinterpretStats(b []byte) (*Stats, error) {
+	if len(b) < 24 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stats{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Stats)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Stats) Size() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.size))[:]))
+}
+func (s *Stats) Count() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.count))[:]))
+}
+func (s *Stats) Blocks() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.blocks))[:]))
+}
+
+type StatsMut struct {
+	Stats
+}
+
+func (s *StatsMut) Clone() *StatsMut {
+	v := &StatsMut{}
+	*v = *s
+	return v
+}
+func (s *StatsMut) Freeze() *Stats {
+	return (*Stats)(unsafe.Pointer(s))
+}
+func (s *StatsMut) SetSize(v int64) *StatsMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.size))[:], uint64(v))
+	return s
+}
+func (s *StatsMut) SetCount(v int64) *StatsMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.count))[:], uint64(v))
+	return s
+}
+func (s *StatsMut) SetBlocks(v int64) *StatsMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.blocks))[:], uint64(v))
+	return s
+}
+
+type Stopped struct {
+	recordID  RecordID
+	timestamp int64
+	starts    int64
+	reason    StopReason
+	_         [7]byte // Padding
+}
+
+func (s *Stopped) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Stopped) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["recordID"] = s.RecordID().MarshalMap(nil)
+	m["timestamp"] = s.Timestamp()
+	m["starts"] = s.Starts()
+	m["reason"] = s.Reason()
+	return m
+}
+
+func (s *Stopped) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 48 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Stopped) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Stopped) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Stopped) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Stopped) Read(b []byte) (n int, err error) {
+	if len(b) < 48 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Stopped)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 48, nil
+}
+func (s *Stopped) UnmarshalBinary(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	v := (*Stopped)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Stopped) Clone() *Stopped {
+	v := &Stopped{}
+	*v = *s
+	return v
+}
+func (s *Stopped) Bytes() []byte {
+	return (*(*[48]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Stopped) Mut() *StoppedMut {
+	return (*StoppedMut)(unsafe.Pointer(s))
+}
+
+// VerifyStopped checks b holds a well formed Stopped that is safe to reinterpret.
+func VerifyStopped(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stopped{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Stopped)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Stopped) Verify(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if err := s.recordID.Verify(b[0:]); err != nil {
+		return fmt.Errorf("Stopped.recordID: %w", err)
+	}
+	if !s.reason.Valid() {
+		return fmt.Errorf("Stopped.reason: %w", wap.ErrInvalidEnum)
+	}
+	return nil
+}
+
+// ReinterpretStopped casts b to a *Stopped without copying.
+func ReinterpretStopped(b []byte) (*Stopped, error) {
+	if len(b) < 48 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stopped{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Stopped)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Stopped) RecordID() *RecordID {
+	return &s.recordID
+}
+func (s *Stopped) Timestamp() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:]))
+}
+func (s *Stopped) Starts() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.starts))[:]))
+}
+func (s *Stopped) Reason() StopReason {
+	return s.reason
+}
+
+type StoppedMut struct {
+	Stopped
+}
+
+func (s *StoppedMut) Clone() *StoppedMut {
+	v := &StoppedMut{}
+	*v = *s
+	return v
+}
+func (s *StoppedMut) Freeze() *Stopped {
+	return (*Stopped)(unsafe.Pointer(s))
+}
+func (s *StoppedMut) RecordID() *RecordIDMut {
+	return s.recordID.Mut()
+}
+func (s *StoppedMut) SetRecordID(v *RecordID) *StoppedMut {
+	s.recordID = *v
+	return s
+}
+func (s *StoppedMut) SetTimestamp(v int64) *StoppedMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.timestamp))[:], uint64(v))
+	return s
+}
+func (s *StoppedMut) SetStarts(v int64) *StoppedMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.starts))[:], uint64(v))
+	return s
+}
+func (s *StoppedMut) SetReason(v StopReason) *StoppedMut {
+	s.reason = v
+	return s
+}
+
+type Stream struct {
+	id        int64
+	created   int64
+	accountID int64
+	duration  int64
+	name      String32
+	record    int32
+	kind      StreamKind
+	schema    SchemaKind
+	realTime  bool
+	blockSize byte
+}
+
+func (s *Stream) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *Stream) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["id"] = s.Id()
+	m["created"] = s.Created()
+	m["accountID"] = s.AccountID()
+	m["duration"] = s.Duration()
+	m["name"] = s.Name()
+	m["record"] = s.Record()
+	m["kind"] = s.Kind()
+	m["schema"] = s.Schema()
+	m["realTime"] = s.RealTime()
+	m["blockSize"] = s.BlockSize()
+	return m
+}
+
+func (s *Stream) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[72]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 72 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *Stream) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[72]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *Stream) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[72]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *Stream) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[72]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *Stream) Read(b []byte) (n int, err error) {
+	if len(b) < 72 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*Stream)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 72, nil
+}
+func (s *Stream) UnmarshalBinary(b []byte) error {
+	if len(b) < 72 {
+		return io.ErrShortBuffer
+	}
+	v := (*Stream)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *Stream) Clone() *Stream {
+	v := &Stream{}
+	*v = *s
+	return v
+}
+func (s *Stream) Bytes() []byte {
+	return (*(*[72]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *Stream) Mut() *StreamMut {
+	return (*StreamMut)(unsafe.Pointer(s))
+}
+
+// VerifyStream checks b holds a well formed Stream that is safe to reinterpret.
+func VerifyStream(b []byte) error {
+	if len(b) < 72 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stream{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*Stream)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *Stream) Verify(b []byte) error {
+	if len(b) < 72 {
+		return io.ErrShortBuffer
+	}
+	if err := s.name.Verify(); err != nil {
+		return fmt.Errorf("Stream.name: %w", err)
+	}
+	if !s.kind.Valid() {
+		return fmt.Errorf("Stream.kind: %w", wap.ErrInvalidEnum)
+	}
+	if !s.schema.Valid() {
+		return fmt.Errorf("Stream.schema: %w", wap.ErrInvalidEnum)
+	}
+	return nil
+}
+
+// ReinterpretStream casts b to a *Stream without copying.
+func ReinterpretStream(b []byte) (*Stream, error) {
+	if len(b) < 72 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(Stream{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*Stream)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *Stream) Id() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.id))[:]))
+}
+func (s *Stream) Created() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.created))[:]))
+}
+func (s *Stream) AccountID() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.accountID))[:]))
+}
+func (s *Stream) Duration() int64 {
+	return int64(binary.LittleEndian.Uint64((*[8]byte)(unsafe.Pointer(&s.duration))[:]))
+}
+func (s *Stream) Name() *String32 {
+	return &s.name
+}
+func (s *Stream) Record() int32 {
+	return int32(binary.LittleEndian.Uint32((*[4]byte)(unsafe.Pointer(&s.record))[:]))
+}
+func (s *Stream) Kind() StreamKind {
+	return s.kind
+}
+func (s *Stream) Schema() SchemaKind {
+	return s.schema
+}
+func (s *Stream) RealTime() bool {
+	return s.realTime
+}
+func (s *Stream) BlockSize() byte {
+	return s.blockSize
+}
+
+type StreamMut struct {
+	Stream
+}
+
+func (s *StreamMut) Clone() *StreamMut {
+	v := &StreamMut{}
+	*v = *s
+	return v
+}
+func (s *StreamMut) Freeze() *Stream {
+	return (*Stream)(unsafe.Pointer(s))
+}
+func (s *StreamMut) SetId(v int64) *StreamMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.id))[:], uint64(v))
+	return s
+}
+func (s *StreamMut) SetCreated(v int64) *StreamMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.created))[:], uint64(v))
+	return s
+}
+func (s *StreamMut) SetAccountID(v int64) *StreamMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.accountID))[:], uint64(v))
+	return s
+}
+func (s *StreamMut) SetDuration(v int64) *StreamMut {
+	binary.LittleEndian.PutUint64((*[8]byte)(unsafe.Pointer(&s.duration))[:], uint64(v))
+	return s
+}
+func (s *StreamMut) Name() *String32Mut {
+	return s.name.Mut()
+}
+func (s *StreamMut) SetName(v *String32) *StreamMut {
+	s.name = *v
+	return s
+}
+func (s *StreamMut) SetRecord(v int32) *StreamMut {
+	binary.LittleEndian.PutUint32((*[4]byte)(unsafe.Pointer(&s.record))[:], uint32(v))
+	return s
+}
+func (s *StreamMut) SetKind(v StreamKind) *StreamMut {
+	s.kind = v
+	return s
+}
+func (s *StreamMut) SetSchema(v SchemaKind) *StreamMut {
+	s.schema = v
+	return s
+}
+func (s *StreamMut) SetRealTime(v bool) *StreamMut {
+	s.realTime = v
+	return s
+}
+func (s *StreamMut) SetBlockSize(v byte) *StreamMut {
+	s.blockSize = v
+	return s
+}
+
+type StreamStats struct {
+	storage  Stats
+	appender Stats
+}
+
+func (s *StreamStats) String() string {
+	return fmt.Sprintf("%v", s.MarshalMap(nil))
+}
+
+func (s *StreamStats) MarshalMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m["storage"] = s.Storage().MarshalMap(nil)
+	m["appender"] = s.Appender().MarshalMap(nil)
+	return m
+}
+
+func (s *StreamStats) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, (*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	if err != nil {
+		return int64(n), err
+	}
+	if n != 48 {
+		return int64(n), io.ErrShortBuffer
+	}
+	return int64(n), nil
+}
+func (s *StreamStats) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write((*(*[48]byte)(unsafe.Pointer(s)))[0:])
+	return int64(n), err
+}
+func (s *StreamStats) MarshalBinaryTo(b []byte) []byte {
+	return append(b, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...)
+}
+func (s *StreamStats) MarshalBinary() ([]byte, error) {
+	var v []byte
+	return append(v, (*(*[48]byte)(unsafe.Pointer(s)))[0:]...), nil
+}
+func (s *StreamStats) Read(b []byte) (n int, err error) {
+	if len(b) < 48 {
+		return -1, io.ErrShortBuffer
+	}
+	v := (*StreamStats)(unsafe.Pointer(&b[0]))
+	*v = *s
+	return 48, nil
+}
+func (s *StreamStats) UnmarshalBinary(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	v := (*StreamStats)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+func (s *StreamStats) Clone() *StreamStats {
+	v := &StreamStats{}
+	*v = *s
+	return v
+}
+func (s *StreamStats) Bytes() []byte {
+	return (*(*[48]byte)(unsafe.Pointer(s)))[0:]
+}
+func (s *StreamStats) Mut() *StreamStatsMut {
+	return (*StreamStatsMut)(unsafe.Pointer(s))
+}
+
+// VerifyStreamStats checks b holds a well formed StreamStats that is safe to reinterpret.
+func VerifyStreamStats(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(StreamStats{}) != 0 {
+		return wap.ErrMisaligned
+	}
+	return (*StreamStats)(unsafe.Pointer(&b[0])).Verify(b)
+}
+
+// Verify checks enum values, string lengths and that every VPointer stays within b.
+// b is the buffer beginning at s.
+func (s *StreamStats) Verify(b []byte) error {
+	if len(b) < 48 {
+		return io.ErrShortBuffer
+	}
+	if err := s.storage.Verify(b[0:]); err != nil {
+		return fmt.Errorf("StreamStats.storage: %w", err)
+	}
+	if err := s.appender.Verify(b[24:]); err != nil {
+		return fmt.Errorf("StreamStats.appender: %w", err)
+	}
+	return nil
+}
+
+// ReinterpretStreamStats casts b to a *StreamStats without copying.
+func ReinterpretStreamStats(b []byte) (*StreamStats, error) {
+	if len(b) < 48 {
+		return nil, io.ErrShortBuffer
+	}
+	if uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(StreamStats{}) != 0 {
+		return nil, wap.ErrMisaligned
+	}
+	return (*StreamStats)(unsafe.Pointer(&b[0])), nil
+}
+
+func (s *StreamStats) Storage() *Stats {
+	return &s.storage
+}
+func (s *StreamStats) Appender() *Stats {
+	return &s.appender
+}
+
+type StreamStatsMut struct {
+	StreamStats
+}
+
+func (s *StreamStatsMut) Clone() *StreamStatsMut {
+	v := &StreamStatsMut{}
+	*v = *s
+	return v
+}
+func (s *StreamStatsMut) Freeze() *StreamStats {
+	return (*StreamStats)(unsafe.Pointer(s))
+}
+func (s *StreamStatsMut) Storage() *StatsMut {
+	return s.storage.Mut()
+}
+func (s *StreamStatsMut) SetStorage(v *Stats) *StreamStatsMut {
+	s.storage = *v
+	return s
+}
+func (s *StreamStatsMut) Appender() *StatsMut {
+	return s.appender.Mut()
+}
+func (s *StreamStatsMut) SetAppender(v *Stats) *StreamStatsMut {
+	s.appender = *v
+	return s
+}
+
+type Bytes16306 [16306]byte
+
+func NewBytes16306(s string) *Bytes16306 {
+	v := Bytes16306{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes16306) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes16306) Len() int {
+	return 16306
+}
+func (s *Bytes16306) Cap() int {
+	return 16306
+}
+func (s *Bytes16306) StringClone() string {
+	b := s[0:16306]
+	return string(b)
+}
+func (s *Bytes16306) String() string {
+	b := s[0:16306]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes16306) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes16306) Clone() *Bytes16306 {
+	v := Bytes16306{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes16306) Mut() *Bytes16306Mut {
+	return *(**Bytes16306Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes16306) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes16306) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes16306) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes16306) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes16306) UnmarshalBinary(b []byte) error {
+	if len(b) < 16306 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes16306)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes16306Mut struct {
+	Bytes16306
+}
+
+func (s *Bytes16306Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes1968 [1968]byte
+
+func NewBytes1968(s string) *Bytes1968 {
+	v := Bytes1968{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes1968) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes1968) Len() int {
+	return 1968
+}
+func (s *Bytes1968) Cap() int {
+	return 1968
+}
+func (s *Bytes1968) StringClone() string {
+	b := s[0:1968]
+	return string(b)
+}
+func (s *Bytes1968) String() string {
+	b := s[0:1968]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes1968) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes1968) Clone() *Bytes1968 {
+	v := Bytes1968{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes1968) Mut() *Bytes1968Mut {
+	return *(**Bytes1968Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes1968) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes1968) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes1968) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes1968) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes1968) UnmarshalBinary(b []byte) error {
+	if len(b) < 1968 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes1968)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes1968Mut struct {
+	Bytes1968
+}
+
+func (s *Bytes1968Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes32688 [32688]byte
+
+func NewBytes32688(s string) *Bytes32688 {
+	v := Bytes32688{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes32688) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes32688) Len() int {
+	return 32688
+}
+func (s *Bytes32688) Cap() int {
+	return 32688
+}
+func (s *Bytes32688) StringClone() string {
+	b := s[0:32688]
+	return string(b)
+}
+func (s *Bytes32688) String() string {
+	b := s[0:32688]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes32688) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes32688) Clone() *Bytes32688 {
+	v := Bytes32688{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes32688) Mut() *Bytes32688Mut {
+	return *(**Bytes32688Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes32688) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes32688) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes32688) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes32688) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes32688) UnmarshalBinary(b []byte) error {
+	if len(b) < 32688 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes32688)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes32688Mut struct {
+	Bytes32688
+}
+
+func (s *Bytes32688Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes4016 [4016]byte
+
+func NewBytes4016(s string) *Bytes4016 {
+	v := Bytes4016{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes4016) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes4016) Len() int {
+	return 4016
+}
+func (s *Bytes4016) Cap() int {
+	return 4016
+}
+func (s *Bytes4016) StringClone() string {
+	b := s[0:4016]
+	return string(b)
+}
+func (s *Bytes4016) String() string {
+	b := s[0:4016]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes4016) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes4016) Clone() *Bytes4016 {
+	v := Bytes4016{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes4016) Mut() *Bytes4016Mut {
+	return *(**Bytes4016Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes4016) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes4016) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes4016) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes4016) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes4016) UnmarshalBinary(b []byte) error {
+	if len(b) < 4016 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes4016)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes4016Mut struct {
+	Bytes4016
+}
+
+func (s *Bytes4016Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes65456 [65456]byte
+
+func NewBytes65456(s string) *Bytes65456 {
+	v := Bytes65456{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes65456) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes65456) Len() int {
+	return 65456
+}
+func (s *Bytes65456) Cap() int {
+	return 65456
+}
+func (s *Bytes65456) StringClone() string {
+	b := s[0:65456]
+	return string(b)
+}
+func (s *Bytes65456) String() string {
+	b := s[0:65456]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes65456) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes65456) Clone() *Bytes65456 {
+	v := Bytes65456{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes65456) Mut() *Bytes65456Mut {
+	return *(**Bytes65456Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes65456) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes65456) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes65456) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes65456) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes65456) UnmarshalBinary(b []byte) error {
+	if len(b) < 65456 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes65456)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes65456Mut struct {
+	Bytes65456
+}
+
+func (s *Bytes65456Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes8112 [8112]byte
+
+func NewBytes8112(s string) *Bytes8112 {
+	v := Bytes8112{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes8112) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes8112) Len() int {
+	return 8112
+}
+func (s *Bytes8112) Cap() int {
+	return 8112
+}
+func (s *Bytes8112) StringClone() string {
+	b := s[0:8112]
+	return string(b)
+}
+func (s *Bytes8112) String() string {
+	b := s[0:8112]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes8112) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes8112) Clone() *Bytes8112 {
+	v := Bytes8112{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes8112) Mut() *Bytes8112Mut {
+	return *(**Bytes8112Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes8112) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes8112) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes8112) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes8112) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes8112) UnmarshalBinary(b []byte) error {
+	if len(b) < 8112 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes8112)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes8112Mut struct {
+	Bytes8112
+}
+
+func (s *Bytes8112Mut) Set(v string) {
+	s.set(v)
+}
+
+type Bytes944 [944]byte
+
+func NewBytes944(s string) *Bytes944 {
+	v := Bytes944{}
+	v.set(s)
+	return &v
+}
+func (s *Bytes944) set(v string) {
+	copy(s[0:], v)
+}
+func (s *Bytes944) Len() int {
+	return 944
+}
+func (s *Bytes944) Cap() int {
+	return 944
+}
+func (s *Bytes944) StringClone() string {
+	b := s[0:944]
+	return string(b)
+}
+func (s *Bytes944) String() string {
+	b := s[0:944]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *Bytes944) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *Bytes944) Clone() *Bytes944 {
+	v := Bytes944{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *Bytes944) Mut() *Bytes944Mut {
+	return *(**Bytes944Mut)(unsafe.Pointer(&s))
+}
+func (s *Bytes944) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *Bytes944) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *Bytes944) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *Bytes944) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *Bytes944) UnmarshalBinary(b []byte) error {
+	if len(b) < 944 {
+		return io.ErrShortBuffer
+	}
+	v := (*Bytes944)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type Bytes944Mut struct {
+	Bytes944
+}
+
+func (s *Bytes944Mut) Set(v string) {
+	s.set(v)
+}
+
+type String32 [32]byte
+
+func NewString32(s string) *String32 {
+	v := String32{}
+	v.set(s)
+	return &v
+}
+func (s *String32) set(v string) {
+	copy(s[0:31], v)
+	c := 31
+	l := len(v)
+	if l > c {
+		s[31] = byte(c)
+	} else {
+		s[31] = byte(l)
+	}
+}
+func (s *String32) Len() int {
+	return int(s[31])
+}
+
+// Verify checks the length byte does not exceed the capacity.
+func (s *String32) Verify() error {
+	if int(s[31]) > 31 {
+		return wap.ErrInvalidLength
+	}
+	return nil
+}
+func (s *String32) Cap() int {
+	return 31
+}
+func (s *String32) StringClone() string {
+	b := s[0:s.Len()]
+	return string(b)
+}
+func (s *String32) String() string {
+	b := s[0:s.Len()]
+	return *(*string)(unsafe.Pointer(&b))
+}
+func (s *String32) Bytes() []byte {
+	return s[0:s.Len()]
+}
+func (s *String32) Clone() *String32 {
+	v := String32{}
+	copy(s[0:], v[0:])
+	return &v
+}
+func (s *String32) Mut() *String32Mut {
+	return *(**String32Mut)(unsafe.Pointer(&s))
+}
+func (s *String32) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, s[0:])
+	return int64(n), err
+}
+func (s *String32) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(s[0:])
+	return int64(n), err
+}
+func (s *String32) MarshalBinaryTo(b []byte) []byte {
+	return append(b, s[0:]...)
+}
+func (s *String32) MarshalBinary() ([]byte, error) {
+	return s[0:s.Len()], nil
+}
+func (s *String32) UnmarshalBinary(b []byte) error {
+	if len(b) < 32 {
+		return io.ErrShortBuffer
+	}
+	v := (*String32)(unsafe.Pointer(&b[0]))
+	*s = *v
+	return nil
+}
+
+type String32Mut struct {
+	String32
+}
+
+func (s *String32Mut) Set(v string) {
+	s.set(v)
+}
+func init() {
+	layout := func(name string, actual, expected uintptr) {
+		if actual != expected {
+			panic(fmt.Sprintf("%s = %d, expected = %d", name, actual, expected))
+		}
+	}
+	layout("sizeof AccountStats", unsafe.Sizeof(AccountStats{}), 64)
+	layout("sizeof AccountStatsMut", unsafe.Sizeof(AccountStatsMut{}), 64)
+	layout("offsetof AccountStats.id", unsafe.Offsetof(AccountStats{}.id), 0)
+	layout("sizeof AccountStats.id", unsafe.Sizeof(AccountStats{}.id), 8)
+	layout("offsetof AccountStats.storage", unsafe.Offsetof(AccountStats{}.storage), 8)
+	layout("sizeof AccountStats.storage", unsafe.Sizeof(AccountStats{}.storage), 24)
+	layout("offsetof AccountStats.appender", unsafe.Offsetof(AccountStats{}.appender), 32)
+	layout("sizeof AccountStats.appender", unsafe.Sizeof(AccountStats{}.appender), 24)
+	layout("offsetof AccountStats.streams", unsafe.Offsetof(AccountStats{}.streams), 56)
+	layout("sizeof AccountStats.streams", unsafe.Sizeof(AccountStats{}.streams), 8)
+	layout("sizeof Block1", unsafe.Sizeof(Block1{}), 1056)
+	layout("sizeof Block1Mut", unsafe.Sizeof(Block1Mut{}), 1056)
+	layout("offsetof Block1.head", unsafe.Offsetof(Block1{}.head), 0)
+	layout("sizeof Block1.head", unsafe.Sizeof(Block1{}.head), 112)
+	layout("offsetof Block1.body", unsafe.Offsetof(Block1{}.body), 112)
+	layout("sizeof Block1.body", unsafe.Sizeof(Block1{}.body), 944)
+	layout("sizeof Block16", unsafe.Sizeof(Block16{}), 16424)
+	layout("sizeof Block16Mut", unsafe.Sizeof(Block16Mut{}), 16424)
+	layout("offsetof Block16.head", unsafe.Offsetof(Block16{}.head), 0)
+	layout("sizeof Block16.head", unsafe.Sizeof(Block16{}.head), 112)
+	layout("offsetof Block16.body", unsafe.Offsetof(Block16{}.body), 112)
+	layout("sizeof Block16.body", unsafe.Sizeof(Block16{}.body), 16306)
+	layout("sizeof Block2", unsafe.Sizeof(Block2{}), 2080)
+	layout("sizeof Block2Mut", unsafe.Sizeof(Block2Mut{}), 2080)
+	layout("offsetof Block2.head", unsafe.Offsetof(Block2{}.head), 0)
+	layout("sizeof Block2.head", unsafe.Sizeof(Block2{}.head), 112)
+	layout("offsetof Block2.body", unsafe.Offsetof(Block2{}.body), 112)
+	layout("sizeof Block2.body", unsafe.Sizeof(Block2{}.body), 1968)
+	layout("sizeof Block32", unsafe.Sizeof(Block32{}), 32800)
+	layout("sizeof Block32Mut", unsafe.Sizeof(Block32Mut{}), 32800)
+	layout("offsetof Block32.head", unsafe.Offsetof(Block32{}.head), 0)
+	layout("sizeof Block32.head", unsafe.Sizeof(Block32{}.head), 112)
+	layout("offsetof Block32.body", unsafe.Offsetof(Block32{}.body), 112)
+	layout("sizeof Block32.body", unsafe.Sizeof(Block32{}.body), 32688)
+	layout("sizeof Block4", unsafe.Sizeof(Block4{}), 4128)
+	layout("sizeof Block4Mut", unsafe.Sizeof(Block4Mut{}), 4128)
+	layout("offsetof Block4.head", unsafe.Offsetof(Block4{}.head), 0)
+	layout("sizeof Block4.head", unsafe.Sizeof(Block4{}.head), 112)
+	layout("offsetof Block4.body", unsafe.Offsetof(Block4{}.body), 112)
+	layout("sizeof Block4.body", unsafe.Sizeof(Block4{}.body), 4016)
+	layout("sizeof Block64", unsafe.Sizeof(Block64{}), 65568)
+	layout("sizeof Block64Mut", unsafe.Sizeof(Block64Mut{}), 65568)
+	layout("offsetof Block64.head", unsafe.Offsetof(Block64{}.head), 0)
+	layout("sizeof Block64.head", unsafe.Sizeof(Block64{}.head), 112)
+	layout("offsetof Block64.body", unsafe.Offsetof(Block64{}.body), 112)
+	layout("sizeof Block64.body", unsafe.Sizeof(Block64{}.body), 65456)
+	layout("sizeof Block8", unsafe.Sizeof(Block8{}), 8224)
+	layout("sizeof Block8Mut", unsafe.Sizeof(Block8Mut{}), 8224)
+	layout("offsetof Block8.head", unsafe.Offsetof(Block8{}.head), 0)
+	layout("sizeof Block8.head", unsafe.Sizeof(Block8{}.head), 112)
+	layout("offsetof Block8.body", unsafe.Offsetof(Block8{}.body), 112)
+	layout("sizeof Block8.body", unsafe.Sizeof(Block8{}.body), 8112)
+	layout("sizeof BlockHeader", unsafe.Sizeof(BlockHeader{}), 112)
+	layout("sizeof BlockHeaderMut", unsafe.Sizeof(BlockHeaderMut{}), 112)
+	layout("offsetof BlockHeader.streamID", unsafe.Offsetof(BlockHeader{}.streamID), 0)
+	layout("sizeof BlockHeader.streamID", unsafe.Sizeof(BlockHeader{}.streamID), 8)
+	layout("offsetof BlockHeader.id", unsafe.Offsetof(BlockHeader{}.id), 8)
+	layout("sizeof BlockHeader.id", unsafe.Sizeof(BlockHeader{}.id), 8)
+	layout("offsetof BlockHeader.created", unsafe.Offsetof(BlockHeader{}.created), 16)
+	layout("sizeof BlockHeader.created", unsafe.Sizeof(BlockHeader{}.created), 8)
+	layout("offsetof BlockHeader.completed", unsafe.Offsetof(BlockHeader{}.completed), 24)
+	layout("sizeof BlockHeader.completed", unsafe.Sizeof(BlockHeader{}.completed), 8)
+	layout("offsetof BlockHeader.min", unsafe.Offsetof(BlockHeader{}.min), 32)
+	layout("sizeof BlockHeader.min", unsafe.Sizeof(BlockHeader{}.min), 8)
+	layout("offsetof BlockHeader.max", unsafe.Offsetof(BlockHeader{}.max), 40)
+	layout("sizeof BlockHeader.max", unsafe.Sizeof(BlockHeader{}.max), 8)
+	layout("offsetof BlockHeader.start", unsafe.Offsetof(BlockHeader{}.start), 48)
+	layout("sizeof BlockHeader.start", unsafe.Sizeof(BlockHeader{}.start), 8)
+	layout("offsetof BlockHeader.end", unsafe.Offsetof(BlockHeader{}.end), 56)
+	layout("sizeof BlockHeader.end", unsafe.Sizeof(BlockHeader{}.end), 8)
+	layout("offsetof BlockHeader.savepoint", unsafe.Offsetof(BlockHeader{}.savepoint), 64)
+	layout("sizeof BlockHeader.savepoint", unsafe.Sizeof(BlockHeader{}.savepoint), 8)
+	layout("offsetof BlockHeader.blocks", unsafe.Offsetof(BlockHeader{}.blocks), 72)
+	layout("sizeof BlockHeader.blocks", unsafe.Sizeof(BlockHeader{}.blocks), 8)
+	layout("offsetof BlockHeader.records", unsafe.Offsetof(BlockHeader{}.records), 80)
+	layout("sizeof BlockHeader.records", unsafe.Sizeof(BlockHeader{}.records), 8)
+	layout("offsetof BlockHeader.count", unsafe.Offsetof(BlockHeader{}.count), 88)
+	layout("sizeof BlockHeader.count", unsafe.Sizeof(BlockHeader{}.count), 4)
+	layout("offsetof BlockHeader.seq", unsafe.Offsetof(BlockHeader{}.seq), 92)
+	layout("sizeof BlockHeader.seq", unsafe.Sizeof(BlockHeader{}.seq), 4)
+	layout("offsetof BlockHeader.size", unsafe.Offsetof(BlockHeader{}.size), 96)
+	layout("sizeof BlockHeader.size", unsafe.Sizeof(BlockHeader{}.size), 4)
+	layout("offsetof BlockHeader.sizeU", unsafe.Offsetof(BlockHeader{}.sizeU), 100)
+	layout("sizeof BlockHeader.sizeU", unsafe.Sizeof(BlockHeader{}.sizeU), 4)
+	layout("offsetof BlockHeader.sizeX", unsafe.Offsetof(BlockHeader{}.sizeX), 104)
+	layout("sizeof BlockHeader.sizeX", unsafe.Sizeof(BlockHeader{}.sizeX), 4)
+	layout("offsetof BlockHeader.compression", unsafe.Offsetof(BlockHeader{}.compression), 108)
+	layout("sizeof BlockHeader.compression", unsafe.Sizeof(BlockHeader{}.compression), 1)
+	layout("sizeof BlockID", unsafe.Sizeof(BlockID{}), 16)
+	layout("sizeof BlockIDMut", unsafe.Sizeof(BlockIDMut{}), 16)
+	layout("offsetof BlockID.streamID", unsafe.Offsetof(BlockID{}.streamID), 0)
+	layout("sizeof BlockID.streamID", unsafe.Sizeof(BlockID{}.streamID), 8)
+	layout("offsetof BlockID.id", unsafe.Offsetof(BlockID{}.id), 8)
+	layout("sizeof BlockID.id", unsafe.Sizeof(BlockID{}.id), 8)
+	layout("sizeof EOB", unsafe.Sizeof(EOB{}), 40)
+	layout("sizeof EOBMut", unsafe.Sizeof(EOBMut{}), 40)
+	layout("offsetof EOB.recordID", unsafe.Offsetof(EOB{}.recordID), 0)
+	layout("sizeof EOB.recordID", unsafe.Sizeof(EOB{}.recordID), 24)
+	layout("offsetof EOB.timestamp", unsafe.Offsetof(EOB{}.timestamp), 24)
+	layout("sizeof EOB.timestamp", unsafe.Sizeof(EOB{}.timestamp), 8)
+	layout("offsetof EOB.savepoint", unsafe.Offsetof(EOB{}.savepoint), 32)
+	layout("sizeof EOB.savepoint", unsafe.Sizeof(EOB{}.savepoint), 8)
+	layout("sizeof EOS", unsafe.Sizeof(EOS{}), 48)
+	layout("sizeof EOSMut", unsafe.Sizeof(EOSMut{}), 48)
+	layout("offsetof EOS.recordID", unsafe.Offsetof(EOS{}.recordID), 0)
+	layout("sizeof EOS.recordID", unsafe.Sizeof(EOS{}.recordID), 24)
+	layout("offsetof EOS.timestamp", unsafe.Offsetof(EOS{}.timestamp), 24)
+	layout("sizeof EOS.timestamp", unsafe.Sizeof(EOS{}.timestamp), 8)
+	layout("offsetof EOS.writerID", unsafe.Offsetof(EOS{}.writerID), 32)
+	layout("sizeof EOS.writerID", unsafe.Sizeof(EOS{}.writerID), 8)
+	layout("offsetof EOS.closed", unsafe.Offsetof(EOS{}.closed), 40)
+	layout("sizeof EOS.closed", unsafe.Sizeof(EOS{}.closed), 1)
+	layout("offsetof EOS.waiting", unsafe.Offsetof(EOS{}.waiting), 41)
+	layout("sizeof EOS.waiting", unsafe.Sizeof(EOS{}.waiting), 1)
+	layout("sizeof Progress", unsafe.Sizeof(Progress{}), 64)
+	layout("sizeof ProgressMut", unsafe.Sizeof(ProgressMut{}), 64)
+	layout("offsetof Progress.recordID", unsafe.Offsetof(Progress{}.recordID), 0)
+	layout("sizeof Progress.recordID", unsafe.Sizeof(Progress{}.recordID), 24)
+	layout("offsetof Progress.timestamp", unsafe.Offsetof(Progress{}.timestamp), 24)
+	layout("sizeof Progress.timestamp", unsafe.Sizeof(Progress{}.timestamp), 8)
+	layout("offsetof Progress.writerID", unsafe.Offsetof(Progress{}.writerID), 32)
+	layout("sizeof Progress.writerID", unsafe.Sizeof(Progress{}.writerID), 8)
+	layout("offsetof Progress.started", unsafe.Offsetof(Progress{}.started), 40)
+	layout("sizeof Progress.started", unsafe.Sizeof(Progress{}.started), 8)
+	layout("offsetof Progress.count", unsafe.Offsetof(Progress{}.count), 48)
+	layout("sizeof Progress.count", unsafe.Sizeof(Progress{}.count), 8)
+	layout("offsetof Progress.remaining", unsafe.Offsetof(Progress{}.remaining), 56)
+	layout("sizeof Progress.remaining", unsafe.Sizeof(Progress{}.remaining), 8)
+	layout("sizeof RecordHeader", unsafe.Sizeof(RecordHeader{}), 80)
+	layout("sizeof RecordHeaderMut", unsafe.Sizeof(RecordHeaderMut{}), 80)
+	layout("offsetof RecordHeader.streamID", unsafe.Offsetof(RecordHeader{}.streamID), 0)
+	layout("sizeof RecordHeader.streamID", unsafe.Sizeof(RecordHeader{}.streamID), 8)
+	layout("offsetof RecordHeader.blockID", unsafe.Offsetof(RecordHeader{}.blockID), 8)
+	layout("sizeof RecordHeader.blockID", unsafe.Sizeof(RecordHeader{}.blockID), 8)
+	layout("offsetof RecordHeader.id", unsafe.Offsetof(RecordHeader{}.id), 16)
+	layout("sizeof RecordHeader.id", unsafe.Sizeof(RecordHeader{}.id), 8)
+	layout("offsetof RecordHeader.timestamp", unsafe.Offsetof(RecordHeader{}.timestamp), 24)
+	layout("sizeof RecordHeader.timestamp", unsafe.Sizeof(RecordHeader{}.timestamp), 8)
+	layout("offsetof RecordHeader.start", unsafe.Offsetof(RecordHeader{}.start), 32)
+	layout("sizeof RecordHeader.start", unsafe.Sizeof(RecordHeader{}.start), 8)
+	layout("offsetof RecordHeader.end", unsafe.Offsetof(RecordHeader{}.end), 40)
+	layout("sizeof RecordHeader.end", unsafe.Sizeof(RecordHeader{}.end), 8)
+	layout("offsetof RecordHeader.savepoint", unsafe.Offsetof(RecordHeader{}.savepoint), 48)
+	layout("sizeof RecordHeader.savepoint", unsafe.Sizeof(RecordHeader{}.savepoint), 8)
+	layout("offsetof RecordHeader.savepointR", unsafe.Offsetof(RecordHeader{}.savepointR), 56)
+	layout("sizeof RecordHeader.savepointR", unsafe.Sizeof(RecordHeader{}.savepointR), 8)
+	layout("offsetof RecordHeader.seq", unsafe.Offsetof(RecordHeader{}.seq), 64)
+	layout("sizeof RecordHeader.seq", unsafe.Sizeof(RecordHeader{}.seq), 2)
+	layout("offsetof RecordHeader.size", unsafe.Offsetof(RecordHeader{}.size), 66)
+	layout("sizeof RecordHeader.size", unsafe.Sizeof(RecordHeader{}.size), 2)
+	layout("offsetof RecordHeader.sizeU", unsafe.Offsetof(RecordHeader{}.sizeU), 68)
+	layout("sizeof RecordHeader.sizeU", unsafe.Sizeof(RecordHeader{}.sizeU), 2)
+	layout("offsetof RecordHeader.sizeX", unsafe.Offsetof(RecordHeader{}.sizeX), 70)
+	layout("sizeof RecordHeader.sizeX", unsafe.Sizeof(RecordHeader{}.sizeX), 2)
+	layout("offsetof RecordHeader.compression", unsafe.Offsetof(RecordHeader{}.compression), 72)
+	layout("sizeof RecordHeader.compression", unsafe.Sizeof(RecordHeader{}.compression), 1)
+	layout("offsetof RecordHeader.eob", unsafe.Offsetof(RecordHeader{}.eob), 73)
+	layout("sizeof RecordHeader.eob", unsafe.Sizeof(RecordHeader{}.eob), 1)
+	layout("sizeof RecordID", unsafe.Sizeof(RecordID{}), 24)
+	layout("sizeof RecordIDMut", unsafe.Sizeof(RecordIDMut{}), 24)
+	layout("offsetof RecordID.streamID", unsafe.Offsetof(RecordID{}.streamID), 0)
+	layout("sizeof RecordID.streamID", unsafe.Sizeof(RecordID{}.streamID), 8)
+	layout("offsetof RecordID.blockID", unsafe.Offsetof(RecordID{}.blockID), 8)
+	layout("sizeof RecordID.blockID", unsafe.Sizeof(RecordID{}.blockID), 8)
+	layout("offsetof RecordID.id", unsafe.Offsetof(RecordID{}.id), 16)
+	layout("sizeof RecordID.id", unsafe.Sizeof(RecordID{}.id), 8)
+	layout("sizeof Savepoint", unsafe.Sizeof(Savepoint{}), 40)
+	layout("sizeof SavepointMut", unsafe.Sizeof(SavepointMut{}), 40)
+	layout("offsetof Savepoint.recordID", unsafe.Offsetof(Savepoint{}.recordID), 0)
+	layout("sizeof Savepoint.recordID", unsafe.Sizeof(Savepoint{}.recordID), 24)
+	layout("offsetof Savepoint.timestamp", unsafe.Offsetof(Savepoint{}.timestamp), 24)
+	layout("sizeof Savepoint.timestamp", unsafe.Sizeof(Savepoint{}.timestamp), 8)
+	layout("offsetof Savepoint.writerID", unsafe.Offsetof(Savepoint{}.writerID), 32)
+	layout("sizeof Savepoint.writerID", unsafe.Sizeof(Savepoint{}.writerID), 8)
+	layout("sizeof Started", unsafe.Sizeof(Started{}), 48)
+	layout("sizeof StartedMut", unsafe.Sizeof(StartedMut{}), 48)
+	layout("offsetof Started.recordID", unsafe.Offsetof(Started{}.recordID), 0)
+	layout("sizeof Started.recordID", unsafe.Sizeof(Started{}.recordID), 24)
+	layout("offsetof Started.timestamp", unsafe.Offsetof(Started{}.timestamp), 24)
+	layout("sizeof Started.timestamp", unsafe.Sizeof(Started{}.timestamp), 8)
+	layout("offsetof Started.writerID", unsafe.Offsetof(Started{}.writerID), 32)
+	layout("sizeof Started.writerID", unsafe.Sizeof(Started{}.writerID), 8)
+	layout("offsetof Started.stops", unsafe.Offsetof(Started{}.stops), 40)
+	layout("sizeof Started.stops", unsafe.Sizeof(Started{}.stops), 8)
+	layout("sizeof Starting", unsafe.Sizeof(Starting{}), 40)
+	layout("sizeof StartingMut", unsafe.Sizeof(StartingMut{}), 40)
+	layout("offsetof Starting.recordID", unsafe.Offsetof(Starting{}.recordID), 0)
+	layout("sizeof Starting.recordID", unsafe.Sizeof(Starting{}.recordID), 24)
+	layout("offsetof Starting.timestamp", unsafe.Offsetof(Starting{}.timestamp), 24)
+	layout("sizeof Starting.timestamp", unsafe.Sizeof(Starting{}.timestamp), 8)
+	layout("offsetof Starting.writerID", unsafe.Offsetof(Starting{}.writerID), 32)
+	layout("sizeof Starting.writerID", unsafe.Sizeof(Starting{}.writerID), 8)
+	layout("sizeof Stats", unsafe.Sizeof(Stats{}), 24)
+	layout("sizeof StatsMut", unsafe.Sizeof(StatsMut{}), 24)
+	layout("offsetof Stats.size", unsafe.Offsetof(Stats{}.size), 0)
+	layout("sizeof Stats.size", unsafe.Sizeof(Stats{}.size), 8)
+	layout("offsetof Stats.count", unsafe.Offsetof(Stats{}.count), 8)
+	layout("sizeof Stats.count", unsafe.Sizeof(Stats{}.count), 8)
+	layout("offsetof Stats.blocks", unsafe.Offsetof(Stats{}.blocks), 16)
+	layout("sizeof Stats.blocks", unsafe.Sizeof(Stats{}.blocks), 8)
+	layout("sizeof Stopped", unsafe.Sizeof(Stopped{}), 48)
+	layout("sizeof StoppedMut", unsafe.Sizeof(StoppedMut{}), 48)
+	layout("offsetof Stopped.recordID", unsafe.Offsetof(Stopped{}.recordID), 0)
+	layout("sizeof Stopped.recordID", unsafe.Sizeof(Stopped{}.recordID), 24)
+	layout("offsetof Stopped.timestamp", unsafe.Offsetof(Stopped{}.timestamp), 24)
+	layout("sizeof Stopped.timestamp", unsafe.Sizeof(Stopped{}.timestamp), 8)
+	layout("offsetof Stopped.starts", unsafe.Offsetof(Stopped{}.starts), 32)
+	layout("sizeof Stopped.starts", unsafe.Sizeof(Stopped{}.starts), 8)
+	layout("offsetof Stopped.reason", unsafe.Offsetof(Stopped{}.reason), 40)
+	layout("sizeof Stopped.reason", unsafe.Sizeof(Stopped{}.reason), 1)
+	layout("sizeof Stream", unsafe.Sizeof(Stream{}), 72)
+	layout("sizeof StreamMut", unsafe.Sizeof(StreamMut{}), 72)
+	layout("offsetof Stream.id", unsafe.Offsetof(Stream{}.id), 0)
+	layout("sizeof Stream.id", unsafe.Sizeof(Stream{}.id), 8)
+	layout("offsetof Stream.created", unsafe.Offsetof(Stream{}.created), 8)
+	layout("sizeof Stream.created", unsafe.Sizeof(Stream{}.created), 8)
+	layout("offsetof Stream.accountID", unsafe.Offsetof(Stream{}.accountID), 16)
+	layout("sizeof Stream.accountID", unsafe.Sizeof(Stream{}.accountID), 8)
+	layout("offsetof Stream.duration", unsafe.Offsetof(Stream{}.duration), 24)
+	layout("sizeof Stream.duration", unsafe.Sizeof(Stream{}.duration), 8)
+	layout("offsetof Stream.name", unsafe.Offsetof(Stream{}.name), 32)
+	layout("sizeof Stream.name", unsafe.Sizeof(Stream{}.name), 32)
+	layout("offsetof Stream.record", unsafe.Offsetof(Stream{}.record), 64)
+	layout("sizeof Stream.record", unsafe.Sizeof(Stream{}.record), 4)
+	layout("offsetof Stream.kind", unsafe.Offsetof(Stream{}.kind), 68)
+	layout("sizeof Stream.kind", unsafe.Sizeof(Stream{}.kind), 1)
+	layout("offsetof Stream.schema", unsafe.Offsetof(Stream{}.schema), 69)
+	layout("sizeof Stream.schema", unsafe.Sizeof(Stream{}.schema), 1)
+	layout("offsetof Stream.realTime", unsafe.Offsetof(Stream{}.realTime), 70)
+	layout("sizeof Stream.realTime", unsafe.Sizeof(Stream{}.realTime), 1)
+	layout("offsetof Stream.blockSize", unsafe.Offsetof(Stream{}.blockSize), 71)
+	layout("sizeof Stream.blockSize", unsafe.Sizeof(Stream{}.blockSize), 1)
+	layout("sizeof StreamStats", unsafe.Sizeof(StreamStats{}), 48)
+	layout("sizeof StreamStatsMut", unsafe.Sizeof(StreamStatsMut{}), 48)
+	layout("offsetof StreamStats.storage", unsafe.Offsetof(StreamStats{}.storage), 0)
+	layout("sizeof StreamStats.storage", unsafe.Sizeof(StreamStats{}.storage), 24)
+	layout("offsetof StreamStats.appender", unsafe.Offsetof(StreamStats{}.appender), 24)
+	layout("sizeof StreamStats.appender", unsafe.Sizeof(StreamStats{}.appender), 24)
+}
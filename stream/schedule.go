@@ -0,0 +1,330 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrNotScheduled = errors.New("stream not scheduled")
+
+// Session is a period during which a stream is open, e.g. the trading hours of an
+// exchange. Open is inclusive and Close exclusive.
+type Session struct {
+	Open  time.Time
+	Close time.Time
+}
+
+func (s Session) Contains(t time.Time) bool {
+	return !t.Before(s.Open) && t.Before(s.Close)
+}
+
+// Calendar is the ordered list of sessions of a stream.
+type Calendar struct {
+	sessions []Session
+}
+
+// NewCalendar returns the calendar of sessions which must not overlap.
+func NewCalendar(sessions ...Session) (*Calendar, error) {
+	sorted := append([]Session(nil), sessions...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Open.Before(sorted[j].Open)
+	})
+	for i, s := range sorted {
+		if !s.Open.Before(s.Close) {
+			return nil, fmt.Errorf("session %s closes at %s before it opens", s.Open, s.Close)
+		}
+		if i > 0 && s.Open.Before(sorted[i-1].Close) {
+			return nil, fmt.Errorf("session %s overlaps session %s", s.Open, sorted[i-1].Open)
+		}
+	}
+	return &Calendar{sessions: sorted}, nil
+}
+
+// Sessions returns the sessions of the calendar.
+func (c *Calendar) Sessions() []Session {
+	return c.sessions
+}
+
+// search returns the index of the first session closing after t.
+func (c *Calendar) search(t time.Time) int {
+	return sort.Search(len(c.sessions), func(i int) bool {
+		return c.sessions[i].Close.After(t)
+	})
+}
+
+// Session returns the session open at t.
+func (c *Calendar) Session(t time.Time) (Session, bool) {
+	i := c.search(t)
+	if i < len(c.sessions) && c.sessions[i].Contains(t) {
+		return c.sessions[i], true
+	}
+	return Session{}, false
+}
+
+func (c *Calendar) IsOpen(t time.Time) bool {
+	_, ok := c.Session(t)
+	return ok
+}
+
+// NextOpen returns t when the stream is open at t and the opening of the next session
+// otherwise. It returns false when no session follows t.
+func (c *Calendar) NextOpen(t time.Time) (time.Time, bool) {
+	i := c.search(t)
+	if i == len(c.sessions) {
+		return time.Time{}, false
+	}
+	if c.sessions[i].Contains(t) {
+		return t, true
+	}
+	return c.sessions[i].Open, true
+}
+
+// NextSlot returns the start of the first series slot of duration starting at or after
+// t. Slots are aligned to the opening of their session and closed periods produce no
+// slots; a partial slot at the end of a session is kept.
+func (c *Calendar) NextSlot(t time.Time, duration time.Duration) (time.Time, bool) {
+	for i := c.search(t); i < len(c.sessions); i++ {
+		s := c.sessions[i]
+		if !t.After(s.Open) {
+			return s.Open, true
+		}
+		slot := s.Open.Add((t.Sub(s.Open) + duration - 1) / duration * duration)
+		if slot.Before(s.Close) {
+			return slot, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Weekly describes sessions repeating on days of the week.
+type Weekly struct {
+	Location *time.Location
+	Days     []time.Weekday
+	// Open and Close are wall clock offsets from midnight of the session day, so a 17:00
+	// open stays at 17:00 on the days daylight saving time begins or ends. Close may
+	// exceed 24 hours for sessions ending the next day.
+	Open  time.Duration
+	Close time.Duration
+	// Holidays are the dates without a session.
+	Holidays []time.Time
+}
+
+// Sessions returns the sessions opening on the days from to to inclusive.
+func (w *Weekly) Sessions(from, to time.Time) []Session {
+	location := w.Location
+	if location == nil {
+		location = time.UTC
+	}
+	holidays := make(map[string]bool, len(w.Holidays))
+	for _, h := range w.Holidays {
+		holidays[h.Format("2006-01-02")] = true
+	}
+	var sessions []Session
+	from, to = from.In(location), to.In(location)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	for ; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if holidays[day.Format("2006-01-02")] || !w.trades(day.Weekday()) {
+			continue
+		}
+		sessions = append(sessions, Session{Open: wallClock(day, w.Open), Close: wallClock(day, w.Close)})
+	}
+	return sessions
+}
+
+// wallClock returns the time reading offset on the clock of day's location. Adding offset
+// to midnight would be an hour off on the days the clock changes.
+func wallClock(day time.Time, offset time.Duration) time.Time {
+	days := offset / (24 * time.Hour)
+	offset -= days * 24 * time.Hour
+	hours := offset / time.Hour
+	offset -= hours * time.Hour
+	minutes := offset / time.Minute
+	offset -= minutes * time.Minute
+	seconds := offset / time.Second
+	offset -= seconds * time.Second
+	return time.Date(day.Year(), day.Month(), day.Day()+int(days),
+		int(hours), int(minutes), int(seconds), int(offset), day.Location())
+}
+
+func (w *Weekly) trades(day time.Weekday) bool {
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduler stops and starts streams at the boundaries of their sessions. Readers see
+// Stopped with StopReason_Paused when a session closes, carrying the opening of the
+// next one, and Started when it opens, carrying its close. The messages carry the head
+// and writer of the stream found in the LeaseStore.
+type Scheduler struct {
+	hub     *Hub
+	leases  LeaseStore
+	mu      sync.Mutex
+	streams map[int64]*schedule
+}
+
+type schedule struct {
+	calendar *Calendar
+	at       time.Time
+}
+
+func NewScheduler(hub *Hub, leases LeaseStore) *Scheduler {
+	return &Scheduler{
+		hub:     hub,
+		leases:  leases,
+		streams: make(map[int64]*schedule),
+	}
+}
+
+// Schedule follows the calendar for the stream from the time from on.
+func (s *Scheduler) Schedule(streamID int64, calendar *Calendar, from time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams[streamID] = &schedule{calendar: calendar, at: from}
+}
+
+func (s *Scheduler) Unschedule(streamID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, streamID)
+}
+
+// IsOpen reports whether the stream is open at t.
+func (s *Scheduler) IsOpen(streamID int64, t time.Time) (bool, error) {
+	calendar, err := s.calendar(streamID)
+	if err != nil {
+		return false, err
+	}
+	return calendar.IsOpen(t), nil
+}
+
+// NextOpen returns when the stream opens at or after t. See Calendar.NextOpen.
+func (s *Scheduler) NextOpen(streamID int64, t time.Time) (time.Time, bool, error) {
+	calendar, err := s.calendar(streamID)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	open, ok := calendar.NextOpen(t)
+	return open, ok, nil
+}
+
+func (s *Scheduler) calendar(streamID int64) (*Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheduled, ok := s.streams[streamID]
+	if !ok {
+		return nil, fmt.Errorf("stream %d: %w", streamID, ErrNotScheduled)
+	}
+	return scheduled.calendar, nil
+}
+
+// Advance publishes the messages of every session boundary passed since the previous
+// call, in order.
+func (s *Scheduler) Advance(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for streamID, scheduled := range s.streams {
+		if !t.After(scheduled.at) {
+			continue
+		}
+		sessions := scheduled.calendar.sessions
+		for i := scheduled.calendar.search(scheduled.at); i < len(sessions); i++ {
+			session := sessions[i]
+			if session.Open.After(t) {
+				break
+			}
+			if session.Open.After(scheduled.at) {
+				s.started(streamID, session)
+			}
+			if session.Close.After(t) {
+				break
+			}
+			var next time.Time
+			if i+1 < len(sessions) {
+				next = sessions[i+1].Open
+			}
+			s.stopped(streamID, session.Close, next)
+		}
+		scheduled.at = t
+	}
+}
+
+// Next returns the earliest session boundary after t of all the scheduled streams.
+func (s *Scheduler) Next(t time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, scheduled := range s.streams {
+		sessions := scheduled.calendar.sessions
+		i := scheduled.calendar.search(t)
+		if i == len(sessions) {
+			continue
+		}
+		boundary := sessions[i].Close
+		if sessions[i].Open.After(t) {
+			boundary = sessions[i].Open
+		}
+		if next.IsZero() || boundary.Before(next) {
+			next = boundary
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Run advances the scheduler at every session boundary until stop is closed.
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		t := time.Now()
+		s.Advance(t)
+		next, ok := s.Next(t)
+		wait := time.Minute
+		if ok && next.Sub(t) < wait {
+			wait = next.Sub(t)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) lease(streamID int64) Lease {
+	lease, _ := s.leases.Lease(streamID)
+	lease.Head.Mut().SetStreamID(streamID)
+	return lease
+}
+
+func (s *Scheduler) started(streamID int64, session Session) {
+	lease := s.lease(streamID)
+	var started Started
+	started.Mut().
+		SetRecordID(&lease.Head).
+		SetTimestamp(session.Open.UnixNano()).
+		SetWriterID(lease.WriterID).
+		SetStops(session.Close.UnixNano())
+	s.hub.Publish(streamID, &Message{Type: MessageType_Started, Started: &started})
+}
+
+func (s *Scheduler) stopped(streamID int64, close, next time.Time) {
+	lease := s.lease(streamID)
+	var starts int64
+	if !next.IsZero() {
+		starts = next.UnixNano()
+	}
+	var stopped Stopped
+	stopped.Mut().
+		SetRecordID(&lease.Head).
+		SetTimestamp(close.UnixNano()).
+		SetStarts(starts).
+		SetReason(StopReason_Paused)
+	s.hub.Publish(streamID, &Message{Type: MessageType_Stopped, Stopped: &stopped})
+}
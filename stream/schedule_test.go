@@ -0,0 +1,152 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.July, day, hour, minute, 0, 0, newYork)
+	}
+	// Wednesday July 3rd to Monday July 8th, closed on the 4th.
+	weekly := &Weekly{
+		Location: newYork,
+		Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Open:     9*time.Hour + 30*time.Minute,
+		Close:    16 * time.Hour,
+		Holidays: []time.Time{at(4, 0, 0)},
+	}
+	calendar, err := NewCalendar(weekly.Sessions(at(3, 0, 0), at(8, 0, 0))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(calendar.Sessions()); n != 3 {
+		t.Fatalf("%d sessions", n)
+	}
+	if !calendar.IsOpen(at(3, 9, 30)) || calendar.IsOpen(at(3, 16, 0)) || calendar.IsOpen(at(4, 12, 0)) {
+		t.Fatal("IsOpen")
+	}
+	for _, test := range []struct {
+		t, open time.Time
+	}{
+		{at(3, 8, 0), at(3, 9, 30)},
+		{at(3, 12, 0), at(3, 12, 0)},
+		{at(3, 16, 0), at(5, 9, 30)},
+		{at(5, 17, 0), at(8, 9, 30)},
+	} {
+		if open, ok := calendar.NextOpen(test.t); !ok || !open.Equal(test.open) {
+			t.Fatalf("NextOpen(%s) = %s", test.t, open)
+		}
+	}
+	if _, ok := calendar.NextOpen(at(8, 16, 0)); ok {
+		t.Fatal("NextOpen after the last session")
+	}
+
+	// Hourly slots resume at the next opening instead of running through the night.
+	for _, test := range []struct {
+		t, slot time.Time
+	}{
+		{at(3, 9, 30), at(3, 9, 30)},
+		{at(3, 9, 31), at(3, 10, 30)},
+		{at(3, 15, 31), at(5, 9, 30)},
+		{at(3, 15, 29), at(3, 15, 30)},
+	} {
+		if slot, ok := calendar.NextSlot(test.t, time.Hour); !ok || !slot.Equal(test.slot) {
+			t.Fatalf("NextSlot(%s) = %s", test.t, slot)
+		}
+	}
+
+	if _, err = NewCalendar(Session{at(3, 9, 0), at(3, 12, 0)}, Session{at(3, 11, 0), at(3, 13, 0)}); err == nil {
+		t.Fatal("overlapping sessions accepted")
+	}
+}
+
+func TestWeeklyDaylightSaving(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Futures open Sunday at 17:00 and close Monday at 16:00. The clocks moved forward
+	// on Sunday March 8th 2026 and back on Sunday November 1st 2026.
+	weekly := &Weekly{
+		Location: chicago,
+		Days:     []time.Weekday{time.Sunday},
+		Open:     17 * time.Hour,
+		Close:    40 * time.Hour,
+	}
+	for _, day := range []int{1, 8} {
+		for _, month := range []time.Month{time.March, time.November} {
+			date := time.Date(2026, month, day, 0, 0, 0, 0, chicago)
+			sessions := weekly.Sessions(date, date)
+			if len(sessions) != 1 {
+				t.Fatalf("%s: %d sessions", date, len(sessions))
+			}
+			open := time.Date(2026, month, day, 17, 0, 0, 0, chicago)
+			closes := time.Date(2026, month, day+1, 16, 0, 0, 0, chicago)
+			if s := sessions[0]; !s.Open.Equal(open) || !s.Close.Equal(closes) {
+				t.Fatalf("session = %s - %s, expected %s - %s", s.Open, s.Close, open, closes)
+			}
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	day := func(hour int) time.Time {
+		return time.Date(2024, time.January, 2, hour, 0, 0, 0, time.UTC)
+	}
+	calendar, err := NewCalendar(Session{day(9), day(12)}, Session{day(13), day(17)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewHub()
+	leases := NewMemoryLeaseStore()
+	w, err := ClaimWriter(leases, hub, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []*Message
+	hub.Subscribe(4, func(m *Message) {
+		messages = append(messages, m)
+	})
+	if _, err = w.Append(day(8).UnixNano(), []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScheduler(hub, leases)
+	if _, _, err = s.NextOpen(4, day(8)); err == nil {
+		t.Fatal("NextOpen of unscheduled stream")
+	}
+	s.Schedule(4, calendar, day(8))
+	if next, ok := s.Next(day(8)); !ok || !next.Equal(day(9)) {
+		t.Fatalf("Next = %s", next)
+	}
+	s.Advance(day(10))
+	s.Advance(day(10))
+	s.Advance(day(18))
+	if open, ok, _ := s.NextOpen(4, day(12)); !ok || !open.Equal(day(13)) {
+		t.Fatalf("NextOpen = %s", open)
+	}
+
+	messages = messages[1:]
+	if len(messages) != 4 {
+		t.Fatalf("%d messages", len(messages))
+	}
+	started := messages[0].Started
+	if started == nil || started.Timestamp() != day(9).UnixNano() || started.Stops() != day(12).UnixNano() ||
+		started.WriterID() != 1 || started.RecordID().Id() != 1 {
+		t.Fatalf("Started = %+v", messages[0])
+	}
+	stopped := messages[1].Stopped
+	if stopped == nil || stopped.Timestamp() != day(12).UnixNano() || stopped.Starts() != day(13).UnixNano() ||
+		stopped.Reason() != StopReason_Paused {
+		t.Fatalf("Stopped = %+v", messages[1])
+	}
+	if messages[2].Started == nil || messages[3].Stopped == nil || messages[3].Stopped.Starts() != 0 {
+		t.Fatalf("last session = %+v, %+v", messages[2], messages[3])
+	}
+}
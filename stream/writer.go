@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var ErrStaleWriter = errors.New("stale writer")
+
+// Lease is the ownership of a stream by a writer. Writer IDs act as fencing tokens: a
+// stream is only ever claimed by increasing writer IDs, so an ID doubles as the epoch
+// of its writer.
+type Lease struct {
+	StreamID int64
+	WriterID int64
+	// Head is the ID of the last record appended to the stream.
+	Head RecordID
+}
+
+// LeaseStore keeps the lease of every stream and fences the appends of writers that
+// lost it.
+type LeaseStore interface {
+	// Claim gives the stream to writerID and returns the previous lease. It fails with
+	// ErrStaleWriter unless writerID is higher than the ID of the current owner.
+	Claim(streamID, writerID int64) (previous Lease, err error)
+	// Advance moves the head of the stream to id. It fails with ErrStaleWriter when
+	// writerID does not own the stream.
+	Advance(streamID, writerID int64, id *RecordID) error
+	// Lease returns the current lease of the stream.
+	Lease(streamID int64) (Lease, bool)
+}
+
+// MemoryLeaseStore is a LeaseStore local to the process.
+type MemoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[int64]Lease
+}
+
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{leases: make(map[int64]Lease)}
+}
+
+func (s *MemoryLeaseStore) Claim(streamID, writerID int64) (Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.leases[streamID]
+	if writerID <= previous.WriterID {
+		return previous, fmt.Errorf("stream %d: writer %d is not above writer %d: %w",
+			streamID, writerID, previous.WriterID, ErrStaleWriter)
+	}
+	lease := previous
+	lease.StreamID = streamID
+	lease.WriterID = writerID
+	s.leases[streamID] = lease
+	return previous, nil
+}
+
+func (s *MemoryLeaseStore) Advance(streamID, writerID int64, id *RecordID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lease, ok := s.leases[streamID]
+	if !ok || lease.WriterID != writerID {
+		return fmt.Errorf("stream %d: writer %d does not own the stream: %w",
+			streamID, writerID, ErrStaleWriter)
+	}
+	lease.Head = *id
+	s.leases[streamID] = lease
+	return nil
+}
+
+func (s *MemoryLeaseStore) Lease(streamID int64) (Lease, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lease, ok := s.leases[streamID]
+	return lease, ok
+}
+
+// Writer appends the records of a stream it holds the lease of.
+type Writer struct {
+	streamID int64
+	writerID int64
+	store    LeaseStore
+	hub      *Hub
+	fence    *sync.Mutex
+	mu       sync.Mutex
+	head     RecordID
+	last     RecordHeader
+}
+
+// ClaimWriter claims the stream for writerID and announces it to the readers. When the
+// stream had another writer the readers first see Stopped with StopReason_Migrate, then
+// Started from the new writer. The claim and its announcement are ordered with the
+// appends of writers sharing hub, so none of their records follows the new Started.
+func ClaimWriter(store LeaseStore, hub *Hub, streamID, writerID int64) (*Writer, error) {
+	fence := hub.fence(streamID)
+	fence.Lock()
+	defer fence.Unlock()
+	previous, err := store.Claim(streamID, writerID)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		streamID: streamID,
+		writerID: writerID,
+		store:    store,
+		hub:      hub,
+		fence:    fence,
+		head:     previous.Head,
+	}
+	w.head.Mut().SetStreamID(streamID)
+	w.last.Mut().
+		SetStreamID(streamID).
+		SetBlockID(w.head.BlockID()).
+		SetId(w.head.Id())
+	timestamp := now()
+	if previous.WriterID != 0 {
+		var stopped Stopped
+		stopped.Mut().
+			SetRecordID(&w.head).
+			SetTimestamp(timestamp).
+			SetStarts(timestamp).
+			SetReason(StopReason_Migrate)
+		hub.Publish(streamID, &Message{Type: MessageType_Stopped, Stopped: &stopped})
+	}
+	var started Started
+	started.Mut().
+		SetRecordID(&w.head).
+		SetTimestamp(timestamp).
+		SetWriterID(writerID)
+	hub.Publish(streamID, &Message{Type: MessageType_Started, Started: &started})
+	return w, nil
+}
+
+func (w *Writer) StreamID() int64 {
+	return w.streamID
+}
+
+func (w *Writer) WriterID() int64 {
+	return w.writerID
+}
+
+// Append appends a record to the stream and publishes it. It fails with ErrStaleWriter
+// once another writer claimed the stream.
+func (w *Writer) Append(timestamp int64, data []byte) (*RecordHeader, error) {
+	if len(data) > math.MaxUint16 {
+		return nil, fmt.Errorf("stream %d: record of %d bytes exceeds %d", w.streamID, len(data), math.MaxUint16)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fence.Lock()
+	defer w.fence.Unlock()
+	id := w.head
+	id.Mut().SetId(w.head.Id() + 1)
+	if err := w.store.Advance(w.streamID, w.writerID, &id); err != nil {
+		return nil, err
+	}
+	w.head = id
+	var header RecordHeader
+	header.Mut().
+		SetStreamID(id.StreamID()).
+		SetBlockID(id.BlockID()).
+		SetId(id.Id()).
+		SetTimestamp(timestamp).
+		SetSize(uint16(len(data)))
+	w.last = header
+	w.hub.Publish(w.streamID, &Message{Type: MessageType_Record, Record: &header, Data: data})
+	return &header, nil
+}
+
+// Savepoint returns the Savepoint after the last record appended by the writer.
+func (w *Writer) Savepoint() *Savepoint {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return SavepointOf(&w.last, w.writerID)
+}
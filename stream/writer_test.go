@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWriterMigrate(t *testing.T) {
+	store := NewMemoryLeaseStore()
+	hub := NewHub()
+	var messages []*Message
+	cancel := hub.Subscribe(5, func(m *Message) {
+		messages = append(messages, m)
+	})
+	defer cancel()
+
+	w1, err := ClaimWriter(store, hub, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w1.Append(100, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w1.Append(200, []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ClaimWriter(store, hub, 5, 1); !errors.Is(err, ErrStaleWriter) {
+		t.Fatalf("claim with the same writer = %v", err)
+	}
+
+	w2, err := ClaimWriter(store, hub, 5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w1.Append(300, []byte("stale")); !errors.Is(err, ErrStaleWriter) {
+		t.Fatalf("stale Append = %v", err)
+	}
+	record, err := w2.Append(300, []byte("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Id() != 3 || record.StreamID() != 5 {
+		t.Fatalf("record = %s", record.String())
+	}
+	if s := w2.Savepoint(); s.WriterID() != 2 || s.RecordID().Id() != 3 || s.Timestamp() != 300 {
+		t.Fatalf("Savepoint = %s", s.String())
+	}
+
+	expected := []MessageType{
+		MessageType_Started,
+		MessageType_Record,
+		MessageType_Record,
+		MessageType_Stopped,
+		MessageType_Started,
+		MessageType_Record,
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("%d messages, expected %d", len(messages), len(expected))
+	}
+	for i, m := range messages {
+		if m.Type != expected[i] {
+			t.Fatalf("message %d is %d, expected %d", i, m.Type, expected[i])
+		}
+	}
+	if stopped := messages[3].Stopped; stopped.Reason() != StopReason_Migrate || stopped.RecordID().Id() != 2 {
+		t.Fatalf("Stopped = %s", stopped.String())
+	}
+	if started := messages[4].Started; started.WriterID() != 2 || started.RecordID().Id() != 2 {
+		t.Fatalf("Started = %s", started.String())
+	}
+	if string(messages[5].Data) != "c" {
+		t.Fatalf("record data = %q", messages[5].Data)
+	}
+	if lease, _ := store.Lease(5); lease.WriterID != 2 || lease.Head.Id() != 3 {
+		t.Fatalf("lease = %+v", lease)
+	}
+}
+
+// hookStore calls advanced after each successful Advance.
+type hookStore struct {
+	*MemoryLeaseStore
+	advanced func()
+}
+
+func (s *hookStore) Advance(streamID, writerID int64, id *RecordID) error {
+	if err := s.MemoryLeaseStore.Advance(streamID, writerID, id); err != nil {
+		return err
+	}
+	if s.advanced != nil {
+		s.advanced()
+	}
+	return nil
+}
+
+func TestWriterClaimDuringAppend(t *testing.T) {
+	store := &hookStore{MemoryLeaseStore: NewMemoryLeaseStore()}
+	hub := NewHub()
+	var messages []*Message
+	cancel := hub.Subscribe(5, func(m *Message) {
+		messages = append(messages, m)
+	})
+	defer cancel()
+
+	w1, err := ClaimWriter(store, hub, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second writer claims the stream after the append advanced the lease but before
+	// it was published. The claim must wait for the record to be published.
+	claimed := make(chan error, 1)
+	store.advanced = func() {
+		store.advanced = nil
+		go func() {
+			_, err := ClaimWriter(store, hub, 5, 2)
+			claimed <- err
+		}()
+		select {
+		case err := <-claimed:
+			claimed <- err
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	if _, err = w1.Append(100, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-claimed; err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []MessageType{
+		MessageType_Started,
+		MessageType_Record,
+		MessageType_Stopped,
+		MessageType_Started,
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("%d messages, expected %d", len(messages), len(expected))
+	}
+	for i, m := range messages {
+		if m.Type != expected[i] {
+			t.Fatalf("message %d is %d, expected %d", i, m.Type, expected[i])
+		}
+	}
+	if stopped := messages[2].Stopped; stopped.RecordID().Id() != 1 {
+		t.Fatalf("Stopped = %s", stopped.String())
+	}
+}
@@ -0,0 +1,54 @@
+package wap
+
+import (
+	"encoding/binary"
+	"errors"
+	"unsafe"
+)
+
+var (
+	ErrOutOfBounds   = errors.New("out of bounds")
+	ErrMisaligned    = errors.New("misaligned")
+	ErrInvalidEnum   = errors.New("invalid enum value")
+	ErrInvalidLength = errors.New("invalid length")
+)
+
+// VerifyVSlice is VerifyVPointer for a slice written by Mutable.WriteSlice whose elements
+// have the given alignment. It returns the offset of the elements and their size in bytes
+// and also checks the elements are aligned.
+func VerifyVSlice(b []byte, offset, align int) (int, int, error) {
+	p, n, err := verifyVPointer(b, offset, int(SliceHeader(int32(align))))
+	if err != nil || n == 0 {
+		return p, n, err
+	}
+	if uintptr(unsafe.Pointer(&b[p]))%uintptr(align) != 0 {
+		return 0, 0, ErrMisaligned
+	}
+	return p, n, nil
+}
+
+// VerifyVPointer checks the VPointer stored at offset in b and the length prefixed data it
+// points to are both within b. It returns the offset of the data and its length in bytes.
+// A nil VPointer returns a length of 0.
+func VerifyVPointer(b []byte, offset int) (int, int, error) {
+	return verifyVPointer(b, offset, 4)
+}
+
+func verifyVPointer(b []byte, offset, header int) (int, int, error) {
+	if offset < 0 || offset+4 > len(b) {
+		return 0, 0, ErrOutOfBounds
+	}
+	vp := int(int32(binary.LittleEndian.Uint32(b[offset:])))
+	if vp == 0 {
+		return 0, 0, nil
+	}
+	target := offset + vp
+	if target < 0 || target+header > len(b) {
+		return 0, 0, ErrOutOfBounds
+	}
+	n := int(int32(binary.LittleEndian.Uint32(b[target:])))
+	if n < 0 || target+header+n > len(b) {
+		return 0, 0, ErrOutOfBounds
+	}
+	return target + header, n, nil
+}
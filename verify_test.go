@@ -0,0 +1,58 @@
+package wap
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestVerifyVPointer(t *testing.T) {
+	b := make([]byte, 16)
+	if _, n, err := VerifyVPointer(b, 0); err != nil || n != 0 {
+		t.Fatalf("nil pointer: n = %d, err = %v", n, err)
+	}
+
+	// Pointer at 0 to a 3 byte slab at 8
+	binary.LittleEndian.PutUint32(b[0:], 8)
+	binary.LittleEndian.PutUint32(b[8:], 3)
+	p, n, err := VerifyVPointer(b, 0)
+	if err != nil || p != 12 || n != 3 {
+		t.Fatalf("p = %d, n = %d, err = %v", p, n, err)
+	}
+
+	// Length runs past the end
+	binary.LittleEndian.PutUint32(b[8:], 5)
+	if _, _, err = VerifyVPointer(b, 0); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+
+	// Pointer before the start
+	binary.LittleEndian.PutUint32(b[4:], uint32(0xFFFFFFF0))
+	if _, _, err = VerifyVPointer(b, 4); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+
+	// Pointer itself outside of b
+	if _, _, err = VerifyVPointer(b, 14); !errors.Is(err, ErrOutOfBounds) {
+		t.Fatalf("expected ErrOutOfBounds, got %v", err)
+	}
+}
+
+func TestVerifyVSlice(t *testing.T) {
+	b := make([]byte, 32)
+
+	// Pointer at 0 to a slice of one int64 at 8, following its padded header.
+	binary.LittleEndian.PutUint32(b[0:], 8)
+	binary.LittleEndian.PutUint32(b[8:], 8)
+	p, n, err := VerifyVSlice(b, 0, 8)
+	if err != nil || p != 16 || n != 8 {
+		t.Fatalf("p = %d, n = %d, err = %v", p, n, err)
+	}
+
+	// The same slice at 4 leaves the int64 misaligned.
+	binary.LittleEndian.PutUint32(b[0:], 4)
+	binary.LittleEndian.PutUint32(b[4:], 8)
+	if _, _, err = VerifyVSlice(b, 0, 8); !errors.Is(err, ErrMisaligned) {
+		t.Fatalf("expected ErrMisaligned, got %v", err)
+	}
+}